@@ -37,12 +37,16 @@ type PoetConfig struct {
 	GracePeriod       time.Duration `mapstructure:"grace-period"`
 	RequestRetryDelay time.Duration `mapstructure:"retry-delay"`
 	MaxRequestRetries int           `mapstructure:"retry-max"`
+	// MaxMissedProofs is the number of consecutive rounds in which poet failed to deliver proof
+	// before it is deprioritised. Poets are never deprioritised if zero.
+	MaxMissedProofs int `mapstructure:"max-missed-proofs"`
 }
 
 func DefaultPoetConfig() PoetConfig {
 	return PoetConfig{
 		RequestRetryDelay: 400 * time.Millisecond,
 		MaxRequestRetries: 10,
+		MaxMissedProofs:   2,
 	}
 }
 
@@ -91,6 +95,7 @@ type Builder struct {
 
 	// pendingATX is created with current commitment and nipost from current challenge.
 	pendingATX            *types.ActivationTx
+	publish               publishTracker
 	layerClock            layerClock
 	syncer                syncer
 	log                   log.Logger
@@ -99,13 +104,15 @@ type Builder struct {
 	poetCfg               PoetConfig
 	poetRetryInterval     time.Duration
 	poetClientInitializer PoETClientInitializer
+	clockOffset           clockOffset
+	maxClockOffset        time.Duration
 }
 
 // BuilderOption ...
 type BuilderOption func(*Builder)
 
 // WithPoetRetryInterval modifies time that builder will have to wait before retrying ATX build process
-// if it failed due to issues with PoET server.
+// if it failed due to issues with PoET server. The interval doubles with every failed attempt, see PublishSchedule.
 func WithPoetRetryInterval(interval time.Duration) BuilderOption {
 	return func(b *Builder) {
 		b.poetRetryInterval = interval
@@ -122,6 +129,13 @@ func WithPoETClientInitializer(initializer PoETClientInitializer) BuilderOption
 	}
 }
 
+// WithPoetRegistry creates PoET clients for the updated servers with the registry.
+func WithPoetRegistry(registry *PoetRegistry) BuilderOption {
+	return func(b *Builder) {
+		b.poetClientInitializer = registry.NewClient
+	}
+}
+
 // WithContext modifies parent context for background job.
 func WithContext(ctx context.Context) BuilderOption {
 	return func(b *Builder) {
@@ -136,6 +150,14 @@ func WithPoetConfig(c PoetConfig) BuilderOption {
 	}
 }
 
+// WithClockOffset enables the check of the system clock in Preflight.
+func WithClockOffset(source clockOffset, max time.Duration) BuilderOption {
+	return func(b *Builder) {
+		b.clockOffset = source
+		b.maxClockOffset = max
+	}
+}
+
 func WithValidator(v nipostValidator) BuilderOption {
 	return func(b *Builder) {
 		b.validator = v
@@ -174,7 +196,7 @@ func NewBuilder(
 		started:               atomic.NewBool(false),
 		log:                   log,
 		poetRetryInterval:     defaultPoetRetryInterval,
-		poetClientInitializer: defaultPoetClientFunc,
+		poetClientInitializer: NewPoetRegistry(log.Zap().Named("poet")).NewClient,
 	}
 	for _, opt := range opts {
 		opt(b)
@@ -272,6 +294,10 @@ func (b *Builder) StopSmeshing(deleteFiles bool) error {
 			b.log.With().Error("failed to delete post", log.Err(err))
 			return err
 		}
+		if err := discardPublishState(b.nipostBuilder.DataDir()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			b.log.With().Error("failed to delete atx publish state", log.Err(err))
+			return err
+		}
 
 		return nil
 	default:
@@ -365,6 +391,7 @@ func (b *Builder) receivePendingPoetClients() *[]PoetProvingServiceClient {
 func (b *Builder) loop(ctx context.Context) {
 	defer b.log.Info("atx builder stopped")
 
+	b.restorePublishState()
 	for {
 		if poetClients := b.receivePendingPoetClients(); poetClients != nil {
 			b.nipostBuilder.UpdatePoETProvers(*poetClients)
@@ -378,14 +405,29 @@ func (b *Builder) loop(ctx context.Context) {
 			return
 		}
 
-		b.log.WithContext(ctx).With().Warning("failed to publish atx",
-			b.layerClock.CurrentLayer(),
-			b.currentEpoch(),
-			log.Err(err),
-		)
+		state := b.failPublishPhase(err)
+		phase := PublishPhase(state.Phase)
+		if phase.done() {
+			// the publish epoch was completed before, the builder waits for the next one
+			b.log.WithContext(ctx).With().Debug("failed to publish atx",
+				b.layerClock.CurrentLayer(),
+				log.Stringer("publish_epoch", state.PublishEpoch),
+				log.Stringer("phase", phase),
+				log.Err(err),
+			)
+		} else {
+			b.log.WithContext(ctx).With().Warning("failed to publish atx",
+				b.layerClock.CurrentLayer(),
+				b.currentEpoch(),
+				log.Err(err),
+			)
+		}
+		schedule := b.schedule(state.PublishEpoch)
+		delay, retry := schedule.retryDelay(phase, state.Attempts, b.poetRetryInterval, time.Now())
 
 		switch {
-		case errors.Is(err, ErrATXChallengeExpired):
+		case errors.Is(err, ErrATXChallengeExpired) || !retry:
+			b.missPublishEpoch(state)
 			b.log.WithContext(ctx).Debug("retrying with new challenge after waiting for a layer")
 			if err = b.discardChallenge(); err != nil {
 				b.log.WithContext(ctx).Error("failed to discard challenge", log.Err(err))
@@ -398,11 +440,15 @@ func (b *Builder) loop(ctx context.Context) {
 			case <-b.layerClock.AwaitLayer(currentLayer.Add(1)):
 			}
 		case errors.Is(err, ErrPoetServiceUnstable):
-			b.log.WithContext(ctx).With().Warning("retrying after poet retry interval", log.Duration("interval", b.poetRetryInterval))
+			b.log.WithContext(ctx).With().Warning("retrying after poet retry interval",
+				log.Duration("interval", delay),
+				log.Stringer("phase", phase),
+				log.Time("deadline", schedule.Deadline(phase)),
+			)
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(b.poetRetryInterval):
+			case <-time.After(delay):
 			}
 		default:
 			b.log.WithContext(ctx).With().Warning("unknown error", log.Err(err))
@@ -423,16 +469,12 @@ func (b *Builder) buildNIPostChallenge(ctx context.Context) (*types.NIPostChalle
 		return nil, ctx.Err()
 	case <-b.syncer.RegisterForATXSynced():
 	}
-	current := b.currentEpoch()
-	prev, err := b.cdb.GetLastAtx(b.nodeID)
+	current, err := b.challengeEpoch()
 	if err != nil {
-		if !errors.Is(err, sql.ErrNotFound) {
-			return nil, err
-		}
-	} else if prev.PublishEpoch == current+1 {
-		current += 1
+		return nil, err
 	}
 
+	b.setPublishPhase(current+1, PublishPhaseWait)
 	until := time.Until(b.poetRoundStart(current))
 	if until <= 0 {
 		metrics.PublishLateWindowLatency.Observe(-until.Seconds())
@@ -457,13 +499,42 @@ func (b *Builder) buildNIPostChallenge(ctx context.Context) (*types.NIPostChalle
 		}
 	}
 
+	b.setPublishPhase(current+1, PublishPhaseChallenge)
+	challenge, err := b.newChallenge(current + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err = SaveNipostChallenge(b.nipostBuilder.DataDir(), challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// challengeEpoch returns the epoch in which the challenge for the next atx is submitted to poets.
+// It is the current epoch, unless atx for the next epoch was already published.
+func (b *Builder) challengeEpoch() (types.EpochID, error) {
+	current := b.currentEpoch()
+	prev, err := b.cdb.GetLastAtx(b.nodeID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNotFound) {
+			return 0, err
+		}
+	} else if prev.PublishEpoch == current+1 {
+		current += 1
+	}
+	return current, nil
+}
+
+// newChallenge selects positioning atx and links the challenge to the previous atx of the node,
+// or to the commitment atx and the initial post if the node didn't publish an atx yet.
+func (b *Builder) newChallenge(publish types.EpochID) (*types.NIPostChallenge, error) {
 	posAtx, err := b.GetPositioningAtx()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get positioning ATX: %w", err)
 	}
 
 	challenge := &types.NIPostChallenge{
-		PublishEpoch:   current + 1,
+		PublishEpoch:   publish,
 		PositioningATX: posAtx,
 	}
 
@@ -478,14 +549,11 @@ func (b *Builder) buildNIPostChallenge(ctx context.Context) (*types.NIPostChalle
 		challenge.PrevATXID = prevAtx.ID
 		challenge.Sequence = prevAtx.Sequence + 1
 	}
-
-	if err = SaveNipostChallenge(b.nipostBuilder.DataDir(), challenge); err != nil {
-		return nil, err
-	}
 	return challenge, nil
 }
 
 // UpdatePoETServers updates poet client. Context is used to verify that the target is responsive.
+// Transport of the client is chosen by the scheme of the endpoint, see PoetRegistry.
 func (b *Builder) UpdatePoETServers(ctx context.Context, endpoints []string) error {
 	b.log.WithContext(ctx).With().Debug("request to update poet services",
 		log.Array("endpoints", log.ArrayMarshalerFunc(func(encoder log.ArrayEncoder) error {
@@ -585,6 +653,7 @@ func (b *Builder) PublishActivationTx(ctx context.Context) error {
 	}
 
 	atx := b.pendingATX
+	b.setPublishPhase(atx.PublishEpoch, PublishPhaseBroadcast)
 	atxReceived := b.atxHandler.AwaitAtx(atx.ID())
 	defer b.atxHandler.UnsubscribeAtx(atx.ID())
 	size, err := b.broadcast(ctx, atx)
@@ -603,6 +672,7 @@ func (b *Builder) PublishActivationTx(ctx context.Context) error {
 	select {
 	case <-atxReceived:
 		logger.With().Info("received atx in db", atx.ID())
+		b.setPublishPhase(atx.PublishEpoch, PublishPhasePublished)
 		if err := b.discardChallenge(); err != nil {
 			return fmt.Errorf("%w: after published atx", err)
 		}
@@ -624,10 +694,12 @@ func (b *Builder) poetRoundStart(epoch types.EpochID) time.Time {
 func (b *Builder) createAtx(ctx context.Context, challenge *types.NIPostChallenge) (*types.ActivationTx, error) {
 	pubEpoch := challenge.PublishEpoch
 
+	b.startNIPost(challenge)
 	nipost, err := b.nipostBuilder.BuildNIPost(ctx, challenge)
 	if err != nil {
 		return nil, fmt.Errorf("build NIPost: %w", err)
 	}
+	b.setPublishPhase(pubEpoch, PublishPhaseAwaitEpoch)
 
 	b.log.With().Info("awaiting atx publication epoch",
 		log.Stringer("pub_epoch", pubEpoch),
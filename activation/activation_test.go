@@ -1225,7 +1225,6 @@ func TestBuilder_UpdatePoetsUnstable(t *testing.T) {
 }
 
 func TestWaitPositioningAtx(t *testing.T) {
-	genesis := time.Now()
 	for _, tc := range []struct {
 		desc         string
 		shift, grace time.Duration
@@ -1238,6 +1237,7 @@ func TestWaitPositioningAtx(t *testing.T) {
 	} {
 		tc := tc
 		t.Run(tc.desc, func(t *testing.T) {
+			genesis := time.Now()
 			tab := newTestBuilder(t, WithPoetConfig(PoetConfig{
 				PhaseShift:  tc.shift,
 				GracePeriod: tc.grace,
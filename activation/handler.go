@@ -7,13 +7,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/spacemeshos/post/config"
 	"github.com/spacemeshos/post/shared"
 	"golang.org/x/exp/maps"
 
+	atxmetrics "github.com/spacemeshos/go-spacemesh/activation/metrics"
 	"github.com/spacemeshos/go-spacemesh/codec"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/datastore"
 	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/hash"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/metrics"
 	"github.com/spacemeshos/go-spacemesh/p2p"
@@ -22,6 +25,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/verifiedposts"
 	"github.com/spacemeshos/go-spacemesh/system"
 )
 
@@ -54,6 +58,45 @@ type Handler struct {
 	atxChannels     map[types.ATXID]*atxChan
 	fetcher         system.Fetcher
 	poetCfg         PoetConfig
+
+	// verifiedPosts is set if PoST verification is skipped for atxs verified before.
+	verifiedPosts *verifiedPostsCache
+}
+
+type verifiedPostsCache struct {
+	params   types.Hash32
+	reverify bool
+}
+
+// HandlerOption configures Handler.
+type HandlerOption func(*Handler)
+
+// WithVerifiedPosts records atxs with valid PoST in the database, and skips verification of PoST
+// that was verified before with the same cfg, such as when atxs are synced again after restart
+// or recovery from checkpoint. If reverify is true, PoST is always verified and records are updated.
+func WithVerifiedPosts(cfg PostConfig, reverify bool) HandlerOption {
+	return func(h *Handler) {
+		h.verifiedPosts = &verifiedPostsCache{params: postVerificationParams(cfg), reverify: reverify}
+	}
+}
+
+// postVerificationParams is the hash of the parameters that affect the result of PoST verification.
+func postVerificationParams(cfg PostConfig) types.Hash32 {
+	scrypt := config.DefaultLabelParams()
+	return hash.Sum([]byte(fmt.Sprintf("%d/%d/%d/%d/%d/%d/%x/%d/%d/%d",
+		cfg.MinNumUnits, cfg.MaxNumUnits, cfg.LabelsPerUnit,
+		cfg.K1, cfg.K2, cfg.K3, cfg.PowDifficulty[:],
+		scrypt.N, scrypt.R, scrypt.P,
+	)))
+}
+
+// postProofHash is the hash of PoSTs included in the atx.
+func postProofHash(atx *types.ActivationTx) types.Hash32 {
+	chunks := [][]byte{codec.MustEncode(atx.NIPost)}
+	if atx.InitialPost != nil {
+		chunks = append(chunks, codec.MustEncode(atx.InitialPost))
+	}
+	return hash.Sum(chunks...)
 }
 
 // NewHandler returns a data handler for ATX.
@@ -71,8 +114,9 @@ func NewHandler(
 	tortoise system.Tortoise,
 	log log.Log,
 	poetCfg PoetConfig,
+	opts ...HandlerOption,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		cdb:             cdb,
 		edVerifier:      edVerifier,
 		clock:           c,
@@ -88,6 +132,40 @@ func NewHandler(
 		tortoise:        tortoise,
 		poetCfg:         poetCfg,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// postVerified returns true if PoST of the atx was verified before.
+func (h *Handler) postVerified(atx *types.ActivationTx) bool {
+	if h.verifiedPosts == nil || h.verifiedPosts.reverify {
+		return false
+	}
+	verified, err := verifiedposts.Has(h.cdb, verifiedposts.Record{
+		Atx:    atx.ID(),
+		Proof:  postProofHash(atx),
+		Params: h.verifiedPosts.params,
+	})
+	if err != nil {
+		h.log.With().Warning("failed to check whether post was verified", atx.ID(), log.Err(err))
+		return false
+	}
+	return verified
+}
+
+func (h *Handler) recordVerifiedPost(atx *types.ActivationTx) {
+	if h.verifiedPosts == nil {
+		return
+	}
+	if err := verifiedposts.Add(h.cdb, verifiedposts.Record{
+		Atx:    atx.ID(),
+		Proof:  postProofHash(atx),
+		Params: h.verifiedPosts.params,
+	}); err != nil {
+		h.log.With().Warning("failed to record verified post", atx.ID(), log.Err(err))
+	}
 }
 
 var closedChan = make(chan struct{})
@@ -188,8 +266,14 @@ func (h *Handler) SyntacticallyValidateAtx(ctx context.Context, atx *types.Activ
 		return nil, fmt.Errorf("atx publish epoch is too far in the future: %d > %d", atx.PublishEpoch, current.GetEpoch()+1)
 	}
 
+	postVerified := h.postVerified(atx)
+	if postVerified {
+		atxmetrics.VerifiedPostsSkipped.Inc()
+		h.log.WithContext(ctx).With().Debug("post was verified before", atx.ID())
+	}
+
 	if atx.PrevATXID == types.EmptyATXID {
-		if err := h.validateInitialAtx(ctx, atx); err != nil {
+		if err := h.validateInitialAtx(ctx, atx, postVerified); err != nil {
 			return nil, err
 		}
 		commitmentATX = atx.CommitmentATX // validateInitialAtx checks that commitmentATX is not nil and references an existing valid ATX
@@ -218,15 +302,23 @@ func (h *Handler) SyntacticallyValidateAtx(ctx context.Context, atx *types.Activ
 	expectedChallengeHash := atx.NIPostChallenge.Hash()
 	h.log.WithContext(ctx).With().Info("validating nipost", log.String("expected_challenge_hash", expectedChallengeHash.String()), atx.ID())
 
-	leaves, err := h.nipostValidator.NIPost(ctx, atx.PublishEpoch, atx.SmesherID, *commitmentATX, atx.NIPost, expectedChallengeHash, atx.NumUnits)
+	var leaves uint64
+	if postVerified {
+		leaves, err = h.nipostValidator.NIPostWithVerifiedPost(atx.NIPost, expectedChallengeHash, atx.NumUnits)
+	} else {
+		leaves, err = h.nipostValidator.NIPost(ctx, atx.PublishEpoch, atx.SmesherID, *commitmentATX, atx.NIPost, expectedChallengeHash, atx.NumUnits)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid nipost: %w", err)
 	}
+	if !postVerified {
+		h.recordVerifiedPost(atx)
+	}
 
 	return atx.Verify(baseTickHeight, leaves/h.tickSize)
 }
 
-func (h *Handler) validateInitialAtx(ctx context.Context, atx *types.ActivationTx) error {
+func (h *Handler) validateInitialAtx(ctx context.Context, atx *types.ActivationTx, postVerified bool) error {
 	if atx.InitialPost == nil {
 		return fmt.Errorf("no prevATX declared, but initial Post is not included")
 	}
@@ -244,8 +336,10 @@ func (h *Handler) validateInitialAtx(ctx context.Context, atx *types.ActivationT
 	initialPostMetadata := *atx.NIPost.PostMetadata
 	initialPostMetadata.Challenge = shared.ZeroChallenge
 
-	if err := h.nipostValidator.Post(ctx, atx.PublishEpoch, atx.SmesherID, *atx.CommitmentATX, atx.InitialPost, &initialPostMetadata, atx.NumUnits); err != nil {
-		return fmt.Errorf("invalid initial Post: %w", err)
+	if !postVerified {
+		if err := h.nipostValidator.Post(ctx, atx.PublishEpoch, atx.SmesherID, *atx.CommitmentATX, atx.InitialPost, &initialPostMetadata, atx.NumUnits); err != nil {
+			return fmt.Errorf("invalid initial Post: %w", err)
+		}
 	}
 
 	if atx.VRFNonce == nil {
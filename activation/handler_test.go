@@ -28,6 +28,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/verifiedposts"
 	"github.com/spacemeshos/go-spacemesh/system/mocks"
 )
 
@@ -85,7 +86,7 @@ type testHandler struct {
 	mtortoise  *mocks.MockTortoise
 }
 
-func newTestHandler(tb testing.TB, goldenATXID types.ATXID) *testHandler {
+func newTestHandler(tb testing.TB, goldenATXID types.ATXID, opts ...HandlerOption) *testHandler {
 	lg := logtest.New(tb)
 	cdb := datastore.NewCachedDB(sql.InMemory(), lg)
 
@@ -101,7 +102,7 @@ func newTestHandler(tb testing.TB, goldenATXID types.ATXID) *testHandler {
 	mbeacon := NewMockAtxReceiver(ctrl)
 	mtortoise := mocks.NewMockTortoise(ctrl)
 
-	atxHdlr := NewHandler(cdb, verifier, mclock, mpub, mockFetch, types.GetLayersPerEpoch(), 1, goldenATXID, mValidator, mbeacon, mtortoise, lg, PoetConfig{})
+	atxHdlr := NewHandler(cdb, verifier, mclock, mpub, mockFetch, types.GetLayersPerEpoch(), 1, goldenATXID, mValidator, mbeacon, mtortoise, lg, PoetConfig{}, opts...)
 	return &testHandler{
 		Handler: atxHdlr,
 
@@ -569,6 +570,95 @@ func TestHandler_SyntacticallyValidateAtx(t *testing.T) {
 	})
 }
 
+func TestHandler_VerifiedPosts(t *testing.T) {
+	layers := types.GetLayersPerEpoch()
+	types.SetLayersPerEpoch(layersPerEpochBig)
+	t.Cleanup(func() { types.SetLayersPerEpoch(layers) })
+
+	sig, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	otherSig, err := signing.NewEdSigner()
+	require.NoError(t, err)
+
+	goldenATXID := types.ATXID{2, 3, 4}
+	currentLayer := types.LayerID(1012)
+	poetRef := []byte{0xba, 0xbe}
+	npst := newNIPostWithChallenge(t, types.HexToHash32("0x3333"), poetRef)
+	posAtx := newActivationTx(t, otherSig, 0, types.EmptyATXID, types.EmptyATXID, &goldenATXID, currentLayer.GetEpoch()-1, 0, 100, types.GenerateAddress([]byte("bbbb")), 100, npst)
+
+	ctxID := posAtx.ID()
+	challenge := newChallenge(0, types.EmptyATXID, posAtx.ID(), currentLayer.GetEpoch(), &ctxID)
+	atx := newAtx(t, sig, challenge, &types.NIPost{}, 100, types.GenerateAddress([]byte("aaaa")))
+	atx.InitialPost = &types.Post{Indices: make([]byte, 10)}
+	atx.VRFNonce = new(types.VRFPostIndex)
+	atx.InnerActivationTx.NodeID = new(types.NodeID)
+	*atx.InnerActivationTx.NodeID = sig.NodeID()
+	atx.NIPost = newNIPostWithChallenge(t, atx.NIPostChallenge.Hash(), poetRef)
+	require.NoError(t, SignAndFinalizeAtx(sig, atx))
+
+	cfg := DefaultPostConfig()
+	validate := func(tb testing.TB, atxHdlr *testHandler, verified bool) {
+		atxHdlr.mclock.EXPECT().CurrentLayer().Return(currentLayer)
+		atxHdlr.mValidator.EXPECT().InitialNIPostChallenge(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		atxHdlr.mValidator.EXPECT().VRFNonce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		atxHdlr.mValidator.EXPECT().PositioningAtx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		if verified {
+			atxHdlr.mValidator.EXPECT().NIPostWithVerifiedPost(atx.NIPost, atx.NIPostChallenge.Hash(), atx.NumUnits).Return(uint64(1), nil)
+		} else {
+			atxHdlr.mValidator.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			atxHdlr.mValidator.EXPECT().NIPost(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(1), nil)
+		}
+		_, err := atxHdlr.SyntacticallyValidateAtx(context.Background(), atx)
+		require.NoError(tb, err)
+	}
+	record := func(tb testing.TB, atxHdlr *testHandler) verifiedposts.Record {
+		all, err := verifiedposts.All(atxHdlr.cdb)
+		require.NoError(tb, err)
+		require.Len(tb, all, 1)
+		return all[0]
+	}
+
+	t.Run("skipped if verified before", func(t *testing.T) {
+		atxHdlr := newTestHandler(t, goldenATXID, WithVerifiedPosts(cfg, false))
+		require.NoError(t, atxs.Add(atxHdlr.cdb, posAtx))
+		validate(t, atxHdlr, false)
+		require.Equal(t, verifiedposts.Record{
+			Atx:    atx.ID(),
+			Proof:  postProofHash(atx),
+			Params: postVerificationParams(cfg),
+		}, record(t, atxHdlr))
+		validate(t, atxHdlr, true)
+	})
+	t.Run("verified with different parameters", func(t *testing.T) {
+		atxHdlr := newTestHandler(t, goldenATXID, WithVerifiedPosts(cfg, false))
+		require.NoError(t, atxs.Add(atxHdlr.cdb, posAtx))
+		other := cfg
+		other.K3++
+		require.NoError(t, verifiedposts.Add(atxHdlr.cdb, verifiedposts.Record{
+			Atx:    atx.ID(),
+			Proof:  postProofHash(atx),
+			Params: postVerificationParams(other),
+		}))
+		validate(t, atxHdlr, false)
+		require.Equal(t, postVerificationParams(cfg), record(t, atxHdlr).Params)
+	})
+	t.Run("reverify", func(t *testing.T) {
+		atxHdlr := newTestHandler(t, goldenATXID, WithVerifiedPosts(cfg, true))
+		require.NoError(t, atxs.Add(atxHdlr.cdb, posAtx))
+		validate(t, atxHdlr, false)
+		validate(t, atxHdlr, false)
+		record(t, atxHdlr)
+	})
+	t.Run("not recorded without option", func(t *testing.T) {
+		atxHdlr := newTestHandler(t, goldenATXID)
+		require.NoError(t, atxs.Add(atxHdlr.cdb, posAtx))
+		validate(t, atxHdlr, false)
+		all, err := verifiedposts.All(atxHdlr.cdb)
+		require.NoError(t, err)
+		require.Empty(t, all)
+	})
+}
+
 func TestHandler_ContextuallyValidateAtx(t *testing.T) {
 	// Arrange
 	layers := types.GetLayersPerEpoch()
@@ -20,12 +20,27 @@ type AtxReceiver interface {
 
 type PostVerifier interface {
 	io.Closer
+	// Verify uses the node id from the metadata as the pow creator, options are applied after it.
 	Verify(ctx context.Context, p *shared.Proof, m *shared.ProofMetadata, opts ...verifying.OptionFunc) error
 }
+
+// RemotePostVerifier verifies proofs in another process, see grpcserver.PostVerifierClient.
+// Proofs are verified with the default label params and the pow creator from the metadata.
+type RemotePostVerifier interface {
+	io.Closer
+	Address() string
+	// Verify returns an error wrapping ErrRemotePostVerifierUnavailable if the proof wasn't verified.
+	Verify(ctx context.Context, p *shared.Proof, m *shared.ProofMetadata) error
+	// Health returns an error if the remote verifier is unreachable or verifies proofs
+	// with different parameters.
+	Health(ctx context.Context) error
+}
+
 type nipostValidator interface {
 	InitialNIPostChallenge(challenge *types.NIPostChallenge, atxs atxProvider, goldenATXID types.ATXID) error
 	NIPostChallenge(challenge *types.NIPostChallenge, atxs atxProvider, nodeID types.NodeID) error
 	NIPost(ctx context.Context, publishEpoch types.EpochID, nodeId types.NodeID, atxId types.ATXID, NIPost *types.NIPost, expectedChallenge types.Hash32, numUnits uint32, opts ...verifying.OptionFunc) (uint64, error)
+	NIPostWithVerifiedPost(NIPost *types.NIPost, expectedChallenge types.Hash32, numUnits uint32) (uint64, error)
 
 	NumUnits(cfg *PostConfig, numUnits uint32) error
 	Post(ctx context.Context, publishEpoch types.EpochID, nodeId types.NodeID, atxId types.ATXID, Post *types.Post, PostMetadata *types.PostMetadata, numUnits uint32, opts ...verifying.OptionFunc) error
@@ -43,6 +58,7 @@ type layerClock interface {
 
 type nipostBuilder interface {
 	UpdatePoETProvers([]PoetProvingServiceClient)
+	Poets() []PoetProvingServiceClient
 	BuildNIPost(ctx context.Context, challenge *types.NIPostChallenge) (*types.NIPost, error)
 	DataDir() string
 }
@@ -52,6 +68,11 @@ type atxHandler interface {
 	UnsubscribeAtx(id types.ATXID)
 }
 
+// clockOffset reports offset of the system clock from the clock of peers.
+type clockOffset interface {
+	Offset() (time.Duration, bool)
+}
+
 type syncer interface {
 	RegisterForATXSynced() chan struct{}
 }
@@ -84,4 +105,6 @@ type SmeshingProvider interface {
 	Coinbase() types.Address
 	SetCoinbase(coinbase types.Address)
 	UpdatePoETServers(ctx context.Context, endpoints []string) error
+	Preflight(ctx context.Context, withPost bool) (*PreflightReport, error)
+	PublishStatus() *PublishStatus
 }
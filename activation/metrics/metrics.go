@@ -43,3 +43,29 @@ var PostVerificationLatency = metrics.NewHistogramWithBuckets(
 	[]string{},
 	prometheus.ExponentialBuckets(1, 2, 20),
 ).WithLabelValues()
+
+// PostVerifications counts proofs verified by local and remote workers. Proofs that remote worker
+// failed to verify are counted as fallback, and once again when local worker verifies them.
+var PostVerifications = metrics.NewCounter(
+	"post_verifications",
+	namespace,
+	"number of post proofs verified by workers",
+	[]string{"worker"},
+)
+
+// VerifiedPostsSkipped counts atxs with PoST that wasn't verified because it was verified before.
+var VerifiedPostsSkipped = metrics.NewCounter(
+	"verified_posts_skipped",
+	namespace,
+	"number of atxs with post that was verified before",
+	[]string{},
+).WithLabelValues()
+
+// PublishEpochsMissed counts publish epochs that the atx builder gave up on, by the phase
+// in which the deadline was missed.
+var PublishEpochsMissed = metrics.NewCounter(
+	"publish_epochs_missed",
+	namespace,
+	"number of publish epochs in which atx was not published",
+	[]string{"phase"},
+)
@@ -179,6 +179,181 @@ func (c *PostVerifierVerifyCall) DoAndReturn(f func(context.Context, *shared.Pro
 	return c
 }
 
+// MockRemotePostVerifier is a mock of RemotePostVerifier interface.
+type MockRemotePostVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockRemotePostVerifierMockRecorder
+}
+
+// MockRemotePostVerifierMockRecorder is the mock recorder for MockRemotePostVerifier.
+type MockRemotePostVerifierMockRecorder struct {
+	mock *MockRemotePostVerifier
+}
+
+// NewMockRemotePostVerifier creates a new mock instance.
+func NewMockRemotePostVerifier(ctrl *gomock.Controller) *MockRemotePostVerifier {
+	mock := &MockRemotePostVerifier{ctrl: ctrl}
+	mock.recorder = &MockRemotePostVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRemotePostVerifier) EXPECT() *MockRemotePostVerifierMockRecorder {
+	return m.recorder
+}
+
+// Address mocks base method.
+func (m *MockRemotePostVerifier) Address() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Address")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Address indicates an expected call of Address.
+func (mr *MockRemotePostVerifierMockRecorder) Address() *RemotePostVerifierAddressCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockRemotePostVerifier)(nil).Address))
+	return &RemotePostVerifierAddressCall{Call: call}
+}
+
+// RemotePostVerifierAddressCall wrap *gomock.Call
+type RemotePostVerifierAddressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *RemotePostVerifierAddressCall) Return(arg0 string) *RemotePostVerifierAddressCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *RemotePostVerifierAddressCall) Do(f func() string) *RemotePostVerifierAddressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *RemotePostVerifierAddressCall) DoAndReturn(f func() string) *RemotePostVerifierAddressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Close mocks base method.
+func (m *MockRemotePostVerifier) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockRemotePostVerifierMockRecorder) Close() *RemotePostVerifierCloseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRemotePostVerifier)(nil).Close))
+	return &RemotePostVerifierCloseCall{Call: call}
+}
+
+// RemotePostVerifierCloseCall wrap *gomock.Call
+type RemotePostVerifierCloseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *RemotePostVerifierCloseCall) Return(arg0 error) *RemotePostVerifierCloseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *RemotePostVerifierCloseCall) Do(f func() error) *RemotePostVerifierCloseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *RemotePostVerifierCloseCall) DoAndReturn(f func() error) *RemotePostVerifierCloseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Health mocks base method.
+func (m *MockRemotePostVerifier) Health(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockRemotePostVerifierMockRecorder) Health(ctx interface{}) *RemotePostVerifierHealthCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockRemotePostVerifier)(nil).Health), ctx)
+	return &RemotePostVerifierHealthCall{Call: call}
+}
+
+// RemotePostVerifierHealthCall wrap *gomock.Call
+type RemotePostVerifierHealthCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *RemotePostVerifierHealthCall) Return(arg0 error) *RemotePostVerifierHealthCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *RemotePostVerifierHealthCall) Do(f func(context.Context) error) *RemotePostVerifierHealthCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *RemotePostVerifierHealthCall) DoAndReturn(f func(context.Context) error) *RemotePostVerifierHealthCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Verify mocks base method.
+func (m_2 *MockRemotePostVerifier) Verify(ctx context.Context, p *shared.Proof, m *shared.ProofMetadata) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Verify", ctx, p, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockRemotePostVerifierMockRecorder) Verify(ctx, p, m interface{}) *RemotePostVerifierVerifyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockRemotePostVerifier)(nil).Verify), ctx, p, m)
+	return &RemotePostVerifierVerifyCall{Call: call}
+}
+
+// RemotePostVerifierVerifyCall wrap *gomock.Call
+type RemotePostVerifierVerifyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *RemotePostVerifierVerifyCall) Return(arg0 error) *RemotePostVerifierVerifyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *RemotePostVerifierVerifyCall) Do(f func(context.Context, *shared.Proof, *shared.ProofMetadata) error) *RemotePostVerifierVerifyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *RemotePostVerifierVerifyCall) DoAndReturn(f func(context.Context, *shared.Proof, *shared.ProofMetadata) error) *RemotePostVerifierVerifyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MocknipostValidator is a mock of nipostValidator interface.
 type MocknipostValidator struct {
 	ctrl     *gomock.Controller
@@ -322,6 +497,45 @@ func (c *nipostValidatorNIPostChallengeCall) DoAndReturn(f func(*types.NIPostCha
 	return c
 }
 
+// NIPostWithVerifiedPost mocks base method.
+func (m *MocknipostValidator) NIPostWithVerifiedPost(NIPost *types.NIPost, expectedChallenge types.Hash32, numUnits uint32) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NIPostWithVerifiedPost", NIPost, expectedChallenge, numUnits)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NIPostWithVerifiedPost indicates an expected call of NIPostWithVerifiedPost.
+func (mr *MocknipostValidatorMockRecorder) NIPostWithVerifiedPost(NIPost, expectedChallenge, numUnits interface{}) *nipostValidatorNIPostWithVerifiedPostCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NIPostWithVerifiedPost", reflect.TypeOf((*MocknipostValidator)(nil).NIPostWithVerifiedPost), NIPost, expectedChallenge, numUnits)
+	return &nipostValidatorNIPostWithVerifiedPostCall{Call: call}
+}
+
+// nipostValidatorNIPostWithVerifiedPostCall wrap *gomock.Call
+type nipostValidatorNIPostWithVerifiedPostCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *nipostValidatorNIPostWithVerifiedPostCall) Return(arg0 uint64, arg1 error) *nipostValidatorNIPostWithVerifiedPostCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *nipostValidatorNIPostWithVerifiedPostCall) Do(f func(*types.NIPost, types.Hash32, uint32) (uint64, error)) *nipostValidatorNIPostWithVerifiedPostCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *nipostValidatorNIPostWithVerifiedPostCall) DoAndReturn(f func(*types.NIPost, types.Hash32, uint32) (uint64, error)) *nipostValidatorNIPostWithVerifiedPostCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NumUnits mocks base method.
 func (m *MocknipostValidator) NumUnits(cfg *PostConfig, numUnits uint32) error {
 	m.ctrl.T.Helper()
@@ -754,6 +968,44 @@ func (c *nipostBuilderDataDirCall) DoAndReturn(f func() string) *nipostBuilderDa
 	return c
 }
 
+// Poets mocks base method.
+func (m *MocknipostBuilder) Poets() []PoetProvingServiceClient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Poets")
+	ret0, _ := ret[0].([]PoetProvingServiceClient)
+	return ret0
+}
+
+// Poets indicates an expected call of Poets.
+func (mr *MocknipostBuilderMockRecorder) Poets() *nipostBuilderPoetsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poets", reflect.TypeOf((*MocknipostBuilder)(nil).Poets))
+	return &nipostBuilderPoetsCall{Call: call}
+}
+
+// nipostBuilderPoetsCall wrap *gomock.Call
+type nipostBuilderPoetsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *nipostBuilderPoetsCall) Return(arg0 []PoetProvingServiceClient) *nipostBuilderPoetsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *nipostBuilderPoetsCall) Do(f func() []PoetProvingServiceClient) *nipostBuilderPoetsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *nipostBuilderPoetsCall) DoAndReturn(f func() []PoetProvingServiceClient) *nipostBuilderPoetsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdatePoETProvers mocks base method.
 func (m *MocknipostBuilder) UpdatePoETProvers(arg0 []PoetProvingServiceClient) {
 	m.ctrl.T.Helper()
@@ -887,6 +1139,68 @@ func (c *atxHandlerUnsubscribeAtxCall) DoAndReturn(f func(types.ATXID)) *atxHand
 	return c
 }
 
+// MockclockOffset is a mock of clockOffset interface.
+type MockclockOffset struct {
+	ctrl     *gomock.Controller
+	recorder *MockclockOffsetMockRecorder
+}
+
+// MockclockOffsetMockRecorder is the mock recorder for MockclockOffset.
+type MockclockOffsetMockRecorder struct {
+	mock *MockclockOffset
+}
+
+// NewMockclockOffset creates a new mock instance.
+func NewMockclockOffset(ctrl *gomock.Controller) *MockclockOffset {
+	mock := &MockclockOffset{ctrl: ctrl}
+	mock.recorder = &MockclockOffsetMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockclockOffset) EXPECT() *MockclockOffsetMockRecorder {
+	return m.recorder
+}
+
+// Offset mocks base method.
+func (m *MockclockOffset) Offset() (time.Duration, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offset")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Offset indicates an expected call of Offset.
+func (mr *MockclockOffsetMockRecorder) Offset() *clockOffsetOffsetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offset", reflect.TypeOf((*MockclockOffset)(nil).Offset))
+	return &clockOffsetOffsetCall{Call: call}
+}
+
+// clockOffsetOffsetCall wrap *gomock.Call
+type clockOffsetOffsetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *clockOffsetOffsetCall) Return(arg0 time.Duration, arg1 bool) *clockOffsetOffsetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *clockOffsetOffsetCall) Do(f func() (time.Duration, bool)) *clockOffsetOffsetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *clockOffsetOffsetCall) DoAndReturn(f func() (time.Duration, bool)) *clockOffsetOffsetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Mocksyncer is a mock of syncer interface.
 type Mocksyncer struct {
 	ctrl     *gomock.Controller
@@ -1523,6 +1837,83 @@ func (c *SmeshingProviderCoinbaseCall) DoAndReturn(f func() types.Address) *Smes
 	return c
 }
 
+// Preflight mocks base method.
+func (m *MockSmeshingProvider) Preflight(ctx context.Context, withPost bool) (*PreflightReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preflight", ctx, withPost)
+	ret0, _ := ret[0].(*PreflightReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preflight indicates an expected call of Preflight.
+func (mr *MockSmeshingProviderMockRecorder) Preflight(ctx, withPost interface{}) *SmeshingProviderPreflightCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preflight", reflect.TypeOf((*MockSmeshingProvider)(nil).Preflight), ctx, withPost)
+	return &SmeshingProviderPreflightCall{Call: call}
+}
+
+// SmeshingProviderPreflightCall wrap *gomock.Call
+type SmeshingProviderPreflightCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SmeshingProviderPreflightCall) Return(arg0 *PreflightReport, arg1 error) *SmeshingProviderPreflightCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SmeshingProviderPreflightCall) Do(f func(context.Context, bool) (*PreflightReport, error)) *SmeshingProviderPreflightCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SmeshingProviderPreflightCall) DoAndReturn(f func(context.Context, bool) (*PreflightReport, error)) *SmeshingProviderPreflightCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PublishStatus mocks base method.
+func (m *MockSmeshingProvider) PublishStatus() *PublishStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishStatus")
+	ret0, _ := ret[0].(*PublishStatus)
+	return ret0
+}
+
+// PublishStatus indicates an expected call of PublishStatus.
+func (mr *MockSmeshingProviderMockRecorder) PublishStatus() *SmeshingProviderPublishStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishStatus", reflect.TypeOf((*MockSmeshingProvider)(nil).PublishStatus))
+	return &SmeshingProviderPublishStatusCall{Call: call}
+}
+
+// SmeshingProviderPublishStatusCall wrap *gomock.Call
+type SmeshingProviderPublishStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SmeshingProviderPublishStatusCall) Return(arg0 *PublishStatus) *SmeshingProviderPublishStatusCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SmeshingProviderPublishStatusCall) Do(f func() *PublishStatus) *SmeshingProviderPublishStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SmeshingProviderPublishStatusCall) DoAndReturn(f func() *PublishStatus) *SmeshingProviderPublishStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetCoinbase mocks base method.
 func (m *MockSmeshingProvider) SetCoinbase(coinbase types.Address) {
 	m.ctrl.T.Helper()
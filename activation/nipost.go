@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/spacemeshos/merkle-tree"
@@ -88,6 +89,8 @@ type NIPostBuilder struct {
 	dataDir           string
 	postSetupProvider postSetupProvider
 	poetProvers       map[string]PoetProvingServiceClient
+	poetRegistry      *PoetRegistry
+	poetHealth        *PoetHealth
 	poetDB            poetDbAPI
 	state             *types.NIPostBuilderState
 	log               log.Log
@@ -95,6 +98,9 @@ type NIPostBuilder struct {
 	layerClock        layerClock
 	poetCfg           PoetConfig
 	validator         nipostValidator
+
+	// poetMu protects poetProvers from readers other than the goroutine that builds nipost.
+	poetMu sync.RWMutex
 }
 
 type NIPostBuilderOption func(*NIPostBuilder)
@@ -105,6 +111,21 @@ func WithNipostValidator(v nipostValidator) NIPostBuilderOption {
 	}
 }
 
+// WithNipostPoetRegistry creates PoET clients for the poet servers with the registry.
+func WithNipostPoetRegistry(registry *PoetRegistry) NIPostBuilderOption {
+	return func(nb *NIPostBuilder) {
+		nb.poetRegistry = registry
+	}
+}
+
+// WithPoetHealth records outcome of the submissions and proof queries, and deprioritises poets
+// that failed to deliver proofs in several consecutive rounds.
+func WithPoetHealth(health *PoetHealth) NIPostBuilderOption {
+	return func(nb *NIPostBuilder) {
+		nb.poetHealth = health
+	}
+}
+
 // withPoetClients allows to pass in clients directly (for testing purposes).
 func withPoetClients(clients []PoetProvingServiceClient) NIPostBuilderOption {
 	return func(nb *NIPostBuilder) {
@@ -133,19 +154,9 @@ func NewNIPostBuilder(
 	layerClock layerClock,
 	opts ...NIPostBuilderOption,
 ) (*NIPostBuilder, error) {
-	poetClients := make(map[string]PoetProvingServiceClient, len(poetServers))
-	for _, address := range poetServers {
-		client, err := NewHTTPPoetClient(address, poetCfg, WithLogger(lg.Zap().Named("poet")))
-		if err != nil {
-			return nil, fmt.Errorf("cannot create poet client: %w", err)
-		}
-		poetClients[client.Address()] = client
-	}
-
 	b := &NIPostBuilder{
 		nodeID:            nodeID,
 		postSetupProvider: postSetupProvider,
-		poetProvers:       poetClients,
 		poetDB:            poetDB,
 		state:             &types.NIPostBuilderState{NIPost: &types.NIPost{}},
 		dataDir:           dataDir,
@@ -158,6 +169,20 @@ func NewNIPostBuilder(
 	for _, opt := range opts {
 		opt(b)
 	}
+	if b.poetProvers != nil {
+		return b, nil
+	}
+	if b.poetRegistry == nil {
+		b.poetRegistry = NewPoetRegistry(lg.Zap().Named("poet"))
+	}
+	b.poetProvers = make(map[string]PoetProvingServiceClient, len(poetServers))
+	for _, address := range poetServers {
+		client, err := b.poetRegistry.NewClient(address, poetCfg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create poet client: %w", err)
+		}
+		b.poetProvers[client.Address()] = client
+	}
 	return b, nil
 }
 
@@ -174,11 +199,25 @@ func (nb *NIPostBuilder) UpdatePoETProvers(poetProvers []PoetProvingServiceClien
 	nb.state = &types.NIPostBuilderState{
 		NIPost: &types.NIPost{},
 	}
-	nb.poetProvers = make(map[string]PoetProvingServiceClient, len(poetProvers))
+	provers := make(map[string]PoetProvingServiceClient, len(poetProvers))
 	for _, poetProver := range poetProvers {
-		nb.poetProvers[poetProver.Address()] = poetProver
+		provers[poetProver.Address()] = poetProver
+	}
+	nb.poetMu.Lock()
+	nb.poetProvers = provers
+	nb.poetMu.Unlock()
+	nb.log.With().Info("updated poet proof service clients", log.Int("count", len(provers)))
+}
+
+// Poets returns clients of the poets that challenges are submitted to. Safe for concurrent use.
+func (nb *NIPostBuilder) Poets() []PoetProvingServiceClient {
+	nb.poetMu.RLock()
+	defer nb.poetMu.RUnlock()
+	poets := make([]PoetProvingServiceClient, 0, len(nb.poetProvers))
+	for _, poet := range nb.poetProvers {
+		poets = append(poets, poet)
 	}
-	nb.log.With().Info("updated poet proof service clients", log.Int("count", len(nb.poetProvers)))
+	return poets
 }
 
 // BuildNIPost uses the given challenge to build a NIPost.
@@ -238,7 +277,7 @@ func (nb *NIPostBuilder) BuildNIPost(ctx context.Context, challenge *types.NIPos
 		prefix := bytes.Join([][]byte{nb.signer.Prefix(), {byte(signing.POET)}}, nil)
 		submitCtx, cancel := context.WithDeadline(ctx, poetRoundStart)
 		defer cancel()
-		poetRequests := nb.submitPoetChallenges(submitCtx, prefix, challengeHash.Bytes(), signature, nb.signer.NodeID())
+		poetRequests := nb.submitPoetChallenges(submitCtx, challenge.PublishEpoch, prefix, challengeHash.Bytes(), signature, nb.signer.NodeID())
 		if len(poetRequests) == 0 {
 			return nil, &PoetSvcUnstableError{msg: "failed to submit challenge to any PoET", source: submitCtx.Err()}
 		}
@@ -363,16 +402,19 @@ func (nb *NIPostBuilder) submitPoetChallenge(ctx context.Context, poet PoetProvi
 }
 
 // Submit the challenge to all registered PoETs.
-func (nb *NIPostBuilder) submitPoetChallenges(ctx context.Context, prefix, challenge []byte, signature types.EdSignature, nodeID types.NodeID) []types.PoetRequest {
+func (nb *NIPostBuilder) submitPoetChallenges(ctx context.Context, publishEpoch types.EpochID, prefix, challenge []byte, signature types.EdSignature, nodeID types.NodeID) []types.PoetRequest {
 	g, ctx := errgroup.WithContext(ctx)
 	poetRequestsChannel := make(chan types.PoetRequest, len(nb.poetProvers))
-	for _, poetProver := range nb.poetProvers {
-		poet := poetProver
+	for address, poetProver := range nb.poetProvers {
+		address, poet := address, poetProver
 		g.Go(func() error {
-			if poetRequest, err := nb.submitPoetChallenge(ctx, poet, prefix, challenge, signature, nodeID); err == nil {
+			start := time.Now()
+			poetRequest, err := nb.submitPoetChallenge(ctx, poet, prefix, challenge, signature, nodeID)
+			nb.poetHealth.Submitted(address, publishEpoch, poetRequest, time.Since(start), err)
+			if err == nil {
 				poetRequestsChannel <- *poetRequest
 			} else {
-				nb.log.With().Warning("failed to submit challenge to PoET", log.Err(err))
+				nb.log.With().Warning("failed to submit challenge to PoET", log.String("poet", address), log.Err(err))
 			}
 			return nil
 		})
@@ -387,13 +429,14 @@ func (nb *NIPostBuilder) submitPoetChallenges(ctx context.Context, prefix, chall
 	return poetRequests
 }
 
-func (nb *NIPostBuilder) getPoetClient(ctx context.Context, id types.PoetServiceID) PoetProvingServiceClient {
-	for _, client := range nb.poetProvers {
+// getPoetClient returns the client for the poet with the service id and its address.
+func (nb *NIPostBuilder) getPoetClient(ctx context.Context, id types.PoetServiceID) (string, PoetProvingServiceClient) {
+	for address, client := range nb.poetProvers {
 		if clientId, err := client.PoetServiceID(ctx); err == nil && bytes.Equal(id.ServiceID, clientId.ServiceID) {
-			return client
+			return address, client
 		}
 	}
-	return nil
+	return "", nil
 }
 
 // membersContainChallenge verifies that the challenge is included in proof's members.
@@ -465,48 +508,69 @@ func (nb *NIPostBuilder) getBestProof(ctx context.Context, challenge types.Hash3
 	}
 	proofs := make(chan *poetProof, len(nb.state.PoetRequests))
 
-	var eg errgroup.Group
+	// proofs from deprioritised poets are awaited only if none of the healthy poets delivered a proof
+	slowCtx, cancelSlow := context.WithCancel(ctx)
+	defer cancelSlow()
+	var healthy, slow errgroup.Group
 	for _, r := range nb.state.PoetRequests {
 		logger := nb.log.WithContext(ctx).WithFields(log.String("poet_id", hex.EncodeToString(r.PoetServiceID.ServiceID)), log.String("round", r.PoetRound.ID))
-		client := nb.getPoetClient(ctx, r.PoetServiceID)
+		address, client := nb.getPoetClient(ctx, r.PoetServiceID)
 		if client == nil {
 			logger.Warning("poet client not found")
 			continue
 		}
 		round := r.PoetRound.ID
-		waitDeadline := proofDeadline(r.PoetRound.End.IntoTime(), nb.poetCfg.CycleGap)
+		roundEnd := r.PoetRound.End.IntoTime()
+		waitDeadline := proofDeadline(roundEnd, nb.poetCfg.CycleGap)
+		eg, pctx := &healthy, ctx
+		if nb.poetHealth.Deprioritized(address) {
+			logger.With().Info("poet is deprioritised after missing proofs", log.String("poet", address))
+			eg, pctx = &slow, slowCtx
+		}
+		canceled := func(msg string) error {
+			if ctx.Err() == nil {
+				// deprioritised poet is not awaited after a healthy poet delivered a proof
+				nb.poetHealth.ProofSkipped(address, publishEpoch)
+				return nil
+			}
+			return fmt.Errorf("%s: %w", msg, ctx.Err())
+		}
 		eg.Go(func() error {
 			logger.With().Info("waiting till poet round end", log.Duration("wait time", time.Until(waitDeadline)))
 			select {
-			case <-ctx.Done():
-				return fmt.Errorf("waiting to query proof: %w", ctx.Err())
+			case <-pctx.Done():
+				return canceled("waiting to query proof")
 			case <-time.After(time.Until(waitDeadline)):
 			}
 
 			// TODO(mafa): this should be renamed from GracePeriod to something like RequestTimeout
 			// and should be much shorter (e.g. 10 seconds instead of 1 hour on mainnet)
-			getProofsCtx, cancel := context.WithTimeout(ctx, nb.poetCfg.GracePeriod)
+			getProofsCtx, cancel := context.WithTimeout(pctx, nb.poetCfg.GracePeriod)
 			defer cancel()
 
 			proof, members, err := client.Proof(getProofsCtx, round)
 			switch {
 			case errors.Is(err, context.Canceled):
-				return fmt.Errorf("querying proof: %w", ctx.Err())
+				return canceled("querying proof")
 			case err != nil:
 				logger.With().Warning("failed to get proof from poet", log.Err(err))
+				nb.poetHealth.ProofMissed(address, publishEpoch, err)
 				return nil
 			}
 
 			if err := nb.poetDB.ValidateAndStore(ctx, proof); err != nil && !errors.Is(err, ErrObjectExists) {
 				logger.With().Warning("failed to validate and store proof", log.Err(err), log.Object("proof", proof))
+				nb.poetHealth.ProofMissed(address, publishEpoch, err)
 				return nil
 			}
 
 			membership, err := constructMerkleProof(challenge, members)
 			if err != nil {
 				logger.With().Warning("failed to construct merkle proof", log.Err(err))
+				nb.poetHealth.ProofMissed(address, publishEpoch, err)
 				return nil
 			}
+			nb.poetHealth.ProofReceived(address, publishEpoch, proof.LeafCount, time.Since(roundEnd))
 
 			proofs <- &poetProof{
 				poet:       proof,
@@ -515,7 +579,15 @@ func (nb *NIPostBuilder) getBestProof(ctx context.Context, challenge types.Hash3
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
+	if err := healthy.Wait(); err != nil {
+		cancelSlow()
+		slow.Wait()
+		return types.PoetProofRef{}, nil, fmt.Errorf("querying for proofs: %w", err)
+	}
+	if len(proofs) > 0 {
+		cancelSlow()
+	}
+	if err := slow.Wait(); err != nil {
 		return types.PoetProofRef{}, nil, fmt.Errorf("querying for proofs: %w", err)
 	}
 	close(proofs)
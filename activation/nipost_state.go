@@ -19,6 +19,7 @@ const (
 	challengeFilename = "nipost_challenge.bin"
 	builderFilename   = "nipost_builder_state.bin"
 	postFilename      = "post.bin"
+	publishFilename   = "atx_publish_state.bin"
 )
 
 func write(path string, data []byte) error {
@@ -183,3 +184,26 @@ func discardPost(dir string) error {
 	}
 	return nil
 }
+
+func savePublishState(dir string, state *types.ATXPublishState) error {
+	if err := save(filepath.Join(dir, publishFilename), state); err != nil {
+		return fmt.Errorf("saving atx publish state: %w", err)
+	}
+	return nil
+}
+
+func loadPublishState(dir string) (*types.ATXPublishState, error) {
+	var state types.ATXPublishState
+	if err := load(filepath.Join(dir, publishFilename), &state); err != nil {
+		return nil, fmt.Errorf("loading atx publish state: %w", err)
+	}
+	return &state, nil
+}
+
+func discardPublishState(dir string) error {
+	filename := filepath.Join(dir, publishFilename)
+	if err := os.Remove(filename); err != nil {
+		return fmt.Errorf("discarding atx publish state: %w", err)
+	}
+	return nil
+}
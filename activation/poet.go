@@ -43,10 +43,6 @@ type HTTPPoetClient struct {
 	logger        *zap.Logger
 }
 
-func defaultPoetClientFunc(address string, cfg PoetConfig) (PoetProvingServiceClient, error) {
-	return NewHTTPPoetClient(address, cfg)
-}
-
 func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
 	if resp != nil && resp.StatusCode == http.StatusNotFound {
 		return true, nil
@@ -199,9 +195,23 @@ func (c *HTTPPoetClient) Proof(ctx context.Context, roundID string) (*types.Poet
 		return nil, nil, fmt.Errorf("getting proof: %w", err)
 	}
 
-	p := resBody.Proof.GetProof()
+	proof, members, err := proofMessage(roundID, &resBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.poetServiceID.ServiceID == nil {
+		c.poetServiceID.ServiceID = proof.PoetServiceID
+	}
 
-	pMembers := resBody.Proof.GetMembers()
+	return proof, members, nil
+}
+
+// proofMessage converts the proof returned by the poet service into the message
+// that is gossiped to the network, statement is the root of the members tree.
+func proofMessage(roundID string, resp *rpcapi.ProofResponse) (*types.PoetProofMessage, []types.Member, error) {
+	p := resp.Proof.GetProof()
+
+	pMembers := resp.Proof.GetMembers()
 	members := make([]types.Member, len(pMembers))
 	for i, m := range pMembers {
 		copy(members[i][:], m)
@@ -211,24 +221,19 @@ func (c *HTTPPoetClient) Proof(ctx context.Context, roundID string) (*types.Poet
 		return nil, nil, fmt.Errorf("calculating root: %w", err)
 	}
 
-	proof := types.PoetProofMessage{
+	return &types.PoetProofMessage{
 		PoetProof: types.PoetProof{
 			MerkleProof: shared.MerkleProof{
 				Root:         p.GetRoot(),
 				ProvenLeaves: p.GetProvenLeaves(),
 				ProofNodes:   p.GetProofNodes(),
 			},
-			LeafCount: resBody.Proof.GetLeaves(),
+			LeafCount: resp.Proof.GetLeaves(),
 		},
-		PoetServiceID: resBody.Pubkey,
+		PoetServiceID: resp.Pubkey,
 		RoundID:       roundID,
 		Statement:     types.BytesToHash(statement),
-	}
-	if c.poetServiceID.ServiceID == nil {
-		c.poetServiceID.ServiceID = proof.PoetServiceID
-	}
-
-	return &proof, members, nil
+	}, members, nil
 }
 
 func (c *HTTPPoetClient) req(ctx context.Context, method, path string, reqBody, resBody proto.Message) error {
@@ -0,0 +1,235 @@
+package activation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// GRPCPoetClient implements PoetProvingServiceClient interface over the grpc api of the poet service.
+type GRPCPoetClient struct {
+	address       string
+	conn          *grpc.ClientConn
+	client        rpcapi.PoetServiceClient
+	cfg           PoetConfig
+	logger        *zap.Logger
+	poetServiceID types.PoetServiceID
+}
+
+// NewGRPCPoetClient returns new instance of GRPCPoetClient connecting to the address grpc://<host>:<port>.
+func NewGRPCPoetClient(address string, cfg PoetConfig, logger *zap.Logger) (*GRPCPoetClient, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing address: %w", err)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("poet address %s doesn't have a host", address)
+	}
+	conn, err := grpc.Dial(parsed.Host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial poet %s: %w", address, err)
+	}
+	client := newGRPCPoetClientWithConn(address, rpcapi.NewPoetServiceClient(conn), cfg, logger)
+	client.conn = conn
+	logger.Info("created poet client", zap.String("url", address), zap.Int("max retries", cfg.MaxRequestRetries))
+	return client, nil
+}
+
+func newGRPCPoetClientWithConn(address string, client rpcapi.PoetServiceClient, cfg PoetConfig, logger *zap.Logger) *GRPCPoetClient {
+	return &GRPCPoetClient{
+		address: address,
+		client:  client,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// Close the connection to the poet service.
+func (c *GRPCPoetClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (c *GRPCPoetClient) Address() string {
+	return c.address
+}
+
+func (c *GRPCPoetClient) PowParams(ctx context.Context) (*PoetPowParams, error) {
+	var resp *rpcapi.PowParamsResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.PowParams(ctx, &rpcapi.PowParamsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying PoW params: %w", err)
+	}
+	return &PoetPowParams{
+		Challenge:  resp.GetPowParams().GetChallenge(),
+		Difficulty: uint(resp.GetPowParams().GetDifficulty()),
+	}, nil
+}
+
+// Submit registers a challenge in the proving service current open round.
+func (c *GRPCPoetClient) Submit(ctx context.Context, prefix, challenge []byte, signature types.EdSignature, nodeID types.NodeID, pow PoetPoW) (*types.PoetRound, error) {
+	request := rpcapi.SubmitRequest{
+		Prefix:    prefix,
+		Challenge: challenge,
+		Signature: signature.Bytes(),
+		Pubkey:    nodeID.Bytes(),
+		Nonce:     pow.Nonce,
+		PowParams: &rpcapi.PowParams{
+			Challenge:  pow.Params.Challenge,
+			Difficulty: uint32(pow.Params.Difficulty),
+		},
+	}
+	var resp *rpcapi.SubmitResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Submit(ctx, &request)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("submitting challenge: %w", err)
+	}
+	roundEnd := time.Time{}
+	if resp.RoundEnd != nil {
+		roundEnd = time.Now().Add(resp.RoundEnd.AsDuration())
+	}
+	return &types.PoetRound{ID: resp.RoundId, End: types.RoundEnd(roundEnd)}, nil
+}
+
+// PoetServiceID returns the public key of the PoET proving service.
+func (c *GRPCPoetClient) PoetServiceID(ctx context.Context) (types.PoetServiceID, error) {
+	if c.poetServiceID.ServiceID != nil {
+		return c.poetServiceID, nil
+	}
+	var resp *rpcapi.InfoResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Info(ctx, &rpcapi.InfoRequest{})
+		return err
+	})
+	if err != nil {
+		return types.PoetServiceID{}, fmt.Errorf("getting poet ID: %w", err)
+	}
+	c.poetServiceID.ServiceID = resp.ServicePubkey
+	return c.poetServiceID, nil
+}
+
+// Proof implements PoetProvingServiceClient.
+func (c *GRPCPoetClient) Proof(ctx context.Context, roundID string) (*types.PoetProofMessage, []types.Member, error) {
+	var resp *rpcapi.ProofResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Proof(ctx, &rpcapi.ProofRequest{RoundId: roundID})
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting proof: %w", err)
+	}
+	proof, members, err := proofMessage(roundID, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.poetServiceID.ServiceID == nil {
+		c.poetServiceID.ServiceID = proof.PoetServiceID
+	}
+	return proof, members, nil
+}
+
+// call retries requests that failed because service is not reachable or doesn't have
+// the requested object yet, same as HTTPPoetClient does.
+func (c *GRPCPoetClient) call(ctx context.Context, request func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := request(ctx)
+		if err == nil {
+			return nil
+		}
+		code := status.Code(err)
+		if (code != codes.Unavailable && code != codes.NotFound) || attempt >= c.cfg.MaxRequestRetries {
+			return poetError(err)
+		}
+		c.logger.Debug("retrying poet request",
+			zap.String("url", c.address),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.cfg.RequestRetryDelay):
+		}
+	}
+}
+
+// poetError maps grpc status to the errors returned by HTTPPoetClient for the same http status.
+func poetError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	case codes.Canceled:
+		return fmt.Errorf("%w: %w", context.Canceled, err)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// localPoetConn calls the service registered in the same process.
+type localPoetConn struct {
+	name     string
+	registry *PoetRegistry
+}
+
+func (c *localPoetConn) service() (rpcapi.PoetServiceServer, error) {
+	svc := c.registry.localService(c.name)
+	if svc == nil {
+		return nil, status.Errorf(codes.Unavailable, "local poet %s is not registered", c.name)
+	}
+	return svc, nil
+}
+
+func (c *localPoetConn) PowParams(ctx context.Context, in *rpcapi.PowParamsRequest, _ ...grpc.CallOption) (*rpcapi.PowParamsResponse, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+	return svc.PowParams(ctx, in)
+}
+
+func (c *localPoetConn) Submit(ctx context.Context, in *rpcapi.SubmitRequest, _ ...grpc.CallOption) (*rpcapi.SubmitResponse, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+	return svc.Submit(ctx, in)
+}
+
+func (c *localPoetConn) Info(ctx context.Context, in *rpcapi.InfoRequest, _ ...grpc.CallOption) (*rpcapi.InfoResponse, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+	return svc.Info(ctx, in)
+}
+
+func (c *localPoetConn) Proof(ctx context.Context, in *rpcapi.ProofRequest, _ ...grpc.CallOption) (*rpcapi.ProofResponse, error) {
+	svc, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+	return svc.Proof(ctx, in)
+}
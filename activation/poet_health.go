@@ -0,0 +1,207 @@
+package activation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
+)
+
+// healthHistory is the number of latest rounds used to decide whether poet is deprioritised.
+const healthHistory = 100
+
+// PoetHealth keeps history of the submissions to every poet and of the proofs received for them.
+// Poets are identified by address, because service id is unknown if poet is not reachable.
+//
+// Poet that failed to deliver proof in MaxMissedProofs consecutive rounds is deprioritised:
+// node still submits challenges to it, but stops waiting for its proof once other poets delivered
+// theirs. Poet is prioritised again after it delivers a proof.
+//
+// Methods are no-op on nil PoetHealth.
+type PoetHealth struct {
+	db        sql.Executor
+	tickSize  uint64
+	maxMissed int
+	log       log.Log
+}
+
+// NewPoetHealth creates tracker that persists history in db.
+func NewPoetHealth(db sql.Executor, tickSize uint64, maxMissed int, logger log.Log) *PoetHealth {
+	return &PoetHealth{
+		db:        db,
+		tickSize:  tickSize,
+		maxMissed: maxMissed,
+		log:       logger,
+	}
+}
+
+// PoetHealthReport summarizes history of the poet.
+type PoetHealthReport struct {
+	Address   string
+	ServiceID []byte
+
+	Submissions    int
+	Submitted      int
+	ProofsReceived int
+	ProofsMissed   int
+	// ConsecutiveMissed is the number of latest rounds without proof.
+	ConsecutiveMissed int
+	Deprioritized     bool
+
+	AvgSubmitLatency time.Duration
+	AvgProofLatency  time.Duration
+
+	// Rounds are ordered by publish epoch, latest first.
+	Rounds []poets.Submission
+}
+
+// Submitted records result of the submission to the poet.
+func (h *PoetHealth) Submitted(address string, epoch types.EpochID, req *types.PoetRequest, latency time.Duration, err error) {
+	if h == nil {
+		return
+	}
+	record := &poets.Submission{
+		Address:       address,
+		Epoch:         epoch,
+		Submitted:     err == nil,
+		SubmitLatency: latency,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.ServiceID = req.PoetServiceID.ServiceID
+		record.RoundID = req.PoetRound.ID
+	}
+	if err := poets.AddSubmission(h.db, record); err != nil {
+		h.log.With().Warning("failed to record poet submission", log.String("poet", address), log.Err(err))
+	}
+}
+
+// ProofReceived records proof delivered by the poet, latency is the time since the end of the round.
+func (h *PoetHealth) ProofReceived(address string, epoch types.EpochID, leafCount uint64, latency time.Duration) {
+	if h == nil {
+		return
+	}
+	var ticks uint64
+	if h.tickSize > 0 {
+		ticks = leafCount / h.tickSize
+	}
+	h.setProof(address, epoch, poets.ProofReceived, latency, leafCount, ticks, "")
+}
+
+// ProofMissed records that poet failed to deliver valid proof before the deadline.
+func (h *PoetHealth) ProofMissed(address string, epoch types.EpochID, err error) {
+	if h == nil {
+		return
+	}
+	h.setProof(address, epoch, poets.ProofMissed, 0, 0, 0, err.Error())
+}
+
+// ProofSkipped records that node stopped waiting for the proof of the deprioritised poet.
+func (h *PoetHealth) ProofSkipped(address string, epoch types.EpochID) {
+	if h == nil {
+		return
+	}
+	h.setProof(address, epoch, poets.ProofSkipped, 0, 0, 0, "")
+}
+
+func (h *PoetHealth) setProof(address string, epoch types.EpochID, state poets.ProofState,
+	latency time.Duration, leafCount, tickCount uint64, errMsg string,
+) {
+	err := poets.SetProof(h.db, address, epoch, state, latency, leafCount, tickCount, errMsg)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		// submission was made before the poet was added to the history
+	case err != nil:
+		h.log.With().Warning("failed to record poet proof",
+			log.String("poet", address),
+			log.Stringer("state", state),
+			log.Err(err),
+		)
+	}
+}
+
+// consecutiveMissed counts latest rounds without proof. Pending and skipped proofs are not counted,
+// but don't break the sequence either.
+func consecutiveMissed(rounds []poets.Submission) int {
+	missed := 0
+	for _, r := range rounds {
+		switch {
+		case !r.Submitted || r.Proof == poets.ProofMissed:
+			missed++
+		case r.Proof == poets.ProofReceived:
+			return missed
+		}
+	}
+	return missed
+}
+
+// Deprioritized returns true if poet failed to deliver proofs in MaxMissedProofs consecutive rounds.
+func (h *PoetHealth) Deprioritized(address string) bool {
+	if h == nil || h.maxMissed <= 0 {
+		return false
+	}
+	rounds, err := poets.Submissions(h.db, address, healthHistory)
+	if err != nil {
+		h.log.With().Warning("failed to load poet history", log.String("poet", address), log.Err(err))
+		return false
+	}
+	return consecutiveMissed(rounds) >= h.maxMissed
+}
+
+// Report summarizes history of every poet for publish epochs starting with from.
+// Deprioritisation is decided using the whole history.
+func (h *PoetHealth) Report(from types.EpochID) ([]PoetHealthReport, error) {
+	if h == nil {
+		return nil, nil
+	}
+	rounds, err := poets.SubmissionsFrom(h.db, from)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		reports  []PoetHealthReport
+		received time.Duration
+	)
+	for _, r := range rounds {
+		if len(reports) == 0 || reports[len(reports)-1].Address != r.Address {
+			reports = append(reports, PoetHealthReport{Address: r.Address})
+			received = 0
+		}
+		report := &reports[len(reports)-1]
+		report.Rounds = append(report.Rounds, r)
+		report.Submissions++
+		if report.ServiceID == nil {
+			report.ServiceID = r.ServiceID
+		}
+		if r.Submitted {
+			report.AvgSubmitLatency += (r.SubmitLatency - report.AvgSubmitLatency) / time.Duration(report.Submitted+1)
+			report.Submitted++
+		}
+		switch r.Proof {
+		case poets.ProofReceived:
+			received += r.ProofLatency
+			report.ProofsReceived++
+			report.AvgProofLatency = received / time.Duration(report.ProofsReceived)
+		case poets.ProofMissed:
+			report.ProofsMissed++
+		}
+	}
+	for i := range reports {
+		report := &reports[i]
+		if from == 0 {
+			report.ConsecutiveMissed = consecutiveMissed(report.Rounds)
+		} else {
+			history, err := poets.Submissions(h.db, report.Address, healthHistory)
+			if err != nil {
+				return nil, err
+			}
+			report.ConsecutiveMissed = consecutiveMissed(history)
+		}
+		report.Deprioritized = h.maxMissed > 0 && report.ConsecutiveMissed >= h.maxMissed
+	}
+	return reports, nil
+}
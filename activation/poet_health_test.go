@@ -0,0 +1,188 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
+)
+
+func TestPoetHealth(t *testing.T) {
+	const poet1, poet2 = "https://poet1", "https://poet2"
+	health := NewPoetHealth(sql.InMemory(), 10, 2, logtest.New(t))
+	request := func(id string) *types.PoetRequest {
+		return &types.PoetRequest{
+			PoetRound:     &types.PoetRound{ID: id},
+			PoetServiceID: types.PoetServiceID{ServiceID: []byte(id)},
+		}
+	}
+
+	health.Submitted(poet1, 2, request("poet1"), time.Second, nil)
+	health.ProofReceived(poet1, 2, 105, time.Minute)
+	health.Submitted(poet2, 2, nil, 3*time.Second, errors.New("unavailable"))
+	require.False(t, health.Deprioritized(poet1))
+	require.False(t, health.Deprioritized(poet2))
+
+	health.Submitted(poet1, 3, request("poet1"), 3*time.Second, nil)
+	health.ProofMissed(poet1, 3, errors.New("not found"))
+	health.Submitted(poet2, 3, request("poet2"), time.Second, nil)
+	health.ProofMissed(poet2, 3, errors.New("not found"))
+	require.False(t, health.Deprioritized(poet1))
+	require.True(t, health.Deprioritized(poet2))
+
+	// skipped and pending proofs don't change priority
+	health.Submitted(poet2, 4, request("poet2"), time.Second, nil)
+	health.ProofSkipped(poet2, 4)
+	health.Submitted(poet2, 5, request("poet2"), time.Second, nil)
+	require.True(t, health.Deprioritized(poet2))
+
+	// proof from unknown submission is ignored
+	health.ProofReceived(poet2, 6, 100, time.Minute)
+	health.ProofReceived(poet2, 5, 100, time.Minute)
+	require.False(t, health.Deprioritized(poet2))
+
+	reports, err := health.Report(3)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.Equal(t, poet1, reports[0].Address)
+	require.Equal(t, 1, reports[0].Submissions)
+	require.Equal(t, 1, reports[0].ProofsMissed)
+	require.Equal(t, 1, reports[0].ConsecutiveMissed)
+	require.Equal(t, "not found", reports[0].Rounds[0].Error)
+
+	reports, err = health.Report(0)
+	require.NoError(t, err)
+	require.Equal(t, PoetHealthReport{
+		Address:           poet1,
+		ServiceID:         []byte("poet1"),
+		Submissions:       2,
+		Submitted:         2,
+		ProofsReceived:    1,
+		ProofsMissed:      1,
+		ConsecutiveMissed: 1,
+		AvgSubmitLatency:  2 * time.Second,
+		AvgProofLatency:   time.Minute,
+		Rounds:            reports[0].Rounds,
+	}, reports[0])
+	require.Equal(t, uint64(105), reports[0].Rounds[1].LeafCount)
+	require.Equal(t, uint64(10), reports[0].Rounds[1].TickCount)
+
+	require.Equal(t, poet2, reports[1].Address)
+	require.Equal(t, []byte("poet2"), reports[1].ServiceID)
+	require.Equal(t, 4, reports[1].Submissions)
+	require.Equal(t, 3, reports[1].Submitted)
+	require.Equal(t, 0, reports[1].ConsecutiveMissed)
+	require.False(t, reports[1].Deprioritized)
+	require.Equal(t, poets.ProofReceived, reports[1].Rounds[0].Proof)
+	require.Equal(t, poets.ProofSkipped, reports[1].Rounds[1].Proof)
+
+	t.Run("disabled", func(t *testing.T) {
+		var health *PoetHealth
+		health.Submitted(poet1, 2, nil, time.Second, errors.New("unavailable"))
+		health.ProofMissed(poet1, 2, errors.New("not found"))
+		require.False(t, health.Deprioritized(poet1))
+		reports, err := health.Report(0)
+		require.NoError(t, err)
+		require.Empty(t, reports)
+
+		health = NewPoetHealth(sql.InMemory(), 10, 0, logtest.New(t))
+		for epoch := types.EpochID(1); epoch < 5; epoch++ {
+			health.Submitted(poet1, epoch, nil, time.Second, errors.New("unavailable"))
+		}
+		require.False(t, health.Deprioritized(poet1))
+	})
+}
+
+func TestNIPostBuilder_DeprioritizedPoet(t *testing.T) {
+	const healthy, slow = "http://localhost:9999", "http://localhost:9998"
+	challenge := types.RandomHash()
+	members := []types.Member{types.Member(challenge)}
+	proof := func(leaves uint64) *types.PoetProofMessage {
+		return &types.PoetProofMessage{PoetProof: types.PoetProof{LeafCount: leaves}}
+	}
+
+	setup := func(t *testing.T) (*NIPostBuilder, *PoetHealth, *MockPoetProvingServiceClient, *MockPoetProvingServiceClient) {
+		health := NewPoetHealth(sql.InMemory(), 1, 2, logtest.New(t))
+		for epoch := types.EpochID(1); epoch <= 2; epoch++ {
+			health.Submitted(slow, epoch, nil, time.Second, errors.New("unavailable"))
+		}
+		require.True(t, health.Deprioritized(slow))
+
+		ctrl := gomock.NewController(t)
+		poetDb := NewMockpoetDbAPI(ctrl)
+		poetDb.EXPECT().ValidateAndStore(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+		healthyPoet := defaultPoetServiceMock(t, []byte("healthy"), healthy)
+		slowPoet := defaultPoetServiceMock(t, []byte("slow"), slow)
+		nb, err := NewNIPostBuilder(
+			types.NodeID{1},
+			nil,
+			poetDb,
+			[]string{},
+			t.TempDir(),
+			logtest.New(t),
+			nil,
+			PoetConfig{GracePeriod: time.Minute},
+			nil,
+			withPoetClients([]PoetProvingServiceClient{healthyPoet, slowPoet}),
+			WithPoetHealth(health),
+		)
+		require.NoError(t, err)
+		nb.state = &types.NIPostBuilderState{Challenge: challenge}
+		for id, address := range map[string]string{"healthy": healthy, "slow": slow} {
+			request := types.PoetRequest{
+				PoetRound:     &types.PoetRound{ID: "3", End: types.RoundEnd(time.Now())},
+				PoetServiceID: types.PoetServiceID{ServiceID: []byte(id)},
+			}
+			nb.state.PoetRequests = append(nb.state.PoetRequests, request)
+			health.Submitted(address, 3, &request, time.Second, nil)
+		}
+		return nb, health, healthyPoet, slowPoet
+	}
+	lastProof := func(t *testing.T, health *PoetHealth, address string) poets.ProofState {
+		rounds, err := poets.Submissions(health.db, address, 1)
+		require.NoError(t, err)
+		require.Len(t, rounds, 1)
+		return rounds[0].Proof
+	}
+
+	t.Run("not awaited", func(t *testing.T) {
+		nb, health, healthyPoet, slowPoet := setup(t)
+		healthyPoet.EXPECT().Proof(gomock.Any(), "3").Return(proof(10), members, nil)
+		slowPoet.EXPECT().Proof(gomock.Any(), "3").AnyTimes().DoAndReturn(
+			func(ctx context.Context, _ string) (*types.PoetProofMessage, []types.Member, error) {
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			})
+
+		ref, _, err := nb.getBestProof(context.Background(), challenge, 3)
+		require.NoError(t, err)
+		expected, err := proof(10).Ref()
+		require.NoError(t, err)
+		require.Equal(t, expected, ref)
+		require.Equal(t, poets.ProofReceived, lastProof(t, health, healthy))
+		require.Equal(t, poets.ProofSkipped, lastProof(t, health, slow))
+		require.True(t, health.Deprioritized(slow))
+	})
+	t.Run("failover", func(t *testing.T) {
+		nb, health, healthyPoet, slowPoet := setup(t)
+		healthyPoet.EXPECT().Proof(gomock.Any(), "3").Return(nil, nil, ErrNotFound)
+		slowPoet.EXPECT().Proof(gomock.Any(), "3").Return(proof(20), members, nil)
+
+		ref, _, err := nb.getBestProof(context.Background(), challenge, 3)
+		require.NoError(t, err)
+		expected, err := proof(20).Ref()
+		require.NoError(t, err)
+		require.Equal(t, expected, ref)
+		require.Equal(t, poets.ProofMissed, lastProof(t, health, healthy))
+		require.Equal(t, poets.ProofReceived, lastProof(t, health, slow))
+		require.False(t, health.Deprioritized(slow))
+	})
+}
@@ -0,0 +1,109 @@
+package activation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"go.uber.org/zap"
+)
+
+// PoetClientFactory creates a client for the poet service at the address.
+// Scheme of the address is the one that the factory is registered for.
+type PoetClientFactory func(address *url.URL, cfg PoetConfig, logger *zap.Logger) (PoetProvingServiceClient, error)
+
+// PoetRegistry creates poet clients with a transport that is chosen by the scheme of the poet address.
+//
+// Transports registered by default:
+//   - http and https, also used if address has no scheme, for the REST api of the poet service.
+//   - grpc, e.g. grpc://poet:50002, for the grpc api of the poet service. Connection is not encrypted.
+//   - local, e.g. local://poet1, for the service registered in the same process with RegisterLocal.
+//
+// Other transports can be added with Register.
+type PoetRegistry struct {
+	logger *zap.Logger
+
+	mu        sync.RWMutex
+	factories map[string]PoetClientFactory
+	local     map[string]rpcapi.PoetServiceServer
+}
+
+// NewPoetRegistry creates registry with default transports.
+func NewPoetRegistry(logger *zap.Logger) *PoetRegistry {
+	r := &PoetRegistry{
+		logger:    logger,
+		factories: map[string]PoetClientFactory{},
+		local:     map[string]rpcapi.PoetServiceServer{},
+	}
+	r.Register("http", newHTTPPoetClient)
+	r.Register("https", newHTTPPoetClient)
+	r.Register("grpc", newGRPCPoetClient)
+	r.Register("local", r.newLocalPoetClient)
+	return r
+}
+
+// Register sets factory for the scheme, previously registered factory is replaced.
+func (r *PoetRegistry) Register(scheme string, factory PoetClientFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[strings.ToLower(scheme)] = factory
+}
+
+// RegisterLocal makes service available for clients with address local://<name>.
+// Clients look up the service on every request, therefore it can be registered
+// after clients were created.
+func (r *PoetRegistry) RegisterLocal(name string, service rpcapi.PoetServiceServer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.local[name] = service
+}
+
+// UnregisterLocal removes service registered with RegisterLocal.
+func (r *PoetRegistry) UnregisterLocal(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.local, name)
+}
+
+func (r *PoetRegistry) localService(name string) rpcapi.PoetServiceServer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.local[name]
+}
+
+// NewClient creates client for the poet service at the address.
+// It has the signature of PoETClientInitializer.
+func (r *PoetRegistry) NewClient(address string, cfg PoetConfig) (PoetProvingServiceClient, error) {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing poet address %s: %w", address, err)
+	}
+	r.mu.RLock()
+	factory, exists := r.factories[strings.ToLower(parsed.Scheme)]
+	r.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unsupported scheme %q of poet address %s", parsed.Scheme, address)
+	}
+	return factory(parsed, cfg, r.logger)
+}
+
+func newHTTPPoetClient(address *url.URL, cfg PoetConfig, logger *zap.Logger) (PoetProvingServiceClient, error) {
+	return NewHTTPPoetClient(address.String(), cfg, WithLogger(logger))
+}
+
+func newGRPCPoetClient(address *url.URL, cfg PoetConfig, logger *zap.Logger) (PoetProvingServiceClient, error) {
+	return NewGRPCPoetClient(address.String(), cfg, logger)
+}
+
+func (r *PoetRegistry) newLocalPoetClient(address *url.URL, cfg PoetConfig, logger *zap.Logger) (PoetProvingServiceClient, error) {
+	if address.Host == "" {
+		return nil, fmt.Errorf("local poet address %s doesn't have a name", address)
+	}
+	conn := &localPoetConn{name: address.Host, registry: r}
+	return newGRPCPoetClientWithConn(address.String(), conn, cfg, logger), nil
+}
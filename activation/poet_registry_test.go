@@ -0,0 +1,130 @@
+package activation
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+)
+
+type testPoetService struct {
+	rpcapi.UnimplementedPoetServiceServer
+	id    []byte
+	proof error
+}
+
+func (s *testPoetService) Info(context.Context, *rpcapi.InfoRequest) (*rpcapi.InfoResponse, error) {
+	return &rpcapi.InfoResponse{ServicePubkey: s.id}, nil
+}
+
+func (s *testPoetService) Proof(context.Context, *rpcapi.ProofRequest) (*rpcapi.ProofResponse, error) {
+	return nil, s.proof
+}
+
+func TestPoetRegistry(t *testing.T) {
+	cfg := DefaultPoetConfig()
+	cfg.MaxRequestRetries = 2
+	cfg.RequestRetryDelay = time.Millisecond
+	registry := NewPoetRegistry(zaptest.NewLogger(t))
+
+	t.Run("http", func(t *testing.T) {
+		for address, expected := range map[string]string{
+			"localhost:9100":          "http://localhost:9100",
+			"http://localhost:9100":   "http://localhost:9100",
+			"https://poet.example.io": "https://poet.example.io",
+		} {
+			client, err := registry.NewClient(address, cfg)
+			require.NoError(t, err)
+			require.IsType(t, &HTTPPoetClient{}, client)
+			require.Equal(t, expected, client.Address())
+		}
+	})
+	t.Run("grpc", func(t *testing.T) {
+		client, err := registry.NewClient("grpc://localhost:50002", cfg)
+		require.NoError(t, err)
+		require.IsType(t, &GRPCPoetClient{}, client)
+		require.Equal(t, "grpc://localhost:50002", client.Address())
+		require.NoError(t, client.(*GRPCPoetClient).Close())
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := registry.NewClient("ftp://localhost", cfg)
+		require.ErrorContains(t, err, "unsupported scheme")
+		_, err = registry.NewClient("local://", cfg)
+		require.Error(t, err)
+	})
+	t.Run("custom", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mock := NewMockPoetProvingServiceClient(ctrl)
+		registry := NewPoetRegistry(zap.NewNop())
+		registry.Register("TEST", func(address *url.URL, _ PoetConfig, _ *zap.Logger) (PoetProvingServiceClient, error) {
+			require.Equal(t, "poet", address.Host)
+			return mock, nil
+		})
+		client, err := registry.NewClient("test://poet", cfg)
+		require.NoError(t, err)
+		require.Equal(t, mock, client)
+	})
+	t.Run("local", func(t *testing.T) {
+		client, err := registry.NewClient("local://poet", cfg)
+		require.NoError(t, err)
+		require.Equal(t, "local://poet", client.Address())
+
+		ctx := context.Background()
+		_, err = client.PoetServiceID(ctx)
+		require.ErrorIs(t, err, ErrUnavailable)
+
+		svc := &testPoetService{id: types.RandomBytes(32), proof: status.Error(codes.NotFound, "no proof")}
+		registry.RegisterLocal("poet", svc)
+		id, err := client.PoetServiceID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, svc.id, id.ServiceID)
+
+		_, _, err = client.Proof(ctx, "1")
+		require.ErrorIs(t, err, ErrNotFound)
+		svc.proof = status.Error(codes.InvalidArgument, "bad round")
+		_, _, err = client.Proof(ctx, "1")
+		require.ErrorIs(t, err, ErrInvalidRequest)
+
+		registry.UnregisterLocal("poet")
+		_, _, err = client.Proof(ctx, "1")
+		require.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestNIPostBuilderPoetRegistry(t *testing.T) {
+	registry := NewPoetRegistry(zap.NewNop())
+	nb, err := NewNIPostBuilder(
+		types.RandomNodeID(),
+		nil,
+		nil,
+		[]string{"local://poet1", "grpc://localhost:50002", "localhost:9100"},
+		t.TempDir(),
+		logtest.New(t),
+		nil,
+		DefaultPoetConfig(),
+		nil,
+		WithNipostPoetRegistry(registry),
+	)
+	require.NoError(t, err)
+	require.Len(t, nb.poetProvers, 3)
+	require.IsType(t, &GRPCPoetClient{}, nb.poetProvers["local://poet1"])
+	require.IsType(t, &GRPCPoetClient{}, nb.poetProvers["grpc://localhost:50002"])
+	require.IsType(t, &HTTPPoetClient{}, nb.poetProvers["http://localhost:9100"])
+
+	_, err = NewNIPostBuilder(
+		types.RandomNodeID(), nil, nil, []string{"ftp://poet"}, t.TempDir(),
+		logtest.New(t), nil, DefaultPoetConfig(), nil,
+	)
+	require.Error(t, err)
+}
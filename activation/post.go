@@ -2,6 +2,7 @@ package activation
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/datastore"
 	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/hash"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/metrics/public"
 	"github.com/spacemeshos/go-spacemesh/sql"
@@ -48,6 +50,19 @@ func (c PostConfig) ToConfig() config.Config {
 	}
 }
 
+// Hash of the parameters that are used to verify proofs. Verifiers with different hashes
+// may disagree on validity of the same proof.
+func (c PostConfig) Hash() types.Hash32 {
+	var buf []byte
+	buf = binary.LittleEndian.AppendUint32(buf, c.MinNumUnits)
+	buf = binary.LittleEndian.AppendUint32(buf, c.MaxNumUnits)
+	buf = binary.LittleEndian.AppendUint64(buf, c.LabelsPerUnit)
+	buf = binary.LittleEndian.AppendUint32(buf, c.K1)
+	buf = binary.LittleEndian.AppendUint32(buf, c.K2)
+	buf = binary.LittleEndian.AppendUint32(buf, c.K3)
+	return types.Hash32(hash.Sum(buf, c.PowDifficulty[:]))
+}
+
 type PowDifficulty [32]byte
 
 func (d PowDifficulty) String() string {
@@ -157,6 +172,8 @@ type PostProofVerifyingOpts struct {
 	Workers int `mapstructure:"smeshing-opts-verifying-workers"`
 	// Flags used for the PoW verification.
 	Flags config.PowFlags `mapstructure:"smeshing-opts-verifying-powflags"`
+	// Reverify PoST of atxs that were verified before with the same parameters.
+	Reverify bool `mapstructure:"smeshing-opts-verifying-reverify"`
 }
 
 func DefaultPostVerifyingOpts() PostProofVerifyingOpts {
@@ -191,6 +208,9 @@ const (
 var (
 	errNotComplete = errors.New("not complete")
 	errNotStarted  = errors.New("not started")
+
+	// ErrPostNotComplete is returned when proof is requested before post data is initialized.
+	ErrPostNotComplete = errNotComplete
 )
 
 // DefaultPostConfig defines the default configuration for Post.
@@ -238,6 +258,111 @@ func (o PostSetupOpts) ToInitOpts() config.InitOpts {
 	}
 }
 
+// PostDataLock gives exclusive access to the post data to initialization, proving and
+// the data check. It is shared by PostSetupManager and PostChecker that use the same data.
+//
+// The check holds the lock with LockPreemptible and releases it when proving or initialization
+// waits for the lock, waiters acquire the lock in the order they started to wait.
+type PostDataLock struct {
+	sem chan struct{}
+
+	mu      sync.Mutex
+	waiting int
+	// preempt is closed when Lock waits while the lock is held with LockPreemptible.
+	preempt chan struct{}
+}
+
+// NewPostDataLock creates a new unlocked PostDataLock.
+func NewPostDataLock() *PostDataLock {
+	return &PostDataLock{sem: make(chan struct{}, 1)}
+}
+
+// Lock waits until the lock is acquired or ctx is canceled.
+func (l *PostDataLock) Lock(ctx context.Context) error {
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	default:
+	}
+	l.mu.Lock()
+	l.waiting++
+	if l.preempt != nil {
+		close(l.preempt)
+		l.preempt = nil
+	}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryLock acquires the lock if it is not held and reports whether it was acquired.
+func (l *PostDataLock) TryLock() bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// TryLockPreemptible acquires the lock if it is not held. The returned channel is closed
+// when the lock is requested with Lock, the holder is expected to release it soon after.
+func (l *PostDataLock) TryLockPreemptible() (<-chan struct{}, bool) {
+	if !l.TryLock() {
+		return nil, false
+	}
+	return l.preemptible(), true
+}
+
+// LockPreemptible waits until the lock is acquired or ctx is canceled, see TryLockPreemptible.
+func (l *PostDataLock) LockPreemptible(ctx context.Context) (<-chan struct{}, error) {
+	select {
+	case l.sem <- struct{}{}:
+		return l.preemptible(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *PostDataLock) preemptible() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	preempt := make(chan struct{})
+	if l.waiting > 0 {
+		close(preempt)
+	} else {
+		l.preempt = preempt
+	}
+	return preempt
+}
+
+// Unlock releases the lock.
+func (l *PostDataLock) Unlock() {
+	l.mu.Lock()
+	l.preempt = nil
+	l.mu.Unlock()
+	<-l.sem
+}
+
+// PostSetupManagerOpt configures PostSetupManager.
+type PostSetupManagerOpt func(*PostSetupManager)
+
+// WithPostSetupDataLock sets the lock that is held while the data is initialized or proven.
+func WithPostSetupDataLock(lock *PostDataLock) PostSetupManagerOpt {
+	return func(mgr *PostSetupManager) {
+		mgr.dataLock = lock
+	}
+}
+
 // PostSetupManager implements the PostProvider interface.
 type PostSetupManager struct {
 	id              types.NodeID
@@ -247,6 +372,7 @@ type PostSetupManager struct {
 	logger      log.Log
 	db          *datastore.CachedDB
 	goldenATXID types.ATXID
+	dataLock    *PostDataLock
 
 	mu          sync.Mutex                  // mu protects setting the values below.
 	lastOpts    *PostSetupOpts              // the last options used to initiate a Post setup session.
@@ -256,16 +382,28 @@ type PostSetupManager struct {
 }
 
 // NewPostSetupManager creates a new instance of PostSetupManager.
-func NewPostSetupManager(id types.NodeID, cfg PostConfig, logger log.Log, db *datastore.CachedDB, goldenATXID types.ATXID, provingOpts PostProvingOpts) (*PostSetupManager, error) {
+func NewPostSetupManager(
+	id types.NodeID,
+	cfg PostConfig,
+	logger log.Log,
+	db *datastore.CachedDB,
+	goldenATXID types.ATXID,
+	provingOpts PostProvingOpts,
+	opts ...PostSetupManagerOpt,
+) (*PostSetupManager, error) {
 	mgr := &PostSetupManager{
 		id:          id,
 		cfg:         cfg,
 		logger:      logger,
 		db:          db,
 		goldenATXID: goldenATXID,
+		dataLock:    NewPostDataLock(),
 		state:       PostSetupStateNotStarted,
 		provingOpts: provingOpts,
 	}
+	for _, opt := range opts {
+		opt(mgr)
+	}
 
 	return mgr, nil
 }
@@ -345,6 +483,11 @@ func (mgr *PostSetupManager) Benchmark(p PostSetupProvider) (int, error) {
 // in progress. It must be ensured that PrepareInitializer is called once
 // before each call to StartSession and that the node is ATX synced.
 func (mgr *PostSetupManager) StartSession(ctx context.Context) error {
+	// data is not initialized while it is checked
+	if err := mgr.dataLock.Lock(ctx); err != nil {
+		return err
+	}
+	defer mgr.dataLock.Unlock()
 	// Ensure only one goroutine can execute initialization at a time.
 	err := func() error {
 		mgr.mu.Lock()
@@ -540,6 +683,10 @@ func (mgr *PostSetupManager) GenerateProof(ctx context.Context, challenge []byte
 	}
 	opts = append(opts, options...)
 
+	if err := mgr.dataLock.Lock(ctx); err != nil {
+		return nil, nil, fmt.Errorf("wait for post data: %w", err)
+	}
+	defer mgr.dataLock.Unlock()
 	proof, proofMetadata, err := proving.Generate(ctx, challenge, mgr.cfg.ToConfig(), mgr.logger.Zap(), opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("generate proof: %w", err)
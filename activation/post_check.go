@@ -0,0 +1,502 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spacemeshos/post/config"
+	"github.com/spacemeshos/post/initialization"
+	"github.com/spacemeshos/post/oracle"
+	"github.com/spacemeshos/post/shared"
+
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+const (
+	// postCheckChunkLabels is the number of consecutive labels that are checked together,
+	// 256 labels are 4KiB, which is a typical size of the disk sector.
+	postCheckChunkLabels = 256
+	// postRepairBatchLabels is the number of labels computed at once during repair.
+	postRepairBatchLabels = 1 << 14
+)
+
+// ErrPostCheckInProgress is returned if the check is started while another one is running.
+var ErrPostCheckInProgress = errors.New("post data check is in progress")
+
+// ErrPostDataInUse is returned if the check is started while the data is initialized or proven.
+var ErrPostDataInUse = errors.New("post data is in use by initialization or proving")
+
+// PostCheckState is the state of the post data check.
+type PostCheckState int32
+
+const (
+	PostCheckStateNotStarted PostCheckState = 1 + iota
+	PostCheckStateInProgress
+	PostCheckStateComplete
+	PostCheckStateStopped
+	PostCheckStateError
+)
+
+func (s PostCheckState) String() string {
+	switch s {
+	case PostCheckStateNotStarted:
+		return "not_started"
+	case PostCheckStateInProgress:
+		return "in_progress"
+	case PostCheckStateComplete:
+		return "complete"
+	case PostCheckStateStopped:
+		return "stopped"
+	case PostCheckStateError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// PostCheckOpts selects how much of the post data is checked.
+type PostCheckOpts struct {
+	// Fraction of the labels that are checked, in percents.
+	Fraction float64
+	// Repair re-initializes corrupted ranges.
+	Repair bool
+}
+
+// PostDataRange is a range of labels [From, To] in a single file of post data.
+// Labels are indexed from the start of the data, not from the start of the file.
+type PostDataRange struct {
+	File      int
+	From, To  uint64
+	Truncated bool
+	Repaired  bool
+}
+
+// NumLabels in the range.
+func (r PostDataRange) NumLabels() uint64 {
+	return r.To - r.From + 1
+}
+
+// PostCheckStatus is a snapshot of the post data check.
+type PostCheckStatus struct {
+	State   PostCheckState
+	DataDir string
+	Opts    PostCheckOpts
+	Started time.Time
+	// SampledLabels is the number of labels selected for the check, CheckedLabels of them are checked.
+	SampledLabels  uint64
+	CheckedLabels  uint64
+	Corrupted      []PostDataRange
+	RepairedLabels uint64
+	Err            error
+}
+
+// Healthy returns true if the check found no corrupted labels, or all of them were repaired.
+func (s *PostCheckStatus) Healthy() bool {
+	for _, r := range s.Corrupted {
+		if !r.Repaired {
+			return false
+		}
+	}
+	return s.State == PostCheckStateComplete
+}
+
+// labelsOracle computes labels of the post data.
+type labelsOracle interface {
+	Positions(start, end uint64) (oracle.WorkOracleResult, error)
+	Close() error
+}
+
+func newLabelsOracle(opts PostSetupOpts, commitment []byte) (labelsOracle, error) {
+	provider := initialization.CPUProviderID()
+	if id := opts.ProviderID.Value(); id != nil {
+		provider = uint32(*id)
+	}
+	return oracle.New(
+		oracle.WithProviderID(&provider),
+		oracle.WithCommitment(commitment),
+		oracle.WithScryptParams(opts.Scrypt),
+		// nonce is not searched, but difficulty is required by the oracle
+		oracle.WithVRFDifficulty(make([]byte, 32)),
+	)
+}
+
+// PostCheckerOpt configures PostChecker.
+type PostCheckerOpt func(*PostChecker)
+
+// WithPostCheckDataLock sets the lock that is held while the data is checked, the check is paused
+// while proving or initialization holds the lock. It must be the same lock as the one of
+// PostSetupManager for the same data.
+func WithPostCheckDataLock(lock *PostDataLock) PostCheckerOpt {
+	return func(c *PostChecker) {
+		c.dataLock = lock
+	}
+}
+
+func withLabelsOracle(create func(PostSetupOpts, []byte) (labelsOracle, error)) PostCheckerOpt {
+	return func(c *PostChecker) {
+		c.newOracle = create
+	}
+}
+
+// PostChecker finds labels of the post data that were corrupted or lost since initialization,
+// and re-initializes them. Labels are sampled in chunks, and compared with the labels computed
+// for the commitment of the data.
+//
+// Sampling finds corrupted ranges with a probability that depends on the fraction of checked labels,
+// truncated and missing files are always found.
+//
+// The check doesn't delay proving: once proving waits for the data lock the check releases it,
+// and resumes from the same labels after proving is complete.
+type PostChecker struct {
+	logger    log.Log
+	newOracle func(PostSetupOpts, []byte) (labelsOracle, error)
+	dataLock  *PostDataLock
+	// preempted is closed when the data lock held by the running check is requested.
+	// It is nil if the check doesn't hold the lock.
+	preempted <-chan struct{}
+
+	mu     sync.Mutex
+	status PostCheckStatus
+	cancel context.CancelFunc
+}
+
+// NewPostChecker creates a new PostChecker.
+func NewPostChecker(logger log.Log, opts ...PostCheckerOpt) *PostChecker {
+	c := &PostChecker{
+		logger:    logger,
+		newOracle: newLabelsOracle,
+		dataLock:  NewPostDataLock(),
+		status:    PostCheckStatus{State: PostCheckStateNotStarted},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Status returns the status of the running or the last completed check.
+func (c *PostChecker) Status() *PostCheckStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := c.status
+	status.Corrupted = append([]PostDataRange(nil), c.status.Corrupted...)
+	return &status
+}
+
+// Start the check in background, progress is available with Status.
+func (c *PostChecker) Start(setup PostSetupOpts, opts PostCheckOpts) error {
+	ctx, err := c.begin(setup, opts)
+	if err != nil {
+		return err
+	}
+	go c.run(ctx, setup, opts)
+	return nil
+}
+
+// Stop the running check.
+func (c *PostChecker) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Check the data and wait until the check is complete.
+func (c *PostChecker) Check(ctx context.Context, setup PostSetupOpts, opts PostCheckOpts) (*PostCheckStatus, error) {
+	checkCtx, err := c.begin(setup, opts)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, c.Stop)
+	defer stop()
+	c.run(checkCtx, setup, opts)
+	status := c.Status()
+	return status, status.Err
+}
+
+func (c *PostChecker) begin(setup PostSetupOpts, opts PostCheckOpts) (context.Context, error) {
+	if opts.Fraction <= 0 || opts.Fraction > 100 {
+		return nil, fmt.Errorf("fraction must be in (0, 100], got %v", opts.Fraction)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State == PostCheckStateInProgress {
+		return nil, ErrPostCheckInProgress
+	}
+	// released when the check finishes in run, or while it is preempted
+	preempted, locked := c.dataLock.TryLockPreemptible()
+	if !locked {
+		return nil, ErrPostDataInUse
+	}
+	c.preempted = preempted
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.status = PostCheckStatus{
+		State:   PostCheckStateInProgress,
+		DataDir: setup.DataDir,
+		Opts:    opts,
+		Started: time.Now(),
+	}
+	return ctx, nil
+}
+
+func (c *PostChecker) run(ctx context.Context, setup PostSetupOpts, opts PostCheckOpts) {
+	c.logger.With().Info("checking post data",
+		log.String("datadir", setup.DataDir),
+		log.String("fraction", fmt.Sprintf("%.2f%%", opts.Fraction)),
+		log.Bool("repair", opts.Repair),
+	)
+	err := c.check(ctx, setup, opts)
+	if c.preempted != nil {
+		c.preempted = nil
+		c.dataLock.Unlock()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stopped := ctx.Err()
+	c.cancel()
+	c.cancel = nil
+	switch {
+	case stopped != nil:
+		c.status.State = PostCheckStateStopped
+		c.status.Err = stopped
+	case err != nil:
+		c.status.State = PostCheckStateError
+		c.status.Err = err
+	default:
+		c.status.State = PostCheckStateComplete
+	}
+	c.logger.With().Info("post data check finished",
+		log.String("datadir", setup.DataDir),
+		log.Stringer("state", c.status.State),
+		log.Uint64("checked_labels", c.status.CheckedLabels),
+		log.Int("corrupted_ranges", len(c.status.Corrupted)),
+		log.Uint64("repaired_labels", c.status.RepairedLabels),
+		log.Err(c.status.Err),
+	)
+}
+
+// postDataLayout describes files of the initialized data.
+type postDataLayout struct {
+	dir          string
+	totalLabels  uint64
+	fileLabels   uint64
+	bytesInLabel uint64
+}
+
+func (l *postDataLayout) numFiles() int {
+	return int((l.totalLabels + l.fileLabels - 1) / l.fileLabels)
+}
+
+func (l *postDataLayout) labelsInFile(file int) uint64 {
+	first := uint64(file) * l.fileLabels
+	return min(l.fileLabels, l.totalLabels-first)
+}
+
+func (l *postDataLayout) path(file int) string {
+	return filepath.Join(l.dir, shared.InitFileName(file))
+}
+
+func (c *PostChecker) check(ctx context.Context, setup PostSetupOpts, opts PostCheckOpts) error {
+	meta, err := initialization.LoadMetadata(setup.DataDir)
+	if err != nil {
+		return fmt.Errorf("load metadata: %w", err)
+	}
+	layout := &postDataLayout{
+		dir:          setup.DataDir,
+		totalLabels:  meta.LabelsPerUnit * uint64(meta.NumUnits),
+		bytesInLabel: uint64(config.BytesPerLabel()),
+	}
+	layout.fileLabels = meta.MaxFileSize / layout.bytesInLabel
+	if layout.totalLabels == 0 || layout.fileLabels == 0 {
+		return fmt.Errorf("invalid metadata: %d labels, max file size %d", layout.totalLabels, meta.MaxFileSize)
+	}
+	labels, err := c.newOracle(setup, oracle.CommitmentBytes(meta.NodeId, meta.CommitmentAtxId))
+	if err != nil {
+		return fmt.Errorf("create oracle: %w", err)
+	}
+	defer labels.Close()
+
+	// written[i] is the number of labels in the file i
+	written := make([]uint64, layout.numFiles())
+	for file := range written {
+		info, err := os.Stat(layout.path(file))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return err
+		default:
+			written[file] = min(uint64(info.Size())/layout.bytesInLabel, layout.labelsInFile(file))
+		}
+		if expected := layout.labelsInFile(file); written[file] < expected {
+			first := uint64(file) * layout.fileLabels
+			c.corrupted(PostDataRange{
+				File:      file,
+				From:      first + written[file],
+				To:        first + expected - 1,
+				Truncated: true,
+			})
+		}
+	}
+
+	chunks := (layout.totalLabels + postCheckChunkLabels - 1) / postCheckChunkLabels
+	step := max(uint64(100/opts.Fraction), 1)
+	sampled := (chunks + step - 1) / step
+	c.mu.Lock()
+	c.status.SampledLabels = sampled * postCheckChunkLabels
+	c.mu.Unlock()
+
+	for chunk := uint64(rand.Int63n(int64(step))); chunk < chunks; chunk += step {
+		if err := c.yield(ctx); err != nil {
+			return err
+		}
+		from := chunk * postCheckChunkLabels
+		to := min(from+postCheckChunkLabels, layout.totalLabels) - 1
+		if err := c.checkChunk(layout, labels, written, from, to); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.status.CheckedLabels += to - from + 1
+		c.mu.Unlock()
+	}
+
+	if !opts.Repair {
+		return nil
+	}
+	for i, r := range c.Status().Corrupted {
+		if err := c.repair(ctx, layout, labels, r); err != nil {
+			return fmt.Errorf("repair labels [%d, %d] in file %d: %w", r.From, r.To, r.File, err)
+		}
+		c.mu.Lock()
+		c.status.Corrupted[i].Repaired = true
+		c.status.RepairedLabels += r.NumLabels()
+		c.mu.Unlock()
+		c.logger.With().Info("repaired post data",
+			log.Int("file", r.File),
+			log.Uint64("from", r.From),
+			log.Uint64("to", r.To),
+		)
+	}
+	return nil
+}
+
+// yield releases the data lock if it was requested by proving or initialization,
+// and waits until the lock is acquired again.
+func (c *PostChecker) yield(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	select {
+	case <-c.preempted:
+	default:
+		return nil
+	}
+	c.preempted = nil
+	c.dataLock.Unlock()
+	c.logger.Info("post data check paused while the data is in use")
+	preempted, err := c.dataLock.LockPreemptible(ctx)
+	if err != nil {
+		return err
+	}
+	c.preempted = preempted
+	c.logger.Info("post data check resumed")
+	return nil
+}
+
+// checkChunk compares labels [from, to] with the computed labels, chunk may span two files.
+func (c *PostChecker) checkChunk(
+	layout *postDataLayout,
+	labels labelsOracle,
+	written []uint64,
+	from, to uint64,
+) error {
+	expected, err := labels.Positions(from, to)
+	if err != nil {
+		return fmt.Errorf("compute labels [%d, %d]: %w", from, to, err)
+	}
+	for start := from; start <= to; {
+		file := int(start / layout.fileLabels)
+		first := uint64(file) * layout.fileLabels
+		end := min(to, first+layout.labelsInFile(file)-1)
+		// labels past the end of the truncated file are already reported
+		available := first + written[file]
+		if start < available {
+			last := min(end, available-1)
+			stored, err := readLabels(layout, file, start-first, last-start+1)
+			if err != nil {
+				return err
+			}
+			offset := (start - from) * layout.bytesInLabel
+			want := expected.Output[offset : offset+uint64(len(stored))]
+			if string(stored) != string(want) {
+				c.corrupted(PostDataRange{File: file, From: start, To: last})
+			}
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+func readLabels(layout *postDataLayout, file int, offset, n uint64) ([]byte, error) {
+	f, err := os.Open(layout.path(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, n*layout.bytesInLabel)
+	if _, err := f.ReadAt(buf, int64(offset*layout.bytesInLabel)); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read %s: %w", f.Name(), err)
+	}
+	return buf, nil
+}
+
+// corrupted records the range, it is merged with the previous range if they are adjacent.
+func (c *PostChecker) corrupted(r PostDataRange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := len(c.status.Corrupted); n > 0 {
+		last := &c.status.Corrupted[n-1]
+		if last.File == r.File && last.Truncated == r.Truncated && last.To+1 == r.From {
+			last.To = r.To
+			return
+		}
+	}
+	c.status.Corrupted = append(c.status.Corrupted, r)
+	c.logger.With().Warning("found corrupted post data",
+		log.Int("file", r.File),
+		log.Uint64("from", r.From),
+		log.Uint64("to", r.To),
+		log.Bool("truncated", r.Truncated),
+	)
+}
+
+func (c *PostChecker) repair(ctx context.Context, layout *postDataLayout, labels labelsOracle, r PostDataRange) error {
+	f, err := os.OpenFile(layout.path(r.File), os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	first := uint64(r.File) * layout.fileLabels
+	for from := r.From; from <= r.To; from += postRepairBatchLabels {
+		if err := c.yield(ctx); err != nil {
+			return err
+		}
+		to := min(from+postRepairBatchLabels-1, r.To)
+		computed, err := labels.Positions(from, to)
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteAt(computed.Output, int64((from-first)*layout.bytesInLabel)); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
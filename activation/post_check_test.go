@@ -0,0 +1,212 @@
+package activation
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spacemeshos/post/initialization"
+	"github.com/spacemeshos/post/oracle"
+	"github.com/spacemeshos/post/shared"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+)
+
+// indexOracle returns the index of the label as its content.
+type indexOracle struct{}
+
+func (indexOracle) Positions(start, end uint64) (oracle.WorkOracleResult, error) {
+	out := make([]byte, 0, (end-start+1)*16)
+	for i := start; i <= end; i++ {
+		out = binary.LittleEndian.AppendUint64(out, i)
+		out = binary.LittleEndian.AppendUint64(out, ^i)
+	}
+	return oracle.WorkOracleResult{Output: out}, nil
+}
+
+func (indexOracle) Close() error { return nil }
+
+func newTestPostData(t *testing.T, totalLabels, fileLabels uint64) string {
+	dir := t.TempDir()
+	require.NoError(t, initialization.SaveMetadata(dir, &shared.PostMetadata{
+		NodeId:          types.RandomNodeID().Bytes(),
+		CommitmentAtxId: types.RandomATXID().Bytes(),
+		LabelsPerUnit:   totalLabels / 2,
+		NumUnits:        2,
+		MaxFileSize:     fileLabels * 16,
+	}))
+	for from, file := uint64(0), 0; from < totalLabels; from, file = from+fileLabels, file+1 {
+		labels, err := indexOracle{}.Positions(from, min(from+fileLabels, totalLabels)-1)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, shared.InitFileName(file)), labels.Output, 0o600))
+	}
+	return dir
+}
+
+func newTestPostChecker(t *testing.T) *PostChecker {
+	return NewPostChecker(logtest.New(t), withLabelsOracle(func(PostSetupOpts, []byte) (labelsOracle, error) {
+		return indexOracle{}, nil
+	}))
+}
+
+func TestPostChecker(t *testing.T) {
+	// 3 files, the last one is shorter
+	dir := newTestPostData(t, 2048, 768)
+	setup := DefaultPostSetupOpts()
+	setup.DataDir = dir
+
+	checker := newTestPostChecker(t)
+	status, err := checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.NoError(t, err)
+	require.True(t, status.Healthy())
+	require.Equal(t, uint64(2048), status.CheckedLabels)
+	require.Empty(t, status.Corrupted)
+
+	// flip a byte in the label 300 and truncate the last file
+	f, err := os.OpenFile(filepath.Join(dir, shared.InitFileName(0)), os.O_RDWR, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff}, 300*16)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, os.Truncate(filepath.Join(dir, shared.InitFileName(2)), 100*16))
+
+	status, err = checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.NoError(t, err)
+	require.False(t, status.Healthy())
+	require.Equal(t, []PostDataRange{
+		{File: 2, From: 1636, To: 2047, Truncated: true},
+		{File: 0, From: 256, To: 511},
+	}, status.Corrupted)
+
+	status, err = checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100, Repair: true})
+	require.NoError(t, err)
+	require.True(t, status.Healthy())
+	require.Equal(t, uint64(412+256), status.RepairedLabels)
+
+	status, err = checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.NoError(t, err)
+	require.True(t, status.Healthy())
+	require.Empty(t, status.Corrupted)
+}
+
+func TestPostChecker_MissingFile(t *testing.T) {
+	dir := newTestPostData(t, 2048, 1024)
+	require.NoError(t, os.Remove(filepath.Join(dir, shared.InitFileName(1))))
+	setup := DefaultPostSetupOpts()
+	setup.DataDir = dir
+
+	// missing data is found regardless of sampling
+	status, err := newTestPostChecker(t).Check(context.Background(), setup, PostCheckOpts{Fraction: 1, Repair: true})
+	require.NoError(t, err)
+	require.Equal(t, []PostDataRange{{File: 1, From: 1024, To: 2047, Truncated: true, Repaired: true}}, status.Corrupted)
+	require.FileExists(t, filepath.Join(dir, shared.InitFileName(1)))
+}
+
+func TestPostChecker_Sampling(t *testing.T) {
+	dir := newTestPostData(t, 256*100, 256*100)
+	setup := DefaultPostSetupOpts()
+	setup.DataDir = dir
+
+	status, err := newTestPostChecker(t).Check(context.Background(), setup, PostCheckOpts{Fraction: 10})
+	require.NoError(t, err)
+	require.Equal(t, uint64(256*10), status.SampledLabels)
+	require.Equal(t, status.SampledLabels, status.CheckedLabels)
+}
+
+func TestPostChecker_InvalidOpts(t *testing.T) {
+	checker := newTestPostChecker(t)
+	for _, fraction := range []float64{0, -1, 101} {
+		_, err := checker.Check(context.Background(), DefaultPostSetupOpts(), PostCheckOpts{Fraction: fraction})
+		require.Error(t, err)
+	}
+	_, err := checker.Check(context.Background(), PostSetupOpts{DataDir: t.TempDir()}, PostCheckOpts{Fraction: 100})
+	require.ErrorContains(t, err, "load metadata")
+	require.Equal(t, PostCheckStateError, checker.Status().State)
+}
+
+func TestPostChecker_DataLock(t *testing.T) {
+	dir := newTestPostData(t, 256, 256)
+	setup := DefaultPostSetupOpts()
+	setup.DataDir = dir
+
+	lock := NewPostDataLock()
+	checker := NewPostChecker(logtest.New(t),
+		WithPostCheckDataLock(lock),
+		withLabelsOracle(func(PostSetupOpts, []byte) (labelsOracle, error) {
+			// data can't be proven or initialized while it is checked
+			require.False(t, lock.TryLock())
+			return indexOracle{}, nil
+		}),
+	)
+	require.NoError(t, lock.Lock(context.Background()))
+	_, err := checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.ErrorIs(t, err, ErrPostDataInUse)
+	require.Equal(t, PostCheckStateNotStarted, checker.Status().State)
+	lock.Unlock()
+
+	status, err := checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.NoError(t, err)
+	require.True(t, status.Healthy())
+	require.True(t, lock.TryLock())
+}
+
+// hookOracle calls hook before labels from start are computed.
+type hookOracle struct {
+	indexOracle
+	hook func(start uint64)
+}
+
+func (o hookOracle) Positions(start, end uint64) (oracle.WorkOracleResult, error) {
+	o.hook(start)
+	return o.indexOracle.Positions(start, end)
+}
+
+func TestPostChecker_PreemptedByProving(t *testing.T) {
+	dir := newTestPostData(t, 4*postCheckChunkLabels, 4*postCheckChunkLabels)
+	setup := DefaultPostSetupOpts()
+	setup.DataDir = dir
+
+	lock := NewPostDataLock()
+	proven := make(chan error, 1)
+	checker := NewPostChecker(logtest.New(t),
+		WithPostCheckDataLock(lock),
+		withLabelsOracle(func(PostSetupOpts, []byte) (labelsOracle, error) {
+			return hookOracle{hook: func(start uint64) {
+				switch start {
+				case postCheckChunkLabels:
+					// proving waits for the data while the second chunk is checked
+					go func() {
+						err := lock.Lock(context.Background())
+						if err == nil {
+							lock.Unlock()
+						}
+						proven <- err
+					}()
+					require.Eventually(t, func() bool {
+						lock.mu.Lock()
+						defer lock.mu.Unlock()
+						return lock.waiting == 1
+					}, time.Second, time.Millisecond)
+				case 2 * postCheckChunkLabels:
+					// check is resumed after proving released the data
+					select {
+					case err := <-proven:
+						require.NoError(t, err)
+					default:
+						require.FailNow(t, "check is not paused for proving")
+					}
+				}
+			}}, nil
+		}),
+	)
+	status, err := checker.Check(context.Background(), setup, PostCheckOpts{Fraction: 100})
+	require.NoError(t, err)
+	require.True(t, status.Healthy())
+	require.Equal(t, uint64(4*postCheckChunkLabels), status.CheckedLabels)
+	require.True(t, lock.TryLock())
+}
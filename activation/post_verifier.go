@@ -2,17 +2,31 @@ package activation
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/spacemeshos/post/config"
 	"github.com/spacemeshos/post/shared"
 	"github.com/spacemeshos/post/verifying"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/spacemeshos/go-spacemesh/activation/metrics"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
 )
 
+// ErrRemotePostVerifierUnavailable is returned by RemotePostVerifier if it failed to verify the proof
+// for reasons other than the proof being invalid.
+var ErrRemotePostVerifierUnavailable = errors.New("remote post verifier is unavailable")
+
+// ErrPostVerifierClosed is returned by OffloadingPostVerifier after it was closed.
+var ErrPostVerifierClosed = errors.New("verifier is closed")
+
+// ErrInvalidPost wraps errors of the proof verification returned by the verifier from NewPostVerifier.
+var ErrInvalidPost = errors.New("invalid post")
+
 type verifyPostJob struct {
 	proof    *shared.Proof
 	metadata *shared.ProofMetadata
@@ -20,13 +34,53 @@ type verifyPostJob struct {
 	result   chan error
 }
 
+// remote is true if the job can be verified by RemotePostVerifier. Options can't be sent to the
+// remote verifier, so jobs with any options (such as label params) are verified locally.
+func (j *verifyPostJob) remote() bool {
+	return len(j.opts) == 0
+}
+
 type OffloadingPostVerifier struct {
 	eg      errgroup.Group
 	stop    context.CancelFunc
 	stopped <-chan struct{}
 	log     log.Log
 	workers []*postVerifierWorker
-	channel chan<- *verifyPostJob
+	channel chan *verifyPostJob
+
+	remoteWorkers []*remotePostVerifierWorker
+	// remote is nil if there are no remote workers.
+	remote chan *verifyPostJob
+}
+
+// OffloadingPostVerifierOpt configures OffloadingPostVerifier.
+type OffloadingPostVerifierOpt func(*OffloadingPostVerifier)
+
+// WithRemotePostVerifiers adds workers that dispatch jobs to the remote verifiers.
+// Every remote verifier gets up to concurrency jobs at a time, and is not used while its health
+// check fails. Jobs that remote verifier failed to verify are verified by the local workers.
+func WithRemotePostVerifiers(
+	concurrency int,
+	healthInterval time.Duration,
+	verifiers ...RemotePostVerifier,
+) OffloadingPostVerifierOpt {
+	return func(v *OffloadingPostVerifier) {
+		if len(verifiers) == 0 {
+			return
+		}
+		v.remote = make(chan *verifyPostJob)
+		for _, verifier := range verifiers {
+			v.remoteWorkers = append(v.remoteWorkers, &remotePostVerifierWorker{
+				verifier:       verifier,
+				concurrency:    concurrency,
+				healthInterval: healthInterval,
+				log:            v.log.Named("remote").WithFields(log.String("address", verifier.Address())),
+				channel:        v.remote,
+				fallback:       v.channel,
+				ready:          make(chan struct{}),
+			})
+		}
+	}
 }
 
 type postVerifierWorker struct {
@@ -43,7 +97,11 @@ type postVerifier struct {
 
 func (v *postVerifier) Verify(ctx context.Context, p *shared.Proof, m *shared.ProofMetadata, opts ...verifying.OptionFunc) error {
 	v.logger.WithContext(ctx).With().Debug("verifying post", log.FieldNamed("proof_node_id", types.BytesToNodeID(m.NodeId)))
-	return v.ProofVerifier.Verify(p, m, v.cfg, v.logger.Zap(), opts...)
+	opts = append([]verifying.OptionFunc{verifying.WithPowCreator(m.NodeId)}, opts...)
+	if err := v.ProofVerifier.Verify(p, m, v.cfg, v.logger.Zap(), opts...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPost, err)
+	}
+	return nil
 }
 
 // NewPostVerifier creates a new post verifier.
@@ -59,9 +117,14 @@ func NewPostVerifier(cfg PostConfig, logger log.Log, opts ...verifying.OptionFun
 // NewOffloadingPostVerifier creates a new post proof verifier with the given number of workers.
 // The verifier will distribute incoming proofs between the workers.
 // It will block if all workers are busy.
-func NewOffloadingPostVerifier(verifiers []PostVerifier, logger log.Log) *OffloadingPostVerifier {
+func NewOffloadingPostVerifier(
+	verifiers []PostVerifier,
+	logger log.Log,
+	opts ...OffloadingPostVerifierOpt,
+) *OffloadingPostVerifier {
 	numWorkers := len(verifiers)
-	channel := make(chan *verifyPostJob, numWorkers)
+	// jobs are not buffered, so that idle remote workers get the job if local workers are busy
+	channel := make(chan *verifyPostJob)
 	workers := make([]*postVerifierWorker, 0, numWorkers)
 
 	for i, verifier := range verifiers {
@@ -89,12 +152,25 @@ func NewOffloadingPostVerifier(verifiers []PostVerifier, logger log.Log) *Offloa
 			}
 		},
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
 
 	v.log.Info("starting post verifier")
 	for _, worker := range v.workers {
 		worker := worker
 		v.eg.Go(func() error { return worker.start(ctx) })
 	}
+	for _, worker := range v.remoteWorkers {
+		worker := worker
+		v.eg.Go(func() error { return worker.checkHealth(ctx) })
+		for i := 0; i < worker.concurrency; i++ {
+			v.eg.Go(func() error { return worker.start(ctx) })
+		}
+	}
+	if len(v.remoteWorkers) > 0 {
+		v.log.With().Info("using remote post verifiers", log.Int("num_remote", len(v.remoteWorkers)))
+	}
 	v.log.Info("started post verifier")
 	return v
 }
@@ -106,11 +182,16 @@ func (v *OffloadingPostVerifier) Verify(ctx context.Context, p *shared.Proof, m
 		opts:     opts,
 		result:   make(chan error, 1),
 	}
+	remote := v.remote
+	if !job.remote() {
+		remote = nil
+	}
 
 	select {
 	case v.channel <- job:
+	case remote <- job:
 	case <-v.stopped:
-		return fmt.Errorf("verifier is closed")
+		return ErrPostVerifierClosed
 	case <-ctx.Done():
 		return fmt.Errorf("submitting verifying job: %w", ctx.Err())
 	}
@@ -119,7 +200,7 @@ func (v *OffloadingPostVerifier) Verify(ctx context.Context, p *shared.Proof, m
 	case res := <-job.result:
 		return res
 	case <-v.stopped:
-		return fmt.Errorf("verifier is closed")
+		return ErrPostVerifierClosed
 	case <-ctx.Done():
 		return fmt.Errorf("waiting for verification result: %w", ctx.Err())
 	}
@@ -135,6 +216,11 @@ func (v *OffloadingPostVerifier) Close() error {
 			return err
 		}
 	}
+	for _, worker := range v.remoteWorkers {
+		if err := worker.verifier.Close(); err != nil {
+			return err
+		}
+	}
 	v.log.Info("stopped post verifier")
 	return nil
 }
@@ -148,6 +234,95 @@ func (w *postVerifierWorker) start(ctx context.Context) error {
 			return ctx.Err()
 		case job := <-w.channel:
 			job.result <- w.verifier.Verify(ctx, job.proof, job.metadata, job.opts...)
+			metrics.PostVerifications.WithLabelValues("local").Inc()
 		}
 	}
 }
+
+// remotePostVerifierWorker takes jobs while remote verifier is healthy.
+type remotePostVerifierWorker struct {
+	verifier       RemotePostVerifier
+	concurrency    int
+	healthInterval time.Duration
+	log            log.Log
+	channel        <-chan *verifyPostJob
+	// fallback is the channel of local workers.
+	fallback chan<- *verifyPostJob
+
+	mu sync.Mutex
+	// ready is closed while remote verifier is healthy.
+	ready   chan struct{}
+	healthy bool
+	checked bool
+}
+
+func (w *remotePostVerifierWorker) available() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ready
+}
+
+func (w *remotePostVerifierWorker) setHealthy(healthy bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.checked && w.healthy == healthy {
+		return
+	}
+	w.checked = true
+	if !healthy {
+		w.log.With().Warning("remote post verifier is unhealthy", log.Err(err))
+		if w.healthy {
+			w.ready = make(chan struct{})
+		}
+		w.healthy = false
+		return
+	}
+	w.log.Info("remote post verifier is healthy")
+	w.healthy = true
+	close(w.ready)
+}
+
+func (w *remotePostVerifierWorker) checkHealth(ctx context.Context) error {
+	ticker := time.NewTicker(w.healthInterval)
+	defer ticker.Stop()
+	for {
+		err := w.verifier.Health(ctx)
+		w.setHealthy(err == nil, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *remotePostVerifierWorker) start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.available():
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case job := <-w.channel:
+			w.verify(ctx, job)
+		}
+	}
+}
+
+func (w *remotePostVerifierWorker) verify(ctx context.Context, job *verifyPostJob) {
+	err := w.verifier.Verify(ctx, job.proof, job.metadata)
+	if !errors.Is(err, ErrRemotePostVerifierUnavailable) {
+		job.result <- err
+		metrics.PostVerifications.WithLabelValues("remote").Inc()
+		return
+	}
+	w.setHealthy(false, err)
+	metrics.PostVerifications.WithLabelValues("fallback").Inc()
+	select {
+	case w.fallback <- job:
+	case <-ctx.Done():
+	}
+}
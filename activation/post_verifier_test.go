@@ -3,10 +3,13 @@ package activation_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/spacemeshos/post/config"
 	"github.com/spacemeshos/post/shared"
+	"github.com/spacemeshos/post/verifying"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/sync/errgroup"
@@ -39,6 +42,72 @@ func TestOffloadingPostVerifier(t *testing.T) {
 	require.ErrorContains(t, err, "invalid proof!")
 }
 
+func TestOffloadingPostVerifier_Remote(t *testing.T) {
+	proof := shared.Proof{}
+	metadata := shared.ProofMetadata{}
+	newRemote := func(t *testing.T, healthErr error) *activation.MockRemotePostVerifier {
+		remote := activation.NewMockRemotePostVerifier(gomock.NewController(t))
+		remote.EXPECT().Address().Return("remote:9095").AnyTimes()
+		remote.EXPECT().Health(gomock.Any()).Return(healthErr).AnyTimes()
+		remote.EXPECT().Close().Return(nil)
+		return remote
+	}
+
+	t.Run("verified remotely", func(t *testing.T) {
+		remote := newRemote(t, nil)
+		v := activation.NewOffloadingPostVerifier(nil, log.NewDefault(t.Name()),
+			activation.WithRemotePostVerifiers(2, time.Hour, remote))
+		defer v.Close()
+
+		remote.EXPECT().Verify(gomock.Any(), &proof, &metadata).Return(nil)
+		require.NoError(t, v.Verify(context.Background(), &proof, &metadata))
+
+		remote.EXPECT().Verify(gomock.Any(), &proof, &metadata).Return(errors.New("invalid proof!"))
+		require.ErrorContains(t, v.Verify(context.Background(), &proof, &metadata), "invalid proof!")
+	})
+	t.Run("unhealthy is not used", func(t *testing.T) {
+		remote := newRemote(t, errors.New("unreachable"))
+		v := activation.NewOffloadingPostVerifier(nil, log.NewDefault(t.Name()),
+			activation.WithRemotePostVerifiers(1, time.Hour, remote))
+		defer v.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, v.Verify(ctx, &proof, &metadata), context.DeadlineExceeded)
+	})
+	t.Run("jobs with options are verified locally", func(t *testing.T) {
+		remote := newRemote(t, nil)
+		local := activation.NewMockPostVerifier(gomock.NewController(t))
+		local.EXPECT().Close().Return(nil)
+		v := activation.NewOffloadingPostVerifier([]activation.PostVerifier{local}, log.NewDefault(t.Name()),
+			activation.WithRemotePostVerifiers(1, time.Hour, remote))
+		defer v.Close()
+
+		local.EXPECT().Verify(gomock.Any(), &proof, &metadata, gomock.Any()).Return(nil).Times(10)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, v.Verify(context.Background(), &proof, &metadata,
+				verifying.WithLabelScryptParams(config.DefaultLabelParams())))
+		}
+	})
+	t.Run("fallback to local", func(t *testing.T) {
+		remote := newRemote(t, nil)
+		local := activation.NewMockPostVerifier(gomock.NewController(t))
+		local.EXPECT().Close().Return(nil)
+		v := activation.NewOffloadingPostVerifier([]activation.PostVerifier{local}, log.NewDefault(t.Name()),
+			activation.WithRemotePostVerifiers(1, time.Hour, remote))
+		defer v.Close()
+
+		// remote is unhealthy after the failure and isn't checked again, all jobs are verified locally
+		remote.EXPECT().Verify(gomock.Any(), &proof, &metadata).
+			Return(fmt.Errorf("%w: connection refused", activation.ErrRemotePostVerifierUnavailable)).
+			MaxTimes(1)
+		local.EXPECT().Verify(gomock.Any(), &proof, &metadata, gomock.Any()).Return(nil).Times(20)
+		for i := 0; i < 20; i++ {
+			require.NoError(t, v.Verify(context.Background(), &proof, &metadata))
+		}
+	})
+}
+
 func TestPostVerifierDetectsInvalidProof(t *testing.T) {
 	verifier, err := activation.NewPostVerifier(activation.PostConfig{}, log.NewDefault(t.Name()))
 	require.NoError(t, err)
@@ -0,0 +1,231 @@
+package activation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/post/proving"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// Names of the checks in PreflightReport.
+const (
+	PreflightCoinbase       = "coinbase"
+	PreflightPostSetup      = "post-setup"
+	PreflightAtxSync        = "atx-sync"
+	PreflightChallenge      = "challenge"
+	PreflightPositioningAtx = "positioning-atx"
+	PreflightPost           = "post"
+	PreflightClock          = "clock"
+	// PreflightPoet is a prefix of the check, full name is poet:<address>.
+	PreflightPoet = "poet:"
+)
+
+// poetPreflightTimeout limits requests to every poet.
+const poetPreflightTimeout = 10 * time.Second
+
+// PreflightCheck is an outcome of a single check, it passed if Err is nil.
+type PreflightCheck struct {
+	Name     string
+	Details  string
+	Err      error
+	Duration time.Duration
+}
+
+// PreflightDeadlines for the next atx.
+type PreflightDeadlines struct {
+	// PoetRoundStart is the deadline for the submission of the challenge to poets.
+	PoetRoundStart time.Time
+	PoetRoundEnd   time.Time
+	// PoetProofDeadline is the last moment node queries poets for the proof.
+	PoetProofDeadline time.Time
+	PublishEpochStart time.Time
+	// PublishEpochEnd is the deadline for the publication of the atx.
+	PublishEpochEnd time.Time
+}
+
+// PreflightReport is the result of the dry run of atx publication.
+type PreflightReport struct {
+	Challenge *types.NIPostChallenge
+	Deadlines PreflightDeadlines
+	Checks    []PreflightCheck
+}
+
+// Failed returns checks that didn't pass.
+func (r *PreflightReport) Failed() []PreflightCheck {
+	var failed []PreflightCheck
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// Preflight runs the steps of atx publication without submitting or broadcasting anything,
+// so that misconfiguration is detected before it makes node miss the deadlines.
+// PoST proof for a random challenge is generated and verified only if withPost is true,
+// as it takes as long as the proof for the real challenge.
+//
+// Errors of the checks are reported in PreflightReport, error is returned only if ctx was canceled.
+func (b *Builder) Preflight(ctx context.Context, withPost bool) (*PreflightReport, error) {
+	report := &PreflightReport{}
+	check := func(name string, f func() (string, error)) {
+		start := time.Now()
+		details, err := f()
+		report.Checks = append(report.Checks, PreflightCheck{
+			Name:     name,
+			Details:  details,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+
+	check(PreflightCoinbase, func() (string, error) {
+		coinbase := b.Coinbase()
+		if coinbase == (types.Address{}) {
+			return "", errors.New("coinbase is not set")
+		}
+		return coinbase.String(), nil
+	})
+	check(PreflightPostSetup, func() (string, error) {
+		status := b.postSetupProvider.Status()
+		if status.State != PostSetupStateComplete {
+			return "", fmt.Errorf("post setup: %w (state %d)", ErrPostNotComplete, status.State)
+		}
+		return fmt.Sprintf("%d units", b.postSetupProvider.LastOpts().NumUnits), nil
+	})
+	check(PreflightAtxSync, func() (string, error) {
+		select {
+		case <-b.syncer.RegisterForATXSynced():
+			return "", nil
+		default:
+			return "", errors.New("atxs are not synced")
+		}
+	})
+	if b.clockOffset != nil {
+		check(PreflightClock, func() (string, error) {
+			offset, ok := b.clockOffset.Offset()
+			switch {
+			case !ok:
+				return "offset is not measured yet", nil
+			case offset > b.maxClockOffset || -offset > b.maxClockOffset:
+				return "", fmt.Errorf("clock offset from peers %v exceeds %v", offset, b.maxClockOffset)
+			}
+			return fmt.Sprintf("offset from peers %v", offset), nil
+		})
+	}
+
+	current := b.currentEpoch()
+	check(PreflightChallenge, func() (string, error) {
+		var err error
+		current, err = b.challengeEpoch()
+		if err != nil {
+			return "", err
+		}
+		report.Challenge, err = b.newChallenge(current + 1)
+		if err != nil {
+			return "", err
+		}
+		if until := time.Until(b.poetRoundStart(current)); until <= 0 {
+			return "", fmt.Errorf("%w: poet round for publish epoch %d started %v ago",
+				ErrATXChallengeExpired, current+1, -until)
+		}
+		if report.Challenge.PrevATXID == types.EmptyATXID {
+			return fmt.Sprintf("initial atx in publish epoch %d", current+1), nil
+		}
+		return fmt.Sprintf("atx %d in publish epoch %d after %s",
+			report.Challenge.Sequence, current+1, report.Challenge.PrevATXID.ShortString()), nil
+	})
+	report.Deadlines = b.deadlines(current + 1)
+
+	check(PreflightPositioningAtx, func() (string, error) {
+		id, err := b.GetPositioningAtx()
+		if err != nil {
+			return "", err
+		}
+		if id == b.goldenATXID {
+			return "golden atx", nil
+		}
+		hdr, err := b.cdb.GetAtxHeader(id)
+		if err != nil {
+			return "", fmt.Errorf("positioning atx %s is not available: %w", id.ShortString(), err)
+		}
+		return fmt.Sprintf("%s from publish epoch %d with tick height %d",
+			id.ShortString(), hdr.PublishEpoch, hdr.TickHeight()), nil
+	})
+
+	for _, poet := range b.preflightPoets() {
+		check(PreflightPoet+poet.Address(), func() (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, poetPreflightTimeout)
+			defer cancel()
+			id, err := poet.PoetServiceID(ctx)
+			if err != nil {
+				return "", fmt.Errorf("query service id: %w", err)
+			}
+			if _, err := poet.PowParams(ctx); err != nil {
+				return "", fmt.Errorf("query pow params: %w", err)
+			}
+			return hex.EncodeToString(id.ServiceID), nil
+		})
+	}
+
+	if withPost {
+		check(PreflightPost, func() (string, error) {
+			return b.preflightPost(ctx, current+1)
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// preflightPoets returns clients that will be used for the next submission.
+func (b *Builder) preflightPoets() []PoetProvingServiceClient {
+	if pending := b.pendingPoetClients.Load(); pending != nil {
+		return *pending
+	}
+	return b.nipostBuilder.Poets()
+}
+
+// preflightPost generates and verifies proof for a random challenge.
+func (b *Builder) preflightPost(ctx context.Context, publish types.EpochID) (string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+	start := time.Now()
+	post, metadata, err := b.postSetupProvider.GenerateProof(ctx, challenge, proving.WithPowCreator(b.nodeID.Bytes()))
+	if err != nil {
+		return "", fmt.Errorf("generate proof: %w", err)
+	}
+	generated := time.Since(start)
+	commitmentAtx, err := b.postSetupProvider.CommitmentAtx()
+	if err != nil {
+		return "", fmt.Errorf("get commitment atx: %w", err)
+	}
+	err = b.validator.Post(ctx, publish, b.nodeID, commitmentAtx, post, metadata, b.postSetupProvider.LastOpts().NumUnits)
+	if err != nil {
+		return "", fmt.Errorf("verify proof: %w", err)
+	}
+	return fmt.Sprintf("generated in %v", generated.Round(time.Millisecond)), nil
+}
+
+// deadlines follows the schedule of NIPostBuilder.BuildNIPost.
+func (b *Builder) deadlines(publish types.EpochID) PreflightDeadlines {
+	publishStart := b.layerClock.LayerToTime(publish.FirstLayer())
+	publishEnd := b.layerClock.LayerToTime((publish + 1).FirstLayer())
+	return PreflightDeadlines{
+		PoetRoundStart:    b.poetRoundStart(publish - 1),
+		PoetRoundEnd:      publishStart.Add(b.poetCfg.PhaseShift).Add(-b.poetCfg.CycleGap),
+		PoetProofDeadline: publishEnd.Add(-b.poetCfg.CycleGap),
+		PublishEpochStart: publishStart,
+		PublishEpochEnd:   publishEnd,
+	}
+}
@@ -0,0 +1,143 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+type fixedClockOffset time.Duration
+
+func (o fixedClockOffset) Offset() (time.Duration, bool) {
+	return time.Duration(o), true
+}
+
+func preflightPoet(t *testing.T, address string, err error) *MockPoetProvingServiceClient {
+	poet := NewMockPoetProvingServiceClient(gomock.NewController(t))
+	poet.EXPECT().Address().Return(address).AnyTimes()
+	poet.EXPECT().PoetServiceID(gomock.Any()).Return(types.PoetServiceID{ServiceID: []byte{1}}, err)
+	if err == nil {
+		poet.EXPECT().PowParams(gomock.Any()).Return(&PoetPowParams{}, nil)
+	}
+	return poet
+}
+
+func failedChecks(report *PreflightReport) []string {
+	var names []string
+	for _, check := range report.Failed() {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+func TestBuilder_Preflight(t *testing.T) {
+	current := (postGenesisEpoch + 1).FirstLayer()
+	now := time.Now()
+	layerTime := func(lid types.LayerID) time.Time {
+		return now.Add(time.Duration(int64(lid)-int64(current)) * layerDuration)
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		tab := newTestBuilder(t,
+			WithPoetConfig(PoetConfig{PhaseShift: 5 * layerDuration, CycleGap: 2 * layerDuration}),
+			WithClockOffset(fixedClockOffset(20*time.Second), 10*time.Second),
+		)
+		prev := addPrevAtx(t, tab.cdb, postGenesisEpoch, tab.sig)
+		tab.mclock.EXPECT().CurrentLayer().Return(current).AnyTimes()
+		tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(layerTime).AnyTimes()
+		tab.mpost.EXPECT().Status().Return(&PostSetupStatus{State: PostSetupStateComplete})
+		tab.mpost.EXPECT().LastOpts().Return(&PostSetupOpts{NumUnits: 4}).AnyTimes()
+		tab.mpost.EXPECT().GenerateProof(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&types.Post{}, &types.PostMetadata{}, nil)
+		commitment := types.RandomATXID()
+		tab.mpost.EXPECT().CommitmentAtx().Return(commitment, nil)
+		tab.mValidator.EXPECT().Post(gomock.Any(), postGenesisEpoch+2, tab.nodeID, commitment,
+			gomock.Any(), gomock.Any(), uint32(4)).Return(nil)
+		tab.mnipost.EXPECT().Poets().Return([]PoetProvingServiceClient{
+			preflightPoet(t, "http://poet1", nil),
+			preflightPoet(t, "http://poet2", ErrUnavailable),
+		})
+
+		report, err := tab.Preflight(context.Background(), true)
+		require.NoError(t, err)
+		require.Equal(t, []string{PreflightClock, PreflightPoet + "http://poet2"}, failedChecks(report))
+		require.ErrorIs(t, report.Failed()[1].Err, ErrUnavailable)
+		for _, check := range report.Checks {
+			if check.Name == PreflightPositioningAtx {
+				require.Contains(t, check.Details, prev.ID().ShortString())
+			}
+		}
+
+		require.Equal(t, &types.NIPostChallenge{
+			PublishEpoch:   postGenesisEpoch + 2,
+			Sequence:       prev.Sequence + 1,
+			PrevATXID:      prev.ID(),
+			PositioningATX: prev.ID(),
+		}, report.Challenge)
+		require.Equal(t, PreflightDeadlines{
+			PoetRoundStart:    layerTime(current).Add(5 * layerDuration),
+			PoetRoundEnd:      layerTime((postGenesisEpoch + 2).FirstLayer()).Add(3 * layerDuration),
+			PoetProofDeadline: layerTime((postGenesisEpoch + 3).FirstLayer()).Add(-2 * layerDuration),
+			PublishEpochStart: layerTime((postGenesisEpoch + 2).FirstLayer()),
+			PublishEpochEnd:   layerTime((postGenesisEpoch + 3).FirstLayer()),
+		}, report.Deadlines)
+
+		_, err = LoadNipostChallenge(tab.mnipost.DataDir())
+		require.Error(t, err, "preflight must not persist the challenge")
+	})
+	t.Run("not ready", func(t *testing.T) {
+		tab := newTestBuilder(t,
+			WithPoETClientInitializer(func(address string, _ PoetConfig) (PoetProvingServiceClient, error) {
+				poet := NewMockPoetProvingServiceClient(gomock.NewController(t))
+				poet.EXPECT().Address().Return(address).AnyTimes()
+				poet.EXPECT().PoetServiceID(gomock.Any()).Return(types.PoetServiceID{ServiceID: []byte{1}}, nil).Times(2)
+				poet.EXPECT().PowParams(gomock.Any()).Return(nil, errors.New("bad request"))
+				return poet, nil
+			}),
+		)
+		tab.SetCoinbase(types.Address{})
+		require.NoError(t, tab.UpdatePoETServers(context.Background(), []string{"http://poet3"}))
+		tab.mclock.EXPECT().CurrentLayer().Return(current.Add(1)).AnyTimes()
+		tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(layerTime).AnyTimes()
+		tab.mpost.EXPECT().Status().Return(&PostSetupStatus{State: PostSetupStateInProgress})
+		commitment := types.RandomATXID()
+		tab.mpost.EXPECT().CommitmentAtx().Return(commitment, nil)
+
+		report, err := tab.Preflight(context.Background(), false)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			PreflightCoinbase,
+			PreflightPostSetup,
+			PreflightChallenge,
+			PreflightPoet + "http://poet3",
+		}, failedChecks(report))
+		require.ErrorIs(t, report.Failed()[1].Err, ErrPostNotComplete)
+		require.ErrorIs(t, report.Failed()[2].Err, ErrATXChallengeExpired)
+		require.Equal(t, &types.NIPostChallenge{
+			PublishEpoch:   postGenesisEpoch + 2,
+			PositioningATX: tab.goldenATXID,
+			CommitmentATX:  &commitment,
+			InitialPost:    tab.initialPost,
+		}, report.Challenge)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		tab := newTestBuilder(t)
+		tab.mclock.EXPECT().CurrentLayer().Return(current).AnyTimes()
+		tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(layerTime).AnyTimes()
+		tab.mpost.EXPECT().Status().Return(&PostSetupStatus{State: PostSetupStateComplete})
+		tab.mpost.EXPECT().LastOpts().Return(&PostSetupOpts{}).AnyTimes()
+		tab.mpost.EXPECT().CommitmentAtx().Return(types.RandomATXID(), nil)
+		tab.mnipost.EXPECT().Poets().Return(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := tab.Preflight(ctx, false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
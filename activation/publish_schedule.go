@@ -0,0 +1,308 @@
+package activation
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/activation/metrics"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// maxPublishRetryBackoff limits the exponent of the delay between retries of the failed phase.
+const maxPublishRetryBackoff = 6
+
+// PublishPhase is a step of the atx publication. Every phase has a hard deadline,
+// atx can't be published in the publish epoch if the phase is not completed before it.
+type PublishPhase uint8
+
+const (
+	// PublishPhaseWait is waiting for the poet round in which the challenge is submitted.
+	PublishPhaseWait PublishPhase = iota
+	// PublishPhaseChallenge is waiting for atx sync and selecting the positioning atx.
+	PublishPhaseChallenge
+	PublishPhasePoetSubmit
+	PublishPhasePoetProof
+	PublishPhasePost
+	// PublishPhaseAwaitEpoch is waiting for the start of the publish epoch with complete nipost.
+	PublishPhaseAwaitEpoch
+	// PublishPhaseBroadcast is broadcasting atx and waiting until it is received back.
+	PublishPhaseBroadcast
+	PublishPhasePublished
+	// PublishPhaseMissed is set once the builder gives up on the publish epoch.
+	PublishPhaseMissed
+)
+
+func (p PublishPhase) String() string {
+	switch p {
+	case PublishPhaseWait:
+		return "wait"
+	case PublishPhaseChallenge:
+		return "challenge"
+	case PublishPhasePoetSubmit:
+		return "poet_submit"
+	case PublishPhasePoetProof:
+		return "poet_proof"
+	case PublishPhasePost:
+		return "post"
+	case PublishPhaseAwaitEpoch:
+		return "await_publish_epoch"
+	case PublishPhaseBroadcast:
+		return "broadcast"
+	case PublishPhasePublished:
+		return "published"
+	case PublishPhaseMissed:
+		return "missed"
+	}
+	return "unknown"
+}
+
+// nipost returns true for the phases of nipost construction, they are tracked by NIPostBuilder.
+func (p PublishPhase) nipost() bool {
+	return p == PublishPhasePoetSubmit || p == PublishPhasePoetProof || p == PublishPhasePost
+}
+
+// done returns true if the publish epoch was completed, either by publishing atx or by giving up on it.
+func (p PublishPhase) done() bool {
+	return p == PublishPhasePublished || p == PublishPhaseMissed
+}
+
+// PublishSchedule is the plan of the atx publication in the publish epoch. It is planned backward
+// from the end of the publish epoch: post is generated within the cycle gap before the end, the poet
+// proof is queried before that, and the challenge is submitted before the poet round starts.
+type PublishSchedule struct {
+	PublishEpoch types.EpochID
+	// ChallengeStart is when the builder starts to build the challenge, a grace period before the poet round.
+	ChallengeStart time.Time
+	PreflightDeadlines
+}
+
+// Deadline returns the moment after which the phase can't be completed in time to publish atx.
+func (s *PublishSchedule) Deadline(phase PublishPhase) time.Time {
+	switch phase {
+	case PublishPhaseWait, PublishPhaseChallenge, PublishPhasePoetSubmit:
+		return s.PoetRoundStart
+	case PublishPhasePoetProof:
+		return s.PoetProofDeadline
+	case PublishPhasePost, PublishPhaseAwaitEpoch, PublishPhaseBroadcast:
+		return s.PublishEpochEnd
+	}
+	return time.Time{}
+}
+
+// retryDelay returns the delay before the next attempt of the phase that failed attempts times,
+// or false if the publish epoch should be given up.
+//
+// Delay doubles with every attempt, but never exceeds half of the time left before the deadline,
+// so that the last attempts are made close to the deadline. The epoch is given up once
+// less than interval is left.
+//
+// Phases of nipost construction are never given up here. NIPostBuilder checks the deadline
+// of the phase it is in and fails with ErrATXChallengeExpired once it has passed, so such phases
+// are retried at least every interval.
+func (s *PublishSchedule) retryDelay(
+	phase PublishPhase,
+	attempts uint32,
+	interval time.Duration,
+	now time.Time,
+) (time.Duration, bool) {
+	left := s.Deadline(phase).Sub(now)
+	delay := interval << min(max(attempts, 1)-1, maxPublishRetryBackoff)
+	switch {
+	case phase.nipost():
+		return max(min(delay, left/2), interval), true
+	case left < interval:
+		return 0, false
+	}
+	return min(delay, left/2), true
+}
+
+// PublishStatus is the current phase of the atx publication.
+type PublishStatus struct {
+	Phase    PublishPhase
+	Deadline time.Time
+	// Attempts is the number of failed attempts in the phase, LastError is the error of the last one.
+	Attempts  uint32
+	LastError string
+	Schedule  PublishSchedule
+}
+
+// publishTracker holds the state of the atx publication and persists it on every change,
+// so that the phase and the number of attempts are known after restart.
+type publishTracker struct {
+	mu        sync.Mutex
+	state     types.ATXPublishState
+	challenge types.Hash32
+}
+
+func (b *Builder) schedule(publish types.EpochID) PublishSchedule {
+	deadlines := b.deadlines(publish)
+	return PublishSchedule{
+		PublishEpoch:       publish,
+		ChallengeStart:     deadlines.PoetRoundStart.Add(-b.poetCfg.GracePeriod),
+		PreflightDeadlines: deadlines,
+	}
+}
+
+// PublishStatus returns the phase of the atx publication and its deadline.
+func (b *Builder) PublishStatus() *PublishStatus {
+	state := b.publishState()
+	phase := PublishPhase(state.Phase)
+	schedule := b.schedule(state.PublishEpoch)
+	return &PublishStatus{
+		Phase:     phase,
+		Deadline:  schedule.Deadline(phase),
+		Attempts:  state.Attempts,
+		LastError: state.LastError,
+		Schedule:  schedule,
+	}
+}
+
+// publishState returns the persisted state, the phase of nipost construction is read from the state
+// of the nipost builder.
+func (b *Builder) publishState() types.ATXPublishState {
+	b.publish.mu.Lock()
+	state, challenge := b.publish.state, b.publish.challenge
+	b.publish.mu.Unlock()
+	if state.PublishEpoch == 0 {
+		// the builder didn't start to build the challenge yet
+		state.PublishEpoch = b.currentEpoch() + 1
+	}
+	if !PublishPhase(state.Phase).nipost() {
+		return state
+	}
+	phase := PublishPhasePost
+	nipost, err := loadBuilderState(b.nipostBuilder.DataDir())
+	switch {
+	case err != nil || nipost.Challenge != challenge || len(nipost.PoetRequests) == 0:
+		phase = PublishPhasePoetSubmit
+	case nipost.PoetProofRef == types.EmptyPoetProofRef:
+		phase = PublishPhasePoetProof
+	}
+	if uint8(phase) != state.Phase {
+		state.Phase = uint8(phase)
+		state.Attempts = 0
+	}
+	return state
+}
+
+// setPublishPhase moves publication to the phase, attempts are reset if the phase changed.
+// Publish epoch that was already completed is not moved to another phase.
+//
+// Only nipost construction and completion of the epoch are persisted. Other phases are short
+// and are not needed after restart: the challenge is built again and the nipost phase that is
+// refined from the nipost builder state has the same deadline as awaiting and broadcasting atx.
+func (b *Builder) setPublishPhase(publish types.EpochID, phase PublishPhase) {
+	b.publish.mu.Lock()
+	defer b.publish.mu.Unlock()
+	if b.publish.state.PublishEpoch == publish &&
+		(b.publish.state.Phase == uint8(phase) || PublishPhase(b.publish.state.Phase).done()) {
+		return
+	}
+	b.publish.state = types.ATXPublishState{PublishEpoch: publish, Phase: uint8(phase)}
+	if phase.nipost() || phase.done() {
+		b.persistPublishState()
+	}
+}
+
+// startNIPost moves publication to the nipost construction for the challenge.
+// Attempts are preserved if the construction is retried.
+func (b *Builder) startNIPost(challenge *types.NIPostChallenge) {
+	b.publish.mu.Lock()
+	b.publish.challenge = challenge.Hash()
+	retry := b.publish.state.PublishEpoch == challenge.PublishEpoch && PublishPhase(b.publish.state.Phase).nipost()
+	b.publish.mu.Unlock()
+	if !retry {
+		b.setPublishPhase(challenge.PublishEpoch, PublishPhasePoetSubmit)
+	}
+}
+
+// failPublishPhase records the failed attempt and returns the state with the phase that failed.
+// Failures after the publish epoch was completed are not counted as attempts.
+func (b *Builder) failPublishPhase(err error) types.ATXPublishState {
+	state := b.publishState()
+	if PublishPhase(state.Phase).done() {
+		return state
+	}
+	b.publish.mu.Lock()
+	defer b.publish.mu.Unlock()
+	if b.publish.state.PublishEpoch != state.PublishEpoch || b.publish.state.Phase != state.Phase {
+		// nipost phase is tracked by the nipost builder, attempts are counted for the refined phase
+		b.publish.state.PublishEpoch = state.PublishEpoch
+		b.publish.state.Phase = state.Phase
+		b.publish.state.Attempts = 0
+	}
+	b.publish.state.Attempts++
+	b.publish.state.LastError = err.Error()
+	if len(b.publish.state.LastError) > 1024 {
+		b.publish.state.LastError = b.publish.state.LastError[:1024]
+	}
+	b.persistPublishState()
+	return b.publish.state
+}
+
+// missPublishEpoch gives up on the publish epoch. It is noop if the epoch was already completed.
+func (b *Builder) missPublishEpoch(state types.ATXPublishState) {
+	phase := PublishPhase(state.Phase)
+	if phase.done() {
+		return
+	}
+	b.log.With().Warning("atx will not be published in the epoch",
+		log.Stringer("publish_epoch", state.PublishEpoch),
+		log.Stringer("phase", phase),
+		log.Uint32("attempts", state.Attempts),
+		log.String("last_error", state.LastError),
+	)
+	metrics.PublishEpochsMissed.WithLabelValues(phase.String()).Inc()
+
+	b.publish.mu.Lock()
+	defer b.publish.mu.Unlock()
+	b.publish.state.PublishEpoch = state.PublishEpoch
+	b.publish.state.Phase = uint8(PublishPhaseMissed)
+	b.persistPublishState()
+}
+
+func (b *Builder) persistPublishState() {
+	if err := savePublishState(b.nipostBuilder.DataDir(), &b.publish.state); err != nil {
+		b.log.With().Warning("failed to persist atx publish state", log.Err(err))
+	}
+}
+
+// restorePublishState loads the state persisted before restart. The epoch is given up
+// if the deadline of the phase in which the node was stopped has passed.
+func (b *Builder) restorePublishState() {
+	state, err := loadPublishState(b.nipostBuilder.DataDir())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return
+	case err != nil:
+		b.log.With().Warning("failed to load atx publish state", log.Err(err))
+		return
+	}
+	b.publish.mu.Lock()
+	b.publish.state = *state
+	if challenge, err := LoadNipostChallenge(b.nipostBuilder.DataDir()); err == nil {
+		b.publish.challenge = challenge.Hash()
+	}
+	b.publish.mu.Unlock()
+
+	restored := b.publishState()
+	phase := PublishPhase(restored.Phase)
+	if phase == PublishPhasePublished || phase == PublishPhaseMissed {
+		return
+	}
+	schedule := b.schedule(restored.PublishEpoch)
+	b.log.With().Info("restored atx publish state",
+		log.Stringer("publish_epoch", restored.PublishEpoch),
+		log.Stringer("phase", phase),
+		log.Time("deadline", schedule.Deadline(phase)),
+	)
+	if !time.Now().Before(schedule.Deadline(phase)) {
+		b.missPublishEpoch(restored)
+		if err := b.discardChallenge(); err != nil {
+			b.log.With().Error("failed to discard challenge", log.Err(err))
+		}
+	}
+}
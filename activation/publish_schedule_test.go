@@ -0,0 +1,187 @@
+package activation
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/spacemeshos/go-spacemesh/activation/metrics"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestPublishSchedule_RetryDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	schedule := PublishSchedule{PreflightDeadlines: PreflightDeadlines{
+		PoetRoundStart:    now.Add(time.Minute),
+		PoetProofDeadline: now.Add(time.Hour),
+		PublishEpochEnd:   now.Add(2 * time.Hour),
+	}}
+	for _, tc := range []struct {
+		desc     string
+		phase    PublishPhase
+		attempts uint32
+		late     bool
+		delay    time.Duration
+		retry    bool
+	}{
+		{desc: "first", phase: PublishPhasePoetSubmit, attempts: 1, delay: time.Second, retry: true},
+		{desc: "doubles", phase: PublishPhasePoetProof, attempts: 3, delay: 4 * time.Second, retry: true},
+		{desc: "capped", phase: PublishPhasePost, attempts: 100, delay: 64 * time.Second, retry: true},
+		{desc: "half of time left", phase: PublishPhasePoetSubmit, attempts: 7, delay: 30 * time.Second, retry: true},
+		{desc: "passed", phase: PublishPhaseChallenge, attempts: 1, late: true, retry: false},
+		{desc: "nipost passed", phase: PublishPhasePoetSubmit, attempts: 3, late: true, delay: time.Second, retry: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			at := now
+			if tc.late {
+				at = schedule.PoetRoundStart.Add(-time.Millisecond)
+			}
+			delay, retry := schedule.retryDelay(tc.phase, tc.attempts, time.Second, at)
+			require.Equal(t, tc.retry, retry)
+			require.Equal(t, tc.delay, delay)
+		})
+	}
+}
+
+func TestBuilder_PublishStatus(t *testing.T) {
+	tab := newTestBuilder(t, WithPoetConfig(PoetConfig{
+		PhaseShift:  layerDuration,
+		CycleGap:    layerDuration,
+		GracePeriod: layerDuration / 2,
+	}))
+	genesis := time.Unix(1700000000, 0)
+	tab.mclock.EXPECT().CurrentLayer().Return(types.LayerID(layersPerEpoch + 1)).AnyTimes()
+	tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(lid types.LayerID) time.Time {
+		return genesis.Add(time.Duration(lid) * layerDuration)
+	}).AnyTimes()
+
+	status := tab.PublishStatus()
+	require.Equal(t, PublishPhaseWait, status.Phase)
+	require.Equal(t, types.EpochID(2), status.Schedule.PublishEpoch)
+	require.Equal(t, genesis.Add(11*layerDuration), status.Deadline)
+	require.Equal(t, genesis.Add(10*layerDuration+layerDuration/2), status.Schedule.ChallengeStart)
+
+	challenge := &types.NIPostChallenge{PublishEpoch: 2}
+	tab.startNIPost(challenge)
+	require.Equal(t, PublishPhasePoetSubmit, tab.PublishStatus().Phase)
+
+	// nipost builder submitted challenge to poets
+	dir := tab.nipostBuilder.DataDir()
+	require.NoError(t, saveBuilderState(dir, &types.NIPostBuilderState{
+		Challenge:    challenge.Hash(),
+		PoetRequests: []types.PoetRequest{{PoetRound: &types.PoetRound{}}},
+	}))
+	status = tab.PublishStatus()
+	require.Equal(t, PublishPhasePoetProof, status.Phase)
+	require.Equal(t, genesis.Add(29*layerDuration), status.Deadline)
+
+	state := tab.failPublishPhase(errors.New("no proof"))
+	require.Equal(t, uint32(1), state.Attempts)
+	// retry keeps attempts
+	tab.startNIPost(challenge)
+	state = tab.failPublishPhase(errors.New("no proof"))
+	require.Equal(t, uint32(2), state.Attempts)
+	require.Equal(t, uint8(PublishPhasePoetProof), state.Phase)
+
+	require.NoError(t, saveBuilderState(dir, &types.NIPostBuilderState{
+		Challenge:    challenge.Hash(),
+		PoetRequests: []types.PoetRequest{{PoetRound: &types.PoetRound{}}},
+		PoetProofRef: types.PoetProofRef{1},
+	}))
+	status = tab.PublishStatus()
+	require.Equal(t, PublishPhasePost, status.Phase)
+	require.Equal(t, genesis.Add(30*layerDuration), status.Deadline)
+	require.Zero(t, status.Attempts)
+
+	persisted, err := loadPublishState(dir)
+	require.NoError(t, err)
+	require.Equal(t, types.ATXPublishState{
+		PublishEpoch: 2,
+		Phase:        uint8(PublishPhasePoetProof),
+		Attempts:     2,
+		LastError:    "no proof",
+	}, *persisted)
+}
+
+func TestBuilder_RestorePublishState(t *testing.T) {
+	t.Run("deadline not passed", func(t *testing.T) {
+		tab := newTestBuilder(t, WithPoetConfig(PoetConfig{PhaseShift: layerDuration}))
+		now := time.Now()
+		tab.mclock.EXPECT().CurrentLayer().Return(types.LayerID(layersPerEpoch)).AnyTimes()
+		tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(lid types.LayerID) time.Time {
+			return now.Add(time.Duration(int(lid)-layersPerEpoch) * layerDuration)
+		}).AnyTimes()
+		dir := tab.nipostBuilder.DataDir()
+		require.NoError(t, SaveNipostChallenge(dir, &types.NIPostChallenge{PublishEpoch: 2}))
+		state := &types.ATXPublishState{PublishEpoch: 2, Phase: uint8(PublishPhasePoetSubmit), Attempts: 3}
+		require.NoError(t, savePublishState(dir, state))
+
+		tab.restorePublishState()
+		status := tab.PublishStatus()
+		require.Equal(t, PublishPhasePoetSubmit, status.Phase)
+		require.Equal(t, uint32(3), status.Attempts)
+		_, err := LoadNipostChallenge(dir)
+		require.NoError(t, err)
+	})
+	t.Run("deadline passed", func(t *testing.T) {
+		tab := newTestBuilder(t, WithPoetConfig(PoetConfig{PhaseShift: layerDuration}))
+		now := time.Now()
+		// poet round for publish epoch 2 started a layer ago
+		tab.mclock.EXPECT().CurrentLayer().Return(types.LayerID(layersPerEpoch + 2)).AnyTimes()
+		tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(lid types.LayerID) time.Time {
+			return now.Add(time.Duration(int(lid)-layersPerEpoch-2) * layerDuration)
+		}).AnyTimes()
+		dir := tab.nipostBuilder.DataDir()
+		require.NoError(t, SaveNipostChallenge(dir, &types.NIPostChallenge{PublishEpoch: 2}))
+		require.NoError(t, savePublishState(dir, &types.ATXPublishState{
+			PublishEpoch: 2,
+			Phase:        uint8(PublishPhasePoetSubmit),
+		}))
+
+		tab.restorePublishState()
+		status := tab.PublishStatus()
+		require.Equal(t, PublishPhaseMissed, status.Phase)
+		require.Equal(t, types.EpochID(2), status.Schedule.PublishEpoch)
+		_, err := LoadNipostChallenge(dir)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestBuilder_PublishEpochDone(t *testing.T) {
+	for _, phase := range []PublishPhase{PublishPhaseMissed, PublishPhasePublished} {
+		t.Run(phase.String(), func(t *testing.T) {
+			tab := newTestBuilder(t, WithPoetConfig(PoetConfig{PhaseShift: layerDuration}))
+			now := time.Now()
+			tab.mclock.EXPECT().CurrentLayer().Return(types.LayerID(layersPerEpoch + 2)).AnyTimes()
+			tab.mclock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(lid types.LayerID) time.Time {
+				return now.Add(time.Duration(int(lid)-layersPerEpoch-2) * layerDuration)
+			}).AnyTimes()
+			missed := testutil.ToFloat64(metrics.PublishEpochsMissed.WithLabelValues(PublishPhaseWait.String()))
+
+			tab.setPublishPhase(2, phase)
+			// every layer after the poet round started the builder fails to build a challenge
+			for i := 0; i < 3; i++ {
+				tab.setPublishPhase(2, PublishPhaseWait)
+				state := tab.failPublishPhase(ErrATXChallengeExpired)
+				require.Equal(t, uint8(phase), state.Phase)
+				require.Zero(t, state.Attempts)
+				tab.missPublishEpoch(state)
+			}
+			require.Equal(t, phase, tab.PublishStatus().Phase)
+			require.Equal(t, missed,
+				testutil.ToFloat64(metrics.PublishEpochsMissed.WithLabelValues(PublishPhaseWait.String())))
+			persisted, err := loadPublishState(tab.nipostBuilder.DataDir())
+			require.NoError(t, err)
+			require.Equal(t, types.ATXPublishState{PublishEpoch: 2, Phase: uint8(phase)}, *persisted)
+
+			// next publish epoch is tracked
+			tab.setPublishPhase(3, PublishPhaseWait)
+			require.Equal(t, PublishPhaseWait, tab.PublishStatus().Phase)
+		})
+	}
+}
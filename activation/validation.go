@@ -67,6 +67,23 @@ func (v *Validator) NIPost(ctx context.Context, publishEpoch types.EpochID, node
 		return 0, fmt.Errorf("invalid Post: %w", err)
 	}
 
+	return v.poetMembership(nipost, expectedChallenge)
+}
+
+// NIPostWithVerifiedPost validates a NIPost like NIPost, except for the Post that is known to be valid.
+func (v *Validator) NIPostWithVerifiedPost(nipost *types.NIPost, expectedChallenge types.Hash32, numUnits uint32) (uint64, error) {
+	if err := v.NumUnits(&v.cfg, numUnits); err != nil {
+		return 0, err
+	}
+
+	if err := v.PostMetadata(&v.cfg, nipost.PostMetadata); err != nil {
+		return 0, err
+	}
+
+	return v.poetMembership(nipost, expectedChallenge)
+}
+
+func (v *Validator) poetMembership(nipost *types.NIPost, expectedChallenge types.Hash32) (uint64, error) {
 	var ref types.PoetProofRef
 	copy(ref[:], nipost.PostMetadata.Challenge)
 	proof, statement, err := v.poetDb.GetProof(ref)
@@ -124,8 +141,6 @@ func (v *Validator) Post(ctx context.Context, publishEpoch types.EpochID, nodeId
 		LabelsPerUnit:   PostMetadata.LabelsPerUnit,
 	}
 
-	opts = append(opts, verifying.WithPowCreator(nodeId.Bytes()))
-
 	start := time.Now()
 	if err := v.postVerifier.Verify(ctx, p, m, opts...); err != nil {
 		return fmt.Errorf("verify PoST: %w", err)
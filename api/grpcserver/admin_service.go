@@ -2,6 +2,8 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +17,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/spacemeshos/go-spacemesh/checkpoint"
@@ -26,8 +29,48 @@ import (
 const (
 	chunksize      = 1024
 	defaultNumAtxs = 4
+	// maxTimelineEpochs limits the number of epochs in a single timeline request.
+	maxTimelineEpochs = 100
 )
 
+const adminServiceName = "AdminService"
+
+// AdminSmesherTimelineMethod is a full name of the method that returns AdminSmesherTimelineResponse.
+var AdminSmesherTimelineMethod = jsonMethod(adminServiceName, "SmesherTimeline")
+
+// AdminSmesherTimelineRequest selects epochs [FromEpoch, ToEpoch].
+type AdminSmesherTimelineRequest struct {
+	FromEpoch uint32 `json:"from_epoch"`
+	ToEpoch   uint32 `json:"to_epoch"`
+}
+
+// AdminTimelineLayer is a layer where smesher was eligible or published a proposal.
+// Reward is one of pending, received, missed or unknown if coinbase of the smesher is not known.
+type AdminTimelineLayer struct {
+	Layer         uint32 `json:"layer"`
+	Eligibilities uint32 `json:"eligibilities"`
+	Proposal      string `json:"proposal,omitempty"`
+	Reward        string `json:"reward"`
+	TotalReward   uint64 `json:"total_reward,omitempty"`
+	LayerReward   uint64 `json:"layer_reward,omitempty"`
+}
+
+// AdminTimelineEpoch is what happened with the smesher in the epoch.
+// Events are spacemesh.v1.Event messages in protojson encoding.
+type AdminTimelineEpoch struct {
+	Epoch          uint32               `json:"epoch"`
+	Atx            string               `json:"atx,omitempty"`
+	EligibilityAtx string               `json:"eligibility_atx,omitempty"`
+	Coinbase       string               `json:"coinbase,omitempty"`
+	Layers         []AdminTimelineLayer `json:"layers"`
+	Events         []json.RawMessage    `json:"events"`
+}
+
+// AdminSmesherTimelineResponse lists epochs that have persisted events.
+type AdminSmesherTimelineResponse struct {
+	Epochs []AdminTimelineEpoch `json:"epochs"`
+}
+
 // AdminService exposes endpoints for node administration.
 type AdminService struct {
 	db      *sql.Database
@@ -57,6 +100,13 @@ func (a AdminService) RegisterService(server *Server) {
 	pb.RegisterAdminServiceServer(server.GrpcServer, a)
 }
 
+// RegisterExperimentalService registers methods with json encoded messages with a grpc server instance.
+func (a AdminService) RegisterExperimentalService(server *Server) {
+	svc := newJSONService(adminServiceName)
+	jsonUnary(svc, "SmesherTimeline", a.SmesherTimeline)
+	svc.register(server, a)
+}
+
 func (a AdminService) CheckpointStream(req *pb.CheckpointStreamRequest, stream pb.AdminService_CheckpointStreamServer) error {
 	// checkpoint data can be more than 4MB, it can cause stress
 	// - on the client side (default limit on the receiving end)
@@ -173,3 +223,59 @@ func (a AdminService) PeerInfoStream(_ *empty.Empty, stream pb.AdminService_Peer
 
 	return nil
 }
+
+// SmesherTimeline returns persisted smesher events joined with the rewards for every epoch in the range.
+func (a AdminService) SmesherTimeline(
+	_ context.Context,
+	req *AdminSmesherTimelineRequest,
+) (*AdminSmesherTimelineResponse, error) {
+	if req.ToEpoch < req.FromEpoch {
+		return nil, status.Errorf(codes.InvalidArgument, "to_epoch %d is before from_epoch %d", req.ToEpoch, req.FromEpoch)
+	}
+	if req.ToEpoch-req.FromEpoch >= maxTimelineEpochs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d epochs can be requested", maxTimelineEpochs)
+	}
+	timeline, err := events.Timeline(a.db, types.EpochID(req.FromEpoch), types.EpochID(req.ToEpoch))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rst := &AdminSmesherTimelineResponse{Epochs: make([]AdminTimelineEpoch, 0, len(timeline))}
+	for _, et := range timeline {
+		epoch := AdminTimelineEpoch{
+			Epoch:  et.Epoch.Uint32(),
+			Layers: make([]AdminTimelineLayer, 0, len(et.Layers)),
+			Events: make([]json.RawMessage, 0, len(et.Events)),
+		}
+		if et.Atx != types.EmptyATXID {
+			epoch.Atx = et.Atx.Hash32().Hex()
+		}
+		if et.EligibilityAtx != types.EmptyATXID {
+			epoch.EligibilityAtx = et.EligibilityAtx.Hash32().Hex()
+		}
+		if et.Coinbase != (types.Address{}) {
+			epoch.Coinbase = et.Coinbase.String()
+		}
+		for _, lt := range et.Layers {
+			layer := AdminTimelineLayer{
+				Layer:         lt.Layer.Uint32(),
+				Eligibilities: lt.Eligibilities,
+				Reward:        lt.Reward.String(),
+				TotalReward:   lt.TotalReward,
+				LayerReward:   lt.LayerReward,
+			}
+			if lt.Proposal != types.EmptyProposalID {
+				layer.Proposal = hex.EncodeToString(lt.Proposal[:])
+			}
+			epoch.Layers = append(epoch.Layers, layer)
+		}
+		for _, ev := range et.Events {
+			buf, err := protojson.Marshal(ev)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			epoch.Events = append(epoch.Events, buf)
+		}
+		rst.Epochs = append(rst.Epochs, epoch)
+	}
+	return rst, nil
+}
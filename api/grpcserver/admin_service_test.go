@@ -10,11 +10,16 @@ import (
 
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/accounts"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/userevents"
 )
 
 const snapshot uint32 = 15
@@ -122,3 +127,66 @@ func TestAdminService_Recovery(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, recoveryCalled.Load())
 }
+
+func TestAdminService_SmesherTimeline(t *testing.T) {
+	db := sql.InMemory()
+	svc := NewAdminService(db, t.TempDir(), nil)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	published := types.ATXID{1}
+	eligibility := types.ATXID{2}
+	lid := types.EpochID(3).FirstLayer()
+	for _, ev := range []struct {
+		epoch types.EpochID
+		kind  string
+		event *pb.Event
+	}{
+		{2, events.KindAtxPublished, &pb.Event{Details: &pb.Event_AtxPublished{
+			AtxPublished: &pb.EventAtxPubished{Current: 2, Target: 3, Id: published[:]},
+		}}},
+		{3, events.KindEligibilities, &pb.Event{Details: &pb.Event_Eligibilities{
+			Eligibilities: &pb.EventEligibilities{
+				Epoch:         3,
+				Atx:           eligibility[:],
+				Eligibilities: []*pb.ProposalEligibility{{Layer: lid.Uint32(), Count: 2}},
+			},
+		}}},
+	} {
+		buf, err := proto.Marshal(ev.event)
+		require.NoError(t, err)
+		require.NoError(t, userevents.Add(db, &userevents.Event{
+			Epoch:     ev.epoch,
+			Kind:      ev.kind,
+			Timestamp: time.Now(),
+			Event:     buf,
+		}))
+	}
+
+	var got AdminSmesherTimelineResponse
+	require.NoError(t, InvokeJSON(ctx, conn, AdminSmesherTimelineMethod,
+		&AdminSmesherTimelineRequest{FromEpoch: 0, ToEpoch: 10}, &got))
+	require.Len(t, got.Epochs, 2)
+	require.Equal(t, uint32(2), got.Epochs[0].Epoch)
+	require.Equal(t, published.Hash32().Hex(), got.Epochs[0].Atx)
+	require.Len(t, got.Epochs[0].Events, 1)
+	require.Contains(t, string(got.Epochs[0].Events[0]), "atxPublished")
+
+	require.Equal(t, eligibility.Hash32().Hex(), got.Epochs[1].EligibilityAtx)
+	require.Empty(t, got.Epochs[1].Coinbase)
+	require.Equal(t, []AdminTimelineLayer{{
+		Layer:         lid.Uint32(),
+		Eligibilities: 2,
+		Reward:        events.RewardUnknown.String(),
+	}}, got.Epochs[1].Layers)
+
+	err := InvokeJSON(ctx, conn, AdminSmesherTimelineMethod,
+		&AdminSmesherTimelineRequest{FromEpoch: 3, ToEpoch: 2}, &got)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = InvokeJSON(ctx, conn, AdminSmesherTimelineMethod,
+		&AdminSmesherTimelineRequest{FromEpoch: 0, ToEpoch: maxTimelineEpochs}, &got)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
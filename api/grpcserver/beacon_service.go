@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const beaconServiceName = "BeaconService"
+
+// BeaconEpochRecordMethod is a full name of the method that returns beacon.EpochRecord.
+var BeaconEpochRecordMethod = jsonMethod(beaconServiceName, "EpochRecord")
+
+// BeaconRecordRequest is a request for the record of the beacon protocol executed in the epoch.
+type BeaconRecordRequest struct {
+	Epoch uint32 `json:"epoch"`
+}
+
+// BeaconService exposes records of the beacon protocol for post-mortem analysis.
+type BeaconService struct {
+	db sql.Executor
+}
+
+// NewBeaconService creates a new grpc service for beacon protocol records.
+func NewBeaconService(db sql.Executor) *BeaconService {
+	return &BeaconService{db: db}
+}
+
+// RegisterService registers this service with a grpc server instance.
+func (s *BeaconService) RegisterService(server *Server) {
+	svc := newJSONService(beaconServiceName)
+	jsonUnary(svc, "EpochRecord", s.EpochRecord)
+	svc.register(server, s)
+}
+
+// EpochRecord returns the record of the beacon protocol executed in the epoch.
+func (s *BeaconService) EpochRecord(_ context.Context, req *BeaconRecordRequest) (*beacon.EpochRecord, error) {
+	record, err := beacon.GetRecord(s.db, types.EpochID(req.Epoch))
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "beacon protocol was not executed in epoch %d", req.Epoch)
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return record, nil
+}
@@ -0,0 +1,49 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/beacons"
+)
+
+func TestBeaconService_EpochRecord(t *testing.T) {
+	db := sql.InMemory()
+	record := &beacon.EpochRecord{
+		Epoch:          3,
+		EpochWeight:    100,
+		Theta:          "1/4",
+		OwnProposal:    beacon.Proposal{1, 2},
+		ProposalSent:   true,
+		ValidProposals: []beacon.Proposal{{1, 2}, {3, 4}},
+		Rounds: []beacon.RoundRecord{{
+			Round:   1,
+			Margins: []beacon.VoteMargin{{Proposal: beacon.Proposal{1, 2}, Margin: "-10"}},
+			HasCoin: true,
+		}},
+		Beacon: [4]byte{1, 1, 1, 1},
+	}
+	require.NoError(t, beacons.SetRecord(db, record.Epoch, codec.MustEncode(record)))
+
+	svc := NewBeaconService(db)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	var got beacon.EpochRecord
+	require.NoError(t, InvokeJSON(ctx, conn, BeaconEpochRecordMethod, &BeaconRecordRequest{Epoch: 3}, &got))
+	require.Equal(t, record, &got)
+
+	err := InvokeJSON(ctx, conn, BeaconEpochRecordMethod, &BeaconRecordRequest{Epoch: 4}, &got)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -13,6 +13,9 @@ type Config struct {
 	GrpcSendMsgSize int       `mapstructure:"grpc-send-msg-size"`
 	GrpcRecvMsgSize int       `mapstructure:"grpc-recv-msg-size"`
 	JSONListener    string    `mapstructure:"grpc-json-listener"`
+	// ExperimentalServices are served on the private listener. Beacon, Mempool and Fee can be
+	// listed only here, Smesher and Admin enable their experimental methods. See json_codec.go.
+	ExperimentalServices []Service `mapstructure:"grpc-experimental-services"`
 
 	SmesherStreamInterval time.Duration
 }
@@ -28,8 +31,16 @@ const (
 	Activation  Service = "activation"
 	Smesher     Service = "smesher"
 	Node        Service = "node"
+	Beacon      Service = "beacon"
+	Mempool     Service = "mempool"
+	Fee         Service = "fee"
 )
 
+// IsExperimental returns true if the service has only experimental methods.
+func IsExperimental(svc Service) bool {
+	return svc == Beacon || svc == Mempool || svc == Fee
+}
+
 // DefaultConfig defines the default configuration options for api.
 func DefaultConfig() Config {
 	return Config{
@@ -2,6 +2,7 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -53,6 +54,9 @@ func (d DebugService) Accounts(ctx context.Context, in *pb.AccountsRequest) (*pb
 	} else {
 		accts, err = accounts.Snapshot(d.db, types.LayerID(in.Layer))
 	}
+	if errors.Is(err, accounts.ErrPruned) {
+		return nil, status.Error(codes.OutOfRange, err.Error())
+	}
 	if err != nil {
 		ctxzap.Error(ctx, " Failed to get all accounts from state", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "error fetching accounts state")
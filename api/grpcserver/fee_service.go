@@ -0,0 +1,141 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/txs"
+)
+
+const (
+	feeServiceName = "FeeService"
+	// maxFeeLayers is the maximal number of layers returned by LayerFees.
+	maxFeeLayers = 100
+)
+
+var (
+	// FeeLayerFeesMethod is a full name of the method that returns FeeLayerFeesResponse.
+	FeeLayerFeesMethod = jsonMethod(feeServiceName, "LayerFees")
+	// FeeMempoolFeesMethod is a full name of the method that returns FeeMempoolFeesResponse.
+	FeeMempoolFeesMethod = jsonMethod(feeServiceName, "MempoolFees")
+	// FeeEstimateFeeMethod is a full name of the method that returns FeeEstimateFeeResponse.
+	FeeEstimateFeeMethod = jsonMethod(feeServiceName, "EstimateFee")
+)
+
+// FeeLayerFeesRequest selects applied layers in [From, To].
+type FeeLayerFeesRequest struct {
+	From uint32 `json:"from"`
+	To   uint32 `json:"to"`
+}
+
+// FeeLayer summarizes gas prices of transactions applied in the layer.
+type FeeLayer struct {
+	Layer        uint32  `json:"layer"`
+	Transactions int     `json:"transactions"`
+	GasUsed      uint64  `json:"gas_used"`
+	GasLimit     uint64  `json:"gas_limit"`
+	Utilization  float64 `json:"utilization"`
+	MinGasPrice  uint64  `json:"min_gas_price"`
+	// GasPrices are gas prices at percentiles listed in FeeLayerFeesResponse.
+	GasPrices []uint64 `json:"gas_prices,omitempty"`
+}
+
+// FeeLayerFeesResponse lists fee statistics of applied layers.
+type FeeLayerFeesResponse struct {
+	Percentiles []int      `json:"percentiles"`
+	Layers      []FeeLayer `json:"layers"`
+}
+
+// FeeMempoolFeesRequest is a request for fee statistics of the mempool.
+type FeeMempoolFeesRequest struct{}
+
+// FeeMempoolFeesResponse summarizes gas prices of transactions in the mempool.
+type FeeMempoolFeesResponse struct {
+	Percentiles  []int    `json:"percentiles"`
+	Transactions int      `json:"transactions"`
+	MaxGas       uint64   `json:"max_gas"`
+	GasPrices    []uint64 `json:"gas_prices,omitempty"`
+}
+
+// FeeEstimateFeeRequest is a request for the gas price to be applied within TargetLayers.
+type FeeEstimateFeeRequest struct {
+	TargetLayers uint32 `json:"target_layers"`
+}
+
+// FeeEstimateFeeResponse is the estimated gas price.
+type FeeEstimateFeeResponse struct {
+	TargetLayers uint32 `json:"target_layers"`
+	GasPrice     uint64 `json:"gas_price"`
+}
+
+// FeeService exposes fee statistics and fee estimation.
+type FeeService struct {
+	fees feeEstimator
+}
+
+// NewFeeService creates a new grpc service for fees.
+func NewFeeService(fees feeEstimator) *FeeService {
+	return &FeeService{fees: fees}
+}
+
+// RegisterService registers this service with a grpc server instance.
+func (s *FeeService) RegisterService(server *Server) {
+	svc := newJSONService(feeServiceName)
+	jsonUnary(svc, "LayerFees", s.LayerFees)
+	jsonUnary(svc, "MempoolFees", s.MempoolFees)
+	jsonUnary(svc, "EstimateFee", s.EstimateFee)
+	svc.register(server, s)
+}
+
+// LayerFees returns gas prices paid and block gas utilization in applied layers.
+func (s *FeeService) LayerFees(_ context.Context, req *FeeLayerFeesRequest) (*FeeLayerFeesResponse, error) {
+	if req.From > req.To {
+		return nil, status.Errorf(codes.InvalidArgument, "from %d is after to %d", req.From, req.To)
+	}
+	if req.To-req.From >= maxFeeLayers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d layers can be requested", maxFeeLayers)
+	}
+	fees, err := s.fees.LayerFees(types.LayerID(req.From), types.LayerID(req.To))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rst := &FeeLayerFeesResponse{Percentiles: txs.FeePercentiles, Layers: make([]FeeLayer, 0, len(fees))}
+	for _, f := range fees {
+		rst.Layers = append(rst.Layers, FeeLayer{
+			Layer:        f.Layer.Uint32(),
+			Transactions: f.Transactions,
+			GasUsed:      f.GasUsed,
+			GasLimit:     f.GasLimit,
+			Utilization:  f.Utilization(),
+			MinGasPrice:  f.MinGasPrice,
+			GasPrices:    f.GasPrices,
+		})
+	}
+	return rst, nil
+}
+
+// MempoolFees returns gas prices of transactions waiting in the mempool as of the last applied layer.
+func (s *FeeService) MempoolFees(context.Context, *FeeMempoolFeesRequest) (*FeeMempoolFeesResponse, error) {
+	fees := s.fees.MempoolFees()
+	return &FeeMempoolFeesResponse{
+		Percentiles:  txs.FeePercentiles,
+		Transactions: fees.Transactions,
+		MaxGas:       fees.MaxGas,
+		GasPrices:    fees.GasPrices,
+	}, nil
+}
+
+// EstimateFee returns the gas price for a transaction to be applied within the target number of layers.
+func (s *FeeService) EstimateFee(_ context.Context, req *FeeEstimateFeeRequest) (*FeeEstimateFeeResponse, error) {
+	if req.TargetLayers == 0 || req.TargetLayers > txs.MaxFeeTarget {
+		return nil, status.Errorf(codes.InvalidArgument, "target layers should be in [1, %d]", txs.MaxFeeTarget)
+	}
+	price, err := s.fees.EstimateFee(req.TargetLayers)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &FeeEstimateFeeResponse{TargetLayers: req.TargetLayers, GasPrice: price}, nil
+}
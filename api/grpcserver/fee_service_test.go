@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/txs"
+)
+
+func TestFeeService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fees := NewMockfeeEstimator(ctrl)
+	svc := NewFeeService(fees)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	t.Run("layer fees", func(t *testing.T) {
+		fees.EXPECT().LayerFees(types.LayerID(5), types.LayerID(6)).Return([]*txs.LayerFees{
+			{Layer: 5, Transactions: 2, GasUsed: 50, GasLimit: 100, MinGasPrice: 2, GasPrices: []uint64{2, 2, 3, 4, 4}},
+			{Layer: 6, GasLimit: 100},
+		}, nil)
+		var got FeeLayerFeesResponse
+		require.NoError(t, InvokeJSON(ctx, conn, FeeLayerFeesMethod, &FeeLayerFeesRequest{From: 5, To: 6}, &got))
+		require.Equal(t, FeeLayerFeesResponse{
+			Percentiles: txs.FeePercentiles,
+			Layers: []FeeLayer{
+				{
+					Layer:        5,
+					Transactions: 2,
+					GasUsed:      50,
+					GasLimit:     100,
+					Utilization:  0.5,
+					MinGasPrice:  2,
+					GasPrices:    []uint64{2, 2, 3, 4, 4},
+				},
+				{Layer: 6, GasLimit: 100},
+			},
+		}, got)
+
+		err := InvokeJSON(ctx, conn, FeeLayerFeesMethod, &FeeLayerFeesRequest{From: 6, To: 5}, &got)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		err = InvokeJSON(ctx, conn, FeeLayerFeesMethod, &FeeLayerFeesRequest{From: 0, To: maxFeeLayers}, &got)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		fees.EXPECT().LayerFees(types.LayerID(1), types.LayerID(1)).Return(nil, errors.New("test"))
+		err = InvokeJSON(ctx, conn, FeeLayerFeesMethod, &FeeLayerFeesRequest{From: 1, To: 1}, &got)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+	t.Run("mempool fees", func(t *testing.T) {
+		fees.EXPECT().MempoolFees().Return(&txs.MempoolFees{
+			Transactions: 3,
+			MaxGas:       300,
+			GasPrices:    []uint64{1, 1, 2, 5, 5},
+		})
+		var got FeeMempoolFeesResponse
+		require.NoError(t, InvokeJSON(ctx, conn, FeeMempoolFeesMethod, &FeeMempoolFeesRequest{}, &got))
+		require.Equal(t, FeeMempoolFeesResponse{
+			Percentiles:  txs.FeePercentiles,
+			Transactions: 3,
+			MaxGas:       300,
+			GasPrices:    []uint64{1, 1, 2, 5, 5},
+		}, got)
+	})
+	t.Run("estimate fee", func(t *testing.T) {
+		fees.EXPECT().EstimateFee(uint32(3)).Return(uint64(7), nil)
+		var got FeeEstimateFeeResponse
+		require.NoError(t, InvokeJSON(ctx, conn, FeeEstimateFeeMethod, &FeeEstimateFeeRequest{TargetLayers: 3}, &got))
+		require.Equal(t, FeeEstimateFeeResponse{TargetLayers: 3, GasPrice: 7}, got)
+
+		for _, target := range []uint32{0, txs.MaxFeeTarget + 1} {
+			err := InvokeJSON(ctx, conn, FeeEstimateFeeMethod, &FeeEstimateFeeRequest{TargetLayers: target}, &got)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+
+		fees.EXPECT().EstimateFee(uint32(1)).Return(uint64(0), errors.New("test"))
+		err := InvokeJSON(ctx, conn, FeeEstimateFeeMethod, &FeeEstimateFeeRequest{TargetLayers: 1}, &got)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
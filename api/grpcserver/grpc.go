@@ -17,6 +17,12 @@ type ServiceAPI interface {
 	RegisterService(*Server)
 }
 
+// ExperimentalServiceAPI is implemented by services defined in spacemeshos/api that also have
+// experimental methods with json encoded messages, see json_codec.go.
+type ExperimentalServiceAPI interface {
+	RegisterExperimentalService(*Server)
+}
+
 // Server is a very basic grpc server.
 type Server struct {
 	Listener string
@@ -52,6 +52,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/accounts"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/identities"
+	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/system"
 	"github.com/spacemeshos/go-spacemesh/txs"
 )
@@ -443,6 +444,8 @@ func newChallenge(sequence uint64, prevAtxID, posAtxID types.ATXID, epoch types.
 // SmeshingAPIMock is a mock for Smeshing API.
 type SmeshingAPIMock struct {
 	UpdatePoETErr error
+	Report        *activation.PreflightReport
+	Publish       *activation.PublishStatus
 }
 
 func (s *SmeshingAPIMock) UpdatePoETServers(context.Context, []string) error {
@@ -472,6 +475,14 @@ func (*SmeshingAPIMock) Coinbase() types.Address {
 func (*SmeshingAPIMock) SetCoinbase(coinbase types.Address) {
 }
 
+func (s *SmeshingAPIMock) Preflight(context.Context, bool) (*activation.PreflightReport, error) {
+	return s.Report, nil
+}
+
+func (s *SmeshingAPIMock) PublishStatus() *activation.PublishStatus {
+	return s.Publish
+}
+
 func marshalProto(t *testing.T, msg proto.Message) string {
 	var buf bytes.Buffer
 	var m jsonpb.Marshaler
@@ -488,6 +499,9 @@ func launchServer(tb testing.TB, cfg Config, services ...ServiceAPI) func() {
 	// attach services
 	for _, svc := range services {
 		svc.RegisterService(grpcService)
+		if experimental, ok := svc.(ExperimentalServiceAPI); ok {
+			experimental.RegisterExperimentalService(grpcService)
+		}
 	}
 
 	// start gRPC and json servers
@@ -916,7 +930,16 @@ func TestSmesherService(t *testing.T) {
 	postProvider.EXPECT().Status().Return(&activation.PostSetupStatus{}).AnyTimes()
 	postProvider.EXPECT().Providers().Return(nil, nil).AnyTimes()
 	smeshingAPI := &SmeshingAPIMock{}
-	svc := NewSmesherService(postProvider, smeshingAPI, 10*time.Millisecond, activation.DefaultPostSetupOpts())
+	poetHealth := NewMockpoetHealthReporter(ctrl)
+	postChecker := NewMockpostDataChecker(ctrl)
+	svc := NewSmesherService(
+		postProvider,
+		smeshingAPI,
+		poetHealth,
+		postChecker,
+		10*time.Millisecond,
+		activation.DefaultPostSetupOpts(),
+	)
 	t.Cleanup(launchServer(t, cfg, svc))
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -924,6 +947,160 @@ func TestSmesherService(t *testing.T) {
 	conn := dialGrpc(ctx, t, cfg.PublicListener)
 	c := pb.NewSmesherServiceClient(conn)
 
+	t.Run("Preflight", func(t *testing.T) {
+		deadline := time.Unix(1700000000, 0).UTC()
+		posAtx := types.ATXID{1}
+		smeshingAPI.Report = &activation.PreflightReport{
+			Challenge: &types.NIPostChallenge{PublishEpoch: 4, PositioningATX: posAtx, CommitmentATX: &posAtx},
+			Deadlines: activation.PreflightDeadlines{PublishEpochEnd: deadline},
+			Checks: []activation.PreflightCheck{
+				{Name: activation.PreflightCoinbase, Details: "addr", Duration: time.Millisecond},
+				{Name: activation.PreflightPostSetup, Err: activation.ErrPostNotComplete},
+			},
+		}
+		var got SmesherPreflightResponse
+		require.NoError(t, InvokeJSON(ctx, conn, SmesherPreflightMethod, &SmesherPreflightRequest{}, &got))
+		require.False(t, got.Passed)
+		require.Equal(t, &SmesherPreflightChallenge{
+			PublishEpoch:   4,
+			PositioningATX: posAtx.Hash32().Hex(),
+			CommitmentATX:  posAtx.Hash32().Hex(),
+		}, got.Challenge)
+		require.True(t, deadline.Equal(got.Deadlines.PublishEpochEnd))
+		require.Equal(t, []SmesherPreflightCheck{
+			{Name: activation.PreflightCoinbase, Passed: true, Details: "addr", Duration: "1ms"},
+			{Name: activation.PreflightPostSetup, Error: activation.ErrPostNotComplete.Error(), Duration: "0s"},
+		}, got.Checks)
+	})
+
+	t.Run("PublishStatus", func(t *testing.T) {
+		deadline := time.Unix(1700000000, 0).UTC()
+		smeshingAPI.Publish = &activation.PublishStatus{
+			Phase:     activation.PublishPhasePoetProof,
+			Deadline:  deadline,
+			Attempts:  2,
+			LastError: "poet service is unstable",
+			Schedule: activation.PublishSchedule{
+				PublishEpoch:       3,
+				PreflightDeadlines: activation.PreflightDeadlines{PoetProofDeadline: deadline},
+			},
+		}
+		var got SmesherPublishStatusResponse
+		require.NoError(t, InvokeJSON(ctx, conn, SmesherPublishStatusMethod, &SmesherPublishStatusRequest{}, &got))
+		require.Equal(t, "poet_proof", got.Phase)
+		require.Equal(t, uint32(3), got.PublishEpoch)
+		require.True(t, deadline.Equal(got.Deadline))
+		require.True(t, deadline.Equal(got.Deadlines.PoetProofDeadline))
+		require.Equal(t, uint32(2), got.Attempts)
+		require.Equal(t, "poet service is unstable", got.LastError)
+	})
+
+	t.Run("PoetHealth", func(t *testing.T) {
+		poetHealth.EXPECT().Report(types.EpochID(2)).Return([]activation.PoetHealthReport{{
+			Address:          "https://poet1",
+			ServiceID:        []byte{1, 2},
+			Submissions:      2,
+			Submitted:        1,
+			ProofsReceived:   1,
+			AvgSubmitLatency: time.Second,
+			AvgProofLatency:  time.Minute,
+			Rounds: []poets.Submission{
+				{Address: "https://poet1", Epoch: 3, Error: "unavailable"},
+				{
+					Address:       "https://poet1",
+					Epoch:         2,
+					RoundID:       "1",
+					Submitted:     true,
+					SubmitLatency: time.Second,
+					Proof:         poets.ProofReceived,
+					ProofLatency:  time.Minute,
+					LeafCount:     100,
+					TickCount:     10,
+				},
+			},
+		}}, nil)
+		var got SmesherPoetHealthResponse
+		require.NoError(t, InvokeJSON(ctx, conn, SmesherPoetHealthMethod, &SmesherPoetHealthRequest{FromEpoch: 2}, &got))
+		require.Equal(t, SmesherPoetHealthResponse{Poets: []SmesherPoet{{
+			Address:          "https://poet1",
+			ServiceID:        "0102",
+			Submissions:      2,
+			Submitted:        1,
+			ProofsReceived:   1,
+			AvgSubmitLatency: "1s",
+			AvgProofLatency:  "1m0s",
+			Rounds: []SmesherPoetRound{
+				{Epoch: 3, Proof: "pending", Error: "unavailable"},
+				{
+					Epoch:         2,
+					RoundID:       "1",
+					Submitted:     true,
+					SubmitLatency: "1s",
+					Proof:         "received",
+					ProofLatency:  "1m0s",
+					LeafCount:     100,
+					TickCount:     10,
+				},
+			},
+		}}}, got)
+	})
+
+	t.Run("PostCheck", func(t *testing.T) {
+		opts := activation.PostCheckOpts{Fraction: 5, Repair: true}
+		postChecker.EXPECT().Start(activation.DefaultPostSetupOpts(), opts).Return(nil)
+		postChecker.EXPECT().Status().Return(&activation.PostCheckStatus{
+			State: activation.PostCheckStateInProgress,
+			Opts:  opts,
+		})
+		var got SmesherPostCheckStatus
+		req := &SmesherPostCheckRequest{Fraction: 5, Repair: true}
+		require.NoError(t, InvokeJSON(ctx, conn, SmesherPostCheckMethod, req, &got))
+		require.Equal(t, "in_progress", got.State)
+
+		postChecker.EXPECT().Start(gomock.Any(), gomock.Any()).Return(activation.ErrPostCheckInProgress)
+		err := InvokeJSON(ctx, conn, SmesherPostCheckMethod, req, &got)
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		postChecker.EXPECT().Start(gomock.Any(), gomock.Any()).Return(activation.ErrPostDataInUse)
+		err = InvokeJSON(ctx, conn, SmesherPostCheckMethod, req, &got)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("PostCheckStatusStream", func(t *testing.T) {
+		corrupted := []activation.PostDataRange{{File: 1, From: 10, To: 20, Truncated: true, Repaired: true}}
+		gomock.InOrder(
+			postChecker.EXPECT().Status().Return(&activation.PostCheckStatus{
+				State:         activation.PostCheckStateInProgress,
+				SampledLabels: 100,
+				CheckedLabels: 50,
+			}).Call,
+			postChecker.EXPECT().Status().Return(&activation.PostCheckStatus{
+				State:          activation.PostCheckStateComplete,
+				SampledLabels:  100,
+				CheckedLabels:  100,
+				Corrupted:      corrupted,
+				RepairedLabels: 11,
+			}).Call,
+		)
+		stream, err := StreamJSON(ctx, conn, SmesherPostCheckStatusStreamMethod, &SmesherPostCheckStatusStreamRequest{})
+		require.NoError(t, err)
+		var got SmesherPostCheckStatus
+		require.NoError(t, stream.RecvMsg(&got))
+		require.Equal(t, "in_progress", got.State)
+		require.Equal(t, uint64(50), got.CheckedLabels)
+
+		require.NoError(t, stream.RecvMsg(&got))
+		require.Equal(t, SmesherPostCheckStatus{
+			State:          "complete",
+			SampledLabels:  100,
+			CheckedLabels:  100,
+			Corrupted:      []SmesherPostDataRange{{File: 1, FromLabel: 10, ToLabel: 20, Truncated: true, Repaired: true}},
+			RepairedLabels: 11,
+		}, got)
+		// stream is closed after the check is finished
+		require.ErrorIs(t, stream.RecvMsg(&got), io.EOF)
+	})
+
 	t.Run("IsSmeshing", func(t *testing.T) {
 		res, err := c.IsSmeshing(context.Background(), &empty.Empty{})
 		require.NoError(t, err)
@@ -4,10 +4,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/spacemeshos/post/proving"
+
 	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/p2p"
 	"github.com/spacemeshos/go-spacemesh/system"
+	"github.com/spacemeshos/go-spacemesh/txs"
 )
 
 //go:generate mockgen -typed -package=grpcserver -destination=./mocks.go -source=./interface.go
@@ -55,6 +58,14 @@ type postSetupProvider interface {
 	Config() activation.PostConfig
 }
 
+// postProver generates proofs for the post data owned by the post service.
+type postProver interface {
+	Status() *activation.PostSetupStatus
+	CommitmentAtx() (types.ATXID, error)
+	GenerateProof(ctx context.Context, challenge []byte, options ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error)
+	VRFNonce() (*types.VRFPostIndex, error)
+}
+
 // peerCounter is an api to get amount of connected peers.
 type peerCounter interface {
 	PeerCount() uint64
@@ -86,3 +97,28 @@ type meshAPI interface {
 type oracle interface {
 	ActiveSet(context.Context, types.EpochID) ([]types.ATXID, error)
 }
+
+// mempool is an API for inspecting and managing pending transactions.
+type mempool interface {
+	NonceGaps(types.Address) (uint64, []txs.NonceRange, error)
+	Pending(...types.Address) []txs.PendingAccount
+	Evict(context.Context, []types.TransactionID) ([]types.TransactionID, error)
+}
+
+// feeEstimator is an API for fee statistics and estimation.
+type feeEstimator interface {
+	LayerFees(from, to types.LayerID) ([]*txs.LayerFees, error)
+	MempoolFees() *txs.MempoolFees
+	EstimateFee(target uint32) (uint64, error)
+}
+
+// poetHealthReporter is an API for the history of submissions to poets.
+type poetHealthReporter interface {
+	Report(from types.EpochID) ([]activation.PoetHealthReport, error)
+}
+
+// postDataChecker checks integrity of the post data in background.
+type postDataChecker interface {
+	Start(setup activation.PostSetupOpts, opts activation.PostCheckOpts) error
+	Status() *activation.PostCheckStatus
+}
@@ -0,0 +1,136 @@
+// This file is the only place where services with json encoded messages are plumbed.
+//
+// EXPERIMENTAL: services that are not defined in spacemeshos/api (AdminService.SmesherTimeline,
+// BeaconService, FeeService, MempoolService, PostService, PostVerifierService and the json methods
+// of SmesherService) use plain go types for requests and responses. Their methods and messages
+// may change in any release without notice. The node serves them only if they are listed in
+// Config.ExperimentalServices.
+//
+// They are described with newJSONService, jsonUnary and jsonServerStream, served under jsonPackage
+// and encoded with JSONCodec. Clients call them with InvokeJSON and StreamJSON, full method names
+// are built with jsonMethod. Messages should move to spacemeshos/api once the services are stable.
+
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// JSONCodec is the name of the grpc codec that encodes messages with encoding/json.
+// Clients select it with grpc.CallContentSubtype.
+const JSONCodec = "json"
+
+// jsonPackage is the package for services with json encoded messages,
+// it marks them as experimental for the clients.
+const jsonPackage = "spacemesh.experimental.v1."
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return JSONCodec
+}
+
+// InvokeJSON calls the unary method of the service with json encoded messages.
+func InvokeJSON(ctx context.Context, conn grpc.ClientConnInterface, method string, req, resp any) error {
+	return conn.Invoke(ctx, method, req, resp, grpc.CallContentSubtype(JSONCodec))
+}
+
+// jsonService builds description of the grpc service with json encoded messages.
+type jsonService struct {
+	desc grpc.ServiceDesc
+}
+
+func newJSONService(name string) *jsonService {
+	return &jsonService{desc: grpc.ServiceDesc{
+		ServiceName: jsonPackage + name,
+		HandlerType: (*any)(nil),
+	}}
+}
+
+// jsonMethod returns the full name of the method in the service with json encoded messages.
+func jsonMethod(service, method string) string {
+	return fmt.Sprintf("/%s%s/%s", jsonPackage, service, method)
+}
+
+func (s *jsonService) register(server *Server, impl any) {
+	server.GrpcServer.RegisterService(&s.desc, impl)
+}
+
+// jsonUnary adds unary method to the service.
+func jsonUnary[Req, Resp any](s *jsonService, name string, handler func(context.Context, *Req) (*Resp, error)) {
+	full := fmt.Sprintf("/%s/%s", s.desc.ServiceName, name)
+	s.desc.Methods = append(s.desc.Methods, grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: full}
+			return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return handler(ctx, req.(*Req))
+			})
+		},
+	})
+}
+
+// jsonStream is a server side of the stream with json encoded messages of type T.
+type jsonStream[T any] struct {
+	grpc.ServerStream
+}
+
+// Send sends a message to the client.
+func (s *jsonStream[T]) Send(msg *T) error {
+	return s.ServerStream.SendMsg(msg)
+}
+
+// jsonServerStream adds method that receives a single request and streams responses.
+func jsonServerStream[Req, Resp any](s *jsonService, name string, handler func(*Req, *jsonStream[Resp]) error) {
+	s.desc.Streams = append(s.desc.Streams, grpc.StreamDesc{
+		StreamName:    name,
+		ServerStreams: true,
+		Handler: func(_ any, stream grpc.ServerStream) error {
+			req := new(Req)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			return handler(req, &jsonStream[Resp]{ServerStream: stream})
+		},
+	})
+}
+
+// StreamJSON calls the server streaming method of the service with json encoded messages.
+// Responses are read with RecvMsg until io.EOF.
+func StreamJSON(ctx context.Context, conn grpc.ClientConnInterface, method string, req any) (grpc.ClientStream, error) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method, grpc.CallContentSubtype(JSONCodec))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
@@ -0,0 +1,292 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/txs"
+)
+
+const mempoolServiceName = "MempoolService"
+
+var (
+	// MempoolNonceGapsMethod is a full name of the method that returns MempoolNonceGapsResponse.
+	MempoolNonceGapsMethod = jsonMethod(mempoolServiceName, "NonceGaps")
+	// MempoolTransactionsMethod is a full name of the method that returns MempoolTransactionsResponse.
+	MempoolTransactionsMethod = jsonMethod(mempoolServiceName, "Transactions")
+	// MempoolEvictMethod is a full name of the method that returns MempoolEvictResponse.
+	MempoolEvictMethod = jsonMethod(mempoolServiceName, "Evict")
+	// MempoolStreamMethod is a full name of the method that streams MempoolUpdate.
+	MempoolStreamMethod = jsonMethod(mempoolServiceName, "Stream")
+)
+
+// MempoolNonceGapsRequest is a request for the nonce gaps of the account.
+type MempoolNonceGapsRequest struct {
+	Address string `json:"address"`
+}
+
+// MempoolNonceRange is an inclusive range of nonces.
+type MempoolNonceRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// MempoolNonceGapsResponse lists ranges of nonces that are missing before pending transactions of the account.
+type MempoolNonceGapsResponse struct {
+	Address   string              `json:"address"`
+	NextNonce uint64              `json:"next_nonce"`
+	Gaps      []MempoolNonceRange `json:"gaps"`
+}
+
+// MempoolTransactionsRequest selects transactions in the mempool.
+// All fields are optional, zero MaxFee means that fee is not bounded.
+type MempoolTransactionsRequest struct {
+	Principal string `json:"principal,omitempty"`
+	MinFee    uint64 `json:"min_fee,omitempty"`
+	MaxFee    uint64 `json:"max_fee,omitempty"`
+}
+
+// MempoolTransaction is a pending transaction in the mempool.
+type MempoolTransaction struct {
+	ID        string    `json:"id"`
+	Principal string    `json:"principal"`
+	Nonce     uint64    `json:"nonce"`
+	GasPrice  uint64    `json:"gas_price"`
+	MaxGas    uint64    `json:"max_gas"`
+	Fee       uint64    `json:"fee"`
+	MaxSpend  uint64    `json:"max_spend"`
+	Received  time.Time `json:"received"`
+	LayerMin  uint32    `json:"layer_min,omitempty"`
+	LayerMax  uint32    `json:"layer_max,omitempty"`
+	// Layer and Block are set if transaction is included in a proposal or a block.
+	Layer uint32 `json:"layer,omitempty"`
+	Block string `json:"block,omitempty"`
+}
+
+// MempoolAccount is an account with transactions in the mempool.
+type MempoolAccount struct {
+	Address string `json:"address"`
+	// ProjectedNonce and ProjectedBalance are expected after all pending transactions are applied.
+	ProjectedNonce   uint64               `json:"projected_nonce"`
+	ProjectedBalance uint64               `json:"projected_balance"`
+	Transactions     []MempoolTransaction `json:"transactions"`
+}
+
+// MempoolTransactionsResponse lists accounts with transactions in the mempool.
+type MempoolTransactionsResponse struct {
+	Accounts []MempoolAccount `json:"accounts"`
+}
+
+// MempoolEvictRequest is a request to evict transactions from the mempool.
+type MempoolEvictRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// MempoolEvictResponse lists transactions that were evicted.
+type MempoolEvictResponse struct {
+	Evicted []string `json:"evicted"`
+}
+
+// MempoolStreamRequest subscribes to mempool updates, optionally for a single principal.
+type MempoolStreamRequest struct {
+	Principal string `json:"principal,omitempty"`
+}
+
+// MempoolUpdate is sent when a transaction is added to or removed from the mempool.
+type MempoolUpdate struct {
+	ID        string `json:"id"`
+	Principal string `json:"principal"`
+	Nonce     uint64 `json:"nonce"`
+	Fee       uint64 `json:"fee"`
+	Added     bool   `json:"added"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// MempoolService exposes pending transactions in the mempool.
+type MempoolService struct {
+	mempool mempool
+}
+
+// NewMempoolService creates a new grpc service for the mempool.
+func NewMempoolService(mempool mempool) *MempoolService {
+	return &MempoolService{mempool: mempool}
+}
+
+// RegisterService registers this service with a grpc server instance.
+func (s *MempoolService) RegisterService(server *Server) {
+	svc := newJSONService(mempoolServiceName)
+	jsonUnary(svc, "NonceGaps", s.NonceGaps)
+	jsonUnary(svc, "Transactions", s.Transactions)
+	jsonUnary(svc, "Evict", s.Evict)
+	jsonServerStream(svc, "Stream", s.Stream)
+	svc.register(server, s)
+}
+
+// NonceGaps returns nonces that must be filled before pending transactions of the account can be applied.
+func (s *MempoolService) NonceGaps(_ context.Context, req *MempoolNonceGapsRequest) (*MempoolNonceGapsResponse, error) {
+	addr, err := types.StringToAddress(req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address %q: %v", req.Address, err)
+	}
+	next, gaps, err := s.mempool.NonceGaps(addr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rst := &MempoolNonceGapsResponse{
+		Address:   addr.String(),
+		NextNonce: next,
+		Gaps:      make([]MempoolNonceRange, 0, len(gaps)),
+	}
+	for _, gap := range gaps {
+		rst.Gaps = append(rst.Gaps, MempoolNonceRange{From: gap.From, To: gap.To})
+	}
+	return rst, nil
+}
+
+// Transactions returns accounts with pending transactions that match the request.
+func (s *MempoolService) Transactions(_ context.Context, req *MempoolTransactionsRequest) (*MempoolTransactionsResponse, error) {
+	var addrs []types.Address
+	if req.Principal != "" {
+		addr, err := types.StringToAddress(req.Principal)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid principal %q: %v", req.Principal, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	if req.MaxFee != 0 && req.MinFee > req.MaxFee {
+		return nil, status.Errorf(codes.InvalidArgument, "min fee %d is larger than max fee %d", req.MinFee, req.MaxFee)
+	}
+	rst := &MempoolTransactionsResponse{Accounts: []MempoolAccount{}}
+	for _, acc := range s.mempool.Pending(addrs...) {
+		casted := MempoolAccount{
+			Address:          acc.Address.String(),
+			ProjectedNonce:   acc.NextNonce,
+			ProjectedBalance: acc.Balance,
+		}
+		for i := range acc.Transactions {
+			ntx := &acc.Transactions[i]
+			fee := ntx.Fee()
+			if fee < req.MinFee || (req.MaxFee != 0 && fee > req.MaxFee) {
+				continue
+			}
+			casted.Transactions = append(casted.Transactions, castMempoolTransaction(ntx))
+		}
+		if len(casted.Transactions) > 0 {
+			rst.Accounts = append(rst.Accounts, casted)
+		}
+	}
+	return rst, nil
+}
+
+// Evict removes pending transactions from the mempool, they are kept in the database.
+// Transactions that are already included in proposals or blocks are not evicted.
+func (s *MempoolService) Evict(ctx context.Context, req *MempoolEvictRequest) (*MempoolEvictResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ids are empty")
+	}
+	tids := make([]types.TransactionID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		tid, err := parseTransactionID(id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		tids = append(tids, tid)
+	}
+	evicted, err := s.mempool.Evict(ctx, tids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rst := &MempoolEvictResponse{Evicted: make([]string, 0, len(evicted))}
+	for _, tid := range evicted {
+		rst.Evicted = append(rst.Evicted, tid.String())
+	}
+	return rst, nil
+}
+
+// Stream streams transactions that are added to or removed from the mempool.
+func (s *MempoolService) Stream(req *MempoolStreamRequest, stream *jsonStream[MempoolUpdate]) error {
+	var matcher func(*events.EventMempool) bool
+	if req.Principal != "" {
+		addr, err := types.StringToAddress(req.Principal)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid principal %q: %v", req.Principal, err)
+		}
+		matcher = func(ev *events.EventMempool) bool {
+			return ev.Principal == addr
+		}
+	}
+	sub, err := events.SubscribeMatched(matcher)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer sub.Close()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return status.Errorf(codes.Unavailable, "can't send header")
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.Full():
+			return status.Error(codes.Canceled, "buffer overflow")
+		case ev := <-sub.Out():
+			if err := stream.Send(&MempoolUpdate{
+				ID:        ev.ID.String(),
+				Principal: ev.Principal.String(),
+				Nonce:     ev.Nonce,
+				Fee:       ev.Fee,
+				Added:     ev.Added,
+				Reason:    ev.Reason,
+			}); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
+	}
+}
+
+func castMempoolTransaction(ntx *txs.NanoTX) MempoolTransaction {
+	rst := MempoolTransaction{
+		ID:        ntx.ID.String(),
+		Principal: ntx.Principal.String(),
+		Nonce:     ntx.Nonce,
+		GasPrice:  ntx.GasPrice,
+		MaxGas:    ntx.MaxGas,
+		Fee:       ntx.Fee(),
+		MaxSpend:  ntx.MaxSpend,
+		Received:  ntx.Received,
+		LayerMin:  ntx.LayerLimits.Min,
+		LayerMax:  ntx.LayerLimits.Max,
+		Layer:     ntx.Layer.Uint32(),
+	}
+	if ntx.Block != types.EmptyBlockID {
+		rst.Block = ntx.Block.String()
+	}
+	return rst
+}
+
+func parseTransactionID(id string) (types.TransactionID, error) {
+	var tid types.TransactionID
+	buf, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+	if err != nil {
+		return tid, fmt.Errorf("invalid transaction id %q: %w", id, err)
+	}
+	if len(buf) != len(tid) {
+		return tid, fmt.Errorf("invalid transaction id %q: expected %d bytes", id, len(tid))
+	}
+	copy(tid[:], buf)
+	return tid, nil
+}
@@ -0,0 +1,190 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/txs"
+)
+
+func TestMempoolService_NonceGaps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mempool := NewMockmempool(ctrl)
+	svc := NewMempoolService(mempool)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	addr := types.GenerateAddress([]byte{1})
+	mempool.EXPECT().NonceGaps(addr).Return(uint64(3), []txs.NonceRange{{From: 4, To: 4}, {From: 6, To: math.MaxUint64 - 1}}, nil)
+	var got MempoolNonceGapsResponse
+	require.NoError(t, InvokeJSON(ctx, conn, MempoolNonceGapsMethod, &MempoolNonceGapsRequest{Address: addr.String()}, &got))
+	require.Equal(t, MempoolNonceGapsResponse{
+		Address:   addr.String(),
+		NextNonce: 3,
+		Gaps:      []MempoolNonceRange{{From: 4, To: 4}, {From: 6, To: math.MaxUint64 - 1}},
+	}, got)
+
+	err := InvokeJSON(ctx, conn, MempoolNonceGapsMethod, &MempoolNonceGapsRequest{Address: "invalid"}, &got)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mempool.EXPECT().NonceGaps(addr).Return(uint64(0), nil, errors.New("test"))
+	err = InvokeJSON(ctx, conn, MempoolNonceGapsMethod, &MempoolNonceGapsRequest{Address: addr.String()}, &got)
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestMempoolService_Transactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mempool := NewMockmempool(ctrl)
+	svc := NewMempoolService(mempool)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	addr := types.GenerateAddress([]byte{1})
+	received := time.Now().UTC()
+	ntx := func(nonce, price uint64) txs.NanoTX {
+		return txs.NanoTX{
+			ID:       types.RandomTransactionID(),
+			Received: received,
+			TxHeader: types.TxHeader{Principal: addr, Nonce: nonce, GasPrice: price, MaxGas: 10, MaxSpend: 100},
+		}
+	}
+	pending := []txs.PendingAccount{{
+		Address:      addr,
+		NextNonce:    3,
+		Balance:      1000,
+		Transactions: []txs.NanoTX{ntx(1, 1), ntx(2, 5)},
+	}}
+	pending[0].Transactions[0].Layer = 7
+	pending[0].Transactions[0].LayerLimits = types.LayerLimits{Min: 5, Max: 10}
+
+	mempool.EXPECT().Pending().Return(pending)
+	var got MempoolTransactionsResponse
+	require.NoError(t, InvokeJSON(ctx, conn, MempoolTransactionsMethod, &MempoolTransactionsRequest{}, &got))
+	require.Equal(t, MempoolTransactionsResponse{Accounts: []MempoolAccount{{
+		Address:          addr.String(),
+		ProjectedNonce:   3,
+		ProjectedBalance: 1000,
+		Transactions: []MempoolTransaction{
+			{
+				ID:        pending[0].Transactions[0].ID.String(),
+				Principal: addr.String(),
+				Nonce:     1,
+				GasPrice:  1,
+				MaxGas:    10,
+				Fee:       10,
+				MaxSpend:  100,
+				Received:  received,
+				LayerMin:  5,
+				LayerMax:  10,
+				Layer:     7,
+			},
+			{
+				ID:        pending[0].Transactions[1].ID.String(),
+				Principal: addr.String(),
+				Nonce:     2,
+				GasPrice:  5,
+				MaxGas:    10,
+				Fee:       50,
+				MaxSpend:  100,
+				Received:  received,
+			},
+		},
+	}}}, got)
+
+	mempool.EXPECT().Pending(addr).Return(pending)
+	got = MempoolTransactionsResponse{}
+	require.NoError(t, InvokeJSON(ctx, conn, MempoolTransactionsMethod,
+		&MempoolTransactionsRequest{Principal: addr.String(), MinFee: 20}, &got))
+	require.Len(t, got.Accounts, 1)
+	require.Len(t, got.Accounts[0].Transactions, 1)
+	require.EqualValues(t, 2, got.Accounts[0].Transactions[0].Nonce)
+
+	mempool.EXPECT().Pending().Return(pending)
+	got = MempoolTransactionsResponse{}
+	require.NoError(t, InvokeJSON(ctx, conn, MempoolTransactionsMethod, &MempoolTransactionsRequest{MaxFee: 5}, &got))
+	require.Empty(t, got.Accounts)
+
+	err := InvokeJSON(ctx, conn, MempoolTransactionsMethod, &MempoolTransactionsRequest{MinFee: 10, MaxFee: 5}, &got)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = InvokeJSON(ctx, conn, MempoolTransactionsMethod, &MempoolTransactionsRequest{Principal: "invalid"}, &got)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMempoolService_Evict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mempool := NewMockmempool(ctrl)
+	svc := NewMempoolService(mempool)
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	tids := []types.TransactionID{types.RandomTransactionID(), types.RandomTransactionID()}
+	mempool.EXPECT().Evict(gomock.Any(), tids).Return(tids[1:], nil)
+	var got MempoolEvictResponse
+	require.NoError(t, InvokeJSON(ctx, conn, MempoolEvictMethod,
+		&MempoolEvictRequest{IDs: []string{tids[0].String(), tids[1].String()}}, &got))
+	require.Equal(t, []string{tids[1].String()}, got.Evicted)
+
+	for _, ids := range [][]string{nil, {"0x01"}, {"zz"}} {
+		err := InvokeJSON(ctx, conn, MempoolEvictMethod, &MempoolEvictRequest{IDs: ids}, &got)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), "%v", ids)
+	}
+}
+
+func TestMempoolService_Stream(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+
+	svc := NewMempoolService(NewMockmempool(gomock.NewController(t)))
+	t.Cleanup(launchServer(t, cfg, svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn := dialGrpc(ctx, t, cfg.PublicListener)
+
+	addr := types.GenerateAddress([]byte{1})
+	stream, err := StreamJSON(ctx, conn, MempoolStreamMethod, &MempoolStreamRequest{Principal: addr.String()})
+	require.NoError(t, err)
+	// header is sent after the subscription is created
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	updates := []events.EventMempool{
+		{ID: types.RandomTransactionID(), Principal: addr, Nonce: 1, Fee: 10, Added: true},
+		{ID: types.RandomTransactionID(), Principal: types.GenerateAddress([]byte{2}), Nonce: 1, Added: true},
+		{ID: types.RandomTransactionID(), Principal: addr, Nonce: 2, Fee: 20, Reason: events.MempoolExpired},
+	}
+	for _, ev := range updates {
+		events.ReportMempool(ev)
+	}
+	var got MempoolUpdate
+	require.NoError(t, stream.RecvMsg(&got))
+	require.Equal(t, updates[0].ID.String(), got.ID)
+	require.True(t, got.Added)
+	require.NoError(t, stream.RecvMsg(&got))
+	require.Equal(t, MempoolUpdate{
+		ID:        updates[2].ID.String(),
+		Principal: addr.String(),
+		Nonce:     2,
+		Fee:       20,
+		Reason:    events.MempoolExpired,
+	}, got)
+}
@@ -13,6 +13,8 @@ import (
 	types "github.com/spacemeshos/go-spacemesh/common/types"
 	p2p "github.com/spacemeshos/go-spacemesh/p2p"
 	system "github.com/spacemeshos/go-spacemesh/system"
+	txs "github.com/spacemeshos/go-spacemesh/txs"
+	proving "github.com/spacemeshos/post/proving"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -928,6 +930,190 @@ func (c *postSetupProviderStatusCall) DoAndReturn(f func() *activation.PostSetup
 	return c
 }
 
+// MockpostProver is a mock of postProver interface.
+type MockpostProver struct {
+	ctrl     *gomock.Controller
+	recorder *MockpostProverMockRecorder
+}
+
+// MockpostProverMockRecorder is the mock recorder for MockpostProver.
+type MockpostProverMockRecorder struct {
+	mock *MockpostProver
+}
+
+// NewMockpostProver creates a new mock instance.
+func NewMockpostProver(ctrl *gomock.Controller) *MockpostProver {
+	mock := &MockpostProver{ctrl: ctrl}
+	mock.recorder = &MockpostProverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpostProver) EXPECT() *MockpostProverMockRecorder {
+	return m.recorder
+}
+
+// CommitmentAtx mocks base method.
+func (m *MockpostProver) CommitmentAtx() (types.ATXID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitmentAtx")
+	ret0, _ := ret[0].(types.ATXID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitmentAtx indicates an expected call of CommitmentAtx.
+func (mr *MockpostProverMockRecorder) CommitmentAtx() *postProverCommitmentAtxCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitmentAtx", reflect.TypeOf((*MockpostProver)(nil).CommitmentAtx))
+	return &postProverCommitmentAtxCall{Call: call}
+}
+
+// postProverCommitmentAtxCall wrap *gomock.Call
+type postProverCommitmentAtxCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postProverCommitmentAtxCall) Return(arg0 types.ATXID, arg1 error) *postProverCommitmentAtxCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postProverCommitmentAtxCall) Do(f func() (types.ATXID, error)) *postProverCommitmentAtxCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postProverCommitmentAtxCall) DoAndReturn(f func() (types.ATXID, error)) *postProverCommitmentAtxCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GenerateProof mocks base method.
+func (m *MockpostProver) GenerateProof(ctx context.Context, challenge []byte, options ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, challenge}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GenerateProof", varargs...)
+	ret0, _ := ret[0].(*types.Post)
+	ret1, _ := ret[1].(*types.PostMetadata)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GenerateProof indicates an expected call of GenerateProof.
+func (mr *MockpostProverMockRecorder) GenerateProof(ctx, challenge interface{}, options ...interface{}) *postProverGenerateProofCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, challenge}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateProof", reflect.TypeOf((*MockpostProver)(nil).GenerateProof), varargs...)
+	return &postProverGenerateProofCall{Call: call}
+}
+
+// postProverGenerateProofCall wrap *gomock.Call
+type postProverGenerateProofCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postProverGenerateProofCall) Return(arg0 *types.Post, arg1 *types.PostMetadata, arg2 error) *postProverGenerateProofCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postProverGenerateProofCall) Do(f func(context.Context, []byte, ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error)) *postProverGenerateProofCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postProverGenerateProofCall) DoAndReturn(f func(context.Context, []byte, ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error)) *postProverGenerateProofCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Status mocks base method.
+func (m *MockpostProver) Status() *activation.PostSetupStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(*activation.PostSetupStatus)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockpostProverMockRecorder) Status() *postProverStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockpostProver)(nil).Status))
+	return &postProverStatusCall{Call: call}
+}
+
+// postProverStatusCall wrap *gomock.Call
+type postProverStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postProverStatusCall) Return(arg0 *activation.PostSetupStatus) *postProverStatusCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postProverStatusCall) Do(f func() *activation.PostSetupStatus) *postProverStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postProverStatusCall) DoAndReturn(f func() *activation.PostSetupStatus) *postProverStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// VRFNonce mocks base method.
+func (m *MockpostProver) VRFNonce() (*types.VRFPostIndex, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VRFNonce")
+	ret0, _ := ret[0].(*types.VRFPostIndex)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VRFNonce indicates an expected call of VRFNonce.
+func (mr *MockpostProverMockRecorder) VRFNonce() *postProverVRFNonceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VRFNonce", reflect.TypeOf((*MockpostProver)(nil).VRFNonce))
+	return &postProverVRFNonceCall{Call: call}
+}
+
+// postProverVRFNonceCall wrap *gomock.Call
+type postProverVRFNonceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postProverVRFNonceCall) Return(arg0 *types.VRFPostIndex, arg1 error) *postProverVRFNonceCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postProverVRFNonceCall) Do(f func() (*types.VRFPostIndex, error)) *postProverVRFNonceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postProverVRFNonceCall) DoAndReturn(f func() (*types.VRFPostIndex, error)) *postProverVRFNonceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MockpeerCounter is a mock of peerCounter interface.
 type MockpeerCounter struct {
 	ctrl     *gomock.Controller
@@ -1541,3 +1727,447 @@ func (c *oracleActiveSetCall) DoAndReturn(f func(context.Context, types.EpochID)
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Mockmempool is a mock of mempool interface.
+type Mockmempool struct {
+	ctrl     *gomock.Controller
+	recorder *MockmempoolMockRecorder
+}
+
+// MockmempoolMockRecorder is the mock recorder for Mockmempool.
+type MockmempoolMockRecorder struct {
+	mock *Mockmempool
+}
+
+// NewMockmempool creates a new mock instance.
+func NewMockmempool(ctrl *gomock.Controller) *Mockmempool {
+	mock := &Mockmempool{ctrl: ctrl}
+	mock.recorder = &MockmempoolMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockmempool) EXPECT() *MockmempoolMockRecorder {
+	return m.recorder
+}
+
+// Evict mocks base method.
+func (m *Mockmempool) Evict(arg0 context.Context, arg1 []types.TransactionID) ([]types.TransactionID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evict", arg0, arg1)
+	ret0, _ := ret[0].([]types.TransactionID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Evict indicates an expected call of Evict.
+func (mr *MockmempoolMockRecorder) Evict(arg0, arg1 interface{}) *mempoolEvictCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evict", reflect.TypeOf((*Mockmempool)(nil).Evict), arg0, arg1)
+	return &mempoolEvictCall{Call: call}
+}
+
+// mempoolEvictCall wrap *gomock.Call
+type mempoolEvictCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *mempoolEvictCall) Return(arg0 []types.TransactionID, arg1 error) *mempoolEvictCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *mempoolEvictCall) Do(f func(context.Context, []types.TransactionID) ([]types.TransactionID, error)) *mempoolEvictCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *mempoolEvictCall) DoAndReturn(f func(context.Context, []types.TransactionID) ([]types.TransactionID, error)) *mempoolEvictCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// NonceGaps mocks base method.
+func (m *Mockmempool) NonceGaps(arg0 types.Address) (uint64, []txs.NonceRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NonceGaps", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]txs.NonceRange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// NonceGaps indicates an expected call of NonceGaps.
+func (mr *MockmempoolMockRecorder) NonceGaps(arg0 interface{}) *mempoolNonceGapsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NonceGaps", reflect.TypeOf((*Mockmempool)(nil).NonceGaps), arg0)
+	return &mempoolNonceGapsCall{Call: call}
+}
+
+// mempoolNonceGapsCall wrap *gomock.Call
+type mempoolNonceGapsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *mempoolNonceGapsCall) Return(arg0 uint64, arg1 []txs.NonceRange, arg2 error) *mempoolNonceGapsCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *mempoolNonceGapsCall) Do(f func(types.Address) (uint64, []txs.NonceRange, error)) *mempoolNonceGapsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *mempoolNonceGapsCall) DoAndReturn(f func(types.Address) (uint64, []txs.NonceRange, error)) *mempoolNonceGapsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Pending mocks base method.
+func (m *Mockmempool) Pending(arg0 ...types.Address) []txs.PendingAccount {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Pending", varargs...)
+	ret0, _ := ret[0].([]txs.PendingAccount)
+	return ret0
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockmempoolMockRecorder) Pending(arg0 ...interface{}) *mempoolPendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*Mockmempool)(nil).Pending), arg0...)
+	return &mempoolPendingCall{Call: call}
+}
+
+// mempoolPendingCall wrap *gomock.Call
+type mempoolPendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *mempoolPendingCall) Return(arg0 []txs.PendingAccount) *mempoolPendingCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *mempoolPendingCall) Do(f func(...types.Address) []txs.PendingAccount) *mempoolPendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *mempoolPendingCall) DoAndReturn(f func(...types.Address) []txs.PendingAccount) *mempoolPendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockfeeEstimator is a mock of feeEstimator interface.
+type MockfeeEstimator struct {
+	ctrl     *gomock.Controller
+	recorder *MockfeeEstimatorMockRecorder
+}
+
+// MockfeeEstimatorMockRecorder is the mock recorder for MockfeeEstimator.
+type MockfeeEstimatorMockRecorder struct {
+	mock *MockfeeEstimator
+}
+
+// NewMockfeeEstimator creates a new mock instance.
+func NewMockfeeEstimator(ctrl *gomock.Controller) *MockfeeEstimator {
+	mock := &MockfeeEstimator{ctrl: ctrl}
+	mock.recorder = &MockfeeEstimatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockfeeEstimator) EXPECT() *MockfeeEstimatorMockRecorder {
+	return m.recorder
+}
+
+// EstimateFee mocks base method.
+func (m *MockfeeEstimator) EstimateFee(target uint32) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateFee", target)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateFee indicates an expected call of EstimateFee.
+func (mr *MockfeeEstimatorMockRecorder) EstimateFee(target interface{}) *feeEstimatorEstimateFeeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateFee", reflect.TypeOf((*MockfeeEstimator)(nil).EstimateFee), target)
+	return &feeEstimatorEstimateFeeCall{Call: call}
+}
+
+// feeEstimatorEstimateFeeCall wrap *gomock.Call
+type feeEstimatorEstimateFeeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *feeEstimatorEstimateFeeCall) Return(arg0 uint64, arg1 error) *feeEstimatorEstimateFeeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *feeEstimatorEstimateFeeCall) Do(f func(uint32) (uint64, error)) *feeEstimatorEstimateFeeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *feeEstimatorEstimateFeeCall) DoAndReturn(f func(uint32) (uint64, error)) *feeEstimatorEstimateFeeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// LayerFees mocks base method.
+func (m *MockfeeEstimator) LayerFees(from, to types.LayerID) ([]*txs.LayerFees, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LayerFees", from, to)
+	ret0, _ := ret[0].([]*txs.LayerFees)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LayerFees indicates an expected call of LayerFees.
+func (mr *MockfeeEstimatorMockRecorder) LayerFees(from, to interface{}) *feeEstimatorLayerFeesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LayerFees", reflect.TypeOf((*MockfeeEstimator)(nil).LayerFees), from, to)
+	return &feeEstimatorLayerFeesCall{Call: call}
+}
+
+// feeEstimatorLayerFeesCall wrap *gomock.Call
+type feeEstimatorLayerFeesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *feeEstimatorLayerFeesCall) Return(arg0 []*txs.LayerFees, arg1 error) *feeEstimatorLayerFeesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *feeEstimatorLayerFeesCall) Do(f func(types.LayerID, types.LayerID) ([]*txs.LayerFees, error)) *feeEstimatorLayerFeesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *feeEstimatorLayerFeesCall) DoAndReturn(f func(types.LayerID, types.LayerID) ([]*txs.LayerFees, error)) *feeEstimatorLayerFeesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MempoolFees mocks base method.
+func (m *MockfeeEstimator) MempoolFees() *txs.MempoolFees {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MempoolFees")
+	ret0, _ := ret[0].(*txs.MempoolFees)
+	return ret0
+}
+
+// MempoolFees indicates an expected call of MempoolFees.
+func (mr *MockfeeEstimatorMockRecorder) MempoolFees() *feeEstimatorMempoolFeesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MempoolFees", reflect.TypeOf((*MockfeeEstimator)(nil).MempoolFees))
+	return &feeEstimatorMempoolFeesCall{Call: call}
+}
+
+// feeEstimatorMempoolFeesCall wrap *gomock.Call
+type feeEstimatorMempoolFeesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *feeEstimatorMempoolFeesCall) Return(arg0 *txs.MempoolFees) *feeEstimatorMempoolFeesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *feeEstimatorMempoolFeesCall) Do(f func() *txs.MempoolFees) *feeEstimatorMempoolFeesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *feeEstimatorMempoolFeesCall) DoAndReturn(f func() *txs.MempoolFees) *feeEstimatorMempoolFeesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockpoetHealthReporter is a mock of poetHealthReporter interface.
+type MockpoetHealthReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockpoetHealthReporterMockRecorder
+}
+
+// MockpoetHealthReporterMockRecorder is the mock recorder for MockpoetHealthReporter.
+type MockpoetHealthReporterMockRecorder struct {
+	mock *MockpoetHealthReporter
+}
+
+// NewMockpoetHealthReporter creates a new mock instance.
+func NewMockpoetHealthReporter(ctrl *gomock.Controller) *MockpoetHealthReporter {
+	mock := &MockpoetHealthReporter{ctrl: ctrl}
+	mock.recorder = &MockpoetHealthReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpoetHealthReporter) EXPECT() *MockpoetHealthReporterMockRecorder {
+	return m.recorder
+}
+
+// Report mocks base method.
+func (m *MockpoetHealthReporter) Report(from types.EpochID) ([]activation.PoetHealthReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", from)
+	ret0, _ := ret[0].([]activation.PoetHealthReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockpoetHealthReporterMockRecorder) Report(from interface{}) *poetHealthReporterReportCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockpoetHealthReporter)(nil).Report), from)
+	return &poetHealthReporterReportCall{Call: call}
+}
+
+// poetHealthReporterReportCall wrap *gomock.Call
+type poetHealthReporterReportCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *poetHealthReporterReportCall) Return(arg0 []activation.PoetHealthReport, arg1 error) *poetHealthReporterReportCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *poetHealthReporterReportCall) Do(f func(types.EpochID) ([]activation.PoetHealthReport, error)) *poetHealthReporterReportCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *poetHealthReporterReportCall) DoAndReturn(f func(types.EpochID) ([]activation.PoetHealthReport, error)) *poetHealthReporterReportCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockpostDataChecker is a mock of postDataChecker interface.
+type MockpostDataChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockpostDataCheckerMockRecorder
+}
+
+// MockpostDataCheckerMockRecorder is the mock recorder for MockpostDataChecker.
+type MockpostDataCheckerMockRecorder struct {
+	mock *MockpostDataChecker
+}
+
+// NewMockpostDataChecker creates a new mock instance.
+func NewMockpostDataChecker(ctrl *gomock.Controller) *MockpostDataChecker {
+	mock := &MockpostDataChecker{ctrl: ctrl}
+	mock.recorder = &MockpostDataCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpostDataChecker) EXPECT() *MockpostDataCheckerMockRecorder {
+	return m.recorder
+}
+
+// Start mocks base method.
+func (m *MockpostDataChecker) Start(setup activation.PostSetupOpts, opts activation.PostCheckOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", setup, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockpostDataCheckerMockRecorder) Start(setup, opts interface{}) *postDataCheckerStartCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockpostDataChecker)(nil).Start), setup, opts)
+	return &postDataCheckerStartCall{Call: call}
+}
+
+// postDataCheckerStartCall wrap *gomock.Call
+type postDataCheckerStartCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postDataCheckerStartCall) Return(arg0 error) *postDataCheckerStartCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postDataCheckerStartCall) Do(f func(activation.PostSetupOpts, activation.PostCheckOpts) error) *postDataCheckerStartCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postDataCheckerStartCall) DoAndReturn(f func(activation.PostSetupOpts, activation.PostCheckOpts) error) *postDataCheckerStartCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Status mocks base method.
+func (m *MockpostDataChecker) Status() *activation.PostCheckStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(*activation.PostCheckStatus)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockpostDataCheckerMockRecorder) Status() *postDataCheckerStatusCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockpostDataChecker)(nil).Status))
+	return &postDataCheckerStatusCall{Call: call}
+}
+
+// postDataCheckerStatusCall wrap *gomock.Call
+type postDataCheckerStatusCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *postDataCheckerStatusCall) Return(arg0 *activation.PostCheckStatus) *postDataCheckerStatusCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *postDataCheckerStatusCall) Do(f func() *activation.PostCheckStatus) *postDataCheckerStatusCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *postDataCheckerStatusCall) DoAndReturn(f func() *activation.PostCheckStatus) *postDataCheckerStatusCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
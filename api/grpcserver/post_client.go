@@ -0,0 +1,306 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/spacemeshos/post/proving"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+var errRemotePost = errors.New("not supported for post data on the remote post service")
+
+// PostClientConfig is the configuration of the connection to the remote post service.
+type PostClientConfig struct {
+	// Address of the post service. If empty, post data is expected on the local disk.
+	Address string `mapstructure:"smeshing-remote-post-address"`
+	// Token is sent with every request, it must match the token of the post service.
+	Token string `mapstructure:"smeshing-remote-post-token"`
+	// TLSCACert is a path to the certificate of the authority that signed the certificate
+	// of the post service. If empty, connection is not encrypted, which is allowed only
+	// for loopback addresses.
+	TLSCACert string `mapstructure:"smeshing-remote-post-tls-ca-cert"`
+	// CallTimeout limits duration of all requests except for the proof generation.
+	CallTimeout time.Duration `mapstructure:"smeshing-remote-post-call-timeout"`
+	// PollInterval is the interval for checking whether post service completed initialization.
+	PollInterval time.Duration `mapstructure:"smeshing-remote-post-poll-interval"`
+}
+
+// DefaultPostClientConfig returns the default configuration of the remote post service client.
+func DefaultPostClientConfig() PostClientConfig {
+	return PostClientConfig{
+		CallTimeout:  30 * time.Second,
+		PollInterval: time.Minute,
+	}
+}
+
+// tokenCredentials adds the token to the authorization header of every request.
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: bearerPrefix + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// IsLoopback returns true if the host of the address is a loopback ip or localhost.
+func IsLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// dialWithToken connects to the service that authorizes requests with PostTokenAuth.
+// Connection is encrypted if tlsCACert is not empty, unencrypted connections are allowed
+// only to loopback addresses.
+func dialWithToken(address, token, tlsCACert string) (*grpc.ClientConn, error) {
+	if tlsCACert == "" && !IsLoopback(address) {
+		return nil, fmt.Errorf("tls is required for non-loopback address %s", address)
+	}
+	creds := insecure.NewCredentials()
+	if tlsCACert != "" {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(tlsCACert, "")
+		if err != nil {
+			return nil, fmt.Errorf("load ca certificate: %w", err)
+		}
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{
+			token:  token,
+			secure: tlsCACert != "",
+		}))
+	}
+	return grpc.Dial(address, opts...)
+}
+
+// PostClient forwards requests for proofs to the post service that owns the post data.
+// It satisfies the postSetupProvider interface of the node and can be used instead of
+// activation.PostSetupManager.
+//
+// Post data is initialized by the post service, therefore PrepareInitializer only checks that
+// data is initialized for the node, and StartSession waits until initialization is complete.
+type PostClient struct {
+	id     types.NodeID
+	cfg    activation.PostConfig
+	client PostClientConfig
+	logger log.Log
+	conn   *grpc.ClientConn
+
+	mu         sync.Mutex
+	commitment types.ATXID
+	opts       *activation.PostSetupOpts
+}
+
+// NewPostClient creates a client of the post service for the node with id.
+func NewPostClient(id types.NodeID, cfg activation.PostConfig, client PostClientConfig, logger log.Log) (*PostClient, error) {
+	conn, err := dialWithToken(client.Address, client.Token, client.TLSCACert)
+	if err != nil {
+		return nil, fmt.Errorf("dial post service %s: %w", client.Address, err)
+	}
+	return &PostClient{
+		id:     id,
+		cfg:    cfg,
+		client: client,
+		logger: logger,
+		conn:   conn,
+	}, nil
+}
+
+// Close the connection to the post service.
+func (c *PostClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *PostClient) status(ctx context.Context) (*PostStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.client.CallTimeout)
+	defer cancel()
+	var rst PostStatusResponse
+	if err := InvokeJSON(ctx, c.conn, PostStatusMethod, &PostStatusRequest{}, &rst); err != nil {
+		return nil, fmt.Errorf("post service %s status: %w", c.client.Address, err)
+	}
+	return &rst, nil
+}
+
+// update remembers commitment and options of the post data.
+func (c *PostClient) update(st *PostStatusResponse) *activation.PostSetupOpts {
+	if st.Opts == nil {
+		return nil
+	}
+	opts := activation.DefaultPostSetupOpts()
+	opts.DataDir = st.Opts.DataDir
+	opts.NumUnits = st.Opts.NumUnits
+	opts.MaxFileSize = st.Opts.MaxFileSize
+	opts.Scrypt = st.Opts.Scrypt
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.opts = &opts
+	if st.CommitmentATX != types.EmptyATXID {
+		c.commitment = st.CommitmentATX
+	}
+	return &opts
+}
+
+// Status returns the state of the post data on the post service.
+// State is PostSetupStateError if post service is not reachable.
+func (c *PostClient) Status() *activation.PostSetupStatus {
+	st, err := c.status(context.Background())
+	if err != nil {
+		c.logger.With().Warning("failed to get status of the post service", log.Err(err))
+		return &activation.PostSetupStatus{State: activation.PostSetupStateError}
+	}
+	return &activation.PostSetupStatus{
+		State:            st.State,
+		NumLabelsWritten: st.NumLabelsWritten,
+		LastOpts:         c.update(st),
+	}
+}
+
+// Providers are not available for the remote post data.
+func (c *PostClient) Providers() ([]activation.PostSetupProvider, error) {
+	return nil, errRemotePost
+}
+
+// Benchmark is not available for the remote post data.
+func (c *PostClient) Benchmark(activation.PostSetupProvider) (int, error) {
+	return 0, errRemotePost
+}
+
+// PrepareInitializer checks that the post data on the post service belongs to the node.
+// Options are ignored, post service uses the options of the initialized data.
+func (c *PostClient) PrepareInitializer(ctx context.Context, _ activation.PostSetupOpts) error {
+	st, err := c.status(ctx)
+	if err != nil {
+		return err
+	}
+	if st.NodeID != c.id {
+		return fmt.Errorf("post service %s has data for node %s, expected %s", c.client.Address, st.NodeID, c.id)
+	}
+	if st.LabelsPerUnit != c.cfg.LabelsPerUnit {
+		return fmt.Errorf("post service %s uses %d labels per unit, expected %d",
+			c.client.Address, st.LabelsPerUnit, c.cfg.LabelsPerUnit)
+	}
+	if c.update(st) == nil {
+		return fmt.Errorf("post service %s is not initialized", c.client.Address)
+	}
+	return nil
+}
+
+// StartSession waits until the post service completes initialization of the data.
+func (c *PostClient) StartSession(ctx context.Context) error {
+	ticker := time.NewTicker(c.client.PollInterval)
+	defer ticker.Stop()
+	for {
+		st, err := c.status(ctx)
+		switch {
+		case err != nil:
+			c.logger.With().Warning("failed to get status of the post service", log.Err(err))
+		case st.State == activation.PostSetupStateComplete:
+			c.update(st)
+			return nil
+		case st.State == activation.PostSetupStateError:
+			return fmt.Errorf("post service %s failed to initialize data", c.client.Address)
+		default:
+			c.logger.With().Info("waiting for post service to complete initialization",
+				log.Uint64("num_labels_written", st.NumLabelsWritten),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reset is not available for the remote post data.
+func (c *PostClient) Reset() error {
+	return errRemotePost
+}
+
+// GenerateProof requests the post service to generate proof for the challenge.
+// Options are ignored, proof is generated with the options of the post service.
+func (c *PostClient) GenerateProof(ctx context.Context, challenge []byte, _ ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error) {
+	var rst PostProofResponse
+	err := InvokeJSON(ctx, c.conn, PostProofMethod, &PostProofRequest{Challenge: challenge}, &rst)
+	switch {
+	case status.Code(err) == codes.FailedPrecondition:
+		return nil, nil, fmt.Errorf("%w: %w", activation.ErrPostNotComplete, err)
+	case err != nil:
+		return nil, nil, fmt.Errorf("post service %s proof: %w", c.client.Address, err)
+	case rst.Post == nil || rst.Metadata == nil:
+		return nil, nil, fmt.Errorf("post service %s returned empty proof", c.client.Address)
+	}
+	return rst.Post, rst.Metadata, nil
+}
+
+// CommitmentAtx returns the commitment atx of the post data.
+func (c *PostClient) CommitmentAtx() (types.ATXID, error) {
+	c.mu.Lock()
+	commitment := c.commitment
+	c.mu.Unlock()
+	if commitment != types.EmptyATXID {
+		return commitment, nil
+	}
+	st, err := c.status(context.Background())
+	if err != nil {
+		return types.EmptyATXID, err
+	}
+	if st.CommitmentATX == types.EmptyATXID {
+		return types.EmptyATXID, fmt.Errorf("post service %s is not initialized", c.client.Address)
+	}
+	c.update(st)
+	return st.CommitmentATX, nil
+}
+
+// VRFNonce returns the nonce found by the post service during initialization.
+func (c *PostClient) VRFNonce() (*types.VRFPostIndex, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.client.CallTimeout)
+	defer cancel()
+	var rst PostVRFNonceResponse
+	err := InvokeJSON(ctx, c.conn, PostVRFNonceMethod, &PostVRFNonceRequest{}, &rst)
+	switch {
+	case status.Code(err) == codes.FailedPrecondition:
+		return nil, fmt.Errorf("%w: %w", activation.ErrPostNotComplete, err)
+	case err != nil:
+		return nil, fmt.Errorf("post service %s vrf nonce: %w", c.client.Address, err)
+	}
+	return &rst.Nonce, nil
+}
+
+// LastOpts returns options of the post data, nil until post service is reached.
+func (c *PostClient) LastOpts() *activation.PostSetupOpts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.opts
+}
+
+// Config returns the post protocol config of the node.
+func (c *PostClient) Config() activation.PostConfig {
+	return c.cfg
+}
@@ -0,0 +1,162 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/spacemeshos/post/config"
+	"github.com/spacemeshos/post/proving"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+const (
+	postServiceName = "PostService"
+	// authorizationHeader carries the token of the post service client as "Bearer <token>".
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+)
+
+var (
+	// PostStatusMethod is a full name of the method that returns PostStatusResponse.
+	PostStatusMethod = jsonMethod(postServiceName, "Status")
+	// PostProofMethod is a full name of the method that returns PostProofResponse.
+	PostProofMethod = jsonMethod(postServiceName, "Proof")
+	// PostVRFNonceMethod is a full name of the method that returns PostVRFNonceResponse.
+	PostVRFNonceMethod = jsonMethod(postServiceName, "VRFNonce")
+)
+
+// PostStatusRequest is a request for the state of the post data.
+type PostStatusRequest struct{}
+
+// PostDataOpts describes post data owned by the post service.
+type PostDataOpts struct {
+	DataDir     string              `json:"data_dir"`
+	NumUnits    uint32              `json:"num_units"`
+	MaxFileSize uint64              `json:"max_file_size"`
+	Scrypt      config.ScryptParams `json:"scrypt"`
+}
+
+// PostStatusResponse is the state of the post data and the identity it was initialized for.
+type PostStatusResponse struct {
+	NodeID           types.NodeID              `json:"node_id"`
+	State            activation.PostSetupState `json:"state"`
+	NumLabelsWritten uint64                    `json:"num_labels_written"`
+	LabelsPerUnit    uint64                    `json:"labels_per_unit"`
+	CommitmentATX    types.ATXID               `json:"commitment_atx"`
+	Opts             *PostDataOpts             `json:"opts,omitempty"`
+}
+
+// PostProofRequest is a request to generate proof for the challenge.
+type PostProofRequest struct {
+	Challenge []byte `json:"challenge"`
+}
+
+// PostProofResponse is a proof and its metadata.
+type PostProofResponse struct {
+	Post     *types.Post         `json:"post"`
+	Metadata *types.PostMetadata `json:"metadata"`
+}
+
+// PostVRFNonceRequest is a request for the nonce found during initialization.
+type PostVRFNonceRequest struct{}
+
+// PostVRFNonceResponse is the nonce found during initialization.
+type PostVRFNonceResponse struct {
+	Nonce types.VRFPostIndex `json:"nonce"`
+}
+
+// PostService generates proofs for the post data on the machine where it is stored,
+// it is served by cmd/postservice and used by the node with PostClient.
+type PostService struct {
+	id     types.NodeID
+	cfg    activation.PostConfig
+	prover postProver
+}
+
+// NewPostService creates a new grpc service for the post data initialized for id.
+func NewPostService(id types.NodeID, cfg activation.PostConfig, prover postProver) *PostService {
+	return &PostService{id: id, cfg: cfg, prover: prover}
+}
+
+// RegisterService registers this service with a grpc server instance.
+func (s *PostService) RegisterService(server *Server) {
+	svc := newJSONService(postServiceName)
+	jsonUnary(svc, "Status", s.Status)
+	jsonUnary(svc, "Proof", s.Proof)
+	jsonUnary(svc, "VRFNonce", s.VRFNonce)
+	svc.register(server, s)
+}
+
+// Status returns the state of the post data.
+func (s *PostService) Status(context.Context, *PostStatusRequest) (*PostStatusResponse, error) {
+	st := s.prover.Status()
+	rst := &PostStatusResponse{
+		NodeID:           s.id,
+		State:            st.State,
+		NumLabelsWritten: st.NumLabelsWritten,
+		LabelsPerUnit:    s.cfg.LabelsPerUnit,
+	}
+	if commitment, err := s.prover.CommitmentAtx(); err == nil {
+		rst.CommitmentATX = commitment
+	}
+	if st.LastOpts != nil {
+		rst.Opts = &PostDataOpts{
+			DataDir:     st.LastOpts.DataDir,
+			NumUnits:    st.LastOpts.NumUnits,
+			MaxFileSize: st.LastOpts.MaxFileSize,
+			Scrypt:      st.LastOpts.Scrypt,
+		}
+	}
+	return rst, nil
+}
+
+// Proof generates proof for the challenge. It may take a long time, depending on the size of the data.
+func (s *PostService) Proof(ctx context.Context, req *PostProofRequest) (*PostProofResponse, error) {
+	if len(req.Challenge) != 32 {
+		return nil, status.Errorf(codes.InvalidArgument, "challenge must be 32 bytes, got %d", len(req.Challenge))
+	}
+	post, meta, err := s.prover.GenerateProof(ctx, req.Challenge, proving.WithPowCreator(s.id.Bytes()))
+	switch {
+	case errors.Is(err, activation.ErrPostNotComplete):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case ctx.Err() != nil:
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &PostProofResponse{Post: post, Metadata: meta}, nil
+}
+
+// VRFNonce returns the nonce found during initialization.
+func (s *PostService) VRFNonce(context.Context, *PostVRFNonceRequest) (*PostVRFNonceResponse, error) {
+	nonce, err := s.prover.VRFNonce()
+	switch {
+	case errors.Is(err, activation.ErrPostNotComplete):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &PostVRFNonceResponse{Nonce: *nonce}, nil
+}
+
+// PostTokenAuth rejects requests that don't include the token in the authorization header.
+func PostTokenAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get(authorizationHeader) {
+			received, ok := strings.CutPrefix(value, bearerPrefix)
+			if ok && subtle.ConstantTimeCompare([]byte(received), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+}
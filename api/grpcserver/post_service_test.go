@@ -0,0 +1,134 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+)
+
+func TestPostService(t *testing.T) {
+	const token = "secret"
+	ctrl := gomock.NewController(t)
+	prover := NewMockpostProver(ctrl)
+	id := types.RandomNodeID()
+	postCfg := activation.DefaultPostConfig()
+
+	srv := New("127.0.0.1:0", logtest.New(t).Named("grpc"), grpc.UnaryInterceptor(PostTokenAuth(token)))
+	NewPostService(id, postCfg, prover).RegisterService(srv)
+	require.NoError(t, srv.Start())
+	t.Cleanup(func() { require.NoError(t, srv.Close()) })
+
+	newClient := func(tb testing.TB, id types.NodeID, token string) *PostClient {
+		cfg := DefaultPostClientConfig()
+		cfg.Address = srv.BoundAddress
+		cfg.Token = token
+		cfg.PollInterval = 10 * time.Millisecond
+		client, err := NewPostClient(id, postCfg, cfg, logtest.New(tb))
+		require.NoError(tb, err)
+		tb.Cleanup(func() { require.NoError(tb, client.Close()) })
+		return client
+	}
+	client := newClient(t, id, token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := activation.DefaultPostSetupOpts()
+	opts.DataDir = "/data/post"
+	opts.NumUnits = 4
+	commitment := types.RandomATXID()
+	inProgress := &activation.PostSetupStatus{
+		State:            activation.PostSetupStateInProgress,
+		NumLabelsWritten: 10,
+		LastOpts:         &opts,
+	}
+	complete := &activation.PostSetupStatus{
+		State:            activation.PostSetupStateComplete,
+		NumLabelsWritten: 100,
+		LastOpts:         &opts,
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		err := newClient(t, id, "wrong").PrepareInitializer(ctx, activation.PostSetupOpts{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("prepare", func(t *testing.T) {
+		prover.EXPECT().Status().Return(inProgress)
+		prover.EXPECT().CommitmentAtx().Return(commitment, nil)
+		require.Nil(t, client.LastOpts())
+		require.NoError(t, client.PrepareInitializer(ctx, activation.PostSetupOpts{}))
+		require.Equal(t, opts, *client.LastOpts())
+		got, err := client.CommitmentAtx()
+		require.NoError(t, err)
+		require.Equal(t, commitment, got)
+		require.Equal(t, postCfg, client.Config())
+	})
+	t.Run("prepare for another node", func(t *testing.T) {
+		prover.EXPECT().Status().Return(inProgress)
+		prover.EXPECT().CommitmentAtx().Return(commitment, nil)
+		require.ErrorContains(t, newClient(t, types.RandomNodeID(), token).PrepareInitializer(ctx, opts), "has data for node")
+	})
+	t.Run("status", func(t *testing.T) {
+		prover.EXPECT().Status().Return(inProgress)
+		prover.EXPECT().CommitmentAtx().Return(commitment, nil)
+		st := client.Status()
+		require.Equal(t, inProgress.State, st.State)
+		require.Equal(t, inProgress.NumLabelsWritten, st.NumLabelsWritten)
+		require.Equal(t, opts, *st.LastOpts)
+	})
+	t.Run("start session", func(t *testing.T) {
+		first := prover.EXPECT().Status().Return(inProgress).Times(2)
+		prover.EXPECT().Status().Return(complete).After(first)
+		prover.EXPECT().CommitmentAtx().Return(commitment, nil).Times(3)
+		require.NoError(t, client.StartSession(ctx))
+
+		prover.EXPECT().Status().Return(&activation.PostSetupStatus{State: activation.PostSetupStateError})
+		prover.EXPECT().CommitmentAtx().Return(types.EmptyATXID, nil)
+		require.Error(t, client.StartSession(ctx))
+	})
+	t.Run("proof", func(t *testing.T) {
+		challenge := types.RandomHash().Bytes()
+		post := &types.Post{Nonce: 1, Indices: []byte{1, 2, 3}, Pow: 7}
+		meta := &types.PostMetadata{Challenge: challenge, LabelsPerUnit: postCfg.LabelsPerUnit}
+		prover.EXPECT().GenerateProof(gomock.Any(), challenge, gomock.Any()).Return(post, meta, nil)
+		gotPost, gotMeta, err := client.GenerateProof(ctx, challenge)
+		require.NoError(t, err)
+		require.Equal(t, post, gotPost)
+		require.Equal(t, meta, gotMeta)
+
+		prover.EXPECT().GenerateProof(gomock.Any(), challenge, gomock.Any()).Return(nil, nil, activation.ErrPostNotComplete)
+		_, _, err = client.GenerateProof(ctx, challenge)
+		require.ErrorIs(t, err, activation.ErrPostNotComplete)
+
+		_, _, err = client.GenerateProof(ctx, []byte{1, 2, 3})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("vrf nonce", func(t *testing.T) {
+		nonce := types.VRFPostIndex(42)
+		prover.EXPECT().VRFNonce().Return(&nonce, nil)
+		got, err := client.VRFNonce()
+		require.NoError(t, err)
+		require.Equal(t, nonce, *got)
+
+		prover.EXPECT().VRFNonce().Return(nil, activation.ErrPostNotComplete)
+		_, err = client.VRFNonce()
+		require.ErrorIs(t, err, activation.ErrPostNotComplete)
+	})
+	t.Run("local only", func(t *testing.T) {
+		_, err := client.Providers()
+		require.ErrorIs(t, err, errRemotePost)
+		_, err = client.Benchmark(activation.PostSetupProvider{})
+		require.ErrorIs(t, err, errRemotePost)
+		require.ErrorIs(t, client.Reset(), errRemotePost)
+	})
+}
@@ -0,0 +1,110 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/post/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+// ErrPostParamsMismatch is returned by PostVerifierClient.Health if the post verifier
+// verifies proofs with different post parameters.
+var ErrPostParamsMismatch = errors.New("post params mismatch")
+
+// PostVerifierClientConfig is the configuration of the connections to the remote post verifiers.
+type PostVerifierClientConfig struct {
+	// Addresses of the post verifiers. If empty, proofs are verified only locally.
+	Addresses []string `mapstructure:"smeshing-remote-verifiers-addresses"`
+	// Token is sent with every request, it must match the token of the post verifiers.
+	Token string `mapstructure:"smeshing-remote-verifiers-token"`
+	// TLSCACert is a path to the certificate of the authority that signed the certificates
+	// of the post verifiers. If empty, connections are not encrypted, which is allowed only
+	// for loopback addresses.
+	TLSCACert string `mapstructure:"smeshing-remote-verifiers-tls-ca-cert"`
+	// Concurrency is the number of proofs verified by every post verifier at the same time.
+	Concurrency int `mapstructure:"smeshing-remote-verifiers-concurrency"`
+	// CallTimeout limits duration of a single verification.
+	CallTimeout time.Duration `mapstructure:"smeshing-remote-verifiers-call-timeout"`
+	// HealthInterval is the interval for checking whether post verifier is reachable.
+	HealthInterval time.Duration `mapstructure:"smeshing-remote-verifiers-health-interval"`
+}
+
+// DefaultPostVerifierClientConfig returns the default configuration of the remote post verifiers clients.
+func DefaultPostVerifierClientConfig() PostVerifierClientConfig {
+	return PostVerifierClientConfig{
+		Concurrency:    4,
+		CallTimeout:    time.Minute,
+		HealthInterval: 10 * time.Second,
+	}
+}
+
+// PostVerifierClient sends proofs to the post verifier.
+// It implements activation.RemotePostVerifier.
+type PostVerifierClient struct {
+	address string
+	cfg     PostVerifierClientConfig
+	params  types.Hash32
+	conn    *grpc.ClientConn
+}
+
+// NewPostVerifierClient creates a client of the post verifier at address.
+// The post verifier is used only if it verifies proofs with the same post parameters as post.
+func NewPostVerifierClient(
+	address string,
+	cfg PostVerifierClientConfig,
+	post activation.PostConfig,
+) (*PostVerifierClient, error) {
+	conn, err := dialWithToken(address, cfg.Token, cfg.TLSCACert)
+	if err != nil {
+		return nil, fmt.Errorf("dial post verifier %s: %w", address, err)
+	}
+	return &PostVerifierClient{address: address, cfg: cfg, params: post.Hash(), conn: conn}, nil
+}
+
+// Address of the post verifier.
+func (c *PostVerifierClient) Address() string {
+	return c.address
+}
+
+// Close the connection to the post verifier.
+func (c *PostVerifierClient) Close() error {
+	return c.conn.Close()
+}
+
+// Verify the proof. Error wraps activation.ErrRemotePostVerifierUnavailable unless post verifier
+// found that the proof is invalid.
+func (c *PostVerifierClient) Verify(ctx context.Context, p *shared.Proof, m *shared.ProofMetadata) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.CallTimeout)
+	defer cancel()
+	req := &PostVerifierVerifyRequest{Proof: *p, Metadata: *m}
+	err := InvokeJSON(ctx, c.conn, PostVerifierVerifyMethod, req, &PostVerifierVerifyResponse{})
+	switch {
+	case err == nil:
+		return nil
+	case status.Code(err) == codes.InvalidArgument:
+		return errors.New(status.Convert(err).Message())
+	}
+	return fmt.Errorf("%w: %s: %w", activation.ErrRemotePostVerifierUnavailable, c.address, err)
+}
+
+// Health returns an error if the post verifier is not reachable or uses different post parameters.
+func (c *PostVerifierClient) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.HealthInterval)
+	defer cancel()
+	var resp PostVerifierHealthResponse
+	if err := InvokeJSON(ctx, c.conn, PostVerifierHealthMethod, &PostVerifierHealthRequest{}, &resp); err != nil {
+		return err
+	}
+	if resp.Params != c.params {
+		return fmt.Errorf("%w: post verifier params %s don't match %s", ErrPostParamsMismatch, resp.Params, c.params)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spacemeshos/post/shared"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+const postVerifierServiceName = "PostVerifierService"
+
+var (
+	// PostVerifierVerifyMethod is a full name of the method that returns PostVerifierVerifyResponse.
+	PostVerifierVerifyMethod = jsonMethod(postVerifierServiceName, "Verify")
+	// PostVerifierHealthMethod is a full name of the method that returns PostVerifierHealthResponse.
+	PostVerifierHealthMethod = jsonMethod(postVerifierServiceName, "Health")
+)
+
+// PostVerifierVerifyRequest is a request to verify the proof.
+type PostVerifierVerifyRequest struct {
+	Proof    shared.Proof         `json:"proof"`
+	Metadata shared.ProofMetadata `json:"metadata"`
+}
+
+// PostVerifierVerifyResponse is returned if the proof is valid.
+// Invalid proof is reported with codes.InvalidArgument, any other code means that
+// the proof wasn't verified.
+type PostVerifierVerifyResponse struct{}
+
+// PostVerifierHealthRequest is a request to check that the service can verify proofs.
+type PostVerifierHealthRequest struct{}
+
+// PostVerifierHealthResponse is returned if the service can verify proofs.
+type PostVerifierHealthResponse struct {
+	// Params is the hash of the post parameters used for verification, see activation.PostConfig.Hash.
+	Params types.Hash32 `json:"params"`
+}
+
+// PostVerifierService verifies proofs on behalf of the node, it is served by cmd/postverifier
+// and used by the node with PostVerifierClient to speed up verification of atxs during sync.
+type PostVerifierService struct {
+	verifier activation.PostVerifier
+	params   types.Hash32
+}
+
+// NewPostVerifierService creates a new grpc service that verifies proofs with verifier
+// configured with cfg.
+func NewPostVerifierService(verifier activation.PostVerifier, cfg activation.PostConfig) *PostVerifierService {
+	return &PostVerifierService{verifier: verifier, params: cfg.Hash()}
+}
+
+// RegisterService registers this service with a grpc server instance.
+func (s *PostVerifierService) RegisterService(server *Server) {
+	svc := newJSONService(postVerifierServiceName)
+	jsonUnary(svc, "Verify", s.Verify)
+	jsonUnary(svc, "Health", s.Health)
+	svc.register(server, s)
+}
+
+// Verify the proof with the pow creator from the metadata.
+func (s *PostVerifierService) Verify(
+	ctx context.Context,
+	req *PostVerifierVerifyRequest,
+) (*PostVerifierVerifyResponse, error) {
+	err := s.verifier.Verify(ctx, &req.Proof, &req.Metadata)
+	switch {
+	case err == nil:
+		return &PostVerifierVerifyResponse{}, nil
+	case ctx.Err() != nil:
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case errors.Is(err, activation.ErrPostVerifierClosed):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, activation.ErrInvalidPost):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil, status.Error(codes.Internal, err.Error())
+}
+
+// Health returns the hash of the post parameters while the service is serving requests.
+func (s *PostVerifierService) Health(context.Context, *PostVerifierHealthRequest) (*PostVerifierHealthResponse, error) {
+	return &PostVerifierHealthResponse{Params: s.params}, nil
+}
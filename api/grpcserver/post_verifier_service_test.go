@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spacemeshos/post/shared"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+)
+
+func TestPostVerifierService(t *testing.T) {
+	const token = "secret"
+	verifier := activation.NewMockPostVerifier(gomock.NewController(t))
+	srv := New("127.0.0.1:0", logtest.New(t).Named("grpc"), grpc.UnaryInterceptor(PostTokenAuth(token)))
+	post := activation.DefaultPostConfig()
+	NewPostVerifierService(verifier, post).RegisterService(srv)
+	require.NoError(t, srv.Start())
+	t.Cleanup(func() { require.NoError(t, srv.Close()) })
+
+	newClient := func(tb testing.TB, token string) *PostVerifierClient {
+		cfg := DefaultPostVerifierClientConfig()
+		cfg.Token = token
+		client, err := NewPostVerifierClient(srv.BoundAddress, cfg, post)
+		require.NoError(tb, err)
+		tb.Cleanup(func() { require.NoError(tb, client.Close()) })
+		return client
+	}
+	client := newClient(t, token)
+	require.Equal(t, srv.BoundAddress, client.Address())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := types.RandomNodeID()
+	proof := &shared.Proof{Nonce: 7, Indices: []byte{1, 2, 3}, Pow: 11}
+	metadata := &shared.ProofMetadata{
+		NodeId:          id.Bytes(),
+		CommitmentAtxId: types.RandomATXID().Bytes(),
+		Challenge:       types.RandomHash().Bytes(),
+		NumUnits:        4,
+		LabelsPerUnit:   1024,
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		client := newClient(t, "wrong")
+		require.Equal(t, codes.Unauthenticated, status.Code(client.Health(ctx)))
+		err := client.Verify(ctx, proof, metadata)
+		require.ErrorIs(t, err, activation.ErrRemotePostVerifierUnavailable)
+	})
+	t.Run("health", func(t *testing.T) {
+		require.NoError(t, client.Health(ctx))
+	})
+	t.Run("params mismatch", func(t *testing.T) {
+		other := post
+		other.K3++
+		cfg := DefaultPostVerifierClientConfig()
+		cfg.Token = token
+		client, err := NewPostVerifierClient(srv.BoundAddress, cfg, other)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, client.Close()) })
+		require.ErrorIs(t, client.Health(ctx), ErrPostParamsMismatch)
+	})
+	t.Run("valid", func(t *testing.T) {
+		verifier.EXPECT().Verify(gomock.Any(), proof, metadata).Return(nil)
+		require.NoError(t, client.Verify(ctx, proof, metadata))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		verifier.EXPECT().Verify(gomock.Any(), proof, metadata, gomock.Any()).
+			Return(fmt.Errorf("%w: invalid index", activation.ErrInvalidPost))
+		err := client.Verify(ctx, proof, metadata)
+		require.ErrorContains(t, err, "invalid index")
+		require.NotErrorIs(t, err, activation.ErrRemotePostVerifierUnavailable)
+	})
+	t.Run("internal", func(t *testing.T) {
+		verifier.EXPECT().Verify(gomock.Any(), proof, metadata, gomock.Any()).Return(errors.New("out of memory"))
+		err := client.Verify(ctx, proof, metadata)
+		require.ErrorIs(t, err, activation.ErrRemotePostVerifierUnavailable)
+		require.ErrorContains(t, err, "out of memory")
+	})
+	t.Run("closed", func(t *testing.T) {
+		verifier.EXPECT().Verify(gomock.Any(), proof, metadata, gomock.Any()).Return(activation.ErrPostVerifierClosed)
+		require.ErrorIs(t, client.Verify(ctx, proof, metadata), activation.ErrRemotePostVerifierUnavailable)
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		cfg := DefaultPostVerifierClientConfig()
+		cfg.CallTimeout = 100 * time.Millisecond
+		client, err := NewPostVerifierClient("127.0.0.1:1", cfg, post)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, client.Close()) })
+		require.ErrorIs(t, client.Verify(ctx, proof, metadata), activation.ErrRemotePostVerifierUnavailable)
+	})
+	t.Run("tls required", func(t *testing.T) {
+		_, err := NewPostVerifierClient("10.0.0.1:9095", DefaultPostVerifierClientConfig(), post)
+		require.ErrorContains(t, err, "tls is required")
+	})
+}
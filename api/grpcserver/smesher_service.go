@@ -2,6 +2,7 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -20,10 +21,155 @@ import (
 	"github.com/spacemeshos/go-spacemesh/common/types"
 )
 
+const smesherServiceName = "SmesherService"
+
+var (
+	// SmesherPoetHealthMethod is a full name of the method that returns SmesherPoetHealthResponse.
+	SmesherPoetHealthMethod = jsonMethod(smesherServiceName, "PoetHealth")
+	// SmesherPreflightMethod is a full name of the method that returns SmesherPreflightResponse.
+	SmesherPreflightMethod = jsonMethod(smesherServiceName, "Preflight")
+	// SmesherPostCheckMethod is a full name of the method that returns SmesherPostCheckStatus.
+	SmesherPostCheckMethod = jsonMethod(smesherServiceName, "PostCheck")
+	// SmesherPostCheckStatusStreamMethod is a full name of the method that streams SmesherPostCheckStatus.
+	SmesherPostCheckStatusStreamMethod = jsonMethod(smesherServiceName, "PostCheckStatusStream")
+	// SmesherPublishStatusMethod is a full name of the method that returns SmesherPublishStatusResponse.
+	SmesherPublishStatusMethod = jsonMethod(smesherServiceName, "PublishStatus")
+)
+
+// SmesherPoetHealthRequest selects submissions for publish epochs starting with FromEpoch.
+type SmesherPoetHealthRequest struct {
+	FromEpoch uint32 `json:"from_epoch"`
+}
+
+// SmesherPoetRound is a submission to the poet for the publish epoch and the proof received for it.
+type SmesherPoetRound struct {
+	Epoch         uint32 `json:"epoch"`
+	RoundID       string `json:"round_id,omitempty"`
+	Submitted     bool   `json:"submitted"`
+	SubmitLatency string `json:"submit_latency,omitempty"`
+	Proof         string `json:"proof"`
+	ProofLatency  string `json:"proof_latency,omitempty"`
+	LeafCount     uint64 `json:"leaf_count,omitempty"`
+	TickCount     uint64 `json:"tick_count,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// SmesherPoet summarizes history of the poet.
+type SmesherPoet struct {
+	Address           string             `json:"address"`
+	ServiceID         string             `json:"service_id,omitempty"`
+	Submissions       int                `json:"submissions"`
+	Submitted         int                `json:"submitted"`
+	ProofsReceived    int                `json:"proofs_received"`
+	ProofsMissed      int                `json:"proofs_missed"`
+	ConsecutiveMissed int                `json:"consecutive_missed"`
+	Deprioritized     bool               `json:"deprioritized"`
+	AvgSubmitLatency  string             `json:"avg_submit_latency"`
+	AvgProofLatency   string             `json:"avg_proof_latency"`
+	Rounds            []SmesherPoetRound `json:"rounds"`
+}
+
+// SmesherPoetHealthResponse lists history of every poet the node submitted challenges to.
+type SmesherPoetHealthResponse struct {
+	Poets []SmesherPoet `json:"poets"`
+}
+
+// SmesherPreflightRequest is a request for the dry run of atx publication.
+// Generating PoST proof takes as long as for the real atx, it is skipped unless WithPost is set.
+type SmesherPreflightRequest struct {
+	WithPost bool `json:"with_post"`
+}
+
+// SmesherPreflightChallenge is the challenge that would be submitted to poets for the next atx.
+type SmesherPreflightChallenge struct {
+	PublishEpoch   uint32 `json:"publish_epoch"`
+	Sequence       uint64 `json:"sequence"`
+	PrevATX        string `json:"prev_atx,omitempty"`
+	PositioningATX string `json:"positioning_atx"`
+	CommitmentATX  string `json:"commitment_atx,omitempty"`
+}
+
+// SmesherPreflightDeadlines for the next atx.
+type SmesherPreflightDeadlines struct {
+	PoetRoundStart    time.Time `json:"poet_round_start"`
+	PoetRoundEnd      time.Time `json:"poet_round_end"`
+	PoetProofDeadline time.Time `json:"poet_proof_deadline"`
+	PublishEpochStart time.Time `json:"publish_epoch_start"`
+	PublishEpochEnd   time.Time `json:"publish_epoch_end"`
+}
+
+// SmesherPreflightCheck is an outcome of a single check, error is empty if it passed.
+type SmesherPreflightCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Details  string `json:"details,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// SmesherPreflightResponse is the result of the dry run, Passed is set if all checks passed.
+type SmesherPreflightResponse struct {
+	Passed    bool                       `json:"passed"`
+	Challenge *SmesherPreflightChallenge `json:"challenge,omitempty"`
+	Deadlines SmesherPreflightDeadlines  `json:"deadlines"`
+	Checks    []SmesherPreflightCheck    `json:"checks"`
+}
+
+// SmesherPublishStatusRequest is a request for the phase of the atx publication.
+type SmesherPublishStatusRequest struct{}
+
+// SmesherPublishStatusResponse is the phase of the atx publication and its deadline. The atx is not
+// published in the publish epoch if the phase doesn't complete before the deadline.
+type SmesherPublishStatusResponse struct {
+	PublishEpoch   uint32                    `json:"publish_epoch"`
+	Phase          string                    `json:"phase"`
+	Deadline       time.Time                 `json:"deadline"`
+	TimeLeft       string                    `json:"time_left"`
+	Attempts       uint32                    `json:"attempts"`
+	LastError      string                    `json:"last_error,omitempty"`
+	ChallengeStart time.Time                 `json:"challenge_start"`
+	Deadlines      SmesherPreflightDeadlines `json:"deadlines"`
+}
+
+// SmesherPostCheckRequest starts the check of the post data. Fraction is the percent of labels
+// that are sampled, corrupted ranges are re-initialized if Repair is set.
+type SmesherPostCheckRequest struct {
+	Fraction float64 `json:"fraction"`
+	Repair   bool    `json:"repair"`
+}
+
+// SmesherPostDataRange is a range of labels that don't match the commitment, labels are indexed
+// from the start of the post data.
+type SmesherPostDataRange struct {
+	File      int    `json:"file"`
+	FromLabel uint64 `json:"from_label"`
+	ToLabel   uint64 `json:"to_label"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Repaired  bool   `json:"repaired"`
+}
+
+// SmesherPostCheckStatus is the progress of the running check or the result of the last check.
+type SmesherPostCheckStatus struct {
+	State          string                 `json:"state"`
+	DataDir        string                 `json:"data_dir,omitempty"`
+	Fraction       float64                `json:"fraction,omitempty"`
+	Repair         bool                   `json:"repair,omitempty"`
+	SampledLabels  uint64                 `json:"sampled_labels"`
+	CheckedLabels  uint64                 `json:"checked_labels"`
+	Corrupted      []SmesherPostDataRange `json:"corrupted"`
+	RepairedLabels uint64                 `json:"repaired_labels"`
+	Error          string                 `json:"error,omitempty"`
+}
+
+// SmesherPostCheckStatusStreamRequest is a request to stream progress of the post data check.
+type SmesherPostCheckStatusStreamRequest struct{}
+
 // SmesherService exposes endpoints to manage smeshing.
 type SmesherService struct {
 	postSetupProvider postSetupProvider
 	smeshingProvider  activation.SmeshingProvider
+	poetHealth        poetHealthReporter
+	postChecker       postDataChecker
 
 	streamInterval time.Duration
 	postOpts       activation.PostSetupOpts
@@ -34,11 +180,32 @@ func (s SmesherService) RegisterService(server *Server) {
 	pb.RegisterSmesherServiceServer(server.GrpcServer, s)
 }
 
+// RegisterExperimentalService registers methods with json encoded messages with a grpc server instance.
+func (s SmesherService) RegisterExperimentalService(server *Server) {
+	svc := newJSONService(smesherServiceName)
+	jsonUnary(svc, "PoetHealth", s.PoetHealth)
+	jsonUnary(svc, "Preflight", s.Preflight)
+	jsonUnary(svc, "PublishStatus", s.PublishStatus)
+	jsonUnary(svc, "PostCheck", s.PostCheck)
+	jsonServerStream(svc, "PostCheckStatusStream", s.PostCheckStatusStream)
+	svc.register(server, s)
+}
+
 // NewSmesherService creates a new grpc service using config data.
-func NewSmesherService(post postSetupProvider, smeshing activation.SmeshingProvider, streamInterval time.Duration, postOpts activation.PostSetupOpts) *SmesherService {
+// PoetHealth is not available if poetHealth is nil, and the post data check if postChecker is nil.
+func NewSmesherService(
+	post postSetupProvider,
+	smeshing activation.SmeshingProvider,
+	poetHealth poetHealthReporter,
+	postChecker postDataChecker,
+	streamInterval time.Duration,
+	postOpts activation.PostSetupOpts,
+) *SmesherService {
 	return &SmesherService{
 		postSetupProvider: post,
 		smeshingProvider:  smeshing,
+		poetHealth:        poetHealth,
+		postChecker:       postChecker,
 		streamInterval:    streamInterval,
 		postOpts:          postOpts,
 	}
@@ -268,3 +435,208 @@ func (s SmesherService) UpdatePoetServers(ctx context.Context, req *pb.UpdatePoe
 	}
 	return nil, status.Errorf(codes.Internal, "failed to update poet server")
 }
+
+// PoetHealth returns history of submissions to poets and of the proofs received from them.
+func (s SmesherService) PoetHealth(_ context.Context, req *SmesherPoetHealthRequest) (*SmesherPoetHealthResponse, error) {
+	if s.poetHealth == nil {
+		return nil, status.Error(codes.Unimplemented, "poet health is not tracked")
+	}
+	reports, err := s.poetHealth.Report(types.EpochID(req.FromEpoch))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rst := &SmesherPoetHealthResponse{Poets: make([]SmesherPoet, 0, len(reports))}
+	for _, r := range reports {
+		poet := SmesherPoet{
+			Address:           r.Address,
+			ServiceID:         hex.EncodeToString(r.ServiceID),
+			Submissions:       r.Submissions,
+			Submitted:         r.Submitted,
+			ProofsReceived:    r.ProofsReceived,
+			ProofsMissed:      r.ProofsMissed,
+			ConsecutiveMissed: r.ConsecutiveMissed,
+			Deprioritized:     r.Deprioritized,
+			AvgSubmitLatency:  r.AvgSubmitLatency.String(),
+			AvgProofLatency:   r.AvgProofLatency.String(),
+			Rounds:            make([]SmesherPoetRound, 0, len(r.Rounds)),
+		}
+		for _, round := range r.Rounds {
+			rr := SmesherPoetRound{
+				Epoch:     round.Epoch.Uint32(),
+				RoundID:   round.RoundID,
+				Submitted: round.Submitted,
+				Proof:     round.Proof.String(),
+				LeafCount: round.LeafCount,
+				TickCount: round.TickCount,
+				Error:     round.Error,
+			}
+			if round.Submitted {
+				rr.SubmitLatency = round.SubmitLatency.String()
+			}
+			if round.ProofLatency > 0 {
+				rr.ProofLatency = round.ProofLatency.String()
+			}
+			poet.Rounds = append(poet.Rounds, rr)
+		}
+		rst.Poets = append(rst.Poets, poet)
+	}
+	return rst, nil
+}
+
+// Preflight checks that the node is ready to publish the next atx, nothing is submitted or broadcasted.
+func (s SmesherService) Preflight(ctx context.Context, req *SmesherPreflightRequest) (*SmesherPreflightResponse, error) {
+	report, err := s.smeshingProvider.Preflight(ctx, req.WithPost)
+	if err != nil {
+		return nil, status.Error(codes.Canceled, err.Error())
+	}
+	rst := &SmesherPreflightResponse{
+		Passed: len(report.Failed()) == 0,
+		Deadlines: SmesherPreflightDeadlines{
+			PoetRoundStart:    report.Deadlines.PoetRoundStart,
+			PoetRoundEnd:      report.Deadlines.PoetRoundEnd,
+			PoetProofDeadline: report.Deadlines.PoetProofDeadline,
+			PublishEpochStart: report.Deadlines.PublishEpochStart,
+			PublishEpochEnd:   report.Deadlines.PublishEpochEnd,
+		},
+		Checks: make([]SmesherPreflightCheck, 0, len(report.Checks)),
+	}
+	if ch := report.Challenge; ch != nil {
+		rst.Challenge = &SmesherPreflightChallenge{
+			PublishEpoch:   ch.PublishEpoch.Uint32(),
+			Sequence:       ch.Sequence,
+			PositioningATX: ch.PositioningATX.Hash32().Hex(),
+		}
+		if ch.PrevATXID != types.EmptyATXID {
+			rst.Challenge.PrevATX = ch.PrevATXID.Hash32().Hex()
+		}
+		if ch.CommitmentATX != nil {
+			rst.Challenge.CommitmentATX = ch.CommitmentATX.Hash32().Hex()
+		}
+	}
+	for _, c := range report.Checks {
+		check := SmesherPreflightCheck{
+			Name:     c.Name,
+			Passed:   c.Err == nil,
+			Details:  c.Details,
+			Duration: c.Duration.String(),
+		}
+		if c.Err != nil {
+			check.Error = c.Err.Error()
+		}
+		rst.Checks = append(rst.Checks, check)
+	}
+	return rst, nil
+}
+
+// PublishStatus returns the phase of the atx publication and its deadline.
+func (s SmesherService) PublishStatus(
+	context.Context,
+	*SmesherPublishStatusRequest,
+) (*SmesherPublishStatusResponse, error) {
+	publish := s.smeshingProvider.PublishStatus()
+	deadlines := publish.Schedule.PreflightDeadlines
+	rst := &SmesherPublishStatusResponse{
+		PublishEpoch:   publish.Schedule.PublishEpoch.Uint32(),
+		Phase:          publish.Phase.String(),
+		Deadline:       publish.Deadline,
+		Attempts:       publish.Attempts,
+		LastError:      publish.LastError,
+		ChallengeStart: publish.Schedule.ChallengeStart,
+		Deadlines: SmesherPreflightDeadlines{
+			PoetRoundStart:    deadlines.PoetRoundStart,
+			PoetRoundEnd:      deadlines.PoetRoundEnd,
+			PoetProofDeadline: deadlines.PoetProofDeadline,
+			PublishEpochStart: deadlines.PublishEpochStart,
+			PublishEpochEnd:   deadlines.PublishEpochEnd,
+		},
+	}
+	if !publish.Deadline.IsZero() {
+		rst.TimeLeft = time.Until(publish.Deadline).Round(time.Second).String()
+	}
+	return rst, nil
+}
+
+// PostCheck starts the check of the post data in background, the progress is available with
+// PostCheckStatusStream. Data is checked in the directory of the last initialization, or in the
+// configured one if the node didn't initialize data since it started.
+// The check fails with codes.FailedPrecondition while the data is initialized or proven,
+// and if the data is on the remote post service.
+func (s SmesherService) PostCheck(_ context.Context, req *SmesherPostCheckRequest) (*SmesherPostCheckStatus, error) {
+	if s.postChecker == nil {
+		return nil, status.Error(codes.Unimplemented, "post data check is not supported")
+	}
+	if _, remote := s.postSetupProvider.(*PostClient); remote {
+		return nil, status.Error(codes.FailedPrecondition, "post data is on the remote post service")
+	}
+	setup := s.postOpts
+	postStatus := s.postSetupProvider.Status()
+	if postStatus.State == activation.PostSetupStateInProgress {
+		return nil, status.Error(codes.FailedPrecondition, "post setup is in progress")
+	}
+	if postStatus.LastOpts != nil {
+		setup = *postStatus.LastOpts
+	}
+	err := s.postChecker.Start(setup, activation.PostCheckOpts{Fraction: req.Fraction, Repair: req.Repair})
+	switch {
+	case errors.Is(err, activation.ErrPostCheckInProgress):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, activation.ErrPostDataInUse):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return postCheckStatus(s.postChecker.Status()), nil
+}
+
+// PostCheckStatusStream streams progress of the post data check until it finishes.
+func (s SmesherService) PostCheckStatusStream(
+	_ *SmesherPostCheckStatusStreamRequest,
+	stream *jsonStream[SmesherPostCheckStatus],
+) error {
+	if s.postChecker == nil {
+		return status.Error(codes.Unimplemented, "post data check is not supported")
+	}
+	timer := time.NewTicker(s.streamInterval)
+	defer timer.Stop()
+
+	for {
+		check := s.postChecker.Status()
+		if err := stream.Send(postCheckStatus(check)); err != nil {
+			return fmt.Errorf("send to stream: %w", err)
+		}
+		if check.State != activation.PostCheckStateInProgress {
+			return nil
+		}
+		select {
+		case <-timer.C:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func postCheckStatus(check *activation.PostCheckStatus) *SmesherPostCheckStatus {
+	rst := &SmesherPostCheckStatus{
+		State:          check.State.String(),
+		DataDir:        check.DataDir,
+		Fraction:       check.Opts.Fraction,
+		Repair:         check.Opts.Repair,
+		SampledLabels:  check.SampledLabels,
+		CheckedLabels:  check.CheckedLabels,
+		Corrupted:      make([]SmesherPostDataRange, 0, len(check.Corrupted)),
+		RepairedLabels: check.RepairedLabels,
+	}
+	for _, r := range check.Corrupted {
+		rst.Corrupted = append(rst.Corrupted, SmesherPostDataRange{
+			File:      r.File,
+			FromLabel: r.From,
+			ToLabel:   r.To,
+			Truncated: r.Truncated,
+			Repaired:  r.Repaired,
+		})
+	}
+	if check.Err != nil {
+		rst.Error = check.Err.Error()
+	}
+	return rst
+}
@@ -10,11 +10,14 @@ import (
 	"github.com/spacemeshos/post/config"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/spacemeshos/go-spacemesh/activation"
 	"github.com/spacemeshos/go-spacemesh/api/grpcserver"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
 )
 
 func TestPostConfig(t *testing.T) {
@@ -22,7 +25,7 @@ func TestPostConfig(t *testing.T) {
 	postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
 
-	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 	postConfig := activation.PostConfig{
 		MinNumUnits:   rand.Uint32(),
@@ -47,7 +50,7 @@ func TestStartSmeshingPassesCorrectSmeshingOpts(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
-	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 	types.SetNetworkHRP("stest")
 	addr, err := types.StringToAddress("stest1qqqqqqrs60l66w5uksxzmaznwq6xnhqfv56c28qlkm4a5")
@@ -81,7 +84,7 @@ func TestSmesherService_PostSetupProviders(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 	smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
-	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+	svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 	providers := []activation.PostSetupProvider{
 		{
@@ -122,7 +125,7 @@ func TestSmesherService_PostSetupStatus(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 		smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
-		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 		postSetupProvider.EXPECT().Status().Return(&activation.PostSetupStatus{
 			State:            activation.PostSetupStateComplete,
@@ -140,7 +143,7 @@ func TestSmesherService_PostSetupStatus(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 		smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
-		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 		id := activation.PostProviderID{}
 		id.SetInt64(1)
@@ -171,7 +174,7 @@ func TestSmesherService_PostSetupStatus(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		postSetupProvider := activation.NewMockpostSetupProvider(ctrl)
 		smeshingProvider := activation.NewMockSmeshingProvider(ctrl)
-		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, time.Second, activation.DefaultPostSetupOpts())
+		svc := grpcserver.NewSmesherService(postSetupProvider, smeshingProvider, nil, nil, time.Second, activation.DefaultPostSetupOpts())
 
 		id := activation.PostProviderID{}
 		id.SetInt64(100)
@@ -198,3 +201,26 @@ func TestSmesherService_PostSetupStatus(t *testing.T) {
 		require.False(t, resp.Status.Opts.Throttle)
 	})
 }
+
+func TestSmesherService_PostCheckRemote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := grpcserver.DefaultPostClientConfig()
+	cfg.Address = "127.0.0.1:0"
+	client, err := grpcserver.NewPostClient(types.RandomNodeID(), activation.DefaultPostConfig(), cfg, logtest.New(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+
+	// the check is not started, since the data is not on this machine
+	checker := activation.NewPostChecker(logtest.New(t))
+	svc := grpcserver.NewSmesherService(
+		client,
+		activation.NewMockSmeshingProvider(ctrl),
+		nil,
+		checker,
+		time.Second,
+		activation.DefaultPostSetupOpts(),
+	)
+	_, err = svc.PostCheck(context.Background(), &grpcserver.SmesherPostCheckRequest{Fraction: 10})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Equal(t, activation.PostCheckStateNotStarted, checker.Status().State)
+}
@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/ALTree/bigfloat"
+	"github.com/benbjohnson/clock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spacemeshos/fixed"
 	"golang.org/x/sync/errgroup"
@@ -91,6 +93,13 @@ func WithConfig(cfg Config) Opt {
 	}
 }
 
+// WithWallclock changes the clock used for timers and timestamps of received messages.
+func WithWallclock(clock clock.Clock) Opt {
+	return func(pd *ProtocolDriver) {
+		pd.wallclock = clock
+	}
+}
+
 func withWeakCoin(wc coin) Opt {
 	return func(pd *ProtocolDriver) {
 		pd.weakCoin = wc
@@ -112,7 +121,7 @@ func New(
 	vrfSigner vrfSigner,
 	vrfVerifier vrfVerifier,
 	cdb *datastore.CachedDB,
-	clock layerClock,
+	nodeclock layerClock,
 	opts ...Opt,
 ) *ProtocolDriver {
 	pd := &ProtocolDriver{
@@ -126,7 +135,8 @@ func New(
 		vrfSigner:      vrfSigner,
 		vrfVerifier:    vrfVerifier,
 		cdb:            cdb,
-		clock:          clock,
+		clock:          nodeclock,
+		wallclock:      clock.New(),
 		beacons:        make(map[types.EpochID]types.Beacon),
 		ballotsBeacons: make(map[types.EpochID]map[types.Beacon]*beaconWeight),
 		states:         make(map[types.EpochID]*state),
@@ -136,7 +146,7 @@ func New(
 		opt(pd)
 	}
 	pd.msgTimes = &messageTimes{
-		clock: clock,
+		clock: nodeclock,
 		conf:  pd.config,
 	}
 
@@ -179,9 +189,10 @@ type ProtocolDriver struct {
 	weakCoin     coin
 	theta        *big.Float
 
-	clock    layerClock
-	msgTimes *messageTimes
-	cdb      *datastore.CachedDB
+	clock     layerClock
+	wallclock clock.Clock
+	msgTimes  *messageTimes
+	cdb       *datastore.CachedDB
 
 	mu sync.RWMutex
 
@@ -691,7 +702,7 @@ func (pd *ProtocolDriver) listenEpochs(ctx context.Context) {
 }
 
 func (pd *ProtocolDriver) setRoundInProgress(round types.RoundID) {
-	now := time.Now()
+	now := pd.wallclock.Now()
 	var nextRoundStartTime time.Time
 	if round == types.FirstRound {
 		nextRoundStartTime = now.Add(pd.config.FirstVotingRoundDuration)
@@ -748,16 +759,27 @@ func (pd *ProtocolDriver) runProtocol(ctx context.Context, epoch types.EpochID,
 	pd.weakCoin.StartEpoch(ctx, epoch)
 	defer pd.weakCoin.FinishEpoch(ctx, epoch)
 
-	if err := pd.runProposalPhase(ctx, epoch, st); err != nil {
-		logger.With().Warning("proposal phase failed", log.Err(err))
+	pd.updateRecord(epoch, func(record *EpochRecord) {
+		record.Epoch = epoch
+		record.Started = unixNano(pd.wallclock.Now())
+	})
+	var failure error
+	defer func() {
+		pd.persistRecord(logger, st, failure)
+	}()
+
+	if failure = pd.runProposalPhase(ctx, epoch, st); failure != nil {
+		logger.With().Warning("proposal phase failed", log.Err(failure))
 		return
 	}
 	lastRoundOwnVotes, err := pd.runConsensusPhase(ctx, epoch, st.nonce)
 	if err != nil {
+		failure = err
 		logger.With().Warning("consensus phase failed", log.Err(err))
 		return
 	}
 	if len(lastRoundOwnVotes.support) == 0 {
+		failure = errNoProposals
 		logger.With().Warning("consensus phase failed", log.Err(errNoProposals))
 		return
 	}
@@ -765,8 +787,12 @@ func (pd *ProtocolDriver) runProtocol(ctx context.Context, epoch types.EpochID,
 	// K rounds passed
 	// After K rounds had passed, tally up votes for proposals using simple tortoise vote counting
 	beacon := calcBeacon(logger, lastRoundOwnVotes.support)
+	pd.updateRecord(epoch, func(record *EpochRecord) {
+		record.Beacon = beacon
+	})
 
 	if err = pd.setBeacon(targetEpoch, beacon); err != nil {
+		failure = err
 		logger.With().Error("failed to set beacon", log.Err(err))
 		return
 	}
@@ -796,7 +822,7 @@ func (pd *ProtocolDriver) runProposalPhase(ctx context.Context, epoch types.Epoc
 	logger.Info("starting beacon proposal phase")
 
 	var cancel func()
-	ctx, cancel = context.WithTimeout(ctx, pd.config.ProposalDuration)
+	ctx, cancel = pd.wallclock.WithTimeout(ctx, pd.config.ProposalDuration)
 	defer cancel()
 
 	if st.nonce != nil {
@@ -812,7 +838,7 @@ func (pd *ProtocolDriver) runProposalPhase(ctx context.Context, epoch types.Epoc
 		return pd.ctx.Err()
 	}
 
-	if err := pd.markProposalPhaseFinished(epoch, time.Now()); err != nil {
+	if err := pd.markProposalPhaseFinished(epoch, pd.wallclock.Now()); err != nil {
 		return err
 	}
 
@@ -839,12 +865,16 @@ func (pd *ProtocolDriver) sendProposal(ctx context.Context, epoch types.EpochID,
 		VRFSignature: vrfSig,
 	}
 
-	if invalid == pd.classifyProposal(logger, m, atx.Received, time.Now(), checker) {
+	if invalid == pd.classifyProposal(logger, m, atx.Received, pd.wallclock.Now(), checker) {
 		logger.With().Debug("own proposal doesn't pass threshold",
 			log.String("proposal", hex.EncodeToString(proposal[:])),
 		)
 		return
 	}
+	pd.updateRecord(epoch, func(record *EpochRecord) {
+		record.OwnProposal = proposal
+		record.ProposalSent = true
+	})
 
 	logger.With().Debug("own proposal passes threshold",
 		log.String("proposal", hex.EncodeToString(proposal[:])),
@@ -868,7 +898,7 @@ func (pd *ProtocolDriver) runConsensusPhase(ctx context.Context, epoch types.Epo
 	// For next rounds,
 	// wait for δ time, and construct a message that points to all messages from previous round received by δ.
 	// rounds 1 to K
-	timer := time.NewTimer(pd.config.FirstVotingRoundDuration)
+	timer := pd.wallclock.Timer(pd.config.FirstVotingRoundDuration)
 	defer timer.Stop()
 
 	var (
@@ -879,6 +909,7 @@ func (pd *ProtocolDriver) runConsensusPhase(ctx context.Context, epoch types.Epo
 	for round := types.FirstRound; round < pd.config.RoundsNumber; round++ {
 		round := round
 		pd.setRoundInProgress(round)
+		started := pd.wallclock.Now()
 		rLogger := logger.WithFields(round)
 		votes := ownVotes
 		if nonce != nil {
@@ -905,10 +936,11 @@ func (pd *ProtocolDriver) runConsensusPhase(ctx context.Context, epoch types.Epo
 		// note that votes after this calcVotes() call will _not_ be counted towards our votes
 		// for this round, as the late votes can be cast after the weak coin is revealed. we
 		// count them towards our votes in the next round.
-		ownVotes, undecided, err = pd.calcVotesBeforeWeakCoin(rLogger, epoch)
+		ownVotes, undecided, err = pd.calcVotesBeforeWeakCoin(rLogger, epoch, round)
 		if err != nil {
 			return allVotes{}, err
 		}
+		metrics.UndecidedProposals.WithLabelValues(strconv.Itoa(int(round))).Set(float64(len(undecided)))
 		if round != types.FirstRound {
 			timer.Reset(pd.config.WeakCoinRoundDuration)
 
@@ -928,7 +960,18 @@ func (pd *ProtocolDriver) runConsensusPhase(ctx context.Context, epoch types.Epo
 				return allVotes{}, err
 			}
 			tallyUndecided(&ownVotes, undecided, flip)
+			metrics.WeakCoin.WithLabelValues(strconv.FormatBool(flip)).Inc()
+			pd.updateRecord(epoch, func(record *EpochRecord) {
+				last := &record.Rounds[len(record.Rounds)-1]
+				last.HasCoin = true
+				last.Coin = flip
+			})
 		}
+		pd.updateRecord(epoch, func(record *EpochRecord) {
+			last := &record.Rounds[len(record.Rounds)-1]
+			last.Started = unixNano(started)
+			last.Finished = unixNano(pd.wallclock.Now())
+		})
 		timer.Reset(pd.config.VotingRoundDuration)
 	}
 
@@ -944,16 +987,25 @@ func (pd *ProtocolDriver) markProposalPhaseFinished(epoch types.EpochID, finishe
 		return errEpochNotActive
 	}
 	pd.states[epoch].proposalPhaseFinishedTime = finishedAt
+	pd.states[epoch].record.ProposalFinished = unixNano(finishedAt)
 	return nil
 }
 
-func (pd *ProtocolDriver) calcVotesBeforeWeakCoin(logger log.Log, epoch types.EpochID) (allVotes, proposalList, error) {
-	pd.mu.RLock()
-	defer pd.mu.RUnlock()
-	if _, ok := pd.states[epoch]; !ok {
+func (pd *ProtocolDriver) calcVotesBeforeWeakCoin(logger log.Log, epoch types.EpochID, round types.RoundID) (allVotes, proposalList, error) {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	s, ok := pd.states[epoch]
+	if !ok {
 		return allVotes{}, nil, errEpochNotActive
 	}
-	decided, undecided := calcVotes(logger, pd.theta, pd.states[epoch])
+	decided, undecided := calcVotes(logger, pd.theta, s)
+	// margins are recorded in the same critical section, otherwise late votes
+	// may change them and the beacon can't be recomputed from the record.
+	s.record.Rounds = append(s.record.Rounds, RoundRecord{
+		Round:     round,
+		Margins:   snapshotMargins(s.votesMargin),
+		Undecided: uint32(len(undecided)),
+	})
 	return decided, undecided, nil
 }
 
@@ -218,6 +218,17 @@ func TestBeacon_MultipleNodes(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEqual(t, types.EmptyBeacon, got)
 		beacons[got] = struct{}{}
+
+		record, err := GetRecord(node.cdb, types.EpochID(2))
+		require.NoError(t, err)
+		require.Equal(t, got, record.Beacon)
+		require.Empty(t, record.Failure)
+		require.Len(t, record.Rounds, int(cfg.RoundsNumber))
+		require.NotEmpty(t, record.ValidProposals)
+		require.Equal(t, node.nodeID != testNodes[0].nodeID, record.ProposalSent)
+		recomputed, err := RecomputeBeacon(record)
+		require.NoError(t, err)
+		require.Equal(t, got, recomputed)
 	}
 	require.Len(t, beacons, 1)
 }
@@ -335,6 +346,13 @@ func TestBeacon_NoProposals(t *testing.T) {
 		got, err := node.GetBeacon(types.EpochID(3))
 		require.Error(t, err)
 		require.Equal(t, types.EmptyBeacon, got)
+
+		record, err := GetRecord(node.cdb, types.EpochID(2))
+		require.NoError(t, err)
+		require.Equal(t, errNoProposals.Error(), record.Failure)
+		require.Empty(t, record.ValidProposals)
+		_, err = RecomputeBeacon(record)
+		require.ErrorIs(t, err, errNoProposals)
 	}
 }
 
@@ -53,7 +53,7 @@ func (pd *ProtocolDriver) HandleProposal(ctx context.Context, peer p2p.Peer, msg
 		return errors.New("beacon protocol closed")
 	}
 
-	receivedTime := time.Now()
+	receivedTime := pd.wallclock.Now()
 	logger := pd.logger.WithContext(ctx)
 
 	var m ProposalMessage
@@ -223,7 +223,7 @@ func (pd *ProtocolDriver) HandleFirstVotes(ctx context.Context, peer p2p.Peer, m
 	logger := pd.logger.WithContext(ctx).WithFields(types.FirstRound, log.Stringer("sender", peer))
 	logger.Debug("new first votes")
 
-	receivedTime := time.Now()
+	receivedTime := pd.wallclock.Now()
 
 	var m FirstVotingMessage
 	if err := codec.Decode(msg, &m); err != nil {
@@ -318,7 +318,7 @@ func (pd *ProtocolDriver) storeFirstVotes(m FirstVotingMessage, nodeID types.Nod
 
 // HandleFollowingVotes handles beacon following votes from gossip.
 func (pd *ProtocolDriver) HandleFollowingVotes(ctx context.Context, peer p2p.Peer, msg []byte) error {
-	receivedTime := time.Now()
+	receivedTime := pd.wallclock.Now()
 
 	if pd.isClosed() || !pd.isInProtocol() {
 		pd.logger.WithContext(ctx).Debug("beacon protocol shutting down or not running, dropping msg")
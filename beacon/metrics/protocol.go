@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/spacemeshos/go-spacemesh/metrics"
+)
+
+var (
+	proposals = metrics.NewGauge(
+		"proposals",
+		subsystem,
+		"Number of proposals received in the last protocol execution",
+		[]string{"validity"},
+	)
+	ValidProposals            = proposals.WithLabelValues("valid")
+	PotentiallyValidProposals = proposals.WithLabelValues("potentially_valid")
+
+	UndecidedProposals = metrics.NewGauge(
+		"undecided_proposals",
+		subsystem,
+		"Number of proposals that were decided with the weak coin in the round of the last protocol execution",
+		[]string{"round"},
+	)
+
+	WeakCoin = metrics.NewCounter(
+		"weak_coin",
+		subsystem,
+		"Number of weak coin values used by the protocol",
+		[]string{"value"},
+	)
+
+	ProtocolFailures = metrics.NewCounter(
+		"protocol_failures",
+		subsystem,
+		"Number of protocol executions that didn't compute the beacon",
+		[]string{},
+	).WithLabelValues()
+)
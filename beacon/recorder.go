@@ -0,0 +1,224 @@
+package beacon
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/beacon/metrics"
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/beacons"
+)
+
+// Limits match scale limits of the EpochRecord.
+const (
+	maxRecordTheta     = 64
+	maxRecordProposals = 1000
+	maxRecordRounds    = 1000
+	maxRecordFailure   = 1024
+	maxRecordMargins   = 2000
+	maxRecordMargin    = 128
+)
+
+//go:generate scalegen
+
+// EpochRecord is a record of the beacon protocol execution in a single epoch.
+// It is persisted when the protocol finishes, successfully or not, and contains enough
+// data to recompute the beacon without the network.
+type EpochRecord struct {
+	Epoch       types.EpochID
+	EpochWeight uint64
+	// Theta is a rational string for the voting threshold fraction, such as 1/4.
+	Theta string `scale:"max=64"` // match maxRecordTheta
+
+	// OwnProposal is set if node was eligible to make a proposal.
+	OwnProposal  Proposal
+	ProposalSent bool
+
+	ValidProposals            []Proposal `scale:"max=1000"` // match maxRecordProposals
+	PotentiallyValidProposals []Proposal `scale:"max=1000"` // match maxRecordProposals
+
+	// Rounds are in the order of execution. The first round doesn't use weak coin.
+	Rounds []RoundRecord `scale:"max=1000"` // match maxRecordRounds
+
+	Beacon types.Beacon
+	// Failure is set if protocol didn't compute the beacon.
+	Failure string `scale:"max=1024"` // match maxRecordFailure
+
+	// Timestamps are in unix nanoseconds.
+	Started          uint64
+	ProposalFinished uint64
+	Finished         uint64
+}
+
+// RoundRecord is a snapshot of the vote margins that were used to compute own votes in the round.
+type RoundRecord struct {
+	Round types.RoundID
+	// Margins are collected before the weak coin is revealed.
+	Margins []VoteMargin `scale:"max=2000"` // match maxRecordMargins
+	// Undecided is a number of proposals that were decided with the weak coin.
+	Undecided uint32
+	HasCoin   bool
+	Coin      bool
+
+	Started  uint64
+	Finished uint64
+}
+
+// VoteMargin is a weight of votes for the proposal minus the weight of votes against it.
+type VoteMargin struct {
+	Proposal Proposal
+	// Margin is a signed decimal integer.
+	Margin string `scale:"max=128"` // match maxRecordMargin
+}
+
+// truncate caps slices and strings to the scale limits, so that the record can be encoded
+// regardless of the number of proposals received from peers.
+// Rounds are copied, as they share memory with the record of the active epoch.
+func (r *EpochRecord) truncate() {
+	r.Theta = truncateString(r.Theta, maxRecordTheta)
+	r.ValidProposals = truncateSlice(r.ValidProposals, maxRecordProposals)
+	r.PotentiallyValidProposals = truncateSlice(r.PotentiallyValidProposals, maxRecordProposals)
+	r.Failure = truncateString(r.Failure, maxRecordFailure)
+	r.Rounds = slices.Clone(truncateSlice(r.Rounds, maxRecordRounds))
+	for i := range r.Rounds {
+		margins := truncateSlice(r.Rounds[i].Margins, maxRecordMargins)
+		r.Rounds[i].Margins = make([]VoteMargin, len(margins))
+		for j, margin := range margins {
+			margin.Margin = truncateString(margin.Margin, maxRecordMargin)
+			r.Rounds[i].Margins[j] = margin
+		}
+	}
+}
+
+func truncateSlice[T any](s []T, limit int) []T {
+	if len(s) > limit {
+		return s[:limit]
+	}
+	return s
+}
+
+func truncateString(s string, limit int) string {
+	if len(s) > limit {
+		return s[:limit]
+	}
+	return s
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p *Proposal) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(p[:])), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Proposal) UnmarshalText(buf []byte) error {
+	if hex.DecodedLen(len(buf)) != len(p) {
+		return fmt.Errorf("proposal should be %d bytes long", len(p))
+	}
+	_, err := hex.Decode(p[:], buf)
+	return err
+}
+
+// GetRecord loads the protocol record for the epoch from the database.
+func GetRecord(db sql.Executor, epoch types.EpochID) (*EpochRecord, error) {
+	buf, err := beacons.GetRecord(db, epoch)
+	if err != nil {
+		return nil, err
+	}
+	var record EpochRecord
+	if err := codec.Decode(buf, &record); err != nil {
+		return nil, fmt.Errorf("decode record for epoch %v: %w", epoch, err)
+	}
+	return &record, nil
+}
+
+// RecomputeBeacon computes the beacon from the vote margins and the weak coin
+// recorded in the last round, following the same steps as the protocol.
+func RecomputeBeacon(record *EpochRecord) (types.Beacon, error) {
+	if len(record.Rounds) == 0 {
+		return types.EmptyBeacon, errors.New("no rounds in the record")
+	}
+	theta, ok := new(big.Rat).SetString(record.Theta)
+	if !ok {
+		return types.EmptyBeacon, fmt.Errorf("invalid theta %q", record.Theta)
+	}
+	last := record.Rounds[len(record.Rounds)-1]
+	st := &state{
+		epochWeight: record.EpochWeight,
+		votesMargin: make(map[Proposal]*big.Int, len(last.Margins)),
+	}
+	for _, margin := range last.Margins {
+		value, ok := new(big.Int).SetString(margin.Margin, 10)
+		if !ok {
+			return types.EmptyBeacon, fmt.Errorf("invalid margin %q for proposal %x", margin.Margin, margin.Proposal)
+		}
+		st.votesMargin[margin.Proposal] = value
+	}
+	logger := log.NewNop()
+	votes, undecided := calcVotes(logger, new(big.Float).SetRat(theta), st)
+	if len(undecided) > 0 {
+		if !last.HasCoin {
+			return types.EmptyBeacon, fmt.Errorf("%d proposals are undecided without weak coin", len(undecided))
+		}
+		tallyUndecided(&votes, undecided, last.Coin)
+	}
+	if len(votes.support) == 0 {
+		return types.EmptyBeacon, errNoProposals
+	}
+	return calcBeacon(logger, votes.support), nil
+}
+
+func unixNano(t time.Time) uint64 {
+	return uint64(t.UnixNano())
+}
+
+func snapshotMargins(margins map[Proposal]*big.Int) []VoteMargin {
+	rst := make([]VoteMargin, 0, len(margins))
+	for proposal, margin := range margins {
+		rst = append(rst, VoteMargin{Proposal: proposal, Margin: margin.String()})
+	}
+	return rst
+}
+
+// updateRecord applies update to the record of the active epoch.
+func (pd *ProtocolDriver) updateRecord(epoch types.EpochID, update func(*EpochRecord)) {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if s, ok := pd.states[epoch]; ok {
+		update(&s.record)
+	}
+}
+
+// persistRecord completes the record with received proposals and stores it in the database.
+func (pd *ProtocolDriver) persistRecord(logger log.Log, st *state, failure error) {
+	pd.mu.Lock()
+	record := st.record
+	record.Finished = unixNano(pd.wallclock.Now())
+	record.EpochWeight = st.epochWeight
+	record.Theta = pd.config.Theta.RatString()
+	record.ValidProposals = st.incomingProposals.valid.sort()
+	record.PotentiallyValidProposals = st.incomingProposals.potentiallyValid.sort()
+	pd.mu.Unlock()
+
+	if failure != nil {
+		record.Failure = failure.Error()
+		metrics.ProtocolFailures.Inc()
+	}
+	metrics.ValidProposals.Set(float64(len(record.ValidProposals)))
+	metrics.PotentiallyValidProposals.Set(float64(len(record.PotentiallyValidProposals)))
+	record.truncate()
+	buf, err := codec.Encode(&record)
+	if err != nil {
+		logger.With().Error("failed to encode beacon protocol record", log.Err(err))
+		return
+	}
+	if err := beacons.SetRecord(pd.cdb, record.Epoch, buf); err != nil {
+		logger.With().Error("failed to persist beacon protocol record", log.Err(err))
+	}
+}
@@ -0,0 +1,46 @@
+package beacon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/codec"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+func TestRecordTruncate(t *testing.T) {
+	proposals := make([]Proposal, maxRecordProposals+1)
+	margins := make([]VoteMargin, maxRecordMargins+1)
+	for i := range margins {
+		margins[i].Margin = strings.Repeat("1", maxRecordMargin+1)
+	}
+	record := EpochRecord{
+		Epoch:                     types.EpochID(3),
+		Theta:                     strings.Repeat("1", maxRecordTheta+1),
+		ValidProposals:            proposals,
+		PotentiallyValidProposals: proposals,
+		Rounds:                    make([]RoundRecord, maxRecordRounds+1),
+		Failure:                   strings.Repeat("f", maxRecordFailure+1),
+	}
+	record.Rounds[0].Margins = margins
+	rounds := record.Rounds
+	_, err := codec.Encode(&record)
+	require.Error(t, err)
+
+	record.truncate()
+	buf, err := codec.Encode(&record)
+	require.NoError(t, err)
+	var decoded EpochRecord
+	require.NoError(t, codec.Decode(buf, &decoded))
+	require.Len(t, decoded.ValidProposals, maxRecordProposals)
+	require.Len(t, decoded.Rounds, maxRecordRounds)
+	require.Len(t, decoded.Rounds[0].Margins, maxRecordMargins)
+	require.Len(t, decoded.Rounds[0].Margins[0].Margin, maxRecordMargin)
+	require.Len(t, decoded.Failure, maxRecordFailure)
+
+	// record of the active epoch is not modified
+	require.Len(t, rounds[0].Margins, maxRecordMargins+1)
+	require.Len(t, rounds[0].Margins[0].Margin, maxRecordMargin+1)
+}
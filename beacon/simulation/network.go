@@ -0,0 +1,238 @@
+package simulation
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/p2p"
+	"github.com/spacemeshos/go-spacemesh/p2p/pubsub"
+)
+
+// NetworkConfig describes the simulated gossip layer.
+type NetworkConfig struct {
+	// Delay is the minimal time for the message to reach another node.
+	Delay time.Duration
+	// Jitter is the upper bound of the random delay added to Delay.
+	Jitter time.Duration
+	// Loss is a probability that the message will not reach another node.
+	Loss float64
+	// Partitions split nodes into groups that can't reach each other.
+	Partitions []Partition
+}
+
+// Partition isolates groups of nodes from each other in the [Start, End) interval.
+// Start and End are offsets from the start of the simulation.
+// Nodes that are not listed in any group form an additional group.
+type Partition struct {
+	Start, End time.Duration
+	Groups     [][]int
+}
+
+func (p *Partition) active(offset time.Duration) bool {
+	return offset >= p.Start && offset < p.End
+}
+
+func (p *Partition) group(node int) int {
+	for i, group := range p.Groups {
+		for _, member := range group {
+			if member == node {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// NetworkStats counts messages that passed through the simulated gossip.
+type NetworkStats struct {
+	Sent, Delivered, Dropped, Rejected int
+}
+
+type envelope struct {
+	at       time.Time
+	seq      uint64
+	from, to int
+	protocol string
+	data     []byte
+}
+
+type envelopes []*envelope
+
+func (e envelopes) Len() int { return len(e) }
+
+func (e envelopes) Less(i, j int) bool {
+	if e[i].at.Equal(e[j].at) {
+		return e[i].seq < e[j].seq
+	}
+	return e[i].at.Before(e[j].at)
+}
+
+func (e envelopes) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+
+func (e *envelopes) Push(x any) { *e = append(*e, x.(*envelope)) }
+
+func (e *envelopes) Pop() any {
+	old := *e
+	last := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return last
+}
+
+// network is a full mesh where every published message is sent directly to every node.
+// Messages are queued until the simulation advances the clock to their delivery time.
+type network struct {
+	cfg   NetworkConfig
+	clock *clock.Mock
+	start time.Time
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	seq     uint64
+	queue   envelopes
+	drivers []*beacon.ProtocolDriver
+	stats   NetworkStats
+}
+
+func newNetwork(cfg NetworkConfig, clock *clock.Mock, rng *rand.Rand) *network {
+	return &network{cfg: cfg, clock: clock, start: clock.Now(), rng: rng}
+}
+
+func (n *network) validate(nodes int) error {
+	if n.cfg.Loss < 0 || n.cfg.Loss >= 1 {
+		return fmt.Errorf("loss %v must be in [0, 1)", n.cfg.Loss)
+	}
+	for _, partition := range n.cfg.Partitions {
+		if partition.End <= partition.Start {
+			return fmt.Errorf("partition end %v must be after start %v", partition.End, partition.Start)
+		}
+		for _, group := range partition.Groups {
+			for _, node := range group {
+				if node < 0 || node >= nodes {
+					return fmt.Errorf("partition refers to node %d out of %d", node, nodes)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type publisherFunc func(context.Context, string, []byte) error
+
+func (f publisherFunc) Publish(ctx context.Context, protocol string, data []byte) error {
+	return f(ctx, protocol, data)
+}
+
+func (n *network) publisher(from int) pubsub.Publisher {
+	return publisherFunc(func(ctx context.Context, protocol string, data []byte) error {
+		n.publish(ctx, from, protocol, data)
+		return nil
+	})
+}
+
+func (n *network) connected(from, to int, now time.Time) bool {
+	offset := now.Sub(n.start)
+	for i := range n.cfg.Partitions {
+		partition := &n.cfg.Partitions[i]
+		if partition.active(offset) && partition.group(from) != partition.group(to) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *network) publish(ctx context.Context, from int, protocol string, data []byte) {
+	n.mu.Lock()
+	now := n.clock.Now()
+	for to := range n.drivers {
+		if to == from {
+			continue
+		}
+		n.stats.Sent++
+		if !n.connected(from, to, now) || n.rng.Float64() < n.cfg.Loss {
+			n.stats.Dropped++
+			continue
+		}
+		delay := n.cfg.Delay
+		if n.cfg.Jitter > 0 {
+			delay += time.Duration(n.rng.Int63n(int64(n.cfg.Jitter)))
+		}
+		n.seq++
+		heap.Push(&n.queue, &envelope{
+			at:       now.Add(delay),
+			seq:      n.seq,
+			from:     from,
+			to:       to,
+			protocol: protocol,
+			data:     data,
+		})
+	}
+	n.mu.Unlock()
+	// pubsub validates own messages before broadcasting them
+	n.handle(ctx, from, from, protocol, data)
+}
+
+// next returns delivery time of the earliest queued message.
+func (n *network) next() (time.Time, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.queue) == 0 {
+		return time.Time{}, false
+	}
+	return n.queue[0].at, true
+}
+
+// deliver hands over all messages that are due at the current time.
+func (n *network) deliver(ctx context.Context) {
+	now := n.clock.Now()
+	for {
+		n.mu.Lock()
+		if len(n.queue) == 0 || n.queue[0].at.After(now) {
+			n.mu.Unlock()
+			return
+		}
+		env := heap.Pop(&n.queue).(*envelope)
+		n.mu.Unlock()
+		n.handle(ctx, env.from, env.to, env.protocol, env.data)
+	}
+}
+
+func (n *network) handle(ctx context.Context, from, to int, protocol string, data []byte) {
+	var (
+		driver = n.drivers[to]
+		peer   = p2p.Peer(fmt.Sprintf("node-%d", from))
+		err    error
+	)
+	switch protocol {
+	case pubsub.BeaconProposalProtocol:
+		err = driver.HandleProposal(ctx, peer, data)
+	case pubsub.BeaconFirstVotesProtocol:
+		err = driver.HandleFirstVotes(ctx, peer, data)
+	case pubsub.BeaconFollowingVotesProtocol:
+		err = driver.HandleFollowingVotes(ctx, peer, data)
+	case pubsub.BeaconWeakCoinProtocol:
+		err = driver.HandleWeakCoinProposal(ctx, peer, data)
+	default:
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if from != to {
+		n.stats.Delivered++
+	}
+	if err != nil {
+		n.stats.Rejected++
+	}
+}
+
+func (n *network) getStats() NetworkStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stats
+}
@@ -0,0 +1,130 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// BeaconCount is a number of nodes that computed the beacon.
+type BeaconCount struct {
+	Beacon types.Beacon
+	Nodes  int
+}
+
+// EpochReport summarizes the protocol executed by all nodes in one epoch.
+type EpochReport struct {
+	Epoch types.EpochID
+	// Beacons computed by nodes, ordered by the number of nodes. Nodes that failed are not included.
+	Beacons []BeaconCount
+	// Agreement is a fraction of all nodes that computed the most common beacon.
+	Agreement float64
+	// Failed is a number of nodes that didn't compute a beacon.
+	Failed int
+	// Rounds is the largest number of voting rounds completed by a node.
+	Rounds int
+	// Decided is the first round after which no node had undecided proposals.
+	// 0 if some proposals remained undecided until the last round.
+	Decided int
+	// CoinRounds is a number of rounds where at least one node computed the weak coin.
+	// CoinAgreements is a number of such rounds where all nodes computed the same weak coin.
+	CoinRounds, CoinAgreements int
+	// Proposals is the largest number of valid proposals received by a node.
+	Proposals int
+}
+
+// CoinAgreement is a fraction of rounds where all nodes agreed on the weak coin.
+func (r *EpochReport) CoinAgreement() float64 {
+	if r.CoinRounds == 0 {
+		return 1
+	}
+	return float64(r.CoinAgreements) / float64(r.CoinRounds)
+}
+
+// Report of the simulation.
+type Report struct {
+	Seed    int64
+	Epochs  []EpochReport
+	Network NetworkStats
+}
+
+func (s *Simulation) report() (*Report, error) {
+	report := &Report{Seed: s.cfg.Seed, Network: s.network.getStats()}
+	for epoch := s.first; epoch <= s.last; epoch++ {
+		records := make([]*beacon.EpochRecord, 0, len(s.nodes))
+		for i, n := range s.nodes {
+			record, err := beacon.GetRecord(n.db, epoch)
+			switch {
+			case errors.Is(err, sql.ErrNotFound):
+				// protocol was not executed, it is accounted as failed
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("record of node %d in epoch %d: %w", i, epoch, err)
+			}
+			records = append(records, record)
+		}
+		report.Epochs = append(report.Epochs, summarize(epoch, len(s.nodes), records))
+	}
+	return report, nil
+}
+
+func summarize(epoch types.EpochID, nodes int, records []*beacon.EpochRecord) EpochReport {
+	rst := EpochReport{Epoch: epoch, Failed: nodes - len(records)}
+	beacons := map[types.Beacon]int{}
+	// round -> coin -> count
+	coins := map[types.RoundID]map[bool]int{}
+	decided := map[types.RoundID]int{}
+	for _, record := range records {
+		if len(record.Failure) > 0 || record.Beacon == types.EmptyBeacon {
+			rst.Failed++
+		} else {
+			beacons[record.Beacon]++
+		}
+		if len(record.Rounds) > rst.Rounds {
+			rst.Rounds = len(record.Rounds)
+		}
+		if len(record.ValidProposals) > rst.Proposals {
+			rst.Proposals = len(record.ValidProposals)
+		}
+		for _, round := range record.Rounds {
+			if round.Undecided == 0 {
+				decided[round.Round]++
+			}
+			if round.HasCoin {
+				if coins[round.Round] == nil {
+					coins[round.Round] = map[bool]int{}
+				}
+				coins[round.Round][round.Coin]++
+			}
+		}
+	}
+	for beacon, count := range beacons {
+		rst.Beacons = append(rst.Beacons, BeaconCount{Beacon: beacon, Nodes: count})
+	}
+	sort.Slice(rst.Beacons, func(i, j int) bool {
+		if rst.Beacons[i].Nodes == rst.Beacons[j].Nodes {
+			return rst.Beacons[i].Beacon.String() < rst.Beacons[j].Beacon.String()
+		}
+		return rst.Beacons[i].Nodes > rst.Beacons[j].Nodes
+	})
+	if len(rst.Beacons) > 0 && nodes > 0 {
+		rst.Agreement = float64(rst.Beacons[0].Nodes) / float64(nodes)
+	}
+	for round := types.FirstRound; int(round) < rst.Rounds; round++ {
+		if len(records) > 0 && decided[round] == len(records) {
+			rst.Decided = int(round) + 1
+			break
+		}
+	}
+	for _, values := range coins {
+		rst.CoinRounds++
+		if len(values) == 1 {
+			rst.CoinAgreements++
+		}
+	}
+	return rst
+}
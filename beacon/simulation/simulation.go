@@ -0,0 +1,317 @@
+// Package simulation runs several beacon protocol drivers in a single process
+// on a simulated clock and gossip network.
+//
+// Nodes use the real ProtocolDriver and weak coin, only time and networking are simulated.
+// Results are collected from the per-epoch records that every driver persists in its database,
+// which makes the harness suitable for tuning beacon.Config for new networks and for
+// regression testing protocol changes without deploying a cluster.
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/datastore"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+)
+
+// Config of the simulation.
+type Config struct {
+	// Seed for all random choices in the simulated network. If 0 seed is generated,
+	// and can be found in the report.
+	Seed   int64
+	Nodes  int
+	Epochs int
+	// Units is the number of space units in the atx of every node.
+	Units uint32
+	// LayersPerEpoch updates global types.LayersPerEpoch if not 0.
+	LayersPerEpoch uint32
+	// LayerDuration is computed to fit the whole protocol into the epoch if 0.
+	LayerDuration time.Duration
+	Beacon        beacon.Config
+	Network       NetworkConfig
+
+	// Step is the longest interval of the simulated time that passes at once.
+	// Defaults to one tenth of the shortest duration in the beacon config.
+	Step time.Duration
+	// Settle is the real time that nodes are given to react after every step.
+	Settle time.Duration
+}
+
+// DefaultConfig returns configuration for a small network with instant message delivery.
+func DefaultConfig() Config {
+	return Config{
+		Nodes:  10,
+		Epochs: 2,
+		Units:  1,
+		Beacon: beacon.NodeSimUnitTestConfig(),
+		Settle: time.Millisecond,
+	}
+}
+
+// Opt for configuring simulation.
+type Opt func(*Simulation)
+
+// WithLogger changes logger of the simulation and all nodes.
+func WithLogger(logger log.Log) Opt {
+	return func(s *Simulation) {
+		s.logger = logger
+	}
+}
+
+type node struct {
+	signer *signing.EdSigner
+	db     *datastore.CachedDB
+	driver *beacon.ProtocolDriver
+}
+
+type synced struct{}
+
+func (synced) IsSynced(context.Context) bool { return true }
+
+func (synced) IsBeaconSynced(types.EpochID) bool { return true }
+
+// Simulation of the beacon protocol executed by several nodes.
+type Simulation struct {
+	logger log.Log
+	cfg    Config
+
+	clock   *clock.Mock
+	lclock  *layerClock
+	network *network
+	nodes   []*node
+
+	first, last types.EpochID
+}
+
+// New creates nodes and populates their databases with atxs for all simulated epochs.
+func New(cfg Config, opts ...Opt) (*Simulation, error) {
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if cfg.LayersPerEpoch != 0 {
+		types.SetLayersPerEpoch(cfg.LayersPerEpoch)
+	}
+	if types.GetLayersPerEpoch() == 0 {
+		return nil, errors.New("layers per epoch is not set")
+	}
+	if cfg.Nodes <= 0 || cfg.Epochs <= 0 {
+		return nil, fmt.Errorf("nodes (%d) and epochs (%d) must be positive", cfg.Nodes, cfg.Epochs)
+	}
+	if cfg.Units == 0 {
+		cfg.Units = 1
+	}
+	if cfg.Step == 0 {
+		cfg.Step = shortest(cfg.Beacon) / 10
+	}
+	if cfg.Step <= 0 {
+		return nil, errors.New("step must be positive")
+	}
+	protocol := protocolDuration(cfg.Beacon)
+	epoch := cfg.LayerDuration * time.Duration(types.GetLayersPerEpoch())
+	if cfg.LayerDuration == 0 {
+		layers := time.Duration(types.GetLayersPerEpoch())
+		cfg.LayerDuration = (protocol + 2*cfg.Beacon.GracePeriodDuration + layers - 1) / layers
+	} else if epoch < protocol+cfg.Beacon.GracePeriodDuration {
+		return nil, fmt.Errorf("epoch duration %v is shorter than the protocol %v with grace period %v",
+			epoch, protocol, cfg.Beacon.GracePeriodDuration)
+	}
+
+	s := &Simulation{
+		logger: log.NewNop(),
+		cfg:    cfg,
+		clock:  clock.NewMock(),
+		first:  types.GetEffectiveGenesis().GetEpoch() + 1,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.last = s.first + types.EpochID(cfg.Epochs) - 1
+	s.lclock = &layerClock{clock: s.clock, genesis: s.clock.Now(), layerDuration: cfg.LayerDuration}
+	s.network = newNetwork(cfg.Network, s.clock, rand.New(rand.NewSource(cfg.Seed)))
+	if err := s.network.validate(cfg.Nodes); err != nil {
+		return nil, err
+	}
+	// start right before the epoch where the first protocol is executed
+	// so that the first proposals are timely.
+	s.clock.Set(s.lclock.LayerToTime(s.first.FirstLayer() - 1))
+	s.network.start = s.clock.Now()
+
+	verifier, err := signing.NewEdVerifier()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < cfg.Nodes; i++ {
+		signer, err := signing.NewEdSigner()
+		if err != nil {
+			return nil, err
+		}
+		vrfSigner, err := signer.VRFSigner()
+		if err != nil {
+			return nil, err
+		}
+		logger := s.logger.Named(fmt.Sprintf("node-%d", i))
+		db := datastore.NewCachedDB(sql.InMemory(), logger)
+		driver := beacon.New(signer.NodeID(), s.network.publisher(i), signer, verifier,
+			vrfSigner, signing.NewVRFVerifier(), db, s.lclock,
+			beacon.WithConfig(cfg.Beacon),
+			beacon.WithLogger(logger),
+			beacon.WithWallclock(s.clock),
+		)
+		driver.SetSyncState(synced{})
+		s.nodes = append(s.nodes, &node{signer: signer, db: db, driver: driver})
+		s.network.drivers = append(s.network.drivers, driver)
+	}
+	for epoch := s.first; epoch <= s.last; epoch++ {
+		if err := s.addATXs(epoch); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// addATXs publishes atxs from every node targeting the epoch and adds them to all databases.
+func (s *Simulation) addATXs(target types.EpochID) error {
+	received := s.lclock.LayerToTime(target.FirstLayer()).Add(-2 * s.cfg.Beacon.GracePeriodDuration)
+	for _, n := range s.nodes {
+		nonce := types.VRFPostIndex(1)
+		atx := types.NewActivationTx(
+			types.NIPostChallenge{PublishEpoch: target - 1},
+			types.Address{},
+			nil,
+			s.cfg.Units,
+			&nonce,
+		)
+		atx.SetEffectiveNumUnits(s.cfg.Units)
+		atx.SetReceived(received)
+		nodeID := n.signer.NodeID()
+		atx.NodeID = &nodeID
+		atx.Signature = n.signer.Sign(signing.ATX, atx.SignedBytes())
+		atx.SmesherID = nodeID
+		if err := atx.Initialize(); err != nil {
+			return err
+		}
+		verified, err := atx.Verify(0, 1)
+		if err != nil {
+			return err
+		}
+		for _, other := range s.nodes {
+			if err := atxs.Add(other.db, verified); err != nil {
+				return fmt.Errorf("add atx %s: %w", verified.ID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Run advances the simulated clock until the protocol is completed for all epochs.
+func (s *Simulation) Run(ctx context.Context) (*Report, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	for _, n := range s.nodes {
+		n.driver.Start(ctx)
+		go drain(n.driver)
+	}
+	end := s.lclock.LayerToTime((s.last + 1).FirstLayer())
+	s.logger.With().Info("starting beacon simulation",
+		log.Uint64("seed", uint64(s.cfg.Seed)),
+		log.Int("nodes", len(s.nodes)),
+		log.Stringer("first", s.first),
+		log.Stringer("last", s.last),
+		log.Duration("layer_duration", s.cfg.LayerDuration),
+		log.Duration("step", s.cfg.Step),
+	)
+	var err error
+	for now := s.clock.Now(); now.Before(end); now = s.clock.Now() {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		next := now.Add(s.cfg.Step)
+		if at, ok := s.network.next(); ok && at.Before(next) {
+			next = at
+		}
+		if next.After(end) {
+			next = end
+		}
+		s.clock.Set(next)
+		time.Sleep(s.cfg.Settle)
+		s.network.deliver(ctx)
+		time.Sleep(s.cfg.Settle)
+	}
+	cancel()
+	for _, n := range s.nodes {
+		n.driver.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.report()
+}
+
+func drain(driver *beacon.ProtocolDriver) {
+	for range driver.Results() {
+	}
+}
+
+// protocolDuration is the time between the start of the epoch and the end of the last voting round.
+func protocolDuration(cfg beacon.Config) time.Duration {
+	duration := cfg.ProposalDuration + cfg.FirstVotingRoundDuration
+	if cfg.RoundsNumber > 1 {
+		duration += time.Duration(cfg.RoundsNumber-1) * (cfg.VotingRoundDuration + cfg.WeakCoinRoundDuration)
+	}
+	return duration
+}
+
+func shortest(cfg beacon.Config) time.Duration {
+	rst := cfg.GracePeriodDuration
+	for _, d := range []time.Duration{
+		cfg.ProposalDuration,
+		cfg.FirstVotingRoundDuration,
+		cfg.VotingRoundDuration,
+		cfg.WeakCoinRoundDuration,
+	} {
+		if d < rst {
+			rst = d
+		}
+	}
+	return rst
+}
+
+// layerClock derives layers from the simulated time.
+type layerClock struct {
+	clock         *clock.Mock
+	genesis       time.Time
+	layerDuration time.Duration
+}
+
+func (c *layerClock) LayerToTime(lid types.LayerID) time.Time {
+	return c.genesis.Add(time.Duration(lid) * c.layerDuration)
+}
+
+func (c *layerClock) CurrentLayer() types.LayerID {
+	elapsed := c.clock.Now().Sub(c.genesis)
+	if elapsed < 0 {
+		return 0
+	}
+	return types.LayerID(elapsed / c.layerDuration)
+}
+
+func (c *layerClock) AwaitLayer(lid types.LayerID) <-chan struct{} {
+	ch := make(chan struct{})
+	wait := c.clock.Until(c.LayerToTime(lid))
+	if wait <= 0 {
+		close(ch)
+		return ch
+	}
+	c.clock.AfterFunc(wait, func() { close(ch) })
+	return ch
+}
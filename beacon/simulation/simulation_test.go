@@ -0,0 +1,83 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+)
+
+func run(tb testing.TB, cfg Config) *Report {
+	tb.Helper()
+	sim, err := New(cfg, WithLogger(logtest.New(tb)))
+	require.NoError(tb, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	report, err := sim.Run(ctx)
+	require.NoError(tb, err)
+	require.Len(tb, report.Epochs, cfg.Epochs)
+	return report
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Seed = 101
+	cfg.Nodes = 6
+	cfg.LayersPerEpoch = 3
+	cfg.Beacon.RoundsNumber = 4
+	return cfg
+}
+
+func TestSimulation_Agreement(t *testing.T) {
+	cfg := testConfig()
+	cfg.Network.Delay = 10 * time.Millisecond
+	cfg.Network.Jitter = 20 * time.Millisecond
+	report := run(t, cfg)
+	for _, epoch := range report.Epochs {
+		require.Zero(t, epoch.Failed, "epoch %d", epoch.Epoch)
+		require.Len(t, epoch.Beacons, 1, "epoch %d", epoch.Epoch)
+		require.Equal(t, 1.0, epoch.Agreement)
+		require.Equal(t, int(cfg.Beacon.RoundsNumber), epoch.Rounds)
+		require.Equal(t, cfg.Nodes, epoch.Proposals)
+		require.Equal(t, epoch.CoinRounds, epoch.CoinAgreements)
+		require.Equal(t, int(cfg.Beacon.RoundsNumber)-1, epoch.CoinRounds)
+	}
+	require.Zero(t, report.Network.Dropped)
+}
+
+func TestSimulation_Partition(t *testing.T) {
+	cfg := testConfig()
+	cfg.Epochs = 1
+	cfg.Network.Partitions = []Partition{{
+		End:    time.Hour,
+		Groups: [][]int{{0, 1, 2}},
+	}}
+	report := run(t, cfg)
+	epoch := report.Epochs[0]
+	require.Len(t, epoch.Beacons, 2)
+	require.Equal(t, 3, epoch.Beacons[0].Nodes)
+	require.Equal(t, 3, epoch.Beacons[1].Nodes)
+	require.Equal(t, 0.5, epoch.Agreement)
+	require.Equal(t, 3, epoch.Proposals)
+	require.NotZero(t, report.Network.Dropped)
+}
+
+func TestSimulation_InvalidConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.Network.Loss = 1
+	_, err := New(cfg)
+	require.ErrorContains(t, err, "loss")
+
+	cfg = testConfig()
+	cfg.Network.Partitions = []Partition{{End: time.Second, Groups: [][]int{{cfg.Nodes}}}}
+	_, err = New(cfg)
+	require.ErrorContains(t, err, "out of")
+
+	cfg = testConfig()
+	cfg.LayerDuration = time.Millisecond
+	_, err = New(cfg)
+	require.ErrorContains(t, err, "shorter than the protocol")
+}
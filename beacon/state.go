@@ -34,6 +34,8 @@ type state struct {
 	proposalPhaseFinishedTime time.Time
 	proposalChecker           eligibilityChecker
 	minerAtxs                 map[types.NodeID]*minerInfo
+	// record is updated while the protocol is running and persisted when it finishes.
+	record EpochRecord
 }
 
 func newState(
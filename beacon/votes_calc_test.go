@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log/logtest"
 )
 
@@ -183,3 +184,40 @@ func TestBeacon_votingThreshold(t *testing.T) {
 		})
 	}
 }
+
+func TestRecomputeBeacon(t *testing.T) {
+	proposals := []Proposal{{1}, {2}, {3}}
+	record := &EpochRecord{
+		EpochWeight: 100,
+		Theta:       "1/4",
+		Rounds: []RoundRecord{
+			{Round: types.FirstRound},
+			{
+				Round: 1,
+				Margins: []VoteMargin{
+					{Proposal: proposals[0], Margin: "30"},
+					{Proposal: proposals[1], Margin: "-30"},
+					{Proposal: proposals[2], Margin: "10"},
+				},
+				HasCoin: true,
+				Coin:    true,
+			},
+		},
+	}
+	beacon, err := RecomputeBeacon(record)
+	require.NoError(t, err)
+	require.Equal(t, calcBeacon(logtest.New(t), proposalSet{proposals[0]: {}, proposals[2]: {}}), beacon)
+
+	record.Rounds[1].Coin = false
+	beacon, err = RecomputeBeacon(record)
+	require.NoError(t, err)
+	require.Equal(t, calcBeacon(logtest.New(t), proposalSet{proposals[0]: {}}), beacon)
+
+	record.Rounds[1].HasCoin = false
+	_, err = RecomputeBeacon(record)
+	require.Error(t, err)
+
+	record.Theta = "invalid"
+	_, err = RecomputeBeacon(record)
+	require.Error(t, err)
+}
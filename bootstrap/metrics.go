@@ -41,6 +41,13 @@ var (
 	updateOkCount      = updateCount.WithLabelValues(success)
 	updateFailureCount = updateCount.WithLabelValues(failure)
 
+	disagreementCount = metrics.NewCounter(
+		"disagreements",
+		namespace,
+		"number of times bootstrap sources served different updates for the same epoch",
+		nil,
+	).WithLabelValues()
+
 	queryDuration = metrics.NewHistogramWithBuckets(
 		"query_duration",
 		namespace,
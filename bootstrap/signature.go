@@ -0,0 +1,91 @@
+package bootstrap
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/hash"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+var (
+	ErrNotSigned        = errors.New("update is not signed")
+	ErrUntrustedSigner  = errors.New("update is signed by untrusted key")
+	ErrInvalidSignature = errors.New("invalid update signature")
+)
+
+// Digest returns the hash of the update that is signed by the bootstrap operator.
+// Updates with equal digests are considered identical when counting the quorum.
+func Digest(version string, data *EpochOverride) types.Hash32 {
+	buf := make([]byte, 0, 4+len(version)+4+types.BeaconSize+len(data.ActiveSet)*types.Hash32Length)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(version)))
+	buf = append(buf, version...)
+	buf = binary.BigEndian.AppendUint32(buf, data.Epoch.Uint32())
+	buf = append(buf, data.Beacon[:]...)
+	for _, atx := range data.ActiveSet {
+		buf = append(buf, atx[:]...)
+	}
+	return hash.Sum(buf)
+}
+
+// SignUpdate sets signer and signature of the update.
+func SignUpdate(signer *signing.EdSigner, update *Update) error {
+	data, err := parseData(update)
+	if err != nil {
+		return err
+	}
+	digest := Digest(update.Version, data)
+	sig := signer.Sign(signing.BOOTSTRAP, digest[:])
+	update.Signer = hex.EncodeToString(signer.PublicKey().Bytes())
+	update.Signature = hex.EncodeToString(sig[:])
+	return nil
+}
+
+func parseTrustedKeys(keys []string) (map[types.NodeID]struct{}, error) {
+	trusted := make(map[types.NodeID]struct{}, len(keys))
+	for _, key := range keys {
+		decoded, err := hex.DecodeString(key)
+		if err != nil || len(decoded) != len(types.NodeID{}) {
+			return nil, fmt.Errorf("invalid trusted key %q", key)
+		}
+		trusted[types.BytesToNodeID(decoded)] = struct{}{}
+	}
+	return trusted, nil
+}
+
+// verifySignature returns the trusted key that signed the update.
+func verifySignature(keys []string, update *Update, data *EpochOverride) (types.NodeID, error) {
+	trusted, err := parseTrustedKeys(keys)
+	if err != nil {
+		return types.EmptyNodeID, err
+	}
+	if len(update.Signer) == 0 || len(update.Signature) == 0 {
+		return types.EmptyNodeID, ErrNotSigned
+	}
+	decoded, err := hex.DecodeString(update.Signer)
+	if err != nil || len(decoded) != len(types.NodeID{}) {
+		return types.EmptyNodeID, fmt.Errorf("%w: signer %q", ErrUntrustedSigner, update.Signer)
+	}
+	signer := types.BytesToNodeID(decoded)
+	if _, ok := trusted[signer]; !ok {
+		return types.EmptyNodeID, fmt.Errorf("%w: %s", ErrUntrustedSigner, update.Signer)
+	}
+	var sig types.EdSignature
+	decoded, err = hex.DecodeString(update.Signature)
+	if err != nil || len(decoded) != len(sig) {
+		return types.EmptyNodeID, fmt.Errorf("%w: malformed", ErrInvalidSignature)
+	}
+	copy(sig[:], decoded)
+	verifier, err := signing.NewEdVerifier()
+	if err != nil {
+		return types.EmptyNodeID, err
+	}
+	digest := Digest(update.Version, data)
+	if !verifier.Verify(signing.BOOTSTRAP, signer, digest[:], sig) {
+		return types.EmptyNodeID, ErrInvalidSignature
+	}
+	return signer, nil
+}
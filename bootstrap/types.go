@@ -8,6 +8,10 @@ import (
 type Update struct {
 	Version string    `json:"version"`
 	Data    InnerData `json:"data"`
+	// Signer is a hex encoded ed25519 public key, and Signature is a hex encoded signature
+	// of the update digest. Both are required if node is configured with trusted keys.
+	Signer    string `json:"signer,omitempty"`
+	Signature string `json:"signature,omitempty"`
 }
 
 type InnerData struct {
@@ -23,6 +27,8 @@ type EpochData struct {
 type VerifiedUpdate struct {
 	Data      *EpochOverride
 	Persisted string
+	// Signer is the trusted key that signed the update. Empty if trusted keys are not configured.
+	Signer types.NodeID
 }
 
 type EpochOverride struct {
@@ -8,6 +8,11 @@
 // by the spacemesh administrator, verifies the data, persists on disk and
 // notifies subscribers of a new update.
 //
+// The updater can be configured with several independent sources. In that case
+// an update is accepted only if the quorum of sources served identical data,
+// so that a single compromised or misconfigured host can't push a bad beacon
+// or active set. Disagreements between sources are reported via events.
+//
 // Subscribers register by calling `Subscribe()` to receive a channel for
 // the latest update.
 package bootstrap
@@ -19,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -33,6 +39,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/log"
 )
 
@@ -57,6 +64,14 @@ var (
 type Config struct {
 	URL     string `mapstructure:"bootstrap-url"`
 	Version string `mapstructure:"bootstrap-version"`
+	// Sources are queried for updates in addition to URL.
+	Sources []string `mapstructure:"bootstrap-sources"`
+	// Quorum is a number of sources that must serve identical update for it to be accepted.
+	// If TrustedKeys are set, it is a number of distinct trusted keys that must sign identical update.
+	Quorum int `mapstructure:"bootstrap-quorum"`
+	// TrustedKeys are hex encoded ed25519 public keys. If not empty, every update
+	// must be signed by one of them.
+	TrustedKeys []string `mapstructure:"bootstrap-trusted-keys"`
 
 	DataDir  string
 	Interval time.Duration
@@ -66,11 +81,52 @@ func DefaultConfig() Config {
 	return Config{
 		URL:      DefaultURL,
 		Version:  "https://spacemesh.io/bootstrap.schema.json.1.0",
+		Quorum:   1,
 		DataDir:  os.TempDir(),
 		Interval: 30 * time.Second,
 	}
 }
 
+// urls returns deduplicated list of sources including URL.
+// Sources are compared after normalization, so that the same host is not counted twice.
+func (cfg *Config) urls() []string {
+	urls := make([]string, 0, len(cfg.Sources)+1)
+	seen := map[string]struct{}{}
+	for _, url := range append([]string{cfg.URL}, cfg.Sources...) {
+		if len(url) == 0 {
+			continue
+		}
+		normalized := normalizeURL(url)
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		urls = append(urls, strings.TrimRight(url, "/"))
+	}
+	return urls
+}
+
+// normalizeURL lowercases scheme and host, and removes default port and trailing slashes.
+// Unparsable urls are returned as is.
+func normalizeURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host, port := strings.ToLower(parsed.Hostname()), parsed.Port()
+	if (parsed.Scheme == "https" && port == "443") || (parsed.Scheme == "http" && port == "80") {
+		port = ""
+	}
+	parsed.Host = host
+	if port != "" {
+		parsed.Host = net.JoinHostPort(host, port)
+	}
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String()
+}
+
 type Updater struct {
 	cfg    Config
 	logger log.Log
@@ -125,6 +181,9 @@ func New(clock layerClock, opts ...Opt) *Updater {
 	for _, opt := range opts {
 		opt(u)
 	}
+	if u.cfg.Quorum < 1 {
+		u.cfg.Quorum = 1
+	}
 	return u
 }
 
@@ -161,6 +220,16 @@ func (u *Updater) Start() error {
 	if len(u.cfg.DataDir) == 0 {
 		return fmt.Errorf("data dir not set %s", u.cfg.DataDir)
 	}
+	if sources := len(u.cfg.urls()); u.cfg.Quorum > sources {
+		return fmt.Errorf("quorum %d is larger than the number of sources %d", u.cfg.Quorum, sources)
+	}
+	trusted, err := parseTrustedKeys(u.cfg.TrustedKeys)
+	if err != nil {
+		return err
+	}
+	if len(trusted) > 0 && u.cfg.Quorum > len(trusted) {
+		return fmt.Errorf("quorum %d is larger than the number of trusted keys %d", u.cfg.Quorum, len(trusted))
+	}
 	u.once.Do(func() {
 		u.eg.Go(func() error {
 			ctx := log.WithNewSessionID(context.Background())
@@ -168,7 +237,8 @@ func (u *Updater) Start() error {
 				return err
 			}
 			u.logger.With().Info("start listening to update",
-				log.String("source", u.cfg.URL),
+				log.String("sources", strings.Join(u.cfg.urls(), ",")),
+				log.Int("quorum", u.cfg.Quorum),
 				log.Duration("interval", u.cfg.Interval),
 			)
 			for {
@@ -269,11 +339,10 @@ func makeUri(url string, epoch types.EpochID, suffix string) string {
 }
 
 func (u *Updater) checkEpochUpdate(ctx context.Context, epoch types.EpochID, suffix string) (*VerifiedUpdate, bool, error) {
-	uri := makeUri(u.cfg.URL, epoch, suffix)
 	if u.Downloaded(epoch, suffix) {
 		return nil, true, nil
 	}
-	verified, data, err := u.get(ctx, uri)
+	verified, data, err := u.getQuorum(ctx, epoch, suffix)
 	if err != nil {
 		return nil, false, err
 	}
@@ -281,7 +350,7 @@ func (u *Updater) checkEpochUpdate(ctx context.Context, epoch types.EpochID, suf
 		return nil, false, nil
 	}
 	u.addUpdate(epoch, suffix)
-	filename := PersistFilename(u.cfg.DataDir, epoch, filepath.Base(uri))
+	filename := PersistFilename(u.cfg.DataDir, epoch, UpdateName(epoch, suffix))
 	if err = u.fs.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
 		return nil, false, fmt.Errorf("%w: create bootstrap data dir: %s", err, filename)
 	}
@@ -312,6 +381,114 @@ func (u *Updater) updateAndNotify(ctx context.Context, verified *VerifiedUpdate)
 	return nil
 }
 
+type servedUpdate struct {
+	source   string
+	verified *VerifiedUpdate
+	data     []byte
+}
+
+// getQuorum queries all sources and returns the update that was served by the quorum of them.
+// It returns nil if no update reached the quorum yet.
+func (u *Updater) getQuorum(ctx context.Context, epoch types.EpochID, suffix string) (*VerifiedUpdate, []byte, error) {
+	urls := u.cfg.urls()
+	served := make([]servedUpdate, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, source := range urls {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			verified, data, err := u.get(ctx, makeUri(source, epoch, suffix))
+			served[i] = servedUpdate{source: source, verified: verified, data: data}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", source, err)
+			}
+		}(i, source)
+	}
+	wg.Wait()
+
+	var (
+		digests []types.Hash32
+		groups  = map[types.Hash32][]servedUpdate{}
+	)
+	for _, update := range served {
+		if update.verified == nil {
+			continue
+		}
+		digest := Digest(u.cfg.Version, update.verified.Data)
+		if _, ok := groups[digest]; !ok {
+			digests = append(digests, digest)
+		}
+		groups[digest] = append(groups[digest], update)
+	}
+	var accepted []servedUpdate
+	for _, digest := range digests {
+		if u.votes(groups[digest]) < u.cfg.Quorum {
+			continue
+		}
+		if accepted != nil {
+			// possible only if quorum is not a majority of sources
+			accepted = nil
+			break
+		}
+		accepted = groups[digest]
+	}
+	if len(groups) > 1 {
+		u.reportDisagreement(ctx, epoch, suffix, digests, groups, accepted != nil)
+	}
+	if accepted == nil {
+		return nil, nil, errors.Join(errs...)
+	}
+	return accepted[0].verified, accepted[0].data, nil
+}
+
+// votes returns the number of independent votes for the identical updates. Every source is a vote,
+// unless trusted keys are configured. In that case a vote is a distinct key that signed the update,
+// as the same key may be served by several sources.
+func (u *Updater) votes(updates []servedUpdate) int {
+	if len(u.cfg.TrustedKeys) == 0 {
+		return len(updates)
+	}
+	signers := map[types.NodeID]struct{}{}
+	for _, update := range updates {
+		signers[update.verified.Signer] = struct{}{}
+	}
+	return len(signers)
+}
+
+func (u *Updater) reportDisagreement(
+	ctx context.Context,
+	epoch types.EpochID,
+	suffix string,
+	digests []types.Hash32,
+	groups map[types.Hash32][]servedUpdate,
+	accepted bool,
+) {
+	disagreementCount.Inc()
+	ev := events.EventBootstrapDisagreement{
+		Epoch:    epoch,
+		Suffix:   suffix,
+		Accepted: accepted,
+	}
+	for _, digest := range digests {
+		group := events.BootstrapSources{
+			Beacon:        groups[digest][0].verified.Data.Beacon,
+			ActiveSetSize: len(groups[digest][0].verified.Data.ActiveSet),
+		}
+		for _, update := range groups[digest] {
+			group.Sources = append(group.Sources, update.source)
+		}
+		ev.Groups = append(ev.Groups, group)
+	}
+	u.logger.WithContext(ctx).With().Warning("bootstrap sources disagree on update",
+		epoch,
+		log.String("suffix", suffix),
+		log.Bool("accepted", accepted),
+		log.String("groups", fmt.Sprintf("%+v", ev.Groups)),
+	)
+	events.ReportBootstrapDisagreement(ev)
+}
+
 func (u *Updater) get(ctx context.Context, uri string) (*VerifiedUpdate, []byte, error) {
 	resource, err := url.Parse(uri)
 	if err != nil {
@@ -398,16 +575,29 @@ func validateData(cfg Config, update *Update) (*VerifiedUpdate, error) {
 	if update.Version != cfg.Version {
 		return nil, fmt.Errorf("%w: expected %v, got %v", ErrWrongVersion, cfg.Version, update.Version)
 	}
-	verified := &VerifiedUpdate{
-		Data: &EpochOverride{
-			Epoch: types.EpochID(update.Data.Epoch.ID),
-		},
+	data, err := parseData(update)
+	if err != nil {
+		return nil, err
+	}
+	verified := &VerifiedUpdate{Data: data}
+	if len(cfg.TrustedKeys) > 0 {
+		verified.Signer, err = verifySignature(cfg.TrustedKeys, update, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return verified, nil
+}
+
+func parseData(update *Update) (*EpochOverride, error) {
+	data := &EpochOverride{
+		Epoch: types.EpochID(update.Data.Epoch.ID),
 	}
 	beaconByte, err := hex.DecodeString(update.Data.Epoch.Beacon)
 	if err != nil || len(beaconByte) < types.BeaconSize {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidBeacon, update.Data.Epoch.Beacon)
 	}
-	verified.Data.Beacon = types.BytesToBeacon(beaconByte)
+	data.Beacon = types.BytesToBeacon(beaconByte)
 
 	if len(update.Data.Epoch.ActiveSet) > 0 {
 		// json schema guarantees the active set has unique members
@@ -415,9 +605,9 @@ func validateData(cfg Config, update *Update) (*VerifiedUpdate, error) {
 		for _, atx := range update.Data.Epoch.ActiveSet {
 			activeSet = append(activeSet, types.ATXID(types.HexToHash32(atx)))
 		}
-		verified.Data.ActiveSet = activeSet
+		data.ActiveSet = activeSet
 	}
-	return verified, nil
+	return data, nil
 }
 
 func renameLegacyFile(fs afero.Fs, path string) string {
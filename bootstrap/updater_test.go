@@ -2,12 +2,15 @@ package bootstrap_test
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,7 +21,9 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/bootstrap"
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/signing"
 )
 
 const (
@@ -785,3 +790,269 @@ func TestClose(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, ch)
 }
+
+func serveUpdates(tb testing.TB, updates map[string]string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(tb, http.MethodGet, r.Method)
+		contents, ok := updates[r.URL.String()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(contents))
+	}))
+	tb.Cleanup(ts.Close)
+	return ts
+}
+
+func TestQuorum(t *testing.T) {
+	path := "/" + bootstrap.UpdateName(3, bootstrap.SuffixBeacon)
+	other := strings.Replace(update3, "f70cf90b", "f70cf90c", 1)
+	tcs := []struct {
+		desc     string
+		served   []string
+		quorum   int
+		accepted bool
+		groups   int
+	}{
+		{
+			desc:     "all agree",
+			served:   []string{update3, update3, update3},
+			quorum:   3,
+			accepted: true,
+		},
+		{
+			desc:     "majority agrees",
+			served:   []string{update3, other, update3},
+			quorum:   2,
+			accepted: true,
+			groups:   2,
+		},
+		{
+			desc:   "no quorum",
+			served: []string{update3, other, ""},
+			quorum: 2,
+			groups: 2,
+		},
+		{
+			desc:   "ambiguous quorum",
+			served: []string{update3, other},
+			quorum: 1,
+			groups: 2,
+		},
+		{
+			desc:     "not served yet",
+			served:   []string{update3, "", update3},
+			quorum:   2,
+			accepted: true,
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			events.InitializeReporter()
+			t.Cleanup(events.CloseEventReporter)
+			sub := events.SubscribeBootstrapDisagreement()
+
+			cfg := bootstrap.DefaultConfig()
+			cfg.Quorum = tc.quorum
+			cfg.URL = ""
+			for _, served := range tc.served {
+				updates := map[string]string{}
+				if len(served) > 0 {
+					updates[path] = served
+				}
+				cfg.Sources = append(cfg.Sources, serveUpdates(t, updates).URL)
+			}
+			mc := bootstrap.NewMocklayerClock(gomock.NewController(t))
+			mc.EXPECT().CurrentLayer().Return(current.FirstLayer())
+			updater := bootstrap.New(
+				mc,
+				bootstrap.WithConfig(cfg),
+				bootstrap.WithLogger(logtest.New(t)),
+				bootstrap.WithFilesystem(afero.NewMemMapFs()),
+			)
+			ch, err := updater.Subscribe()
+			require.NoError(t, err)
+			require.NoError(t, updater.DoIt(context.Background()))
+			require.Equal(t, tc.accepted, updater.Downloaded(3, bootstrap.SuffixBeacon))
+			if tc.accepted {
+				require.Len(t, ch, 1)
+				checkUpdate3(t, <-ch)
+			} else {
+				require.Empty(t, ch)
+			}
+			if tc.groups == 0 {
+				require.Empty(t, sub.Out())
+				return
+			}
+			select {
+			case ev := <-sub.Out():
+				disagreement := ev.(events.EventBootstrapDisagreement)
+				require.EqualValues(t, 3, disagreement.Epoch)
+				require.Equal(t, bootstrap.SuffixBeacon, disagreement.Suffix)
+				require.Equal(t, tc.accepted, disagreement.Accepted)
+				require.Len(t, disagreement.Groups, tc.groups)
+			case <-time.After(time.Second):
+				require.Fail(t, "disagreement is not reported")
+			}
+		})
+	}
+}
+
+func signedUpdate(tb testing.TB, signer *signing.EdSigner, data string) string {
+	var update bootstrap.Update
+	require.NoError(tb, json.Unmarshal([]byte(data), &update))
+	require.NoError(tb, bootstrap.SignUpdate(signer, &update))
+	buf, err := json.Marshal(update)
+	require.NoError(tb, err)
+	return string(buf)
+}
+
+func TestSignedUpdate(t *testing.T) {
+	trusted, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	untrusted, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	tampered := strings.Replace(signedUpdate(t, trusted, update3), "f70cf90b", "f70cf90c", 1)
+
+	tcs := []struct {
+		desc   string
+		update string
+		err    error
+	}{
+		{desc: "trusted", update: signedUpdate(t, trusted, update3)},
+		{desc: "not signed", update: update3, err: bootstrap.ErrNotSigned},
+		{desc: "untrusted", update: signedUpdate(t, untrusted, update3), err: bootstrap.ErrUntrustedSigner},
+		{desc: "tampered", update: tampered, err: bootstrap.ErrInvalidSignature},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ts := serveUpdates(t, map[string]string{
+				"/" + bootstrap.UpdateName(3, bootstrap.SuffixBeacon): tc.update,
+			})
+			cfg := bootstrap.DefaultConfig()
+			cfg.URL = ts.URL
+			cfg.TrustedKeys = []string{hex.EncodeToString(trusted.PublicKey().Bytes())}
+			mc := bootstrap.NewMocklayerClock(gomock.NewController(t))
+			mc.EXPECT().CurrentLayer().Return(current.FirstLayer())
+			updater := bootstrap.New(
+				mc,
+				bootstrap.WithConfig(cfg),
+				bootstrap.WithLogger(logtest.New(t)),
+				bootstrap.WithFilesystem(afero.NewMemMapFs()),
+				bootstrap.WithHttpClient(ts.Client()),
+			)
+			ch, err := updater.Subscribe()
+			require.NoError(t, err)
+			err = updater.DoIt(context.Background())
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Empty(t, ch)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, ch, 1)
+			checkUpdate3(t, <-ch)
+		})
+	}
+}
+
+func TestSignedQuorum(t *testing.T) {
+	path := "/" + bootstrap.UpdateName(3, bootstrap.SuffixBeacon)
+	first, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	second, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	trusted := []string{
+		hex.EncodeToString(first.PublicKey().Bytes()),
+		hex.EncodeToString(second.PublicKey().Bytes()),
+	}
+
+	tcs := []struct {
+		desc     string
+		signers  []*signing.EdSigner
+		accepted bool
+	}{
+		{desc: "distinct signers", signers: []*signing.EdSigner{first, second}, accepted: true},
+		{desc: "same signer", signers: []*signing.EdSigner{first, first, first}},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := bootstrap.DefaultConfig()
+			cfg.Quorum = 2
+			cfg.URL = ""
+			cfg.TrustedKeys = trusted
+			for _, signer := range tc.signers {
+				cfg.Sources = append(cfg.Sources, serveUpdates(t, map[string]string{
+					path: signedUpdate(t, signer, update3),
+				}).URL)
+			}
+			mc := bootstrap.NewMocklayerClock(gomock.NewController(t))
+			mc.EXPECT().CurrentLayer().Return(current.FirstLayer())
+			updater := bootstrap.New(
+				mc,
+				bootstrap.WithConfig(cfg),
+				bootstrap.WithLogger(logtest.New(t)),
+				bootstrap.WithFilesystem(afero.NewMemMapFs()),
+			)
+			ch, err := updater.Subscribe()
+			require.NoError(t, err)
+			require.NoError(t, updater.DoIt(context.Background()))
+			require.Equal(t, tc.accepted, updater.Downloaded(3, bootstrap.SuffixBeacon))
+			if tc.accepted {
+				require.Len(t, ch, 1)
+				checkUpdate3(t, <-ch)
+			} else {
+				require.Empty(t, ch)
+			}
+		})
+	}
+}
+
+func TestQuorumConfig(t *testing.T) {
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	tcs := []struct {
+		desc    string
+		sources []string
+		trusted []string
+		err     string
+	}{
+		{
+			desc:    "same host",
+			sources: []string{"https://bootstrap.example.com", "HTTPS://Bootstrap.Example.com:443/"},
+			err:     "larger than the number of sources 1",
+		},
+		{
+			desc:    "trusted keys",
+			sources: []string{"https://a.example.com", "https://b.example.com"},
+			trusted: []string{hex.EncodeToString(signer.PublicKey().Bytes())},
+			err:     "larger than the number of trusted keys 1",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := bootstrap.DefaultConfig()
+			cfg.URL = ""
+			cfg.Quorum = 2
+			cfg.Sources = tc.sources
+			cfg.TrustedKeys = tc.trusted
+			cfg.DataDir = t.TempDir()
+			mc := bootstrap.NewMocklayerClock(gomock.NewController(t))
+			mc.EXPECT().CurrentLayer().Return(current.FirstLayer()).AnyTimes()
+			updater := bootstrap.New(
+				mc,
+				bootstrap.WithConfig(cfg),
+				bootstrap.WithLogger(logtest.New(t)),
+				bootstrap.WithFilesystem(afero.NewMemMapFs()),
+			)
+			require.ErrorContains(t, updater.Start(), tc.err)
+		})
+	}
+}
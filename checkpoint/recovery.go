@@ -21,6 +21,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/sql/recovery"
+	"github.com/spacemeshos/go-spacemesh/sql/verifiedposts"
 )
 
 const recoveryDir = "recovery"
@@ -173,6 +174,11 @@ func recoverFromLocalFile(
 			log.Int("own atx deps", len(deps)),
 		)
 	}
+	// atxs synced after the checkpoint were verified before, records spare verification of their post
+	verified, err := verifiedposts.All(db)
+	if err != nil {
+		logger.With().Warning("failed to load verified posts", log.Err(err))
+	}
 	if err := db.Close(); err != nil {
 		return nil, fmt.Errorf("close old db: %w", err)
 	}
@@ -219,6 +225,11 @@ func recoverFromLocalFile(
 				catx.SmesherID,
 			)
 		}
+		for _, record := range verified {
+			if err = verifiedposts.Add(tx, record); err != nil {
+				return fmt.Errorf("restore verified post: %w", err)
+			}
+		}
 		if err = recovery.SetCheckpoint(tx, cfg.Restore); err != nil {
 			return fmt.Errorf("save checkppoint info: %w", err)
 		}
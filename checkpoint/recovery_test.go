@@ -32,6 +32,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 	"github.com/spacemeshos/go-spacemesh/sql/poets"
 	"github.com/spacemeshos/go-spacemesh/sql/recovery"
+	"github.com/spacemeshos/go-spacemesh/sql/verifiedposts"
 	smocks "github.com/spacemeshos/go-spacemesh/system/mocks"
 )
 
@@ -157,6 +158,12 @@ func TestRecover(t *testing.T) {
 			bsdir := filepath.Join(cfg.DataDir, bootstrap.DirName)
 			require.NoError(t, fs.MkdirAll(bsdir, 0o700))
 			db := sql.InMemory()
+			verified := verifiedposts.Record{
+				Atx:    types.RandomATXID(),
+				Proof:  types.RandomHash(),
+				Params: types.RandomHash(),
+			}
+			require.NoError(t, verifiedposts.Add(db, verified))
 			preserve, err := checkpoint.RecoverWithDb(ctx, logtest.New(t), db, fs, cfg)
 			if tc.expErr != nil {
 				require.ErrorIs(t, err, tc.expErr)
@@ -169,6 +176,9 @@ func TestRecover(t *testing.T) {
 			require.NotNil(t, newdb)
 			defer newdb.Close()
 			verifyDbContent(t, newdb)
+			has, err := verifiedposts.Has(newdb, verified)
+			require.NoError(t, err)
+			require.True(t, has)
 			restore, err := recovery.CheckpointInfo(newdb)
 			require.NoError(t, err)
 			require.EqualValues(t, recoverLayer, restore)
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/spacemeshos/go-spacemesh/api/grpcserver"
+	"github.com/spacemeshos/go-spacemesh/beacon"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const usage = `Usage:
+	beacon -epoch <epoch> -db <path to state.sql>
+	beacon -epoch <epoch> -address <private grpc listener>
+		load the record of the beacon protocol executed in the epoch
+		and recompute the beacon from the recorded vote margins and weak coin
+`
+
+var (
+	epoch   = flag.Uint("epoch", 0, "epoch when the beacon protocol was executed")
+	dbPath  = flag.String("db", "", "path to the node database")
+	address = flag.String("address", "", "address of the node with beacon in grpc-experimental-services")
+	timeout = flag.Duration("timeout", 10*time.Second, "timeout for the api request")
+	full    = flag.Bool("json", false, "print the whole record in json")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		record *beacon.EpochRecord
+		err    error
+	)
+	switch {
+	case len(*dbPath) > 0:
+		record, err = fromDB(*dbPath, types.EpochID(*epoch))
+	case len(*address) > 0:
+		record, err = fromAPI(*address, types.EpochID(*epoch))
+	default:
+		return fmt.Errorf("either -db or -address is required")
+	}
+	if err != nil {
+		return err
+	}
+	if *full {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	printSummary(record)
+	recomputed, err := beacon.RecomputeBeacon(record)
+	if err != nil {
+		return fmt.Errorf("recompute beacon: %w", err)
+	}
+	fmt.Printf("recomputed beacon: %s\n", recomputed)
+	if record.Beacon != types.EmptyBeacon && recomputed != record.Beacon {
+		return fmt.Errorf("recomputed beacon %s doesn't match recorded %s", recomputed, record.Beacon)
+	}
+	return nil
+}
+
+func fromDB(path string, epoch types.EpochID) (*beacon.EpochRecord, error) {
+	db, err := sql.Open("file:" + path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer db.Close()
+	return beacon.GetRecord(db, epoch)
+}
+
+func fromAPI(address string, epoch types.EpochID) (*beacon.EpochRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", address, err)
+	}
+	defer conn.Close()
+	var record beacon.EpochRecord
+	err = grpcserver.InvokeJSON(ctx, conn, grpcserver.BeaconEpochRecordMethod,
+		&grpcserver.BeaconRecordRequest{Epoch: epoch.Uint32()}, &record)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func printSummary(record *beacon.EpochRecord) {
+	fmt.Printf("epoch: %d\n", record.Epoch)
+	fmt.Printf("epoch weight: %d theta: %s\n", record.EpochWeight, record.Theta)
+	if record.ProposalSent {
+		fmt.Printf("own proposal: %x\n", record.OwnProposal[:])
+	}
+	fmt.Printf("proposals: valid=%d potentially valid=%d\n",
+		len(record.ValidProposals), len(record.PotentiallyValidProposals))
+	started := time.Unix(0, int64(record.Started))
+	fmt.Printf("started: %s proposal phase: %s total: %s\n",
+		started.UTC().Format(time.RFC3339),
+		time.Unix(0, int64(record.ProposalFinished)).Sub(started),
+		time.Unix(0, int64(record.Finished)).Sub(started),
+	)
+	for _, round := range record.Rounds {
+		coin := "-"
+		if round.HasCoin {
+			coin = fmt.Sprint(round.Coin)
+		}
+		fmt.Printf("round %d: margins=%d undecided=%d coin=%s duration=%s\n",
+			round.Round, len(round.Margins), round.Undecided, coin,
+			time.Unix(0, int64(round.Finished)).Sub(time.Unix(0, int64(round.Started))))
+	}
+	if len(record.Failure) > 0 {
+		fmt.Printf("failure: %s\n", record.Failure)
+	}
+	fmt.Printf("recorded beacon: %s\n", record.Beacon)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/beacon/simulation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+const usage = `Usage:
+	beaconsim [flags]
+		run beacon protocol on several nodes with simulated clock and gossip,
+		and report agreement on the beacon and weak coin for every epoch.
+
+		partitions are specified as <start>-<end>:<group>/<group>, where group
+		is a comma separated list of node indexes. for example:
+			-partition 0s-10m:0,1,2/3,4
+`
+
+var (
+	cfg     = simulation.DefaultConfig()
+	verbose = flag.Bool("verbose", false, "print logs of all nodes")
+	q       = flag.String("q", "", "ratio of dishonest spacetime, default from the beacon config")
+	theta   = flag.String("theta", "", "ratio of votes for reaching consensus, default from the beacon config")
+)
+
+func init() {
+	flag.Int64Var(&cfg.Seed, "seed", 0, "seed for the simulated network, random if 0")
+	flag.IntVar(&cfg.Nodes, "nodes", cfg.Nodes, "number of nodes")
+	flag.IntVar(&cfg.Epochs, "epochs", cfg.Epochs, "number of epochs to simulate")
+	flag.Func("units", "space units of every node", func(value string) error {
+		units, err := strconv.ParseUint(value, 10, 32)
+		cfg.Units = uint32(units)
+		return err
+	})
+	cfg.LayersPerEpoch = 3
+	flag.Func("layers-per-epoch", "number of layers in the epoch (default 3)", func(value string) error {
+		layers, err := strconv.ParseUint(value, 10, 32)
+		cfg.LayersPerEpoch = uint32(layers)
+		return err
+	})
+	flag.DurationVar(&cfg.LayerDuration, "layer-duration", 0, "layer duration, fits the protocol if 0")
+	flag.DurationVar(&cfg.Step, "step", 0, "the longest interval of simulated time that passes at once")
+	flag.DurationVar(&cfg.Settle, "settle", cfg.Settle, "real time given to nodes after every step")
+
+	flag.IntVar(&cfg.Beacon.Kappa, "kappa", cfg.Beacon.Kappa, "security parameter")
+	flag.Func("rounds", fmt.Sprintf("number of voting rounds (default %d)", cfg.Beacon.RoundsNumber),
+		func(value string) error {
+			rounds, err := strconv.ParseUint(value, 10, 32)
+			cfg.Beacon.RoundsNumber = types.RoundID(rounds)
+			return err
+		})
+	flag.DurationVar(&cfg.Beacon.GracePeriodDuration, "grace-period",
+		cfg.Beacon.GracePeriodDuration, "grace period duration")
+	flag.DurationVar(&cfg.Beacon.ProposalDuration, "proposal-duration",
+		cfg.Beacon.ProposalDuration, "proposal phase duration")
+	flag.DurationVar(&cfg.Beacon.FirstVotingRoundDuration, "first-voting-round-duration",
+		cfg.Beacon.FirstVotingRoundDuration, "first voting round duration")
+	flag.DurationVar(&cfg.Beacon.VotingRoundDuration, "voting-round-duration",
+		cfg.Beacon.VotingRoundDuration, "voting round duration")
+	flag.DurationVar(&cfg.Beacon.WeakCoinRoundDuration, "weak-coin-round-duration",
+		cfg.Beacon.WeakCoinRoundDuration, "weak coin round duration")
+	flag.Func("votes-limit", fmt.Sprintf("maximum number of votes (default %d)", cfg.Beacon.VotesLimit),
+		func(value string) error {
+			limit, err := strconv.ParseUint(value, 10, 32)
+			cfg.Beacon.VotesLimit = uint32(limit)
+			return err
+		})
+
+	flag.DurationVar(&cfg.Network.Delay, "delay", 0, "minimal delay of the message")
+	flag.DurationVar(&cfg.Network.Jitter, "jitter", 0, "upper bound of the random delay added to the minimal")
+	flag.Float64Var(&cfg.Network.Loss, "loss", 0, "probability that the message is lost")
+	flag.Func("partition", "isolate groups of nodes, can be repeated", func(value string) error {
+		partition, err := parsePartition(value)
+		if err != nil {
+			return err
+		}
+		cfg.Network.Partitions = append(cfg.Network.Partitions, partition)
+		return nil
+	})
+}
+
+func parsePartition(value string) (simulation.Partition, error) {
+	var partition simulation.Partition
+	interval, groups, ok := strings.Cut(value, ":")
+	if !ok {
+		return partition, fmt.Errorf("partition %q: missing groups", value)
+	}
+	start, end, ok := strings.Cut(interval, "-")
+	if !ok {
+		return partition, fmt.Errorf("partition %q: interval should be <start>-<end>", value)
+	}
+	var err error
+	if partition.Start, err = time.ParseDuration(start); err != nil {
+		return partition, fmt.Errorf("partition %q: %w", value, err)
+	}
+	if partition.End, err = time.ParseDuration(end); err != nil {
+		return partition, fmt.Errorf("partition %q: %w", value, err)
+	}
+	for _, group := range strings.Split(groups, "/") {
+		var nodes []int
+		for _, node := range strings.Split(group, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(node))
+			if err != nil {
+				return partition, fmt.Errorf("partition %q: %w", value, err)
+			}
+			nodes = append(nodes, index)
+		}
+		partition.Groups = append(partition.Groups, nodes)
+	}
+	return partition, nil
+}
+
+func parseRat(value string, rat **big.Rat) error {
+	if len(value) == 0 {
+		return nil
+	}
+	parsed, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return fmt.Errorf("invalid ratio %q", value)
+	}
+	*rat = parsed
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if err := parseRat(*q, &cfg.Beacon.Q); err != nil {
+		return err
+	}
+	if err := parseRat(*theta, &cfg.Beacon.Theta); err != nil {
+		return err
+	}
+	var opts []simulation.Opt
+	if *verbose {
+		opts = append(opts, simulation.WithLogger(log.NewDefault("sim")))
+	}
+	sim, err := simulation.New(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	started := time.Now()
+	report, err := sim.Run(ctx)
+	if err != nil {
+		return err
+	}
+	printReport(report, time.Since(started))
+	return nil
+}
+
+func printReport(report *simulation.Report, elapsed time.Duration) {
+	fmt.Printf("seed: %d elapsed: %s\n", report.Seed, elapsed.Round(time.Millisecond))
+	fmt.Printf("messages: sent=%d delivered=%d dropped=%d rejected=%d\n",
+		report.Network.Sent, report.Network.Delivered, report.Network.Dropped, report.Network.Rejected)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "epoch\tagreement\tbeacons\tfailed\tproposals\trounds\tdecided\tcoin agreement")
+	for _, epoch := range report.Epochs {
+		beacons := make([]string, 0, len(epoch.Beacons))
+		for _, beacon := range epoch.Beacons {
+			beacons = append(beacons, fmt.Sprintf("%s:%d", beacon.Beacon, beacon.Nodes))
+		}
+		fmt.Fprintf(w, "%d\t%.2f\t%s\t%d\t%d\t%d\t%d\t%d/%d\n",
+			epoch.Epoch, epoch.Agreement, strings.Join(beacons, " "), epoch.Failed,
+			epoch.Proposals, epoch.Rounds, epoch.Decided, epoch.CoinAgreements, epoch.CoinRounds)
+	}
+	w.Flush()
+}
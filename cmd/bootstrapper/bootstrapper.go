@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/url"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/signing"
 )
 
 const (
@@ -31,7 +33,9 @@ const (
 )
 
 var (
-	bitcoinEndpoint   string
+	bitcoinEndpoints  []string
+	bitcoinQuorum     int
+	signingKey        string
 	spacemeshEndpoint string
 	genBeacon         bool
 	genActiveSet      bool
@@ -46,8 +50,12 @@ var (
 )
 
 func init() {
-	cmd.PersistentFlags().StringVar(&bitcoinEndpoint, "bitcoin-endpoint",
-		"https://api.blockcypher.com/v1/btc/main", "URL to get bitcoin block hash")
+	cmd.PersistentFlags().StringSliceVar(&bitcoinEndpoints, "bitcoin-endpoint",
+		[]string{"https://api.blockcypher.com/v1/btc/main"}, "URLs to get bitcoin block hash")
+	cmd.PersistentFlags().IntVar(&bitcoinQuorum, "bitcoin-quorum",
+		1, "number of bitcoin endpoints that must agree on the block hash")
+	cmd.PersistentFlags().StringVar(&signingKey, "signing-key",
+		"", "path to the hex encoded ed25519 private key to sign updates")
 	cmd.PersistentFlags().StringVar(&spacemeshEndpoint, "spacemesh-endpoint", "", "grpc endpoint for a spacemesh node")
 
 	// options specific to one-time execution
@@ -94,11 +102,18 @@ var cmd = &cobra.Command{
 			return err
 		}
 		logger := log.NewWithLevel("", lvl)
-		g := NewGenerator(
-			bitcoinEndpoint,
-			spacemeshEndpoint,
+		opts := []Opt{
 			WithLogger(logger.WithName("generator")),
-		)
+			WithBitcoinQuorum(bitcoinQuorum),
+		}
+		if len(signingKey) > 0 {
+			signer, err := loadSigner(signingKey)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, WithSigner(signer))
+		}
+		g := NewGenerator(bitcoinEndpoints, spacemeshEndpoint, opts...)
 
 		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 		defer cancel()
@@ -118,8 +133,8 @@ var cmd = &cobra.Command{
 		if !genBeacon && !genActiveSet {
 			return fmt.Errorf("no action specified via --beacon or --actives")
 		}
-		if genBeacon && len(bitcoinEndpoint) == 0 {
-			return fmt.Errorf("missing bitcoin endpoint for beacon generation")
+		if genBeacon && len(bitcoinEndpoints) < bitcoinQuorum {
+			return fmt.Errorf("not enough bitcoin endpoints for beacon generation with quorum %d", bitcoinQuorum)
 		}
 		if genActiveSet && len(spacemeshEndpoint) == 0 {
 			return fmt.Errorf("missing spacemesh endpoint for active set generation")
@@ -136,6 +151,18 @@ var cmd = &cobra.Command{
 	},
 }
 
+func loadSigner(path string) (*signing.EdSigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key %s: %w", path, err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode signing key %s: %w", path, err)
+	}
+	return signing.NewEdSigner(signing.WithPrivateKey(key))
+}
+
 func upload(ctx context.Context, filename, gsBucket, gsPath string) error {
 	r, err := os.Open(filename)
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/bootstrap"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/signing"
 )
 
 const (
@@ -35,11 +36,13 @@ func PersistedFilename(epoch types.EpochID, suffix string) string {
 }
 
 type Generator struct {
-	logger      log.Log
-	fs          afero.Fs
-	client      *http.Client
-	btcEndpoint string
-	smEndpoint  string
+	logger       log.Log
+	fs           afero.Fs
+	client       *http.Client
+	btcEndpoints []string
+	btcQuorum    int
+	smEndpoint   string
+	signer       *signing.EdSigner
 }
 
 type Opt func(*Generator)
@@ -62,13 +65,28 @@ func WithHttpClient(c *http.Client) Opt {
 	}
 }
 
-func NewGenerator(btcEndpoint, smEndpoint string, opts ...Opt) *Generator {
+// WithBitcoinQuorum sets the number of bitcoin endpoints that must agree on the block hash.
+func WithBitcoinQuorum(quorum int) Opt {
+	return func(g *Generator) {
+		g.btcQuorum = quorum
+	}
+}
+
+// WithSigner sets the key that signs generated updates.
+func WithSigner(signer *signing.EdSigner) Opt {
+	return func(g *Generator) {
+		g.signer = signer
+	}
+}
+
+func NewGenerator(btcEndpoints []string, smEndpoint string, opts ...Opt) *Generator {
 	g := &Generator{
-		logger:      log.NewNop(),
-		fs:          afero.NewOsFs(),
-		client:      &http.Client{},
-		btcEndpoint: btcEndpoint,
-		smEndpoint:  smEndpoint,
+		logger:       log.NewNop(),
+		fs:           afero.NewOsFs(),
+		client:       &http.Client{},
+		btcEndpoints: btcEndpoints,
+		btcQuorum:    1,
+		smEndpoint:   smEndpoint,
 	}
 	for _, opt := range opts {
 		opt(g)
@@ -124,7 +142,7 @@ type BitcoinResponse struct {
 func (g *Generator) genBeacon(ctx context.Context, logger log.Log) (types.Beacon, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	br, err := bitcoinHash(ctx, logger, g.client, g.btcEndpoint)
+	br, err := bitcoinHash(ctx, logger, g.client, g.btcEndpoints, g.btcQuorum)
 	if err != nil {
 		return types.EmptyBeacon, err
 	}
@@ -138,27 +156,65 @@ func (g *Generator) genBeacon(ctx context.Context, logger log.Log) (types.Beacon
 	return beacon, nil
 }
 
-func bitcoinHash(ctx context.Context, logger log.Log, client *http.Client, targetUrl string) (*BitcoinResponse, error) {
-	latest, err := queryBitcoin(ctx, client, targetUrl)
-	if err != nil {
-		return nil, err
-	}
-	logger.With().Info("latest bitcoin block height",
-		log.Uint64("height", latest.Height),
-		log.String("hash", latest.Hash),
+// bitcoinHash returns the confirmed block that has the same hash on at least quorum of endpoints.
+// Endpoints may be at different heights, therefore the confirmed height is counted from the
+// lowest latest block.
+func bitcoinHash(ctx context.Context, logger log.Log, client *http.Client, endpoints []string, quorum int) (*BitcoinResponse, error) {
+	var (
+		available []string
+		height    uint64
 	)
-	height := latest.Height - confirmation
+	for _, endpoint := range endpoints {
+		latest, err := queryBitcoin(ctx, client, endpoint)
+		if err != nil {
+			logger.With().Warning("failed to query latest bitcoin block", log.String("endpoint", endpoint), log.Err(err))
+			continue
+		}
+		logger.With().Info("latest bitcoin block height",
+			log.String("endpoint", endpoint),
+			log.Uint64("height", latest.Height),
+			log.String("hash", latest.Hash),
+		)
+		if len(available) == 0 || latest.Height < height {
+			height = latest.Height
+		}
+		available = append(available, endpoint)
+	}
+	if len(available) < quorum {
+		return nil, fmt.Errorf("only %d out of %d bitcoin endpoints are available, quorum %d", len(available), len(endpoints), quorum)
+	}
+	height -= confirmation
 
-	blockUrl := fmt.Sprintf("%s/blocks/%d", targetUrl, height)
-	confirmed, err := queryBitcoin(ctx, client, blockUrl)
-	if err != nil {
-		return nil, err
+	confirmed := map[string]*BitcoinResponse{}
+	agree := map[string][]string{}
+	for _, endpoint := range available {
+		block, err := queryBitcoin(ctx, client, fmt.Sprintf("%s/blocks/%d", endpoint, height))
+		if err != nil {
+			logger.With().Warning("failed to query confirmed bitcoin block", log.String("endpoint", endpoint), log.Err(err))
+			continue
+		}
+		logger.With().Info("confirmed bitcoin block",
+			log.String("endpoint", endpoint),
+			log.Uint64("height", block.Height),
+			log.String("hash", block.Hash),
+		)
+		confirmed[block.Hash] = block
+		agree[block.Hash] = append(agree[block.Hash], endpoint)
 	}
-	logger.With().Info("confirmed bitcoin block",
-		log.Uint64("height", confirmed.Height),
-		log.String("hash", confirmed.Hash),
-	)
-	return confirmed, nil
+	var accepted *BitcoinResponse
+	for hash, endpoints := range agree {
+		if len(endpoints) < quorum {
+			continue
+		}
+		if accepted != nil {
+			return nil, fmt.Errorf("more than one block at height %d reached quorum %d: %v", height, quorum, agree)
+		}
+		accepted = confirmed[hash]
+	}
+	if accepted == nil {
+		return nil, fmt.Errorf("bitcoin endpoints disagree on block at height %d: %v", height, agree)
+	}
+	return accepted, nil
 }
 
 func queryBitcoin(ctx context.Context, client *http.Client, targetUrl string) (*BitcoinResponse, error) {
@@ -231,6 +287,11 @@ func (g *Generator) GenUpdate(epoch types.EpochID, beacon types.Beacon, activeSe
 	update.Data = bootstrap.InnerData{
 		Epoch: edata,
 	}
+	if g.signer != nil {
+		if err := bootstrap.SignUpdate(g.signer, &update); err != nil {
+			return "", fmt.Errorf("sign update: %w", err)
+		}
+	}
 	data, err := json.Marshal(update)
 	if err != nil {
 		return "", fmt.Errorf("marshal data %v: %w", string(data), err)
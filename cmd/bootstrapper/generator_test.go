@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/datastore"
 	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/atxs"
 )
@@ -207,7 +209,7 @@ func TestGenerator_Generate(t *testing.T) {
 
 			fs := afero.NewMemMapFs()
 			g := NewGenerator(
-				ts.URL,
+				[]string{ts.URL},
 				fmt.Sprintf("%s:%d", target, grpcPort),
 				WithLogger(logtest.New(t)),
 				WithFilesystem(fs),
@@ -231,3 +233,76 @@ func TestGenerator_Generate(t *testing.T) {
 		})
 	}
 }
+
+func bitcoinServer(tb testing.TB, confirmed string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(tb, http.MethodGet, r.Method)
+		w.WriteHeader(http.StatusOK)
+		content := bitcoinResponse1
+		if strings.HasSuffix(r.URL.String(), "/blocks/782685") {
+			content = confirmed
+		}
+		_, err := w.Write([]byte(content))
+		require.NoError(tb, err)
+	}))
+	tb.Cleanup(ts.Close)
+	return ts
+}
+
+func TestGenerator_BitcoinQuorum(t *testing.T) {
+	forked := strings.Replace(bitcoinResponse2, "8f8a2576", "8f8a2577", 1)
+	for _, tc := range []struct {
+		desc      string
+		confirmed []string
+		quorum    int
+		err       bool
+	}{
+		{desc: "all agree", confirmed: []string{bitcoinResponse2, bitcoinResponse2, bitcoinResponse2}, quorum: 3},
+		{desc: "majority", confirmed: []string{bitcoinResponse2, forked, bitcoinResponse2}, quorum: 2},
+		{desc: "no quorum", confirmed: []string{bitcoinResponse2, forked, bitcoinResponse2}, quorum: 3, err: true},
+		{desc: "ambiguous", confirmed: []string{bitcoinResponse2, forked}, quorum: 1, err: true},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			var endpoints []string
+			for _, confirmed := range tc.confirmed {
+				endpoints = append(endpoints, bitcoinServer(t, confirmed).URL)
+			}
+			g := NewGenerator(endpoints, "",
+				WithLogger(logtest.New(t)),
+				WithFilesystem(afero.NewMemMapFs()),
+				WithBitcoinQuorum(tc.quorum),
+			)
+			beacon, err := g.genBeacon(context.Background(), logtest.New(t))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, expectedBeacon, hex.EncodeToString(beacon[:]))
+		})
+	}
+}
+
+func TestGenerator_Signed(t *testing.T) {
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	fs := afero.NewMemMapFs()
+	g := NewGenerator(nil, "", WithFilesystem(fs), WithSigner(signer))
+	beacon := types.Beacon{1, 2, 3, 4}
+	persisted, err := g.GenUpdate(3, beacon, nil, bootstrap.SuffixBeacon)
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fs, persisted)
+	require.NoError(t, err)
+	var update bootstrap.Update
+	require.NoError(t, json.Unmarshal(data, &update))
+	require.Equal(t, hex.EncodeToString(signer.PublicKey().Bytes()), update.Signer)
+
+	sig, err := hex.DecodeString(update.Signature)
+	require.NoError(t, err)
+	verifier, err := signing.NewEdVerifier()
+	require.NoError(t, err)
+	digest := bootstrap.Digest(SchemaVersion, &bootstrap.EpochOverride{Epoch: 3, Beacon: beacon})
+	require.True(t, verifier.Verify(signing.BOOTSTRAP, signer.NodeID(), digest[:], types.EdSignature(sig)))
+}
@@ -102,7 +102,7 @@ func TestServer(t *testing.T) {
 
 	fs := afero.NewMemMapFs()
 	g := NewGenerator(
-		"",
+		nil,
 		fmt.Sprintf("%s:%d", target, grpcPort),
 		WithLogger(logtest.New(t)),
 		WithFilesystem(fs),
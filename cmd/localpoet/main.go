@@ -0,0 +1,204 @@
+// Command localpoet runs a PoET service for private networks and integration tests on a single machine.
+//
+// It follows the round schedule of the network preset (or of the schedule flags) and serves the
+// poet api over http and grpc, nodes connect with http://<listen-http> or grpc://<listen-grpc>
+// in poet-server. State is kept in memory, see package localpoet.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	"github.com/spacemeshos/go-spacemesh/config"
+	"github.com/spacemeshos/go-spacemesh/config/presets"
+	"github.com/spacemeshos/go-spacemesh/localpoet"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+var (
+	listenHTTP  string
+	listenGRPC  string
+	preset      string
+	genesisTime string
+	keyPath     string
+	logLevel    string
+	cfg         = localpoet.DefaultConfig()
+)
+
+func init() {
+	cmd.PersistentFlags().StringVar(&listenHTTP, "listen-http", "0.0.0.0:8080", "address for the http api, disabled if empty")
+	cmd.PersistentFlags().StringVar(&listenGRPC, "listen-grpc", "0.0.0.0:50002", "address for the grpc api, disabled if empty")
+	cmd.PersistentFlags().StringVarP(&preset, "preset", "p", "",
+		fmt.Sprintf("network preset for the round schedule. options %+s", presets.Options()))
+	cmd.PersistentFlags().StringVar(&genesisTime, "genesis-time", "", "genesis time in RFC3339 format, overrides preset")
+	cmd.PersistentFlags().DurationVar(&cfg.EpochDuration, "epoch-duration", cfg.EpochDuration, "epoch duration, overrides preset")
+	cmd.PersistentFlags().DurationVar(&cfg.PhaseShift, "phase-shift", cfg.PhaseShift, "phase shift, overrides preset")
+	cmd.PersistentFlags().DurationVar(&cfg.CycleGap, "cycle-gap", cfg.CycleGap, "cycle gap, overrides preset")
+	cmd.PersistentFlags().DurationVar(&cfg.MaxExecution, "max-execution", cfg.MaxExecution,
+		"limit duration of the proof generation, round end if zero")
+	cmd.PersistentFlags().UintVar(&cfg.PowDifficulty, "pow-difficulty", cfg.PowDifficulty, "difficulty of the proof of work for submissions")
+	cmd.PersistentFlags().StringVar(&cfg.DataDir, "datadir", cfg.DataDir, "directory for the proof generation, temporary if empty")
+	cmd.PersistentFlags().StringVar(&keyPath, "key", "",
+		"path to the hex encoded ed25519 seed of the service, created if missing. random key is used if empty")
+	cmd.PersistentFlags().StringVar(&logLevel, "level", "info", "logging level")
+}
+
+var cmd = &cobra.Command{
+	Use:   "localpoet",
+	Short: "run poet service for private networks",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		lvl, err := zap.ParseAtomicLevel(strings.ToLower(logLevel))
+		if err != nil {
+			return err
+		}
+		logger := log.NewWithLevel("poet", lvl).Zap()
+
+		if preset != "" {
+			if err := applyPreset(cmd, preset); err != nil {
+				return err
+			}
+		}
+		if genesisTime != "" {
+			cfg.Genesis, err = time.Parse(time.RFC3339, genesisTime)
+			if err != nil {
+				return fmt.Errorf("parse genesis time: %w", err)
+			}
+		}
+		if listenHTTP == "" && listenGRPC == "" {
+			return errors.New("either http or grpc listener must be enabled")
+		}
+		opts := []localpoet.Opt{localpoet.WithLogger(logger)}
+		if keyPath != "" {
+			key, err := loadKey(keyPath)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, localpoet.WithKey(key))
+		}
+		svc, err := localpoet.New(cfg, opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		eg, ctx := errgroup.WithContext(ctx)
+		eg.Go(func() error {
+			return svc.Run(ctx)
+		})
+		if listenGRPC != "" {
+			lis, err := net.Listen("tcp", listenGRPC)
+			if err != nil {
+				return err
+			}
+			srv := grpc.NewServer()
+			rpcapi.RegisterPoetServiceServer(srv, svc)
+			eg.Go(func() error {
+				logger.Info("serving grpc api", zap.Stringer("address", lis.Addr()))
+				return srv.Serve(lis)
+			})
+			eg.Go(func() error {
+				<-ctx.Done()
+				srv.Stop()
+				return nil
+			})
+		}
+		if listenHTTP != "" {
+			handler, err := svc.HTTPHandler(ctx)
+			if err != nil {
+				return err
+			}
+			lis, err := net.Listen("tcp", listenHTTP)
+			if err != nil {
+				return err
+			}
+			srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+			eg.Go(func() error {
+				logger.Info("serving http api", zap.Stringer("address", lis.Addr()))
+				if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			})
+			eg.Go(func() error {
+				<-ctx.Done()
+				return srv.Close()
+			})
+		}
+		return eg.Wait()
+	},
+}
+
+// applyPreset sets the schedule of the network, flags that were set explicitly are not changed.
+func applyPreset(cmd *cobra.Command, name string) error {
+	var (
+		network config.Config
+		err     error
+	)
+	if name == "mainnet" {
+		network = config.MainnetConfig()
+	} else if network, err = presets.Get(name); err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("genesis-time") {
+		genesisTime = network.Genesis.GenesisTime
+	}
+	if !cmd.Flags().Changed("epoch-duration") {
+		cfg.EpochDuration = network.LayerDuration * time.Duration(network.LayersPerEpoch)
+	}
+	if !cmd.Flags().Changed("phase-shift") {
+		cfg.PhaseShift = network.POET.PhaseShift
+	}
+	if !cmd.Flags().Changed("cycle-gap") {
+		cfg.CycleGap = network.POET.CycleGap
+	}
+	return nil
+}
+
+// loadKey reads the seed of the key, new seed is generated and saved if file doesn't exist.
+func loadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Seed())), 0o600); err != nil {
+			return nil, fmt.Errorf("save key: %w", err)
+		}
+		return key, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+// Command postcheck checks integrity of the post data and re-initializes corrupted ranges.
+//
+// It samples labels from the data, compares them with the labels computed for the commitment
+// from the metadata and reports ranges that don't match. Truncated and missing files are always
+// reported. The node must not use the data while it is repaired.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/config"
+	"github.com/spacemeshos/go-spacemesh/config/presets"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+var (
+	setup    = activation.DefaultPostSetupOpts()
+	check    = activation.PostCheckOpts{Fraction: 1}
+	preset   string
+	interval time.Duration
+	logLevel string
+)
+
+func init() {
+	cmd.PersistentFlags().StringVarP(&setup.DataDir, "datadir", "d", setup.DataDir, "directory with the post data")
+	cmd.PersistentFlags().StringVarP(&preset, "preset", "p", "",
+		fmt.Sprintf("network preset for scrypt parameters, mainnet if empty. options %+s", presets.Options()))
+	cmd.PersistentFlags().Var(&setup.ProviderID, "provider", "id of the provider that computes labels, cpu if not set")
+	cmd.PersistentFlags().Float64Var(&check.Fraction, "fraction", check.Fraction,
+		"percent of the labels that are checked")
+	cmd.PersistentFlags().BoolVar(&check.Repair, "repair", false, "re-initialize corrupted ranges")
+	cmd.PersistentFlags().DurationVar(&interval, "interval", 10*time.Second, "interval for reporting progress")
+	cmd.PersistentFlags().StringVar(&logLevel, "level", "info", "logging level")
+}
+
+var cmd = &cobra.Command{
+	Use:   "postcheck",
+	Short: "check integrity of the post data",
+	RunE: func(*cobra.Command, []string) error {
+		lvl, err := zap.ParseAtomicLevel(strings.ToLower(logLevel))
+		if err != nil {
+			return err
+		}
+		logger := log.NewWithLevel("postcheck", lvl)
+
+		cfg := config.MainnetConfig()
+		if preset != "" {
+			cfg, err = presets.Get(preset)
+			if err != nil {
+				return err
+			}
+		}
+		setup.Scrypt = cfg.SMESHING.Opts.Scrypt
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		checker := activation.NewPostChecker(logger)
+		if err := checker.Start(setup, check); err != nil {
+			return err
+		}
+		stop := context.AfterFunc(ctx, checker.Stop)
+		defer stop()
+		poll := time.NewTicker(100 * time.Millisecond)
+		defer poll.Stop()
+		reported := time.Now()
+		status := checker.Status()
+		for status.State == activation.PostCheckStateInProgress {
+			if time.Since(reported) >= interval {
+				reported = time.Now()
+				fmt.Printf("checked %d/%d labels, found %d corrupted ranges, repaired %d labels\n",
+					status.CheckedLabels, status.SampledLabels, len(status.Corrupted), status.RepairedLabels)
+			}
+			<-poll.C
+			status = checker.Status()
+		}
+
+		fmt.Printf("%s: checked %d labels in %s\n", status.State, status.CheckedLabels, status.DataDir)
+		for _, r := range status.Corrupted {
+			reason := "corrupted"
+			if r.Truncated {
+				reason = "missing"
+			}
+			fmt.Printf("file %d: labels [%d, %d] %s, repaired: %v\n", r.File, r.From, r.To, reason, r.Repaired)
+		}
+		if status.Err != nil {
+			return status.Err
+		}
+		if !status.Healthy() {
+			return fmt.Errorf("found %d corrupted ranges, run with --repair to re-initialize them",
+				len(status.Corrupted))
+		}
+		return nil
+	},
+}
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
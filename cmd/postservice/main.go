@@ -0,0 +1,185 @@
+// Command postservice owns post data of a single node and generates proofs on request of the node.
+//
+// It allows to keep post data on a different machine than the node. The node connects
+// to the service if smeshing-remote-post-address is set, see grpcserver.PostClientConfig.
+// Data is initialized (or initialization is resumed) when the service starts.
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/api/grpcserver"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/config"
+	"github.com/spacemeshos/go-spacemesh/config/presets"
+	"github.com/spacemeshos/go-spacemesh/datastore"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+var (
+	listen        string
+	preset        string
+	nodeID        string
+	commitmentAtx string
+	token         string
+	tlsCert       string
+	tlsKey        string
+	logLevel      string
+	opts          = activation.DefaultPostSetupOpts()
+)
+
+func init() {
+	cmd.PersistentFlags().StringVar(&listen, "listen", "127.0.0.1:9094",
+		"address to listen for requests from the node, tls is required unless it is a loopback address")
+	cmd.PersistentFlags().StringVarP(&preset, "preset", "p", "",
+		fmt.Sprintf("network preset for post parameters, mainnet if empty. options %+s", presets.Options()))
+	cmd.PersistentFlags().StringVar(&nodeID, "node-id", "", "hex encoded id of the node that owns the data")
+	cmd.PersistentFlags().StringVar(&commitmentAtx, "commitment-atx", "",
+		"hex encoded commitment atx, required to initialize new data")
+	cmd.PersistentFlags().StringVar(&token, "token", "",
+		"token that the node sends with every request, it can also be set in POST_SERVICE_TOKEN")
+	cmd.PersistentFlags().StringVar(&tlsCert, "tls-cert", "", "path to the tls certificate of the service")
+	cmd.PersistentFlags().StringVar(&tlsKey, "tls-key", "", "path to the tls key of the service")
+	cmd.PersistentFlags().StringVar(&logLevel, "level", "info", "logging level")
+
+	cmd.PersistentFlags().StringVar(&opts.DataDir, "datadir", opts.DataDir, "post data directory")
+	cmd.PersistentFlags().Uint32Var(&opts.NumUnits, "numunits", opts.NumUnits, "number of units of post data")
+	cmd.PersistentFlags().Uint64Var(&opts.MaxFileSize, "maxfilesize", opts.MaxFileSize, "max size of a single data file")
+	cmd.PersistentFlags().Var(&opts.ProviderID, "provider", "id of the provider used to initialize data")
+}
+
+var cmd = &cobra.Command{
+	Use:   "postservice",
+	Short: "generate post proofs for the node",
+	RunE: func(*cobra.Command, []string) error {
+		lvl, err := zap.ParseAtomicLevel(strings.ToLower(logLevel))
+		if err != nil {
+			return err
+		}
+		logger := log.NewWithLevel("post", lvl)
+
+		cfg := config.MainnetConfig()
+		if preset != "" {
+			cfg, err = presets.Get(preset)
+			if err != nil {
+				return err
+			}
+		}
+		var id types.NodeID
+		if err := decodeHex(nodeID, id[:]); err != nil {
+			return fmt.Errorf("node id: %w", err)
+		}
+		var commitment types.ATXID
+		if commitmentAtx != "" {
+			if err := decodeHex(commitmentAtx, commitment[:]); err != nil {
+				return fmt.Errorf("commitment atx: %w", err)
+			}
+		}
+		if token == "" {
+			token = os.Getenv("POST_SERVICE_TOKEN")
+		}
+		if token == "" {
+			return errors.New("token must be set, otherwise anyone can request proofs")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		// commitment atx is loaded from the metadata of the initialized data,
+		// for the new data it is used as golden atx when the database has no atxs.
+		db := datastore.NewCachedDB(sql.InMemory(), logger)
+		mgr, err := activation.NewPostSetupManager(id, cfg.POST, logger, db, commitment, cfg.SMESHING.ProvingOpts)
+		if err != nil {
+			return err
+		}
+		if err := mgr.PrepareInitializer(ctx, opts); err != nil {
+			return fmt.Errorf("prepare post data: %w", err)
+		}
+		committed, err := mgr.CommitmentAtx()
+		if err != nil {
+			return err
+		}
+		if committed == types.EmptyATXID {
+			return errors.New("commitment atx must be set to initialize new data")
+		}
+
+		srvopts := []grpc.ServerOption{grpc.UnaryInterceptor(grpcserver.PostTokenAuth(token))}
+		if tlsCert != "" || tlsKey != "" {
+			creds, err := credentials.NewServerTLSFromFile(tlsCert, tlsKey)
+			if err != nil {
+				return fmt.Errorf("load tls certificate: %w", err)
+			}
+			srvopts = append(srvopts, grpc.Creds(creds))
+		} else if !grpcserver.IsLoopback(listen) {
+			return fmt.Errorf("tls is required to listen on non-loopback address %s", listen)
+		}
+		// proofs may take hours, therefore server doesn't use grpcserver.ServerOptions
+		// that limit connection age
+		srv := &grpcserver.Server{Listener: listen, GrpcServer: grpc.NewServer(srvopts...)}
+		grpcserver.NewPostService(id, cfg.POST, mgr).RegisterService(srv)
+		lis, err := net.Listen("tcp", listen)
+		if err != nil {
+			return err
+		}
+
+		eg, ctx := errgroup.WithContext(ctx)
+		eg.Go(func() error {
+			err := mgr.StartSession(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return fmt.Errorf("initialize post data: %w", err)
+			}
+			return nil
+		})
+		eg.Go(func() error {
+			logger.With().Info("serving post data",
+				id,
+				log.Stringer("commitment_atx", committed),
+				log.String("address", lis.Addr().String()),
+				log.String("datadir", opts.DataDir),
+				log.Uint32("numunits", opts.NumUnits),
+			)
+			return srv.GrpcServer.Serve(lis)
+		})
+		eg.Go(func() error {
+			<-ctx.Done()
+			srv.GrpcServer.Stop()
+			return nil
+		})
+		return eg.Wait()
+	},
+}
+
+func decodeHex(value string, dst []byte) error {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(dst) {
+		return fmt.Errorf("expected %d bytes, got %d", len(dst), len(decoded))
+	}
+	copy(dst, decoded)
+	return nil
+}
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
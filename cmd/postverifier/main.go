@@ -0,0 +1,142 @@
+// Command postverifier verifies post proofs on request of the node.
+//
+// It allows to borrow CPU of other machines to verify atxs while the node syncs. The node sends
+// proofs to the verifiers listed in smeshing-remote-verifiers-addresses, see
+// grpcserver.PostVerifierClientConfig, and verifies proofs locally if verifiers are unavailable.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spacemeshos/post/verifying"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/api/grpcserver"
+	"github.com/spacemeshos/go-spacemesh/config"
+	"github.com/spacemeshos/go-spacemesh/config/presets"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+var (
+	listen   string
+	preset   string
+	workers  int
+	token    string
+	tlsCert  string
+	tlsKey   string
+	logLevel string
+)
+
+func init() {
+	cmd.PersistentFlags().StringVar(&listen, "listen", "127.0.0.1:9095",
+		"address to listen for requests from the node, tls is required unless it is a loopback address")
+	cmd.PersistentFlags().StringVarP(&preset, "preset", "p", "",
+		fmt.Sprintf("network preset for post parameters, mainnet if empty. options %+s", presets.Options()))
+	cmd.PersistentFlags().IntVar(&workers, "workers", activation.DefaultPostVerifyingOpts().Workers,
+		"number of proofs verified at the same time")
+	cmd.PersistentFlags().StringVar(&token, "token", "",
+		"token that the node sends with every request, it can also be set in POST_VERIFIER_TOKEN")
+	cmd.PersistentFlags().StringVar(&tlsCert, "tls-cert", "", "path to the tls certificate of the service")
+	cmd.PersistentFlags().StringVar(&tlsKey, "tls-key", "", "path to the tls key of the service")
+	cmd.PersistentFlags().StringVar(&logLevel, "level", "info", "logging level")
+}
+
+var cmd = &cobra.Command{
+	Use:   "postverifier",
+	Short: "verify post proofs for the node",
+	RunE: func(*cobra.Command, []string) error {
+		lvl, err := zap.ParseAtomicLevel(strings.ToLower(logLevel))
+		if err != nil {
+			return err
+		}
+		logger := log.NewWithLevel("postverifier", lvl)
+
+		cfg := config.MainnetConfig()
+		if preset != "" {
+			cfg, err = presets.Get(preset)
+			if err != nil {
+				return err
+			}
+		}
+		if workers < 1 {
+			return fmt.Errorf("workers must be positive, got %d", workers)
+		}
+		if token == "" {
+			token = os.Getenv("POST_VERIFIER_TOKEN")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		local, err := activation.NewPostVerifier(cfg.POST, logger,
+			verifying.WithPowFlags(cfg.SMESHING.VerifyingOpts.Flags))
+		if err != nil {
+			return err
+		}
+		verifiers := make([]activation.PostVerifier, 0, workers)
+		for i := 0; i < workers; i++ {
+			verifiers = append(verifiers, local)
+		}
+		verifier := activation.NewOffloadingPostVerifier(verifiers, logger)
+		defer verifier.Close()
+
+		var srvopts []grpc.ServerOption
+		if token != "" {
+			srvopts = append(srvopts, grpc.UnaryInterceptor(grpcserver.PostTokenAuth(token)))
+		} else {
+			logger.Warning("token is not set, anyone who can reach the service can use it to verify proofs")
+		}
+		if tlsCert == "" && tlsKey == "" && !grpcserver.IsLoopback(listen) {
+			return fmt.Errorf("tls is required to listen on non-loopback address %s", listen)
+		}
+		if tlsCert != "" || tlsKey != "" {
+			creds, err := credentials.NewServerTLSFromFile(tlsCert, tlsKey)
+			if err != nil {
+				return fmt.Errorf("load tls certificate: %w", err)
+			}
+			srvopts = append(srvopts, grpc.Creds(creds))
+		}
+		srv := &grpcserver.Server{
+			Listener:   listen,
+			GrpcServer: grpc.NewServer(append(srvopts, grpcserver.ServerOptions...)...),
+		}
+		grpcserver.NewPostVerifierService(verifier, cfg.POST).RegisterService(srv)
+		lis, err := net.Listen("tcp", listen)
+		if err != nil {
+			return err
+		}
+
+		eg, ctx := errgroup.WithContext(ctx)
+		eg.Go(func() error {
+			logger.With().Info("serving post verifier",
+				log.String("address", lis.Addr().String()),
+				log.Int("workers", workers),
+			)
+			return srv.GrpcServer.Serve(lis)
+		})
+		eg.Go(func() error {
+			<-ctx.Done()
+			srv.GrpcServer.Stop()
+			return nil
+		})
+		return eg.Wait()
+	},
+}
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -143,6 +143,8 @@ func AddCommands(cmd *cobra.Command) {
 		cfg.API.PrivateServices, "List of services that must be kept private or exposed only in secure environments.")
 	cmd.PersistentFlags().StringVar(&cfg.API.PrivateListener, "grpc-private-listener",
 		cfg.API.PrivateListener, "Socket for the list of services specified in grpc-private-services.")
+	cmd.PersistentFlags().StringSliceVar(&cfg.API.ExperimentalServices, "grpc-experimental-services",
+		cfg.API.ExperimentalServices, "List of services with experimental json encoded methods that are served on grpc-private-listener.")
 	cmd.PersistentFlags().IntVar(&cfg.API.GrpcRecvMsgSize, "grpc-recv-msg-size",
 		cfg.API.GrpcRecvMsgSize, "GRPC api recv message size")
 	cmd.PersistentFlags().IntVar(&cfg.API.GrpcSendMsgSize, "grpc-send-msg-size",
@@ -243,6 +245,14 @@ func AddCommands(cmd *cobra.Command) {
 		"", "")
 	cmd.PersistentFlags().BoolVar(&cfg.SMESHING.Opts.Throttle, "smeshing-opts-throttle",
 		cfg.SMESHING.Opts.Throttle, "")
+	cmd.PersistentFlags().Uint32Var(&cfg.SMESHING.EventsRetention, "smeshing-events-retention",
+		cfg.SMESHING.EventsRetention, "number of epochs smeshing events are kept in the database, 0 keeps them forever")
+	cmd.PersistentFlags().BoolVar(&cfg.SMESHING.VerifyingOpts.Reverify, "smeshing-opts-verifying-reverify",
+		cfg.SMESHING.VerifyingOpts.Reverify, "verify post of atxs even if it was verified before with the same parameters")
+	cmd.PersistentFlags().StringSliceVar(&cfg.SMESHING.RemoteVerifiers.Addresses, "smeshing-remote-verifiers-addresses",
+		cfg.SMESHING.RemoteVerifiers.Addresses, "addresses of the post verifiers (cmd/postverifier) that verify proofs of atxs")
+	cmd.PersistentFlags().StringVar(&cfg.SMESHING.RemoteVerifiers.Token, "smeshing-remote-verifiers-token",
+		cfg.SMESHING.RemoteVerifiers.Token, "token that is sent to the post verifiers")
 
 	/**======================== Consensus Flags ========================== **/
 
@@ -257,12 +267,20 @@ func AddCommands(cmd *cobra.Command) {
 		cfg.POET.CycleGap, "cycle gap of poet server")
 	cmd.PersistentFlags().DurationVar(&cfg.POET.GracePeriod, "grace-period",
 		cfg.POET.GracePeriod, "propagation time for ATXs in the network")
+	cmd.PersistentFlags().IntVar(&cfg.POET.MaxMissedProofs, "poet-max-missed-proofs",
+		cfg.POET.MaxMissedProofs, "deprioritise poet after this number of consecutive rounds without proof")
 
 	/**======================== bootstrap data updater Flags ========================== **/
 	cmd.PersistentFlags().StringVar(&cfg.Bootstrap.URL, "bootstrap-url",
 		cfg.Bootstrap.URL, "the url to query bootstrap data update")
 	cmd.PersistentFlags().StringVar(&cfg.Bootstrap.Version, "bootstrap-version",
 		cfg.Bootstrap.Version, "the update version of the bootstrap data")
+	cmd.PersistentFlags().StringSliceVar(&cfg.Bootstrap.Sources, "bootstrap-sources",
+		cfg.Bootstrap.Sources, "additional urls to query bootstrap data update")
+	cmd.PersistentFlags().IntVar(&cfg.Bootstrap.Quorum, "bootstrap-quorum",
+		cfg.Bootstrap.Quorum, "number of bootstrap sources that must serve identical update")
+	cmd.PersistentFlags().StringSliceVar(&cfg.Bootstrap.TrustedKeys, "bootstrap-trusted-keys",
+		cfg.Bootstrap.TrustedKeys, "hex encoded public keys allowed to sign bootstrap updates")
 
 	/**======================== testing related flags ========================== **/
 	cmd.PersistentFlags().StringVar(&cfg.TestConfig.SmesherKey, "testing-smesher-key",
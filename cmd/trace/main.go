@@ -2,7 +2,12 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,21 +16,206 @@ import (
 	"github.com/spacemeshos/go-spacemesh/tortoise"
 )
 
+const usage = `Usage:
+	trace [flags] <trace>
+		replay trace and assert recorded outputs
+	trace diff [flags] <trace>
+		replay trace with recorded config and with config overwritten by flags,
+		and print the first divergent results
+	trace diff -recorded <left> <right>
+		compare results recorded in two traces, for example by two builds using record command
+	trace record [flags] -o <output> <trace>
+		replay trace without asserting outputs and record a new trace with outputs of this build
+	trace minimize [flags] -o <output> <trace>
+		shrink failing trace to the fewest events that still reproduce the failure
+	trace summary <trace>
+		print per-layer inputs and the last recorded decisions
+`
+
 var (
 	level  = zap.LevelFlag("level", zapcore.ErrorLevel, "set verbosity level for execution")
 	bpoint = flag.Bool("breakpoint", false, "enable breakpoint after every step")
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 	atom := zap.NewAtomicLevelAt(*level)
 	logger := log.NewWithLevel("trace", atom)
-	logger.With().Debug("using trace", log.String("path", flag.Arg(0)))
-	var breakpoint func()
-	if *bpoint {
-		breakpoint = runtime.Breakpoint
+	var err error
+	switch flag.Arg(0) {
+	case "diff":
+		err = diff(logger, flag.Args()[1:])
+	case "record":
+		err = record(logger, flag.Args()[1:])
+	case "minimize":
+		err = minimize(logger, flag.Args()[1:])
+	case "summary":
+		err = summary(flag.Args()[1:])
+	default:
+		logger.With().Debug("using trace", log.String("path", flag.Arg(0)))
+		var breakpoint func()
+		if *bpoint {
+			breakpoint = runtime.Breakpoint
+		}
+		err = tortoise.RunTrace(flag.Arg(0), breakpoint, tortoise.WithLogger(logger))
 	}
-	if err := tortoise.RunTrace(flag.Arg(0), breakpoint, tortoise.WithLogger(logger)); err != nil {
+	if err != nil {
 		logger.With().Fatal("run trace failed", log.Err(err))
 	}
 }
+
+type overrides struct {
+	hdist, zdist, window, exceptions, delay, layerSize uint
+}
+
+func (o *overrides) register(fs *flag.FlagSet) {
+	fs.UintVar(&o.hdist, "hdist", 0, "override hdist")
+	fs.UintVar(&o.zdist, "zdist", 0, "override zdist")
+	fs.UintVar(&o.window, "window", 0, "override window size")
+	fs.UintVar(&o.exceptions, "exceptions", 0, "override max exceptions")
+	fs.UintVar(&o.delay, "delay", 0, "override bad beacon vote delay layers")
+	fs.UintVar(&o.layerSize, "layer-size", 0, "override layer size")
+}
+
+func (o *overrides) override() tortoise.ConfigOverride {
+	return func(cfg *tortoise.Config) {
+		if o.hdist != 0 {
+			cfg.Hdist = uint32(o.hdist)
+		}
+		if o.zdist != 0 {
+			cfg.Zdist = uint32(o.zdist)
+		}
+		if o.window != 0 {
+			cfg.WindowSize = uint32(o.window)
+		}
+		if o.exceptions != 0 {
+			cfg.MaxExceptions = int(o.exceptions)
+		}
+		if o.delay != 0 {
+			cfg.BadBeaconVoteDelayLayers = uint32(o.delay)
+		}
+		if o.layerSize != 0 {
+			cfg.LayerSize = uint32(o.layerSize)
+		}
+	}
+}
+
+func diff(logger log.Log, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	recorded := fs.Bool("recorded", false, "compare results recorded in two traces")
+	var over overrides
+	over.register(fs)
+	fs.Parse(args)
+
+	var (
+		div *tortoise.TraceDivergence
+		err error
+	)
+	if *recorded {
+		if fs.NArg() != 2 {
+			return fmt.Errorf("expected two traces, got %d", fs.NArg())
+		}
+		div, err = tortoise.DiffRecordedTraces(fs.Arg(0), fs.Arg(1))
+	} else {
+		div, err = tortoise.DiffTrace(fs.Arg(0), nil, over.override(), tortoise.WithLogger(logger))
+	}
+	if err != nil {
+		return err
+	}
+	if div == nil {
+		fmt.Println("no divergence")
+		return nil
+	}
+	name := "results"
+	if div.Updates {
+		name = "updates"
+	}
+	fmt.Printf("first divergence in %s at event %d for range [%d, %d], layer %d\n",
+		name, div.Event, div.From, div.To, div.Layer)
+	if div.LeftError != div.RightError {
+		fmt.Printf("errors: %q != %q\n", div.LeftError, div.RightError)
+	}
+	fmt.Println(div.Diff)
+	return nil
+}
+
+func record(logger log.Log, args []string) error {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	out := fs.String("o", "", "path for the recorded trace")
+	var over overrides
+	over.register(fs)
+	fs.Parse(args)
+	if len(*out) == 0 {
+		return fmt.Errorf("output path is required")
+	}
+	return tortoise.RecordTrace(fs.Arg(0), *out, over.override(), tortoise.WithLogger(logger))
+}
+
+func minimize(logger log.Log, args []string) error {
+	fs := flag.NewFlagSet("minimize", flag.ExitOnError)
+	out := fs.String("o", "", "path for the minimized trace")
+	fs.Parse(args)
+	if len(*out) == 0 {
+		return fmt.Errorf("output path is required")
+	}
+	rst, err := tortoise.MinimizeTrace(fs.Arg(0), *out, tortoise.WithLogger(logger))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("minimized trace from %d to %d events\n", rst.Original, rst.Minimized)
+	fmt.Printf("failure: %v\n", rst.Err)
+	return nil
+}
+
+func summary(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one trace, got %d", len(args))
+	}
+	rst, err := tortoise.SummarizeTrace(args[0])
+	if err != nil {
+		return err
+	}
+	if rst.Config != nil {
+		fmt.Printf("config: hdist=%d zdist=%d window=%d exceptions=%d delay=%d layer-size=%d epoch-size=%d genesis=%d\n",
+			rst.Config.Hdist, rst.Config.Zdist, rst.Config.WindowSize, rst.Config.MaxExceptions,
+			rst.Config.BadBeaconVoteDelayLayers, rst.Config.LayerSize, rst.Config.EpochSize, rst.Config.EffectiveGenesis)
+	}
+	fmt.Printf("events: %v\n", rst.Events)
+	fmt.Printf("atxs: %v\n", rst.Atxs)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "layer\tballots\tblocks\thare\tcoin\tverified\topinion\tdecisions")
+	for _, layer := range rst.Layers {
+		hare := "-"
+		if layer.Hare != nil {
+			hare = layer.Hare.String()
+		}
+		coin := "-"
+		if layer.Coin != nil {
+			coin = strconv.FormatBool(*layer.Coin)
+		}
+		decisions := make([]string, 0, len(layer.Decisions))
+		for _, block := range layer.Decisions {
+			decisions = append(decisions, fmt.Sprintf("%s:%s", block.Header.ID, decision(block.Valid, block.Invalid, block.Hare)))
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%t\t%s\t%s\n",
+			layer.Layer, layer.Ballots, layer.Blocks, hare, coin,
+			layer.Verified, layer.Opinion.ShortString(), strings.Join(decisions, " "))
+	}
+	return w.Flush()
+}
+
+func decision(valid, invalid, hare bool) string {
+	switch {
+	case valid:
+		return "valid"
+	case invalid:
+		return "invalid"
+	case hare:
+		return "hare"
+	}
+	return "undecided"
+}
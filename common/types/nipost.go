@@ -1,6 +1,6 @@
 package types
 
-//go:generate scalegen -types NIPostBuilderState,PoetRequest,PoetServiceID
+//go:generate scalegen -types NIPostBuilderState,PoetRequest,PoetServiceID,ATXPublishState
 
 type PoetServiceID struct {
 	ServiceID []byte `scale:"max=32"` // public key of the PoET service
@@ -25,3 +25,14 @@ type NIPostBuilderState struct {
 	// PoetProofRef is the root of the proof received from the PoET service.
 	PoetProofRef PoetProofRef
 }
+
+// ATXPublishState is the progress of the atx publication for the publish epoch.
+type ATXPublishState struct {
+	PublishEpoch EpochID
+	// Phase of the publication, see activation.PublishPhase.
+	Phase uint8
+	// Attempts is the number of failed attempts in the phase.
+	Attempts uint32
+	// LastError is the error of the last failed attempt.
+	LastError string `scale:"max=1024"`
+}
@@ -40,9 +40,20 @@ func (h *TxHeader) MarshalLogObject(encoder log.ObjectEncoder) error {
 }
 
 // LayerLimits if defined restricts in what layers transaction may be applied.
+// Zero Max means that transaction doesn't expire.
 type LayerLimits struct {
 	Min, Max uint32
 }
 
+// Contain returns true if transaction with these limits may be applied in the layer.
+func (l LayerLimits) Contain(lid LayerID) bool {
+	return lid.Uint32() >= l.Min && !l.Expired(lid)
+}
+
+// Expired returns true if transaction can't be applied in the layer or any later layer.
+func (l LayerLimits) Expired(lid LayerID) bool {
+	return l.Max != 0 && lid.Uint32() > l.Max
+}
+
 // Nonce alias to uint64.
 type Nonce = uint64
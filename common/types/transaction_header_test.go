@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/spacemeshos/go-scale/tester"
+	"github.com/stretchr/testify/require"
 )
 
 func FuzzTxHeaderConsistency(f *testing.F) {
@@ -21,3 +22,24 @@ func FuzzLayerLimitsConsistency(f *testing.F) {
 func FuzzLayerLimitsSafety(f *testing.F) {
 	tester.FuzzSafety[LayerLimits](f)
 }
+
+func TestLayerLimits(t *testing.T) {
+	for _, tc := range []struct {
+		desc             string
+		limits           LayerLimits
+		lid              LayerID
+		contain, expired bool
+	}{
+		{desc: "unbounded", lid: 100, contain: true},
+		{desc: "before min", limits: LayerLimits{Min: 10}, lid: 9},
+		{desc: "min", limits: LayerLimits{Min: 10}, lid: 10, contain: true},
+		{desc: "max", limits: LayerLimits{Min: 10, Max: 20}, lid: 20, contain: true},
+		{desc: "after max", limits: LayerLimits{Min: 10, Max: 20}, lid: 21, expired: true},
+		{desc: "only max", limits: LayerLimits{Max: 20}, lid: 1, contain: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.contain, tc.limits.Contain(tc.lid))
+			require.Equal(t, tc.expired, tc.limits.Expired(tc.lid))
+		})
+	}
+}
@@ -129,11 +129,15 @@ type PublicMetrics struct {
 
 // SmeshingConfig defines configuration for the node's smeshing (mining).
 type SmeshingConfig struct {
-	Start           bool                              `mapstructure:"smeshing-start"`
-	CoinbaseAccount string                            `mapstructure:"smeshing-coinbase"`
-	Opts            activation.PostSetupOpts          `mapstructure:"smeshing-opts"`
-	ProvingOpts     activation.PostProvingOpts        `mapstructure:"smeshing-proving-opts"`
-	VerifyingOpts   activation.PostProofVerifyingOpts `mapstructure:"smeshing-verifying-opts"`
+	Start           bool                                `mapstructure:"smeshing-start"`
+	CoinbaseAccount string                              `mapstructure:"smeshing-coinbase"`
+	Opts            activation.PostSetupOpts            `mapstructure:"smeshing-opts"`
+	ProvingOpts     activation.PostProvingOpts          `mapstructure:"smeshing-proving-opts"`
+	VerifyingOpts   activation.PostProofVerifyingOpts   `mapstructure:"smeshing-verifying-opts"`
+	RemotePost      grpcserver.PostClientConfig         `mapstructure:"smeshing-remote-post"`
+	RemoteVerifiers grpcserver.PostVerifierClientConfig `mapstructure:"smeshing-remote-verifiers"`
+	// EventsRetention is the number of epochs smeshing events are kept in the database, 0 keeps them forever.
+	EventsRetention uint32 `mapstructure:"smeshing-events-retention"`
 }
 
 // DefaultConfig returns the default configuration for a spacemesh node.
@@ -199,6 +203,9 @@ func DefaultSmeshingConfig() SmeshingConfig {
 		Opts:            activation.DefaultPostSetupOpts(),
 		ProvingOpts:     activation.DefaultPostProvingOpts(),
 		VerifyingOpts:   activation.DefaultPostVerifyingOpts(),
+		RemotePost:      grpcserver.DefaultPostClientConfig(),
+		RemoteVerifiers: grpcserver.DefaultPostVerifierClientConfig(),
+		EventsRetention: 30,
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/checkpoint"
 	"github.com/spacemeshos/go-spacemesh/datastore"
 	"github.com/spacemeshos/go-spacemesh/fetch"
+	vm "github.com/spacemeshos/go-spacemesh/genvm"
 	hareConfig "github.com/spacemeshos/go-spacemesh/hare/config"
 	eligConfig "github.com/spacemeshos/go-spacemesh/hare/eligibility/config"
 	"github.com/spacemeshos/go-spacemesh/p2p"
@@ -110,6 +111,7 @@ func MainnetConfig() Config {
 			GracePeriod:       1 * time.Hour,
 			RequestRetryDelay: 10 * time.Second,
 			MaxRequestRetries: 10,
+			MaxMissedProofs:   2,
 		},
 		POST: activation.PostConfig{
 			MinNumUnits:   4,
@@ -125,6 +127,7 @@ func MainnetConfig() Config {
 			Version:  "https://spacemesh.io/bootstrap.schema.json.1.0",
 			DataDir:  os.TempDir(),
 			Interval: 30 * time.Second,
+			Quorum:   1,
 		},
 		P2P:      p2pconfig,
 		API:      grpcserver.DefaultConfig(),
@@ -142,5 +145,6 @@ func MainnetConfig() Config {
 		},
 		Recovery: checkpoint.DefaultConfig(),
 		Cache:    datastore.DefaultConfig(),
+		VM:       vm.DefaultConfig(),
 	}
 }
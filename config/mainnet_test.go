@@ -0,0 +1,15 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMainnetVMConfig(t *testing.T) {
+	conf := MainnetConfig()
+	require.NoError(t, conf.VM.Validate(conf.Tortoise.Hdist))
+
+	conf.VM.Archive = false
+	require.NoError(t, conf.VM.Validate(conf.Tortoise.Hdist))
+}
@@ -44,6 +44,9 @@ func fastnet() config.Config {
 
 	conf.P2P.MinPeers = 10
 
+	conf.VM.TxVersion1Layer = 0
+	conf.VM.TemplatesUpgradeLayer = 0
+
 	conf.Genesis = &config.GenesisConfig{
 		ExtraData: "fastnet",
 		Accounts:  map[string]uint64{},
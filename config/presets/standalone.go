@@ -47,6 +47,9 @@ func standalone() config.Config {
 	conf.Tortoise.Hdist = 2
 	conf.Tortoise.Zdist = 2
 
+	conf.VM.TxVersion1Layer = 0
+	conf.VM.TemplatesUpgradeLayer = 0
+
 	conf.HareEligibility.ConfidenceParam = 2
 
 	conf.POST.K1 = 12
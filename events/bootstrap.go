@@ -0,0 +1,48 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// BootstrapSources is a group of bootstrap sources that served identical update.
+type BootstrapSources struct {
+	Beacon        types.Beacon
+	ActiveSetSize int
+	Sources       []string
+}
+
+// EventBootstrapDisagreement is reported when bootstrap sources served different updates
+// for the same epoch.
+type EventBootstrapDisagreement struct {
+	Epoch  types.EpochID
+	Suffix string
+	Groups []BootstrapSources
+	// Accepted is true if one of the updates was still served by the quorum of sources.
+	Accepted bool
+}
+
+// SubscribeBootstrapDisagreement subscribes to disagreements between bootstrap sources.
+func SubscribeBootstrapDisagreement() Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		sub, err := reporter.bus.Subscribe(new(EventBootstrapDisagreement))
+		if err != nil {
+			log.With().Panic("failed to subscribe to bootstrap disagreement")
+		}
+		return sub
+	}
+	return nil
+}
+
+// ReportBootstrapDisagreement reports a disagreement between bootstrap sources.
+func ReportBootstrapDisagreement(ev EventBootstrapDisagreement) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.bootstrapEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit bootstrap disagreement", log.Err(err))
+		}
+	}
+}
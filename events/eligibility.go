@@ -0,0 +1,55 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// Outcomes of the layer where smesher was eligible for proposals.
+const (
+	// EligibilityRewarded is set when the applied block rewards the smesher.
+	EligibilityRewarded = "rewarded"
+	// EligibilityNotSynced is set when the proposal wasn't built because the node was not synced.
+	EligibilityNotSynced = "not_synced"
+	// EligibilityNotBuilt is set when the proposal wasn't built for any other reason.
+	EligibilityNotBuilt = "not_built"
+	// EligibilityLate is set when the proposal was published after hare started, and the block doesn't reward the smesher.
+	EligibilityLate = "late"
+	// EligibilityRejected is set when the proposal failed to publish or its ballot is not accepted by the node.
+	EligibilityRejected = "rejected"
+	// EligibilityNotInBlock is set when the proposal was published in time, but the block doesn't reward the smesher.
+	EligibilityNotInBlock = "not_in_block"
+)
+
+// EventEligibility is reported once the layer where smesher was eligible is verified and applied.
+type EventEligibility struct {
+	Layer         types.LayerID
+	Smesher       types.NodeID
+	Atx           types.ATXID
+	Eligibilities uint32
+	// Proposal is empty if the proposal wasn't built.
+	Proposal types.ProposalID
+	Outcome  string
+	Details  string
+	// Reward is the reward of the coinbase in the layer, it includes rewards of other smeshers
+	// with the same coinbase.
+	Reward uint64
+	// ConsecutiveMissed is the number of the latest layers where smesher was eligible, but not rewarded.
+	ConsecutiveMissed int
+}
+
+// Missed returns true if smesher wasn't rewarded.
+func (ev *EventEligibility) Missed() bool {
+	return ev.Outcome != EligibilityRewarded
+}
+
+// ReportEligibility reports the outcome of the layer where smesher was eligible.
+func ReportEligibility(ev EventEligibility) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.eligibilityEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit eligibility outcome", ev.Layer, log.Err(err))
+		}
+	}
+}
@@ -14,6 +14,8 @@ import (
 
 type UserEvent struct {
 	Event *pb.Event
+	// Seq increases with every emitted event, it identifies events that were already received.
+	Seq uint64
 }
 
 func EmitBeacon(epoch types.EpochID, beacon types.Beacon) {
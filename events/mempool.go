@@ -0,0 +1,43 @@
+package events
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// Reasons for removing a transaction from the mempool.
+const (
+	// MempoolApplied is set when the transaction nonce was consumed by an applied transaction.
+	MempoolApplied = "applied"
+	// MempoolReplaced is set when the transaction was replaced by a transaction with higher fee.
+	MempoolReplaced = "replaced"
+	// MempoolExpired is set when the transaction can't be applied after its layer limits.
+	MempoolExpired = "expired"
+	// MempoolInfeasible is set when the principal can't pay for the transaction
+	// or has too many pending transactions. It is reconsidered after layer is applied.
+	MempoolInfeasible = "infeasible"
+	// MempoolEvicted is set when the transaction was evicted by the operator.
+	MempoolEvicted = "evicted"
+)
+
+// EventMempool is reported when a transaction is added to or removed from the mempool.
+type EventMempool struct {
+	ID        types.TransactionID
+	Principal types.Address
+	Nonce     uint64
+	Fee       uint64
+	Added     bool
+	// Reason is set for removed transactions.
+	Reason string
+}
+
+// ReportMempool reports a change in the mempool.
+func ReportMempool(ev EventMempool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if reporter != nil {
+		if err := reporter.mempoolEmitter.Emit(ev); err != nil {
+			log.With().Error("failed to emit mempool update", ev.ID, log.Err(err))
+		}
+	}
+}
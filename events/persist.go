@@ -0,0 +1,195 @@
+package events
+
+import (
+	"context"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/userevents"
+)
+
+// Kinds of the persisted user events.
+const (
+	KindBeacon        = "beacon"
+	KindInitStart     = "init_start"
+	KindInitFailed    = "init_failed"
+	KindInitComplete  = "init_complete"
+	KindPoetWaitRound = "poet_wait_round"
+	KindPoetWaitProof = "poet_wait_proof"
+	KindPostStart     = "post_start"
+	KindPostComplete  = "post_complete"
+	KindAtxPublished  = "atx_published"
+	KindEligibilities = "eligibilities"
+	KindProposal      = "proposal"
+	KindMalfeasance   = "malfeasance"
+	KindUnknown       = "unknown"
+)
+
+// Persister stores user events in the database, so that they survive restarts
+// and can be joined with the data produced by consensus.
+type Persister struct {
+	db        sql.Executor
+	clock     LayerClock
+	retention uint32
+	logger    log.Log
+
+	// last is the sequence number of the latest stored event. Ring copied on resubscription
+	// contains events that were already stored.
+	last   uint64
+	pruned types.EpochID
+}
+
+// NewPersister creates Persister that keeps events from the latest retention epochs.
+// Events are never pruned if retention is 0.
+func NewPersister(db sql.Executor, clock LayerClock, retention uint32, logger log.Log) *Persister {
+	return &Persister{
+		db:        db,
+		clock:     clock,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Run stores events until ctx is canceled. Events emitted before Run was called
+// are stored if they are still in the reporter buffer.
+func (p *Persister) Run(ctx context.Context) error {
+	p.prune(p.clock.CurrentLayer().GetEpoch())
+	for {
+		sub, buf, err := SubscribeUserEvents()
+		if err != nil {
+			return err
+		}
+		if sub == nil {
+			p.logger.With().Info("events reporter is not initialized, events won't be persisted")
+			return nil
+		}
+		buf.Iterate(func(ev UserEvent) bool {
+			p.store(ev)
+			return true
+		})
+		full := p.consume(ctx, sub)
+		sub.Close()
+		if !full {
+			return nil
+		}
+		p.logger.With().Warning("events subscription overflowed, some events won't be persisted")
+	}
+}
+
+func (p *Persister) consume(ctx context.Context, sub *BufferedSubscription[UserEvent]) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-sub.Full():
+			return true
+		case ev := <-sub.Out():
+			p.store(ev)
+		}
+	}
+}
+
+func (p *Persister) store(ev UserEvent) {
+	if ev.Event == nil {
+		return
+	}
+	if ev.Seq <= p.last {
+		return
+	}
+	p.last = ev.Seq
+	ts := ev.Event.Timestamp.AsTime()
+	layer := p.clock.CurrentLayer()
+	epoch := layer.GetEpoch()
+	if explicit, ok := eventEpoch(ev.Event); ok {
+		epoch = explicit
+	}
+	if proposal := ev.Event.GetProposal(); proposal != nil {
+		layer = types.LayerID(proposal.Layer)
+	}
+	kind := eventKind(ev.Event)
+	buf, err := proto.Marshal(ev.Event)
+	if err != nil {
+		p.logger.With().Error("failed to encode event", log.String("kind", kind), log.Err(err))
+		return
+	}
+	if err := userevents.Add(p.db, &userevents.Event{
+		Epoch:     epoch,
+		Layer:     layer,
+		Kind:      kind,
+		Timestamp: ts,
+		Failure:   ev.Event.Failure,
+		Event:     buf,
+	}); err != nil {
+		p.logger.With().Error("failed to persist event", log.Err(err))
+		return
+	}
+	p.prune(layer.GetEpoch())
+}
+
+func (p *Persister) prune(current types.EpochID) {
+	if p.retention == 0 || current < types.EpochID(p.retention) || current <= p.pruned {
+		return
+	}
+	p.pruned = current
+	n, err := userevents.Prune(p.db, current-types.EpochID(p.retention))
+	if err != nil {
+		p.logger.With().Error("failed to prune events", log.Err(err))
+		return
+	}
+	if n > 0 {
+		p.logger.With().Debug("pruned events", current, log.Int("count", n))
+	}
+}
+
+// eventEpoch returns the epoch the event refers to, if it is a part of the event.
+func eventEpoch(ev *pb.Event) (types.EpochID, bool) {
+	switch details := ev.Details.(type) {
+	case *pb.Event_Beacon:
+		return types.EpochID(details.Beacon.Epoch), true
+	case *pb.Event_PoetWaitRound:
+		return types.EpochID(details.PoetWaitRound.Current), true
+	case *pb.Event_PoetWaitProof:
+		return types.EpochID(details.PoetWaitProof.Publish), true
+	case *pb.Event_AtxPublished:
+		return types.EpochID(details.AtxPublished.Current), true
+	case *pb.Event_Eligibilities:
+		return types.EpochID(details.Eligibilities.Epoch), true
+	case *pb.Event_Proposal:
+		return types.LayerID(details.Proposal.Layer).GetEpoch(), true
+	}
+	return 0, false
+}
+
+func eventKind(ev *pb.Event) string {
+	switch ev.Details.(type) {
+	case *pb.Event_Beacon:
+		return KindBeacon
+	case *pb.Event_InitStart:
+		return KindInitStart
+	case *pb.Event_InitFailed:
+		return KindInitFailed
+	case *pb.Event_InitComplete:
+		return KindInitComplete
+	case *pb.Event_PoetWaitRound:
+		return KindPoetWaitRound
+	case *pb.Event_PoetWaitProof:
+		return KindPoetWaitProof
+	case *pb.Event_PostStart:
+		return KindPostStart
+	case *pb.Event_PostComplete:
+		return KindPostComplete
+	case *pb.Event_AtxPublished:
+		return KindAtxPublished
+	case *pb.Event_Eligibilities:
+		return KindEligibilities
+	case *pb.Event_Proposal:
+		return KindProposal
+	case *pb.Event_Malfeasance:
+		return KindMalfeasance
+	}
+	return KindUnknown
+}
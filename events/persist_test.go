@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/rewards"
+	"github.com/spacemeshos/go-spacemesh/sql/userevents"
+)
+
+const layersPerEpoch = 4
+
+func TestMain(m *testing.M) {
+	types.SetLayersPerEpoch(layersPerEpoch)
+
+	res := m.Run()
+	os.Exit(res)
+}
+
+type testClock struct {
+	current atomic.Uint32
+}
+
+func (c *testClock) AwaitLayer(types.LayerID) <-chan struct{} {
+	return make(chan struct{})
+}
+
+func (c *testClock) CurrentLayer() types.LayerID {
+	return types.LayerID(c.current.Load())
+}
+
+func addEligibilityAtx(t *testing.T, db sql.Executor, id types.ATXID, coinbase types.Address) {
+	atx := &types.ActivationTx{
+		InnerActivationTx: types.InnerActivationTx{
+			NIPostChallenge: types.NIPostChallenge{PublishEpoch: 2},
+			NumUnits:        2,
+			Coinbase:        coinbase,
+		},
+	}
+	atx.SetID(id)
+	atx.SmesherID = types.RandomNodeID()
+	atx.SetEffectiveNumUnits(atx.NumUnits)
+	atx.SetReceived(time.Now().Local())
+	vatx, err := atx.Verify(0, 1)
+	require.NoError(t, err)
+	require.NoError(t, atxs.Add(db, vatx))
+}
+
+func TestPersister(t *testing.T) {
+	InitializeReporter()
+	t.Cleanup(CloseEventReporter)
+
+	db := sql.InMemory()
+	clock := &testClock{}
+	clock.current.Store(8)
+	persisted := func(n int) {
+		require.Eventually(t, func() bool {
+			stored, err := userevents.List(db, 0, 100)
+			require.NoError(t, err)
+			return len(stored) == n
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	published := types.RandomATXID()
+	// emitted before persister started and must be recovered from the buffer
+	EmitAtxPublished(2, 3, published, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewPersister(db, clock, 2, logtest.New(t)).Run(ctx)
+	}()
+	persisted(1)
+
+	clock.current.Store(12)
+	eligibilityAtx := types.RandomATXID()
+	coinbase := types.Address{1, 2, 3}
+	EmitEligibilities(3, types.Beacon{1}, eligibilityAtx, 10, map[types.LayerID][]types.VotingEligibility{
+		12: {{}},
+		13: {{}, {}},
+		14: {{}},
+	})
+	p12, p13 := types.ProposalID{12}, types.ProposalID{13}
+	EmitProposal(12, p12)
+	EmitProposal(13, p13)
+	persisted(4)
+
+	addEligibilityAtx(t, db, eligibilityAtx, coinbase)
+	require.NoError(t, rewards.Add(db, &types.Reward{
+		Layer:       12,
+		Coinbase:    coinbase,
+		TotalReward: 100,
+		LayerReward: 50,
+	}))
+	require.NoError(t, layers.SetApplied(db, 13, types.BlockID{1}))
+
+	timeline, err := Timeline(db, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	require.Equal(t, types.EpochID(2), timeline[0].Epoch)
+	require.Equal(t, published, timeline[0].Atx)
+	require.Len(t, timeline[0].Events, 1)
+
+	require.Equal(t, types.EpochID(3), timeline[1].Epoch)
+	require.Equal(t, types.EmptyATXID, timeline[1].Atx)
+	require.Equal(t, eligibilityAtx, timeline[1].EligibilityAtx)
+	require.Equal(t, coinbase, timeline[1].Coinbase)
+	require.Len(t, timeline[1].Events, 3)
+	require.Equal(t, []LayerTimeline{
+		{Layer: 12, Eligibilities: 1, Proposal: p12, Reward: RewardReceived, TotalReward: 100, LayerReward: 50},
+		{Layer: 13, Eligibilities: 2, Proposal: p13, Reward: RewardMissed},
+		{Layer: 14, Eligibilities: 1, Reward: RewardPending},
+	}, timeline[1].Layers)
+
+	// events from epochs before 5-2 are pruned
+	clock.current.Store(20)
+	EmitBeacon(5, types.Beacon{2})
+	persisted(4)
+	timeline, err = Timeline(db, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	require.Equal(t, types.EpochID(3), timeline[0].Epoch)
+	require.Equal(t, types.EpochID(5), timeline[1].Epoch)
+	require.Empty(t, timeline[1].Layers)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "timeout")
+	}
+}
+
+func TestPersister_SameTimestamp(t *testing.T) {
+	db := sql.InMemory()
+	clock := &testClock{}
+	clock.current.Store(8)
+	p := NewPersister(db, clock, 0, logtest.New(t))
+
+	ts := timestamppb.New(time.Now())
+	first := UserEvent{Seq: 1, Event: &pb.Event{Timestamp: ts, Details: &pb.Event_Beacon{
+		Beacon: &pb.EventBeacon{Epoch: 2},
+	}}}
+	second := UserEvent{Seq: 2, Event: &pb.Event{Timestamp: ts, Details: &pb.Event_Beacon{
+		Beacon: &pb.EventBeacon{Epoch: 3},
+	}}}
+	p.store(first)
+	p.store(second)
+	// replayed from the buffer after resubscription
+	p.store(first)
+	p.store(second)
+
+	stored, err := userevents.List(db, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+}
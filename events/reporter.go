@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 
 	"github.com/libp2p/go-libp2p/core/event"
 	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
@@ -21,6 +22,8 @@ var (
 	mu sync.RWMutex
 	// reporter is the event reporter singleton.
 	reporter *EventReporter
+	// userEventSeq is not reset with the reporter, so that sequence numbers are unique within the process.
+	userEventSeq atomic.Uint64
 )
 
 // InitializeReporter initializes the event reporting interface with
@@ -421,6 +424,9 @@ type EventReporter struct {
 	resultsEmitter     event.Emitter
 	proposalsEmitter   event.Emitter
 	malfeasanceEmitter event.Emitter
+	bootstrapEmitter   event.Emitter
+	mempoolEmitter     event.Emitter
+	eligibilityEmitter event.Emitter
 	events             struct {
 		sync.Mutex
 		buf     *Ring[UserEvent]
@@ -432,6 +438,7 @@ type EventReporter struct {
 func (r *EventReporter) emitUserEvent(ev UserEvent) error {
 	r.events.Lock()
 	defer r.events.Unlock()
+	ev.Seq = userEventSeq.Add(1)
 	r.events.buf.insert(ev)
 	return r.events.emitter.Emit(ev)
 }
@@ -493,6 +500,18 @@ func newEventReporter() *EventReporter {
 	if err != nil {
 		log.With().Panic("failed to create malfeasance emitter", log.Err(err))
 	}
+	bootstrapEmitter, err := bus.Emitter(new(EventBootstrapDisagreement))
+	if err != nil {
+		log.With().Panic("failed to create bootstrap emitter", log.Err(err))
+	}
+	mempoolEmitter, err := bus.Emitter(new(EventMempool))
+	if err != nil {
+		log.With().Panic("failed to create mempool emitter", log.Err(err))
+	}
+	eligibilityEmitter, err := bus.Emitter(new(EventEligibility))
+	if err != nil {
+		log.With().Panic("failed to create eligibility emitter", log.Err(err))
+	}
 
 	reporter := &EventReporter{
 		bus:                bus,
@@ -506,6 +525,9 @@ func newEventReporter() *EventReporter {
 		errorEmitter:       errorEmitter,
 		proposalsEmitter:   proposalsEmitter,
 		malfeasanceEmitter: malfeasanceEmitter,
+		bootstrapEmitter:   bootstrapEmitter,
+		mempoolEmitter:     mempoolEmitter,
+		eligibilityEmitter: eligibilityEmitter,
 		stopChan:           make(chan struct{}),
 	}
 	reporter.events.buf = newRing[UserEvent](100)
@@ -548,6 +570,15 @@ func CloseEventReporter() {
 		if err := reporter.malfeasanceEmitter.Close(); err != nil {
 			log.With().Panic("failed to close malfeasanceEmitter", log.Err(err))
 		}
+		if err := reporter.bootstrapEmitter.Close(); err != nil {
+			log.With().Panic("failed to close bootstrapEmitter", log.Err(err))
+		}
+		if err := reporter.mempoolEmitter.Close(); err != nil {
+			log.With().Panic("failed to close mempoolEmitter", log.Err(err))
+		}
+		if err := reporter.eligibilityEmitter.Close(); err != nil {
+			log.With().Panic("failed to close eligibilityEmitter", log.Err(err))
+		}
 
 		close(reporter.stopChan)
 		reporter = nil
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/atxs"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/rewards"
+	"github.com/spacemeshos/go-spacemesh/sql/userevents"
+)
+
+// RewardStatus of the layer where smesher was eligible.
+type RewardStatus int
+
+const (
+	// RewardUnknown if coinbase of the smesher is unknown.
+	RewardUnknown RewardStatus = iota
+	// RewardPending if the layer is not applied yet.
+	RewardPending
+	RewardReceived
+	// RewardMissed if the layer is applied, but coinbase didn't receive rewards in it.
+	RewardMissed
+)
+
+func (s RewardStatus) String() string {
+	switch s {
+	case RewardPending:
+		return "pending"
+	case RewardReceived:
+		return "received"
+	case RewardMissed:
+		return "missed"
+	}
+	return "unknown"
+}
+
+// LayerTimeline is a layer where smesher was eligible or published a proposal.
+type LayerTimeline struct {
+	Layer         types.LayerID
+	Eligibilities uint32
+	// Proposal is empty if smesher didn't publish a proposal in the layer.
+	Proposal    types.ProposalID
+	Reward      RewardStatus
+	TotalReward uint64
+	LayerReward uint64
+}
+
+// EpochTimeline is what happened with the smesher in the epoch.
+type EpochTimeline struct {
+	Epoch types.EpochID
+	// Atx is empty if atx wasn't published in the epoch.
+	Atx types.ATXID
+	// EligibilityAtx is the atx that granted eligibilities in the epoch.
+	EligibilityAtx types.ATXID
+	Coinbase       types.Address
+	Layers         []LayerTimeline
+	Events         []*pb.Event
+}
+
+// Timeline loads persisted events from epochs [from, to] and joins them with rewards.
+// Epochs without events are omitted.
+func Timeline(db sql.Executor, from, to types.EpochID) ([]EpochTimeline, error) {
+	stored, err := userevents.List(db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	lastApplied, err := layers.GetLastApplied(db)
+	if err != nil {
+		return nil, err
+	}
+	var rst []EpochTimeline
+	for i := 0; i < len(stored); {
+		epoch := stored[i].Epoch
+		j := i
+		for j < len(stored) && stored[j].Epoch == epoch {
+			j++
+		}
+		timeline, err := epochTimeline(db, epoch, stored[i:j], lastApplied)
+		if err != nil {
+			return nil, err
+		}
+		rst = append(rst, *timeline)
+		i = j
+	}
+	return rst, nil
+}
+
+func epochTimeline(
+	db sql.Executor,
+	epoch types.EpochID,
+	stored []userevents.Event,
+	lastApplied types.LayerID,
+) (*EpochTimeline, error) {
+	timeline := &EpochTimeline{Epoch: epoch}
+	byLayer := map[types.LayerID]*LayerTimeline{}
+	layer := func(lid types.LayerID) *LayerTimeline {
+		if _, exist := byLayer[lid]; !exist {
+			byLayer[lid] = &LayerTimeline{Layer: lid}
+		}
+		return byLayer[lid]
+	}
+	for _, ev := range stored {
+		decoded := &pb.Event{}
+		if err := proto.Unmarshal(ev.Event, decoded); err != nil {
+			return nil, fmt.Errorf("decode event %d: %w", ev.ID, err)
+		}
+		timeline.Events = append(timeline.Events, decoded)
+		switch details := decoded.Details.(type) {
+		case *pb.Event_AtxPublished:
+			copy(timeline.Atx[:], details.AtxPublished.Id)
+		case *pb.Event_Eligibilities:
+			copy(timeline.EligibilityAtx[:], details.Eligibilities.Atx)
+			for _, eligibility := range details.Eligibilities.Eligibilities {
+				layer(types.LayerID(eligibility.Layer)).Eligibilities = eligibility.Count
+			}
+		case *pb.Event_Proposal:
+			copy(layer(types.LayerID(details.Proposal.Layer)).Proposal[:], details.Proposal.Proposal)
+		}
+	}
+	for _, lt := range byLayer {
+		timeline.Layers = append(timeline.Layers, *lt)
+	}
+	sort.Slice(timeline.Layers, func(i, j int) bool {
+		return timeline.Layers[i].Layer < timeline.Layers[j].Layer
+	})
+
+	if timeline.EligibilityAtx == types.EmptyATXID || len(timeline.Layers) == 0 {
+		return timeline, nil
+	}
+	atx, err := atxs.Get(db, timeline.EligibilityAtx)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		return timeline, nil
+	case err != nil:
+		return nil, err
+	}
+	timeline.Coinbase = atx.Coinbase
+	received, err := rewards.ListRange(db, timeline.Coinbase,
+		timeline.Layers[0].Layer, timeline.Layers[len(timeline.Layers)-1].Layer)
+	if err != nil {
+		return nil, err
+	}
+	for i := range timeline.Layers {
+		lt := &timeline.Layers[i]
+		lt.Reward = RewardMissed
+		if lt.Layer > lastApplied {
+			lt.Reward = RewardPending
+		}
+		for _, reward := range received {
+			if reward.Layer == lt.Layer {
+				lt.Reward = RewardReceived
+				lt.TotalReward = reward.TotalReward
+				lt.LayerReward = reward.LayerReward
+			}
+		}
+	}
+	return timeline, nil
+}
@@ -16,14 +16,26 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/vesting"
 )
 
+// Input is a single allocation. Unless Address is set the amount is locked in the vault
+// owned by the vesting account with Keys.
 type Input struct {
+	// Name is an optional label of the allocation used in the report.
+	Name string `json:"name,omitempty"`
+	// Address receives Total without vesting if set.
+	Address  string   `json:"address,omitempty"`
 	Keys     []string `json:"keys"`
 	Required int      `json:"required"`
 	Total    float64  `json:"total"`
+	// InitialUnlock is unlocked at the VestingStart, a quarter of Total if not set.
+	InitialUnlock *float64 `json:"initial_unlock,omitempty"`
+	// VestingStart and VestingEnd are layers, economics constants are used if not set.
+	VestingStart uint32 `json:"vesting_start,omitempty"`
+	VestingEnd   uint32 `json:"vesting_end,omitempty"`
 }
 
 type Output struct {
 	Debug struct {
+		Name           string
 		VestingAddress string
 		VestingArgs    multisig.SpawnArguments
 		VaultAddress   string
@@ -35,6 +47,16 @@ type Output struct {
 }
 
 func Generate(input Input) Output {
+	if len(input.Address) > 0 {
+		address, err := types.StringToAddress(input.Address)
+		must(err)
+		output := Output{
+			Address: address.String(),
+			Balance: uint64(input.Total),
+		}
+		output.Debug.Name = input.Name
+		return output
+	}
 	var keys []core.PublicKey
 	for _, data := range input.Keys {
 		keys = append(keys, decodeHexKey([]byte(data)))
@@ -58,11 +80,27 @@ func Generate(input Input) Output {
 		VestingStart:        types.LayerID(constants.VestStart),
 		VestingEnd:          types.LayerID(constants.VestEnd),
 	}
+	if input.InitialUnlock != nil {
+		vaultArgs.InitialUnlockAmount = uint64(*input.InitialUnlock)
+	}
+	if input.VestingStart != 0 {
+		vaultArgs.VestingStart = types.LayerID(input.VestingStart)
+	}
+	if input.VestingEnd != 0 {
+		vaultArgs.VestingEnd = types.LayerID(input.VestingEnd)
+	}
+	if vaultArgs.InitialUnlockAmount > vaultArgs.TotalAmount {
+		must(fmt.Errorf("initial unlock (%d) is larger than total (%d)", vaultArgs.InitialUnlockAmount, vaultArgs.TotalAmount))
+	}
+	if vaultArgs.VestingEnd.Before(vaultArgs.VestingStart) {
+		must(fmt.Errorf("vesting end (%s) is before vesting start (%s)", vaultArgs.VestingEnd, vaultArgs.VestingStart))
+	}
 	vaultAddress := core.ComputePrincipal(vault.TemplateAddress, vaultArgs)
 	output := Output{
 		Address: vaultAddress.String(),
 		Balance: uint64(input.Total),
 	}
+	output.Debug.Name = input.Name
 	output.Debug.VestingAddress = vestingAddress.String()
 	output.Debug.VestingArgs = *vestingArgs
 	output.Debug.VaultAddress = vaultAddress.String()
@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func parseJson(f io.Reader) []Input {
+	dec := json.NewDecoder(f)
+	inputs := []Input{}
+	for {
+		var input Input
+		if err := dec.Decode(&input); errors.Is(err, io.EOF) {
+			break
+		} else {
+			must(err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+func parseCsv(f io.Reader) []Input {
+	scan := bufio.NewScanner(f)
+	var inputs []Input
+	for scan.Scan() {
+		var input Input
+		columns := strings.Split(scan.Text(), ",")
+		total, err := strconv.ParseFloat(columns[0], 64)
+		must(err)
+		k, err := strconv.ParseUint(columns[1], 10, 8)
+		must(err)
+		n, err := strconv.ParseInt(columns[2], 10, 8)
+		must(err)
+		input.Required = int(k)
+		input.Total = total
+		for i := int64(3); i < 3+n; i++ {
+			key := columns[i]
+			input.Keys = append(input.Keys, key)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// ParseFile reads inputs from the json or csv file.
+// Json file is a stream of Input objects, csv file has columns total,required,n,key1,...,keyn.
+func ParseFile(path string) []Input {
+	f, err := os.Open(path)
+	must(err)
+	defer f.Close()
+
+	if strings.HasSuffix(f.Name(), "json") {
+		return parseJson(f)
+	} else if strings.HasSuffix(f.Name(), "csv") {
+		return parseCsv(f)
+	}
+	fmt.Printf("unknown file extension for file %v. expect json or csv", f.Name())
+	os.Exit(1)
+	return nil
+}
@@ -0,0 +1,168 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/vault"
+)
+
+// Accounts returns balances of genesis accounts in the format of config.GenesisConfig.
+func Accounts(outputs []Output) (map[string]uint64, error) {
+	accounts := make(map[string]uint64, len(outputs))
+	for _, output := range outputs {
+		if _, exist := accounts[output.Address]; exist {
+			return nil, fmt.Errorf("account %s is allocated more than once", output.Address)
+		}
+		accounts[output.Address] = output.Balance
+	}
+	return accounts, nil
+}
+
+// GenesisAccounts returns genesis accounts ordered by address.
+func GenesisAccounts(accounts map[string]uint64) ([]types.Account, error) {
+	rst := make([]types.Account, 0, len(accounts))
+	for address, balance := range accounts {
+		addr, err := types.StringToAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", address, err)
+		}
+		rst = append(rst, types.Account{Address: addr, Balance: balance})
+	}
+	sort.Slice(rst, func(i, j int) bool { return bytes.Compare(rst[i].Address[:], rst[j].Address[:]) < 0 })
+	return rst, nil
+}
+
+// Report summarizes genesis allocation.
+type Report struct {
+	Accounts int    `json:"accounts"`
+	Total    uint64 `json:"total"`
+	// Liquid is allocated to accounts without vesting.
+	Liquid    uint64           `json:"liquid"`
+	Vaulted   uint64           `json:"vaulted"`
+	Schedules []ScheduleReport `json:"schedules"`
+	Owners    []OwnerReport    `json:"owners"`
+	Unlocks   []UnlockReport   `json:"unlocks"`
+}
+
+// ScheduleReport is a total locked in vaults with the same vesting period.
+type ScheduleReport struct {
+	VestingStart  uint32 `json:"vesting_start"`
+	VestingEnd    uint32 `json:"vesting_end"`
+	Vaults        int    `json:"vaults"`
+	Total         uint64 `json:"total"`
+	InitialUnlock uint64 `json:"initial_unlock"`
+}
+
+// OwnerReport is a total allocated to the owner. Owner of the vault is a vesting account,
+// owner of the account without vesting is the account itself.
+type OwnerReport struct {
+	Owner  string   `json:"owner"`
+	Names  []string `json:"names,omitempty"`
+	Vaults int      `json:"vaults"`
+	Total  uint64   `json:"total"`
+}
+
+// UnlockReport is an amount that can be spent at the layer.
+type UnlockReport struct {
+	Layer uint32 `json:"layer"`
+	// Unlocked is the sum of vault.Vault.Available for all vaults.
+	Unlocked uint64 `json:"unlocked"`
+	// Spendable includes accounts without vesting.
+	Spendable uint64 `json:"spendable"`
+}
+
+// NewReport simulates unlock schedule of the vaults at every step layers and at the start
+// and the end of every vesting period.
+func NewReport(outputs []Output, step uint32) *Report {
+	var (
+		report    = &Report{Accounts: len(outputs)}
+		vaults    []vault.Vault
+		schedules = map[[2]uint32]*ScheduleReport{}
+		owners    = map[string]*OwnerReport{}
+		layers    = map[uint32]struct{}{0: {}}
+	)
+	for _, output := range outputs {
+		report.Total += output.Balance
+		owner := output.Address
+		if output.Debug.VaultAddress == "" {
+			report.Liquid += output.Balance
+		} else {
+			args := output.Debug.VaultArgs
+			report.Vaulted += output.Balance
+			vaults = append(vaults, vault.Vault{
+				Owner:               args.Owner,
+				TotalAmount:         args.TotalAmount,
+				InitialUnlockAmount: args.InitialUnlockAmount,
+				VestingStart:        args.VestingStart,
+				VestingEnd:          args.VestingEnd,
+			})
+			period := [2]uint32{args.VestingStart.Uint32(), args.VestingEnd.Uint32()}
+			schedule, exist := schedules[period]
+			if !exist {
+				schedule = &ScheduleReport{VestingStart: period[0], VestingEnd: period[1]}
+				schedules[period] = schedule
+			}
+			schedule.Vaults++
+			schedule.Total += args.TotalAmount
+			schedule.InitialUnlock += args.InitialUnlockAmount
+
+			layers[period[0]] = struct{}{}
+			layers[period[1]] = struct{}{}
+			if step != 0 {
+				for lid := period[0] + step; lid < period[1]; lid += step {
+					layers[lid] = struct{}{}
+				}
+			}
+			owner = output.Debug.VestingAddress
+		}
+		or, exist := owners[owner]
+		if !exist {
+			or = &OwnerReport{Owner: owner}
+			owners[owner] = or
+		}
+		if output.Debug.VaultAddress != "" {
+			or.Vaults++
+		}
+		if output.Debug.Name != "" {
+			or.Names = append(or.Names, output.Debug.Name)
+		}
+		or.Total += output.Balance
+	}
+
+	for _, schedule := range schedules {
+		report.Schedules = append(report.Schedules, *schedule)
+	}
+	sort.Slice(report.Schedules, func(i, j int) bool {
+		if report.Schedules[i].VestingStart == report.Schedules[j].VestingStart {
+			return report.Schedules[i].VestingEnd < report.Schedules[j].VestingEnd
+		}
+		return report.Schedules[i].VestingStart < report.Schedules[j].VestingStart
+	})
+	for _, owner := range owners {
+		report.Owners = append(report.Owners, *owner)
+	}
+	sort.Slice(report.Owners, func(i, j int) bool {
+		if report.Owners[i].Total == report.Owners[j].Total {
+			return report.Owners[i].Owner < report.Owners[j].Owner
+		}
+		return report.Owners[i].Total > report.Owners[j].Total
+	})
+
+	sorted := make([]uint32, 0, len(layers))
+	for lid := range layers {
+		sorted = append(sorted, lid)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, lid := range sorted {
+		unlock := UnlockReport{Layer: lid}
+		for i := range vaults {
+			unlock.Unlocked += vaults[i].Available(types.LayerID(lid))
+		}
+		unlock.Spendable = unlock.Unlocked + report.Liquid
+		report.Unlocks = append(report.Unlocks, unlock)
+	}
+	return report
+}
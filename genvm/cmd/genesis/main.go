@@ -0,0 +1,123 @@
+// Command genesis computes genesis accounts from the allocation file.
+//
+//	genesis -c allocation.json accounts  # accounts for config.GenesisConfig in json
+//	genesis -c allocation.json go        # accounts as a go function, see config/mainnet_accounts.go
+//	genesis -c allocation.json report    # totals and unlock schedule
+//	genesis -c allocation.json verify -db state.sql
+//
+// Verify compares state root of the computed accounts with the root after ApplyGenesis
+// on the empty state, and with the genesis accounts in the node database if -db is set.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spacemeshos/economics/constants"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	vm "github.com/spacemeshos/go-spacemesh/genvm"
+	"github.com/spacemeshos/go-spacemesh/genvm/cmd/gen"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+var (
+	hrp    = flag.String("hrp", "sm", "network human readable prefix")
+	config = flag.String("c", "", "allocation file in json or csv format. see genvm/cmd/vestingmulti for examples")
+	step   = flag.Uint("step", constants.OneYear/12, "number of layers between points of the unlock schedule in the report")
+	dbpath = flag.String("db", "", "path to the node state database for verify")
+)
+
+func must(err error) {
+	if err != nil {
+		fmt.Println("fatal error: ", err.Error())
+		os.Exit(1)
+	}
+}
+
+func encode(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	must(enc.Encode(v))
+}
+
+func verify(accounts map[string]uint64) {
+	genesis, err := gen.GenesisAccounts(accounts)
+	must(err)
+	expected := vm.GenesisStateRoot(genesis)
+	fmt.Printf("computed state root: %s\n", expected.String())
+
+	state := vm.New(sql.InMemory())
+	must(state.ApplyGenesis(genesis))
+	applied, err := state.GetGenesisStateRoot()
+	must(err)
+	fmt.Printf("state root after ApplyGenesis: %s\n", applied.String())
+	if applied != expected {
+		must(fmt.Errorf("state root after ApplyGenesis doesn't match"))
+	}
+	if len(*dbpath) == 0 {
+		return
+	}
+	db, err := sql.Open("file:"+*dbpath+"?mode=ro", sql.WithMigrations(nil), sql.WithConnections(1))
+	must(err)
+	defer db.Close()
+	node, err := vm.New(db).GetGenesisStateRoot()
+	must(err)
+	fmt.Printf("state root in %s: %s\n", *dbpath, node.String())
+	if node != expected {
+		must(fmt.Errorf("state root in the node database doesn't match"))
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] accounts|go|report|verify [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	command := flag.Arg(0)
+	// flags are also accepted after the command
+	must(flag.CommandLine.Parse(flag.Args()[1:]))
+	types.SetNetworkHRP(*hrp)
+	if len(*config) == 0 {
+		fmt.Println("please specify allocation with -c=<path to file>")
+		os.Exit(1)
+	}
+
+	var outputs []gen.Output
+	for _, input := range gen.ParseFile(*config) {
+		outputs = append(outputs, gen.Generate(input))
+	}
+	accounts, err := gen.Accounts(outputs)
+	must(err)
+
+	switch command {
+	case "accounts":
+		encode(struct {
+			Accounts map[string]uint64 `json:"accounts"`
+		}{Accounts: accounts})
+	case "go":
+		genesis, err := gen.GenesisAccounts(accounts)
+		must(err)
+		fmt.Println("func MainnetAccounts() map[string]uint64 {")
+		fmt.Println("\treturn map[string]uint64{")
+		for _, account := range genesis {
+			fmt.Printf("\t\t%q: %d,\n", account.Address.String(), account.Balance)
+		}
+		fmt.Println("\t}")
+		fmt.Println("}")
+	case "report":
+		encode(gen.NewReport(outputs, uint32(*step)))
+	case "verify":
+		verify(accounts)
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+}
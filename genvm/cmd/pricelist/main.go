@@ -17,6 +17,8 @@ var (
 	chargeUPDATE        = charge{"update (per 8)", 725, "charged for updating active state"}
 	chargeLOAD          = charge{"load (per 8)", 182, "charged for loading active state"}
 	chargeEDVERIFY      = charge{"edverify (per sig)", 3000, "charged for ed25519 verification"}
+	chargeK1VERIFY      = charge{"k1verify (per sig)", 12000, "charged for secp256k1 verification, 4x edverify by benchmark"}
+	chargeP256VERIFY    = charge{"p256verify (per sig)", 7000, "charged for p-256 webauthn verification, 2.3x edverify by benchmark"}
 	chargeSPAWN         = charge{"spawn", 30000, "charged for every spawn transaction"}
 	chargeTX            = charge{"tx", 20000, "charged for every transaction"}
 )
@@ -28,6 +30,8 @@ var charges = []charge{
 	chargeLOAD,
 	chargeACCOUNTACCESS,
 	chargeEDVERIFY,
+	chargeK1VERIFY,
+	chargeP256VERIFY,
 	chargeSPAWN,
 	chargeTX,
 }
@@ -43,6 +47,8 @@ const (
 	kindUpdate
 	kindLoad
 	kindEdverify
+	kindK1verify
+	kindP256verify
 	kindSpawn
 	kindAccountsAccess
 )
@@ -85,6 +91,10 @@ func (o op) cost(f costFn) int {
 		return perWord(o.size) * f(chargeLOAD)
 	case kindEdverify:
 		return o.size * f(chargeEDVERIFY)
+	case kindK1verify:
+		return o.size * f(chargeK1VERIFY)
+	case kindP256verify:
+		return o.size * f(chargeP256VERIFY)
 	case kindSpawn:
 		return f(chargeSPAWN)
 	case kindAccountsAccess:
@@ -109,6 +119,14 @@ func edverify(size int) op {
 	return op{kindEdverify, size}
 }
 
+func k1verify(size int) op {
+	return op{kindK1verify, size}
+}
+
+func p256verify(size int) op {
+	return op{kindP256verify, size}
+}
+
 func spawn() op {
 	return op{kindSpawn, 0}
 }
@@ -124,6 +142,9 @@ func describe(name string, size int, ops ...op) tx {
 const (
 	sizeSpawn = 64
 	sizeSpend = 56
+	// sizeAssertion is a typical webauthn assertion: authenticator data (37),
+	// client data (~134 for the challenge, type and a short origin) and der signature (~71).
+	sizeAssertion = 37 + 134 + 71 + 3
 )
 
 func txs() []tx {
@@ -131,6 +152,18 @@ func txs() []tx {
 		describe("singlesig/selfspawn", sizeSpawn+32+64, store(48), edverify(1), spawn()),
 		describe("singlesig/spawn", sizeSpawn+32+64, accountaccess(), accountaccess(), load(48), store(48), update(16), edverify(1), spawn()),
 		describe("singlesig/spend", sizeSpend+64, accountaccess(), accountaccess(), load(48), load(8), update(16), update(8), edverify(1)),
+		describe("secp256k1/selfspawn", sizeSpawn+33+64, store(49), k1verify(1), spawn()),
+		describe("secp256k1/spend", sizeSpend+64, accountaccess(), accountaccess(), load(49), load(8), update(16), update(8), k1verify(1)),
+		describe("passkey/selfspawn", sizeSpawn+33+sizeAssertion, store(49), p256verify(1), spawn()),
+		describe("passkey/spend", sizeSpend+sizeAssertion, accountaccess(), accountaccess(), load(49), load(8), update(16), update(8), p256verify(1)),
+	}
+	for _, n := range []int{1, 10, 100} {
+		ops := []op{accountaccess(), load(48), update(16), edverify(1)}
+		for i := 0; i < n; i++ {
+			ops = append(ops, accountaccess(), load(8), update(8))
+		}
+		// spend arguments are replaced with compact length and n outputs of 32 bytes
+		txs = append(txs, describe(fmt.Sprintf("singlesig/batchspend/%d", n), sizeSpend-32+1+32*n+64, ops...))
 	}
 	for n := 1; n <= 3; n++ {
 		for k := 1; k <= 10; k++ {
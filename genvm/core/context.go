@@ -48,6 +48,11 @@ func (c *Context) Principal() Address {
 	return c.PrincipalAccount.Address
 }
 
+// Balance returns balance of the principal account.
+func (c *Context) Balance() uint64 {
+	return c.PrincipalAccount.Balance
+}
+
 // Layer returns block layer id.
 func (c *Context) Layer() LayerID {
 	return c.LayerID
@@ -167,6 +172,17 @@ func (c *Context) Relay(remoteTemplate, address Address, call func(Host) error)
 	return nil
 }
 
+// SavePrincipalState encodes template of the principal into the account state,
+// so that changes made by the executed method are persisted.
+func (c *Context) SavePrincipalState() error {
+	buf := bytes.NewBuffer(nil)
+	if _, err := c.PrincipalTemplate.EncodeScale(scale.NewEncoder(buf)); err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	c.PrincipalAccount.State = buf.Bytes()
+	return nil
+}
+
 // Consume gas from the account after validation passes.
 func (c *Context) Consume(gas uint64) (err error) {
 	amount := gas * c.Header.GasPrice
@@ -259,6 +275,11 @@ func (r *RemoteContext) Template() Template {
 	return r.template
 }
 
+// Balance ...
+func (r *RemoteContext) Balance() uint64 {
+	return r.remote.Balance
+}
+
 // Handler ...
 func (r *RemoteContext) Handler() Handler {
 	return r.handler
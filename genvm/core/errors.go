@@ -22,6 +22,8 @@ var (
 	ErrNotSpawned = errors.New("account is not spawned")
 	// ErrMismatchedTemplate raised if target account doesn't match template account.
 	ErrTemplateMismatch = errors.New("relay template mismatch")
+	// ErrLayerLimits raised if transaction is applied outside of its layer limits.
+	ErrLayerLimits = errors.New("outside of layer limits")
 	// ErrTxLimit overflows max tx size.
 	ErrTxLimit = errors.New("overflows tx limit")
 )
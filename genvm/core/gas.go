@@ -27,12 +27,20 @@ const (
 	ACCOUNT_ACCESS uint64 = 2500
 	// EDVERIFY is a cost for running ed25519 single signature verification.
 	EDVERIFY uint64 = 3000
+	// SECP256K1VERIFY is a cost for running secp256k1 ecdsa signature verification.
+	// Verification is ~4 times slower than ed25519, see BenchmarkVerify in templates.
+	SECP256K1VERIFY uint64 = 12000
+	// P256VERIFY is a cost for running p-256 (secp256r1) ecdsa signature verification,
+	// including parsing and hashing of webauthn client data. ~2.3 times slower than ed25519.
+	P256VERIFY uint64 = 7000
 )
 
 const (
-	PUBLIC_KEY_SIZE      = 32
-	ACCOUNT_HEADER_SIZE  = 36 // includes balance (8), nonce (8) and template address (24)
-	ACCOUNT_BALANCE_SIZE = 8
+	PUBLIC_KEY_SIZE = 32
+	// ECDSA_PUBLIC_KEY_SIZE is the size of compressed secp256k1 and p-256 public keys.
+	ECDSA_PUBLIC_KEY_SIZE = 33
+	ACCOUNT_HEADER_SIZE   = 36 // includes balance (8), nonce (8) and template address (24)
+	ACCOUNT_BALANCE_SIZE  = 8
 )
 
 // SizeGas computes total gas cost for a value of the specific size.
@@ -76,6 +76,36 @@ type Template interface {
 	Verify(Host, []byte, *scale.Decoder) bool
 }
 
+// VariableGasTemplate is implemented by templates with methods that have cost
+// depending on the arguments, e.g. on the number of outputs.
+type VariableGasTemplate interface {
+	// VariableGas is charged on top of ExecGas.
+	VariableGas(uint8, any) uint64
+}
+
+// AccessListTemplate is implemented by templates that know which accounts a method may load
+// before it is executed. Transactions of templates that don't implement it are never executed
+// in parallel with other transactions.
+type AccessListTemplate interface {
+	// AccessList returns accounts, other than the principal, that may be loaded by the method.
+	AccessList(uint8, any) []Address
+}
+
+// MutableTemplate is implemented by templates with methods that update the state of the principal.
+// State is encoded and saved only after such methods, other methods leave it unchanged.
+type MutableTemplate interface {
+	// Mutates returns true if the method updates the state of the template.
+	Mutates(uint8) bool
+}
+
+// ConditionalTemplate is implemented by templates with methods that may be submitted without
+// the owner signature. Such transactions are executed only if Executable returns nil,
+// otherwise they are ineffective: they don't pay fees and don't consume the nonce.
+type ConditionalTemplate interface {
+	// Executable returns an error if the method can't make progress at the layer of the host.
+	Executable(Host, uint8, any) error
+}
+
 // AccountLoader is an interface for loading accounts.
 type AccountLoader interface {
 	Get(Address) (Account, error)
@@ -107,6 +137,7 @@ type Host interface {
 	Relay(expectedTemplate, address Address, call func(Host) error) error
 
 	Principal() Address
+	Balance() uint64
 	Handler() Handler
 	Template() Template
 	Layer() LayerID
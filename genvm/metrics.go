@@ -142,3 +142,38 @@ var appliedLayer = metrics.NewGauge(
 	"Applied layer",
 	[]string{},
 ).WithLabelValues()
+
+const (
+	parallel       = "parallel"
+	fallbackAccess = "fallback_access"
+	fallbackGas    = "fallback_gas"
+)
+
+var parallelBlocks = metrics.NewCounter(
+	"parallel_blocks",
+	namespace,
+	"Number of blocks executed in parallel or executed sequentially after parallel execution failed",
+	[]string{"outcome"},
+)
+
+var (
+	prunedAccounts = metrics.NewCounter(
+		"pruned_accounts",
+		namespace,
+		"Number of pruned account states",
+		[]string{},
+	).WithLabelValues()
+	prunedLayer = metrics.NewGauge(
+		"pruned_layer",
+		namespace,
+		"Layer before which account history is pruned",
+		[]string{},
+	).WithLabelValues()
+	pruneDuration = metrics.NewHistogramWithBuckets(
+		"prune_duration",
+		namespace,
+		"Duration in ns to prune a batch of layers",
+		[]string{},
+		prometheus.ExponentialBuckets(1_000_000, 2, 10),
+	).WithLabelValues()
+)
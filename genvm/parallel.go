@@ -0,0 +1,222 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spacemeshos/go-scale"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/log"
+)
+
+// executeParallel executes transactions that don't access the same accounts concurrently.
+//
+// Accounts that will be accessed by the transaction are computed before execution from the state
+// at the start of the block. Every transaction is scheduled after all preceding transactions
+// that access the same accounts, and changes are applied in the order of transactions in the block.
+// If the transaction accesses an account that wasn't predicted, or the block runs out of gas,
+// results are discarded and block is executed sequentially.
+func (v *VM) executeParallel(lctx ApplyContext, ss *core.StagedCache, txs []types.Transaction) ([]types.TransactionWithResult, []types.Transaction, uint64, error) {
+	var (
+		lists    = v.accessLists(lctx, ss, txs)
+		outcomes = make([]txOutcome, len(txs))
+		view     = &lockedCache{cache: core.NewStagedCache(ss)}
+		escaped  atomic.Bool
+	)
+	for _, wave := range schedule(lists) {
+		var eg errgroup.Group
+		eg.SetLimit(v.cfg.ExecutionWorkers)
+		for _, i := range wave {
+			i := i
+			eg.Go(func() error {
+				var (
+					rd      bytes.Reader
+					decoder = scale.NewDecoder(&rd)
+					loader  = core.AccountLoader(view)
+				)
+				if lists[i] != nil {
+					loader = &accessGuard{allowed: lists[i], loader: view, escaped: &escaped}
+				}
+				out, err := v.executeTx(v.logger.WithFields(log.Int("ith", i)), lctx, loader, &rd, decoder, &txs[i], math.MaxUint64)
+				outcomes[i] = out
+				return err
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, nil, 0, err
+		}
+		if escaped.Load() {
+			parallelBlocks.WithLabelValues(fallbackAccess).Inc()
+			return v.executeSequential(lctx, ss, txs)
+		}
+		// transactions in the wave don't share accounts, therefore order doesn't matter
+		for _, i := range wave {
+			if outcomes[i].ctx != nil {
+				if err := outcomes[i].ctx.Apply(view.cache); err != nil {
+					return nil, nil, 0, fmt.Errorf("%w: %w", core.ErrInternal, err)
+				}
+			}
+		}
+	}
+
+	limit := v.cfg.GasLimit
+	for i := range outcomes {
+		if outcomes[i].maxGas > limit {
+			parallelBlocks.WithLabelValues(fallbackGas).Inc()
+			return v.executeSequential(lctx, ss, txs)
+		}
+		if outcomes[i].ctx != nil {
+			limit -= outcomes[i].ctx.Consumed()
+		}
+	}
+
+	var (
+		fees        uint64
+		ineffective []types.Transaction
+		executed    []types.TransactionWithResult
+	)
+	for i := range outcomes {
+		out := &outcomes[i]
+		if out.ineffective != nil {
+			ineffective = append(ineffective, *out.ineffective)
+			continue
+		}
+		if err := out.ctx.Apply(ss); err != nil {
+			return nil, nil, 0, fmt.Errorf("%w: %w", core.ErrInternal, err)
+		}
+		fees += out.ctx.Fee()
+		executed = append(executed, out.result)
+	}
+	parallelBlocks.WithLabelValues(parallel).Inc()
+	return executed, ineffective, fees, nil
+}
+
+// executeCompared executes transactions concurrently on a copy of the state,
+// and then sequentially. Returns an internal error if results are not the same.
+func (v *VM) executeCompared(lctx ApplyContext, ss *core.StagedCache, txs []types.Transaction) ([]types.TransactionWithResult, []types.Transaction, uint64, error) {
+	shadow := core.NewStagedCache(ss)
+	pexecuted, pineffective, pfees, err := v.executeParallel(lctx, shadow, txs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	executed, ineffective, fees, err := v.executeSequential(lctx, ss, txs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var expected, actual []core.Account
+	ss.IterateChanged(func(account *core.Account) bool {
+		expected = append(expected, *account)
+		return true
+	})
+	shadow.IterateChanged(func(account *core.Account) bool {
+		actual = append(actual, *account)
+		return true
+	})
+	switch {
+	case !reflect.DeepEqual(executed, pexecuted):
+		err = fmt.Errorf("executed transactions don't match in layer %s", lctx.Layer)
+	case !reflect.DeepEqual(ineffective, pineffective):
+		err = fmt.Errorf("ineffective transactions don't match in layer %s", lctx.Layer)
+	case fees != pfees:
+		err = fmt.Errorf("fees don't match in layer %s: %d != %d", lctx.Layer, fees, pfees)
+	case !reflect.DeepEqual(expected, actual):
+		err = fmt.Errorf("updated accounts don't match in layer %s", lctx.Layer)
+	}
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%w: %w", core.ErrInternal, err)
+	}
+	return executed, ineffective, fees, nil
+}
+
+// accessLists returns accounts that will be loaded by each transaction if it is executed
+// with the state at the start of the block. List is nil if accounts can't be predicted.
+func (v *VM) accessLists(lctx ApplyContext, loader core.AccountLoader, txs []types.Transaction) [][]core.Address {
+	var (
+		rd      bytes.Reader
+		decoder = scale.NewDecoder(&rd)
+		lists   = make([][]core.Address, len(txs))
+	)
+	for i := range txs {
+		rd.Reset(txs[i].GetRaw().Raw)
+		header, ctx, args, err := parse(v.logger, lctx.Layer, lctx.Layer, v.registry, loader, v.cfg, txs[i].GetRaw().Raw, decoder)
+		if err != nil {
+			continue
+		}
+		if header.Method == core.MethodSpawn {
+			lists[i] = []core.Address{header.Principal, core.ComputePrincipal(header.TemplateAddress, args)}
+			continue
+		}
+		template, ok := ctx.PrincipalTemplate.(core.AccessListTemplate)
+		if !ok {
+			continue
+		}
+		lists[i] = append([]core.Address{header.Principal}, template.AccessList(header.Method, args)...)
+	}
+	return lists
+}
+
+// schedule splits transactions into waves. Transactions in the same wave don't access
+// the same accounts, and are scheduled after all preceding transactions that access them.
+// Transactions without access list are scheduled alone.
+func schedule(lists [][]core.Address) [][]int {
+	var (
+		waves   [][]int
+		last    = map[core.Address]int{}
+		barrier = -1
+	)
+	for i, list := range lists {
+		wave := barrier + 1
+		if list == nil {
+			wave = len(waves)
+			barrier = wave
+		} else {
+			for _, addr := range list {
+				if prev, exist := last[addr]; exist && prev >= wave {
+					wave = prev + 1
+				}
+			}
+			for _, addr := range list {
+				last[addr] = wave
+			}
+		}
+		if wave == len(waves) {
+			waves = append(waves, nil)
+		}
+		waves[wave] = append(waves[wave], i)
+	}
+	return waves
+}
+
+// lockedCache allows to load accounts concurrently.
+type lockedCache struct {
+	mu    sync.Mutex
+	cache *core.StagedCache
+}
+
+func (l *lockedCache) Get(address core.Address) (core.Account, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cache.Get(address)
+}
+
+// accessGuard records if transaction loads an account that is not in the access list.
+type accessGuard struct {
+	allowed []core.Address
+	loader  core.AccountLoader
+	escaped *atomic.Bool
+}
+
+func (g *accessGuard) Get(address core.Address) (core.Account, error) {
+	if !slices.Contains(g.allowed, address) {
+		g.escaped.Store(true)
+	}
+	return g.loader.Get(address)
+}
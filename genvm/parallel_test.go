@@ -0,0 +1,174 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+func TestSchedule(t *testing.T) {
+	a, b, c, d := core.Address{'a'}, core.Address{'b'}, core.Address{'c'}, core.Address{'d'}
+	for _, tc := range []struct {
+		desc   string
+		lists  [][]core.Address
+		expect [][]int
+	}{
+		{
+			desc:   "independent",
+			lists:  [][]core.Address{{a, b}, {c, d}},
+			expect: [][]int{{0, 1}},
+		},
+		{
+			desc:   "same principal",
+			lists:  [][]core.Address{{a, b}, {a, c}, {a, d}},
+			expect: [][]int{{0}, {1}, {2}},
+		},
+		{
+			desc:   "destination",
+			lists:  [][]core.Address{{a, b}, {c, d}, {d, a}, {b, c}},
+			expect: [][]int{{0, 1}, {2, 3}},
+		},
+		{
+			desc:   "chain",
+			lists:  [][]core.Address{{a, b}, {b, c}, {d, d}, {c, d}},
+			expect: [][]int{{0, 2}, {1}, {3}},
+		},
+		{
+			desc:   "barrier",
+			lists:  [][]core.Address{{a, b}, {c, d}, nil, {a, b}, {c, d}},
+			expect: [][]int{{0, 1}, {2}, {3, 4}},
+		},
+		{
+			desc:   "after barrier",
+			lists:  [][]core.Address{{a, b}, {a, c}, nil, {d, d}},
+			expect: [][]int{{0}, {1}, {2}, {3}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expect, schedule(tc.lists))
+		})
+	}
+}
+
+func TestAccessLists(t *testing.T) {
+	tt := newTester(t).withSeed(11).addSingleSig(3).addVesting(1, 1, 2).applyGenesis()
+	lid := types.GetEffectiveGenesis()
+	_, _, err := tt.Apply(testContext(lid), notVerified(tt.selfSpawn(0), tt.selfSpawn(3)), nil)
+	require.NoError(t, err)
+
+	spawned := tt.accounts[0].getAddress()
+	txs := notVerified(
+		tt.spend(0, 1, 100),
+		tt.selfSpawn(1),
+		tt.spend(2, 1, 100),
+		tt.spawn(3, 2),
+	)
+	ss := core.NewStagedCache(core.DBLoader{Executor: tt.db})
+	lists := tt.accessLists(testContext(lid.Add(1)), ss, txs)
+	require.Equal(t, [][]core.Address{
+		{spawned, tt.accounts[1].getAddress()},
+		{tt.accounts[1].getAddress(), tt.accounts[1].getAddress()},
+		nil, // not spawned
+		{tt.accounts[3].getAddress(), tt.accounts[2].getAddress()},
+	}, lists)
+}
+
+func TestParallelExecution(t *testing.T) {
+	const workers = 4
+	for _, tc := range []struct {
+		desc     string
+		gasLimit uint64
+		block    func(*tester) []types.RawTx
+	}{
+		{
+			desc: "random spends",
+			block: func(tt *tester) []types.RawTx {
+				return tt.randSpendN(40, 10)
+			},
+		},
+		{
+			desc: "spawn and spend in the same block",
+			block: func(tt *tester) []types.RawTx {
+				return []types.RawTx{tt.selfSpawn(20), tt.spend(20, 0, 10), tt.spend(0, 20, 10), tt.spend(1, 2, 10)}
+			},
+		},
+		{
+			desc:     "out of gas",
+			gasLimit: 100_000,
+			block: func(tt *tester) []types.RawTx {
+				return tt.randSpendN(40, 10)
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := newTester(t).withSeed(101).addSingleSig(20).applyGenesis().addSingleSig(1)
+			lid := types.GetEffectiveGenesis()
+			_, _, err := tt.Apply(testContext(lid), notVerified(tt.spawnAll()[:20]...), nil)
+			require.NoError(t, err)
+			if tc.gasLimit != 0 {
+				tt.withGasLimit(tc.gasLimit)
+			}
+
+			txs := notVerified(tc.block(tt)...)
+			ctx := testContext(lid.Add(1))
+			sequential := core.NewStagedCache(core.DBLoader{Executor: tt.db})
+			executed, ineffective, fees, err := tt.executeSequential(ctx, sequential, txs)
+			require.NoError(t, err)
+
+			tt.cfg.ExecutionWorkers = workers
+			parallel := core.NewStagedCache(core.DBLoader{Executor: tt.db})
+			pexecuted, pineffective, pfees, err := tt.executeParallel(ctx, parallel, txs)
+			require.NoError(t, err)
+
+			require.Equal(t, executed, pexecuted)
+			require.Equal(t, ineffective, pineffective)
+			require.Equal(t, fees, pfees)
+			var expected, actual []core.Account
+			sequential.IterateChanged(func(account *core.Account) bool {
+				expected = append(expected, *account)
+				return true
+			})
+			parallel.IterateChanged(func(account *core.Account) bool {
+				actual = append(actual, *account)
+				return true
+			})
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestExecuteCountsOnce(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		compare  bool
+		gasLimit uint64
+	}{
+		{desc: "parallel"},
+		{desc: "fallback", gasLimit: 100_000},
+		{desc: "compared", compare: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := newTester(t).withSeed(101).addSingleSig(20).applyGenesis()
+			lid := types.GetEffectiveGenesis()
+			_, _, err := tt.Apply(testContext(lid), notVerified(tt.spawnAll()...), nil)
+			require.NoError(t, err)
+			if tc.gasLimit != 0 {
+				tt.withGasLimit(tc.gasLimit)
+			}
+			tt.cfg.ExecutionWorkers = 4
+			tt.cfg.CompareExecution = tc.compare
+
+			txs := notVerified(tt.randSpendN(40, 10)...)
+			count, invalid := testutil.ToFloat64(txCount), testutil.ToFloat64(invalidTxCount)
+			ss := core.NewStagedCache(core.DBLoader{Executor: tt.db})
+			_, ineffective, _, err := tt.execute(testContext(lid.Add(1)), ss, txs)
+			require.NoError(t, err)
+			require.Equal(t, count+float64(len(txs)), testutil.ToFloat64(txCount))
+			require.Equal(t, invalid+float64(len(ineffective)), testutil.ToFloat64(invalidTxCount))
+		})
+	}
+}
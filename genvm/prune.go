@@ -0,0 +1,116 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql/accounts"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+)
+
+// ErrPruned is returned when reverting to the layer with pruned account history.
+var ErrPruned = accounts.ErrPruned
+
+// pruneBatchLayers is the number of layers pruned in a single database transaction.
+const pruneBatchLayers = 1000
+
+// Prune periodically removes account history older than HistoryLayers from the last
+// applied layer, keeping state at snapshot layers. Returns nil when context is canceled.
+// Does nothing in archive mode.
+func (v *VM) Prune(ctx context.Context) error {
+	if v.cfg.Archive {
+		return nil
+	}
+	if v.cfg.SnapshotInterval == 0 {
+		return errors.New("snapshot interval must be positive")
+	}
+	if v.cfg.PruneInterval <= 0 {
+		return errors.New("prune interval must be positive")
+	}
+	ticker := time.NewTicker(v.cfg.PruneInterval)
+	defer ticker.Stop()
+	// history is not inspected again before the snapshot preceding the last pruned layer.
+	pruned, interval, err := accounts.Pruned(v.db)
+	if err != nil {
+		return err
+	}
+	if pruned != 0 && interval != v.cfg.SnapshotInterval {
+		return fmt.Errorf("%w: history was pruned with snapshot interval %d, configured %d",
+			accounts.ErrSnapshotInterval, interval, v.cfg.SnapshotInterval)
+	}
+	for {
+		applied, err := layers.GetLastApplied(v.db)
+		if err != nil {
+			v.logger.With().Warning("failed to load last applied layer", log.Err(err))
+		} else if applied.Uint32() > v.cfg.HistoryLayers {
+			target := applied.Sub(v.cfg.HistoryLayers)
+			pruned, err = v.prune(ctx, pruned, target)
+			if err != nil && ctx.Err() == nil {
+				v.logger.With().Warning("failed to prune account history",
+					log.Stringer("target", target),
+					log.Err(err),
+				)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune account history in batches from the last pruned layer to the target,
+// and returns the last pruned layer.
+func (v *VM) prune(ctx context.Context, pruned, target types.LayerID) (types.LayerID, error) {
+	v.mu.Lock()
+	if v.horizon.Before(target) {
+		v.horizon = target
+	}
+	v.mu.Unlock()
+	for pruned.Before(target) {
+		if err := ctx.Err(); err != nil {
+			return pruned, err
+		}
+		to := min(pruned.Add(pruneBatchLayers), target)
+		from := types.LayerID(pruned.Uint32() / v.cfg.SnapshotInterval * v.cfg.SnapshotInterval)
+		start := time.Now()
+		rows, err := v.pruneBatch(ctx, from, to)
+		if err != nil {
+			return pruned, err
+		}
+		pruneDuration.Observe(float64(time.Since(start)))
+		prunedAccounts.Add(float64(rows))
+		prunedLayer.Set(float64(to))
+		v.logger.With().Debug("pruned account history",
+			log.Stringer("from", from),
+			log.Stringer("to", to),
+			log.Int("rows", rows),
+			log.Duration("duration", time.Since(start)),
+		)
+		pruned = to
+	}
+	return pruned, nil
+}
+
+func (v *VM) pruneBatch(ctx context.Context, from, to types.LayerID) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	tx, err := v.db.TxImmediate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Release()
+	rows, err := accounts.Prune(tx, from, to, v.cfg.SnapshotInterval)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit pruned accounts: %w", err)
+	}
+	return rows, nil
+}
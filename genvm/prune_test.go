@@ -0,0 +1,100 @@
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/sql/accounts"
+)
+
+func TestPrune(t *testing.T) {
+	const (
+		history  = 3
+		interval = 4
+		applied  = 12
+	)
+	tt := newTester(t).withSeed(7).addSingleSig(5).applyGenesis()
+	tt.cfg.Archive = false
+	tt.cfg.HistoryLayers = history
+	tt.cfg.SnapshotInterval = interval
+
+	genesis := types.GetEffectiveGenesis()
+	_, _, err := tt.Apply(testContext(genesis), notVerified(tt.spawnAll()...), nil)
+	require.NoError(t, err)
+	for i := uint32(1); i <= applied; i++ {
+		_, _, err := tt.Apply(testContext(genesis.Add(i)), notVerified(tt.randSpendN(3, 10)...), nil)
+		require.NoError(t, err)
+	}
+	last := genesis.Add(applied)
+	root, err := tt.GetGenesisStateRoot()
+	require.NoError(t, err)
+	snapshots := map[types.LayerID][]*types.Account{}
+	for lid := types.LayerID(0); !last.Before(lid); lid++ {
+		snapshot, err := accounts.Snapshot(tt.db, lid)
+		require.NoError(t, err)
+		snapshots[lid] = snapshot
+	}
+
+	count := func() int {
+		rows, err := tt.db.Exec("select 1 from accounts;", nil, nil)
+		require.NoError(t, err)
+		return rows
+	}
+	before := count()
+
+	target := last.Sub(history)
+	pruned, err := tt.prune(context.Background(), 0, target)
+	require.NoError(t, err)
+	require.Equal(t, target, pruned)
+	require.Less(t, count(), before)
+	for lid, expected := range snapshots {
+		if lid.Uint32()%interval != 0 && lid.Before(target) {
+			continue
+		}
+		actual, err := accounts.Snapshot(tt.db, lid)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, "layer %s", lid)
+	}
+	pruned, err = tt.prune(context.Background(), pruned, target)
+	require.NoError(t, err)
+	require.Equal(t, target, pruned)
+	groot, err := tt.GetGenesisStateRoot()
+	require.NoError(t, err)
+	require.Equal(t, root, groot)
+
+	require.ErrorIs(t, tt.Revert(target.Sub(1)), ErrPruned)
+	_, err = accounts.Snapshot(tt.db, target.Sub(1))
+	require.ErrorIs(t, err, ErrPruned)
+
+	// horizon is loaded from the database after restart
+	restarted := New(tt.db, WithLogger(logtest.New(t)), WithConfig(tt.cfg))
+	require.ErrorIs(t, restarted.Revert(target.Sub(1)), ErrPruned)
+	require.NoError(t, restarted.Revert(target))
+	actual, err := accounts.Snapshot(tt.db, last)
+	require.NoError(t, err)
+	require.Equal(t, snapshots[target], actual)
+
+	// snapshot interval can't be changed after history was pruned
+	cfg := tt.cfg
+	cfg.SnapshotInterval = interval * 2
+	cfg.PruneInterval = time.Minute
+	restarted = New(tt.db, WithLogger(logtest.New(t)), WithConfig(cfg))
+	require.ErrorIs(t, restarted.Prune(context.Background()), accounts.ErrSnapshotInterval)
+}
+
+func TestPruneArchive(t *testing.T) {
+	tt := newTester(t).addSingleSig(1).applyGenesis()
+	tt.cfg.Archive = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, tt.Prune(ctx))
+
+	tt.cfg.Archive = false
+	tt.cfg.SnapshotInterval = 0
+	require.Error(t, tt.Prune(ctx))
+}
@@ -0,0 +1,137 @@
+package vm
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/spacemeshos/go-scale"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
+	sdkscheduler "github.com/spacemeshos/go-spacemesh/genvm/sdk/scheduler"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/scheduler"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql/accounts"
+)
+
+func signExecute(pk signing.PrivateKey, tx []byte) []byte {
+	return append(tx, ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(types.Hash20{}.Bytes(), tx))...)
+}
+
+func TestScheduler(t *testing.T) {
+	const (
+		balance  = 1_000_000
+		amount   = 1000
+		maxPrice = 2
+	)
+	tt := newTester(t)
+	_, pk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	key := signing.PrivateKey(pk)
+	principal := sdkscheduler.Address(signing.Public(key))
+	recipient := types.Address{'r'}
+	require.NoError(t, tt.ApplyGenesis([]core.Account{{Address: principal, Balance: balance}}))
+
+	apply := func(lid types.LayerID, raw []byte) types.TransactionWithResult {
+		t.Helper()
+		skipped, results, err := tt.Apply(testContext(lid), notVerified(types.NewRawTx(raw)), nil)
+		require.NoError(t, err)
+		require.Empty(t, skipped)
+		require.Len(t, results, 1)
+		return results[0]
+	}
+	ineffective := func(lid types.LayerID, raw []byte) {
+		t.Helper()
+		skipped, results, err := tt.Apply(testContext(lid), notVerified(types.NewRawTx(raw)), nil)
+		require.NoError(t, err)
+		require.Len(t, skipped, 1)
+		require.Empty(t, results)
+	}
+	state := func() *scheduler.Scheduler {
+		t.Helper()
+		account, err := accounts.Latest(tt.db, principal)
+		require.NoError(t, err)
+		var state scheduler.Scheduler
+		_, err = state.DecodeScale(scale.NewDecoder(bytes.NewReader(account.State)))
+		require.NoError(t, err)
+		return &state
+	}
+
+	lid := types.GetEffectiveGenesis()
+	rst := apply(lid, sdkscheduler.SelfSpawn(key, 0))
+	require.Equal(t, types.TransactionSuccess, rst.Status, rst.Message)
+
+	lid = lid.Add(1)
+	rst = apply(lid, sdkscheduler.Schedule(key, &scheduler.ScheduleArguments{
+		Recipient:   recipient,
+		Amount:      amount,
+		Start:       lid.Add(2),
+		Interval:    2,
+		Count:       3,
+		MaxGasPrice: maxPrice,
+	}, 1))
+	require.Equal(t, types.TransactionSuccess, rst.Status, rst.Message)
+	require.Len(t, state().Payments, 1)
+
+	verify := func(raw []byte) bool {
+		t.Helper()
+		req := tt.Validation(types.NewRawTx(raw))
+		_, err := req.Parse()
+		require.NoError(t, err)
+		return req.Verify()
+	}
+	t.Run("unsigned execute", func(t *testing.T) {
+		require.True(t, verify(sdkscheduler.Execute(principal, 0, amount, 2, sdk.WithGasPrice(maxPrice))))
+		require.False(t, verify(sdkscheduler.Execute(principal, 0, amount, 2, sdk.WithGasPrice(maxPrice+1))))
+		require.False(t, verify(sdkscheduler.Execute(principal, 1, amount, 2)))
+		require.False(t, verify(sdkscheduler.Execute(principal, 0, amount-1, 2)))
+	})
+
+	// third party can't spend nonces and balance with executes that don't transfer anything
+	before, err := accounts.Latest(tt.db, principal)
+	require.NoError(t, err)
+	lid = lid.Add(1)
+	ineffective(lid, sdkscheduler.Execute(principal, 0, amount, 2, sdk.WithGasPrice(maxPrice)))
+	ineffective(lid, sdkscheduler.Execute(principal, 1, amount, 2, sdk.WithGasPrice(maxPrice)))
+	ineffective(lid.Add(2), sdkscheduler.Execute(principal, 0, amount-1, 2, sdk.WithGasPrice(maxPrice)))
+	after, err := accounts.Latest(tt.db, principal)
+	require.NoError(t, err)
+	require.Equal(t, before.Balance, after.Balance)
+	require.Equal(t, before.NextNonce, after.NextNonce)
+
+	// two installments are due, both are executed at once by the recipient
+	lid = lid.Add(3)
+	rst = apply(lid, sdkscheduler.Execute(principal, 0, 3*amount, 2, sdk.WithGasPrice(maxPrice)))
+	require.Equal(t, types.TransactionSuccess, rst.Status, rst.Message)
+	received, err := tt.GetBalance(recipient)
+	require.NoError(t, err)
+	require.EqualValues(t, 2*amount, received)
+	require.Len(t, state().Payments, 1)
+	require.EqualValues(t, 1, state().Payments[0].Remaining)
+
+	// owner may execute with a signature at any gas price
+	lid = lid.Add(2)
+	rst = apply(lid, signExecute(key, sdkscheduler.Execute(principal, 0, amount, 3, sdk.WithGasPrice(2*maxPrice))))
+	require.Equal(t, types.TransactionSuccess, rst.Status, rst.Message)
+	received, err = tt.GetBalance(recipient)
+	require.NoError(t, err)
+	require.EqualValues(t, 3*amount, received)
+	require.Empty(t, state().Payments)
+
+	// interval that overflows the layer is rejected, instead of failing when executed
+	lid = lid.Add(1)
+	rst = apply(lid, sdkscheduler.Schedule(key, &scheduler.ScheduleArguments{
+		Recipient: recipient,
+		Amount:    1,
+		Start:     1,
+		Interval:  math.MaxUint32,
+		Count:     2,
+	}, 4))
+	require.Equal(t, types.TransactionFailure, rst.Status)
+	require.Contains(t, rst.Message, scheduler.ErrInvalidSchedule.Error())
+	require.Empty(t, state().Payments)
+}
@@ -91,7 +91,7 @@ func Spawn(ref uint8, pk ed25519.PrivateKey, principal, template types.Address,
 	payload.Nonce = nonce
 	payload.GasPrice = options.GasPrice
 
-	tx := encode(&sdk.TxVersion, &principal, &sdk.MethodSpawn, &template, &payload, args)
+	tx := encode(options.Version(), &principal, &sdk.MethodSpawn, &template, &payload, args)
 	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
 	aggregator := &Aggregator{unsigned: tx, parts: map[uint8]multisig.Part{}}
 	part := multisig.Part{Ref: ref}
@@ -115,7 +115,35 @@ func Spend(ref uint8, pk ed25519.PrivateKey, principal, to types.Address, amount
 	args.Destination = to
 	args.Amount = amount
 
-	tx := encode(&sdk.TxVersion, &principal, &sdk.MethodSpend, &payload, &args)
+	tx := encode(options.Version(), &principal, &sdk.MethodSpend, &payload, &args)
+	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
+	aggregator := &Aggregator{unsigned: tx, parts: map[uint8]multisig.Part{}}
+	part := multisig.Part{Ref: ref}
+	copy(part.Sig[:], sig)
+	aggregator.Add(part)
+	return aggregator
+}
+
+// UpdateKeys creates transaction that replaces public keys and the number of required signatures.
+// It has to be signed by the number of keys that is required before the update.
+func UpdateKeys(ref uint8, pk ed25519.PrivateKey, principal types.Address, required uint8, pubs []ed25519.PublicKey, nonce types.Nonce, opts ...sdk.Opt) *Aggregator {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	payload := core.Payload{}
+	payload.GasPrice = options.GasPrice
+	payload.Nonce = nonce
+
+	args := multisig.UpdateKeysArguments{Required: required}
+	args.PublicKeys = make([]core.PublicKey, len(pubs))
+	for i := range pubs {
+		copy(args.PublicKeys[i][:], pubs[i])
+	}
+
+	method := scale.U8(multisig.MethodUpdateKeys)
+	tx := encode(options.Version(), &principal, &method, &payload, &args)
 	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
 	aggregator := &Aggregator{unsigned: tx, parts: map[uint8]multisig.Part{}}
 	part := multisig.Part{Ref: ref}
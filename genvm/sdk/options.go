@@ -17,8 +17,15 @@ func Defaults() *Options {
 
 // Options to modify common transaction fields.
 type Options struct {
-	GasPrice  uint64
-	GenesisID types.Hash20
+	GasPrice    uint64
+	GenesisID   types.Hash20
+	LayerLimits types.LayerLimits
+}
+
+// Version returns encodable transaction version. Transactions with layer limits
+// are encoded with version 1 and limits are appended right after the version.
+func (o *Options) Version() scale.Encodable {
+	return &version{limits: o.LayerLimits}
 }
 
 // WithGasPrice modifies GasPrice.
@@ -35,9 +42,35 @@ func WithGenesisID(id types.Hash20) Opt {
 	}
 }
 
+// WithLayerLimits restricts layers where transaction may be applied.
+// Zero max means that transaction doesn't expire.
+func WithLayerLimits(min, max uint32) Opt {
+	return func(opts *Options) {
+		opts.LayerLimits = types.LayerLimits{Min: min, Max: max}
+	}
+}
+
+type version struct {
+	limits types.LayerLimits
+}
+
+func (v *version) EncodeScale(enc *scale.Encoder) (int, error) {
+	if v.limits == (types.LayerLimits{}) {
+		return TxVersion.EncodeScale(enc)
+	}
+	total, err := TxVersionLimits.EncodeScale(enc)
+	if err != nil {
+		return total, err
+	}
+	n, err := v.limits.EncodeScale(enc)
+	return total + n, err
+}
+
 var (
 	// TxVersion is the only version supported at genesis.
 	TxVersion = scale.U8(0)
+	// TxVersionLimits is a version with layer limits.
+	TxVersionLimits = scale.U8(1)
 
 	// MethodSpawn ...
 	MethodSpawn = scale.U8(core.MethodSpawn)
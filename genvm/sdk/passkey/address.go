@@ -0,0 +1,12 @@
+package passkey
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/passkey"
+)
+
+// Address computes passkey wallet address from the compressed p-256 public key.
+func Address(pub passkey.PublicKey) types.Address {
+	return core.ComputePrincipal(passkey.TemplateAddress, &passkey.SpawnArguments{PublicKey: pub})
+}
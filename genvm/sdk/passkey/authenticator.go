@@ -0,0 +1,51 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/passkey"
+)
+
+// PublicKey returns compressed public key of the p-256 private key.
+func PublicKey(pk *ecdsa.PrivateKey) passkey.PublicKey {
+	var pub passkey.PublicKey
+	copy(pub[:], elliptic.MarshalCompressed(elliptic.P256(), pk.X, pk.Y))
+	return pub
+}
+
+// Assert produces an assertion for the challenge in the same way as a WebAuthn authenticator
+// registered for the relying party. It is meant for tools and tests that hold the key in memory.
+func Assert(pk *ecdsa.PrivateKey, rpID, origin string, challenge []byte) (*passkey.Assertion, error) {
+	client, err := json.Marshal(passkey.ClientData{
+		Type:      "webauthn.get",
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    origin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode client data: %w", err)
+	}
+	rpHash := sha256.Sum256([]byte(rpID))
+	// flags with user present bit set, followed by zero signature counter
+	auth := append(rpHash[:], 0x01, 0, 0, 0, 0)
+	clientHash := sha256.Sum256(client)
+	digest := sha256.Sum256(append(auth[:len(auth):len(auth)], clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, pk, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign assertion: %w", err)
+	}
+	sig, err = passkey.NormalizeSignature(sig)
+	if err != nil {
+		return nil, fmt.Errorf("normalize signature: %w", err)
+	}
+	return &passkey.Assertion{
+		AuthenticatorData: auth,
+		ClientDataJSON:    client,
+		Signature:         sig,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package passkey
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/passkey"
+)
+
+// Tx is a transaction that has to be asserted by the passkey.
+type Tx struct {
+	unsigned []byte
+	genesis  types.Hash20
+}
+
+// Challenge that has to be passed to the authenticator, e.g. as
+// publicKey.challenge to navigator.credentials.get.
+func (tx *Tx) Challenge() []byte {
+	return passkey.Challenge(core.SigningBody(tx.genesis[:], tx.unsigned))
+}
+
+// Raw returns full raw transaction with the assertion.
+func (tx *Tx) Raw(assertion *passkey.Assertion) []byte {
+	raw := make([]byte, 0, len(tx.unsigned))
+	raw = append(raw, tx.unsigned...)
+	return append(raw, sdk.Encode(assertion)...)
+}
+
+// SelfSpawn creates a self-spawn transaction.
+func SelfSpawn(pub passkey.PublicKey, nonce core.Nonce, opts ...sdk.Opt) *Tx {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	payload := core.Payload{}
+	payload.Nonce = nonce
+	payload.GasPrice = options.GasPrice
+
+	args := passkey.SpawnArguments{PublicKey: pub}
+	principal := core.ComputePrincipal(passkey.TemplateAddress, &args)
+	return &Tx{
+		unsigned: sdk.Encode(options.Version(), &principal, &sdk.MethodSpawn, &passkey.TemplateAddress, &payload, &args),
+		genesis:  options.GenesisID,
+	}
+}
+
+// Spend creates spend transaction.
+func Spend(pub passkey.PublicKey, to types.Address, amount uint64, nonce types.Nonce, opts ...sdk.Opt) *Tx {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	principal := Address(pub)
+
+	payload := core.Payload{}
+	payload.GasPrice = options.GasPrice
+	payload.Nonce = nonce
+
+	args := passkey.SpendArguments{}
+	args.Destination = to
+	args.Amount = amount
+	return &Tx{
+		unsigned: sdk.Encode(options.Version(), &principal, &sdk.MethodSpend, &payload, &args),
+		genesis:  options.GenesisID,
+	}
+}
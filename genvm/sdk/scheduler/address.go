@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/scheduler"
+)
+
+// Address computes scheduler address from the public key.
+func Address(pub []byte) types.Address {
+	if len(pub) != 32 {
+		panic("public key must be 32 bytes")
+	}
+	args := scheduler.SpawnArguments{}
+	copy(args.PublicKey[:], pub)
+	return core.ComputePrincipal(scheduler.TemplateAddress, &args)
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/spacemeshos/go-scale"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/scheduler"
+	"github.com/spacemeshos/go-spacemesh/signing"
+)
+
+func principal(pk signing.PrivateKey) core.Address {
+	return Address(signing.Public(pk))
+}
+
+func signed(pk signing.PrivateKey, options *sdk.Options, fields ...scale.Encodable) []byte {
+	tx := sdk.Encode(fields...)
+	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
+	return append(tx, sig...)
+}
+
+func payload(nonce core.Nonce, opts []sdk.Opt) (*sdk.Options, *core.Payload) {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options, &core.Payload{Nonce: nonce, GasPrice: options.GasPrice}
+}
+
+// SelfSpawn creates a self-spawn transaction.
+func SelfSpawn(pk signing.PrivateKey, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	args := scheduler.SpawnArguments{}
+	copy(args.PublicKey[:], signing.Public(pk))
+	options, payload := payload(nonce, opts)
+	principal := principal(pk)
+	return signed(pk, options, options.Version(), &principal, &sdk.MethodSpawn, &scheduler.TemplateAddress, payload, &args)
+}
+
+// Spend creates spend transaction.
+func Spend(pk signing.PrivateKey, to types.Address, amount uint64, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	options, payload := payload(nonce, opts)
+	principal := principal(pk)
+	args := scheduler.SpendArguments{Destination: to, Amount: amount}
+	return signed(pk, options, options.Version(), &principal, &sdk.MethodSpend, payload, &args)
+}
+
+// Schedule creates a transaction that adds a payment to the schedule.
+func Schedule(pk signing.PrivateKey, args *scheduler.ScheduleArguments, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	options, payload := payload(nonce, opts)
+	principal := principal(pk)
+	method := scale.U8(scheduler.MethodSchedule)
+	return signed(pk, options, options.Version(), &principal, &method, payload, args)
+}
+
+// Cancel creates a transaction that removes a payment from the schedule.
+func Cancel(pk signing.PrivateKey, id uint32, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	options, payload := payload(nonce, opts)
+	principal := principal(pk)
+	method := scale.U8(scheduler.MethodCancel)
+	return signed(pk, options, options.Version(), &principal, &method, payload, &scheduler.CancelArguments{ID: id})
+}
+
+// Execute creates an unsigned transaction that transfers due installments of the payment.
+// Gas price must not exceed MaxGasPrice of the payment.
+func Execute(principal types.Address, id uint32, maxAmount uint64, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	options, payload := payload(nonce, opts)
+	method := scale.U8(scheduler.MethodExecute)
+	args := scheduler.ExecuteArguments{ID: id, MaxAmount: maxAmount}
+	return sdk.Encode(options.Version(), &principal, &method, payload, &args)
+}
@@ -0,0 +1,16 @@
+package secp256k1
+
+import (
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/secp256k1"
+)
+
+// Address computes secp256k1 wallet address from the public key.
+func Address(pub *secp.PublicKey) types.Address {
+	args := secp256k1.SpawnArguments{}
+	copy(args.PublicKey[:], pub.SerializeCompressed())
+	return core.ComputePrincipal(secp256k1.TemplateAddress, &args)
+}
@@ -0,0 +1,60 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/secp256k1"
+)
+
+// Sign returns compact signature (r || s) for the sha256 digest of the signing body.
+func Sign(pk *secp.PrivateKey, genesis types.Hash20, tx []byte) []byte {
+	hash := sha256.Sum256(core.SigningBody(genesis[:], tx))
+	// first byte is a public key recovery code, it is not used by the template
+	return ecdsa.SignCompact(pk, hash[:], true)[1:]
+}
+
+// SelfSpawn creates a self-spawn transaction.
+func SelfSpawn(pk *secp.PrivateKey, nonce core.Nonce, opts ...sdk.Opt) []byte {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	payload := core.Payload{}
+	payload.Nonce = nonce
+	payload.GasPrice = options.GasPrice
+
+	args := secp256k1.SpawnArguments{}
+	copy(args.PublicKey[:], pk.PubKey().SerializeCompressed())
+	principal := core.ComputePrincipal(secp256k1.TemplateAddress, &args)
+
+	tx := sdk.Encode(options.Version(), &principal, &sdk.MethodSpawn, &secp256k1.TemplateAddress, &payload, &args)
+	return append(tx, Sign(pk, options.GenesisID, tx)...)
+}
+
+// Spend creates spend transaction.
+func Spend(pk *secp.PrivateKey, to types.Address, amount uint64, nonce types.Nonce, opts ...sdk.Opt) []byte {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	principal := Address(pk.PubKey())
+
+	payload := core.Payload{}
+	payload.GasPrice = options.GasPrice
+	payload.Nonce = nonce
+
+	args := secp256k1.SpendArguments{}
+	args.Destination = to
+	args.Amount = amount
+
+	tx := sdk.Encode(options.Version(), &principal, &sdk.MethodSpend, &payload, &args)
+	return append(tx, Sign(pk, options.GenesisID, tx)...)
+}
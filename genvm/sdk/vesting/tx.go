@@ -39,7 +39,7 @@ func DrainVault(ref uint8, pk ed25519.PrivateKey, principal, vault, receiver typ
 	args.Amount = amount
 
 	method := scale.U8(vesting.MethodDrainVault)
-	tx := sdk.Encode(&sdk.TxVersion, &principal, &method, &payload, &args)
+	tx := sdk.Encode(options.Version(), &principal, &method, &payload, &args)
 	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
 	aggregator := NewAggregator(tx)
 	part := vesting.Part{Ref: ref}
@@ -48,7 +48,7 @@ func Spawn(pk signing.PrivateKey, template core.Address, args scale.Encodable, n
 	// note that principal is computed from pk
 	principal := core.ComputePrincipal(wallet.TemplateAddress, public)
 
-	tx := encode(&sdk.TxVersion, &principal, &sdk.MethodSpawn, &template, &payload, args)
+	tx := encode(options.Version(), &principal, &sdk.MethodSpawn, &template, &payload, args)
 	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
 	return append(tx, sig...)
 }
@@ -72,7 +72,30 @@ func Spend(pk signing.PrivateKey, to types.Address, amount uint64, nonce types.N
 	args.Destination = to
 	args.Amount = amount
 
-	tx := encode(&sdk.TxVersion, &principal, &sdk.MethodSpend, &payload, &args)
+	tx := encode(options.Version(), &principal, &sdk.MethodSpend, &payload, &args)
+	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
+	return append(tx, sig...)
+}
+
+// BatchSpend creates transaction that transfers coins to every output.
+func BatchSpend(pk signing.PrivateKey, outputs []wallet.SpendArguments, nonce types.Nonce, opts ...sdk.Opt) []byte {
+	options := sdk.Defaults()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	spawnargs := wallet.SpawnArguments{}
+	copy(spawnargs.PublicKey[:], signing.Public(pk))
+	principal := core.ComputePrincipal(wallet.TemplateAddress, &spawnargs)
+
+	payload := core.Payload{}
+	payload.GasPrice = options.GasPrice
+	payload.Nonce = nonce
+
+	args := wallet.BatchSpendArguments{Outputs: outputs}
+	method := scale.U8(wallet.MethodBatchSpend)
+
+	tx := encode(options.Version(), &principal, &method, &payload, &args)
 	sig := ed25519.Sign(ed25519.PrivateKey(pk), core.SigningBody(options.GenesisID[:], tx))
 	return append(tx, sig...)
 }
@@ -0,0 +1,90 @@
+package vm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	sdkpasskey "github.com/spacemeshos/go-spacemesh/genvm/sdk/passkey"
+	sdksecp256k1 "github.com/spacemeshos/go-spacemesh/genvm/sdk/secp256k1"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/passkey"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/secp256k1"
+)
+
+type schemeTestCase struct {
+	desc      string
+	principal core.Address
+	spawn     func(core.Nonce) []byte
+	spend     func(core.Nonce) []byte
+	gas       func(method uint8) uint64
+}
+
+func TestSignatureSchemes(t *testing.T) {
+	const (
+		balance = 1_000_000_000
+		amount  = 100
+	)
+	recipient := types.Address{'r'}
+	for _, tc := range []schemeTestCase{
+		func() (tc schemeTestCase) {
+			pk, err := secp.GeneratePrivateKey()
+			require.NoError(t, err)
+			tc.desc = "secp256k1"
+			tc.principal = sdksecp256k1.Address(pk.PubKey())
+			tc.spawn = func(nonce core.Nonce) []byte { return sdksecp256k1.SelfSpawn(pk, nonce) }
+			tc.spend = func(nonce core.Nonce) []byte { return sdksecp256k1.Spend(pk, recipient, amount, nonce) }
+			tc.gas = func(method uint8) uint64 {
+				return secp256k1.BaseGas(method) + secp256k1.LoadGas() + secp256k1.ExecGas(method)
+			}
+			return tc
+		}(),
+		func() (tc schemeTestCase) {
+			pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+			pub := sdkpasskey.PublicKey(pk)
+			sign := func(tx *sdkpasskey.Tx) []byte {
+				assertion, err := sdkpasskey.Assert(pk, "example.com", "https://example.com", tx.Challenge())
+				require.NoError(t, err)
+				return tx.Raw(assertion)
+			}
+			tc.desc = "passkey"
+			tc.principal = sdkpasskey.Address(pub)
+			tc.spawn = func(nonce core.Nonce) []byte { return sign(sdkpasskey.SelfSpawn(pub, nonce)) }
+			tc.spend = func(nonce core.Nonce) []byte { return sign(sdkpasskey.Spend(pub, recipient, amount, nonce)) }
+			tc.gas = func(method uint8) uint64 {
+				return passkey.BaseGas(method) + passkey.LoadGas() + passkey.ExecGas(method)
+			}
+			return tc
+		}(),
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := newTester(t)
+			require.NoError(t, tt.ApplyGenesis([]core.Account{{Address: tc.principal, Balance: balance}}))
+
+			lid := types.GetEffectiveGenesis()
+			spawn, spend := tc.spawn(0), tc.spend(1)
+			skipped, results, err := tt.Apply(testContext(lid), notVerified(
+				types.NewRawTx(spawn),
+				types.NewRawTx(spend),
+				types.NewRawTx(tc.spend(1)[:len(spend)-1]),
+			), nil)
+			require.NoError(t, err)
+			require.Len(t, skipped, 1, "corrupted signature")
+			require.Len(t, results, 2)
+			for _, rst := range results {
+				require.Equal(t, types.TransactionSuccess, rst.Status, rst.Message)
+			}
+			require.Equal(t, core.TxDataGas(len(spend))+tc.gas(core.MethodSpend), results[1].Gas)
+
+			received, err := tt.GetBalance(recipient)
+			require.NoError(t, err)
+			require.EqualValues(t, amount, received)
+		})
+	}
+}
@@ -10,7 +10,7 @@ func BaseGas(method uint8, signatures int) uint64 {
 	switch method {
 	case core.MethodSpawn:
 		return core.TX + core.EDVERIFY*uint64(signatures) + core.SPAWN
-	case core.MethodSpend:
+	case core.MethodSpend, MethodUpdateKeys:
 		return core.TX + core.EDVERIFY*uint64(signatures)
 	}
 	return math.MaxUint64
@@ -30,6 +30,13 @@ func ExecGas(method uint8, keys int) uint64 {
 		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE)
 		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_BALANCE_SIZE)
 		return gas
+	case MethodUpdateKeys:
+		return core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE)
 	}
 	return math.MaxUint64
 }
+
+// KeysGas is a cost of storing n keys, charged in addition to ExecGas of UpdateKeys.
+func KeysGas(n int) uint64 {
+	return core.SizeGas(core.UPDATE, core.PUBLIC_KEY_SIZE*n)
+}
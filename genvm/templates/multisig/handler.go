@@ -50,11 +50,8 @@ func (h *handler) Parse(host core.Host, method uint8, decoder *scale.Decoder) (o
 // New instantiates k-multisig instance.
 func (h *handler) New(args any) (core.Template, error) {
 	spawn := args.(*SpawnArguments)
-	if spawn.Required == 0 {
-		return nil, fmt.Errorf("number of required signatures must be larger than zero")
-	}
-	if len(spawn.PublicKeys) < int(spawn.Required) {
-		return nil, fmt.Errorf("multisig requires atleast %d keys", spawn.Required)
+	if err := validate(spawn.Required, spawn.PublicKeys); err != nil {
+		return nil, err
 	}
 	return &MultiSig{
 		PublicKeys: spawn.PublicKeys,
@@ -83,6 +80,10 @@ func (h *handler) Exec(host core.Host, method uint8, args scale.Encodable) error
 		if err := host.Template().(SpendTemplate).Spend(host, args.(*SpendArguments)); err != nil {
 			return err
 		}
+	case MethodUpdateKeys:
+		if err := host.Template().(UpdateKeysTemplate).UpdateKeys(args.(*UpdateKeysArguments)); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
 	}
@@ -96,6 +97,8 @@ func (h *handler) Args(method uint8) scale.Type {
 		return &SpawnArguments{}
 	case core.MethodSpend:
 		return &SpendArguments{}
+	case MethodUpdateKeys:
+		return &UpdateKeysArguments{}
 	}
 	return nil
 }
@@ -104,3 +107,8 @@ func (h *handler) Args(method uint8) scale.Type {
 type SpendTemplate interface {
 	Spend(core.Host, *SpendArguments) error
 }
+
+// UpdateKeysTemplate interface for the template that support UpdateKeys method.
+type UpdateKeysTemplate interface {
+	UpdateKeys(*UpdateKeysArguments) error
+}
@@ -29,10 +29,18 @@ func (ms *MultiSig) ExecGas(method uint8) uint64 {
 	return ExecGas(method, len(ms.PublicKeys))
 }
 
+// VariableGas charges for every key of the UpdateKeys method.
+func (ms *MultiSig) VariableGas(method uint8, args any) uint64 {
+	if method == MethodUpdateKeys {
+		return KeysGas(len(args.(*UpdateKeysArguments).PublicKeys))
+	}
+	return 0
+}
+
 // MaxSpend returns amount specified in the SpendArguments.
 func (ms *MultiSig) MaxSpend(method uint8, args any) (uint64, error) {
 	switch method {
-	case core.MethodSpawn:
+	case core.MethodSpawn, MethodUpdateKeys:
 		return 0, nil
 	case core.MethodSpend:
 		return args.(*SpendArguments).Amount, nil
@@ -41,6 +49,14 @@ func (ms *MultiSig) MaxSpend(method uint8, args any) (uint64, error) {
 	}
 }
 
+// AccessList returns destination of the Spend method.
+func (ms *MultiSig) AccessList(method uint8, args any) []core.Address {
+	if method == core.MethodSpend {
+		return []core.Address{args.(*SpendArguments).Destination}
+	}
+	return nil
+}
+
 // Verify that transaction is signed has k valid signatures.
 func (ms *MultiSig) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool {
 	sig := make(Signatures, ms.Required)
@@ -65,7 +81,33 @@ func (ms *MultiSig) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool
 	return verified
 }
 
+// Mutates returns true for UpdateKeys.
+func (ms *MultiSig) Mutates(method uint8) bool {
+	return method == MethodUpdateKeys
+}
+
 // Spend transfers an amount to the address specified in SpendArguments.
 func (ms *MultiSig) Spend(host core.Host, args *SpendArguments) error {
 	return host.Transfer(args.Destination, args.Amount)
 }
+
+// UpdateKeys replaces public keys and the number of required signatures.
+// Transaction is verified against the keys and threshold that were set before the update.
+func (ms *MultiSig) UpdateKeys(args *UpdateKeysArguments) error {
+	if err := validate(args.Required, args.PublicKeys); err != nil {
+		return err
+	}
+	ms.Required = args.Required
+	ms.PublicKeys = args.PublicKeys
+	return nil
+}
+
+func validate(required uint8, keys []core.PublicKey) error {
+	if required == 0 {
+		return fmt.Errorf("number of required signatures must be larger than zero")
+	}
+	if len(keys) < int(required) {
+		return fmt.Errorf("multisig requires atleast %d keys", required)
+	}
+	return nil
+}
@@ -188,3 +188,32 @@ func prepSigFromKeys(tb testing.TB, message []byte, privates []ed25519.PrivateKe
 	require.NoError(tb, err)
 	return buf.Bytes()
 }
+
+func TestUpdateKeys(t *testing.T) {
+	ms := MultiSig{Required: 2, PublicKeys: make([]core.PublicKey, 3)}
+	for _, tc := range []struct {
+		desc     string
+		required uint8
+		keys     int
+		err      string
+	}{
+		{desc: "zero required", keys: 2, err: "larger than zero"},
+		{desc: "not enough keys", required: 3, keys: 2, err: "atleast 3 keys"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ms.UpdateKeys(&UpdateKeysArguments{Required: tc.required, PublicKeys: make([]core.PublicKey, tc.keys)})
+			require.ErrorContains(t, err, tc.err)
+			require.EqualValues(t, 2, ms.Required)
+			require.Len(t, ms.PublicKeys, 3)
+		})
+	}
+	t.Run("valid", func(t *testing.T) {
+		keys := []core.PublicKey{{1}, {2}, {3}, {4}}
+		require.NoError(t, ms.UpdateKeys(&UpdateKeysArguments{Required: 3, PublicKeys: keys}))
+		require.EqualValues(t, 3, ms.Required)
+		require.Equal(t, keys, ms.PublicKeys)
+	})
+	max, err := ms.MaxSpend(MethodUpdateKeys, &UpdateKeysArguments{})
+	require.NoError(t, err)
+	require.Zero(t, max)
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
 )
 
+// MethodUpdateKeys replaces the set of public keys and the number of required signatures.
+// Vesting embeds multisig and uses 17 for draining a vault, therefore the selector starts from 18.
+const MethodUpdateKeys = 18
+
+// MaxKeys is the maximal number of public keys in multisig, matches scale limit on PublicKeys.
+const MaxKeys = 10
+
 //go:generate scalegen
 
 // SpawnArguments contains a collection with PublicKeys.
@@ -25,6 +32,13 @@ func (s *SpawnArguments) String() string {
 	return builder.String()
 }
 
+// UpdateKeysArguments contain a new set of public keys and a number of signatures
+// that will be required to authorize subsequent transactions.
+type UpdateKeysArguments struct {
+	Required   uint8
+	PublicKeys []core.PublicKey `scale:"max=10"` // match MaxKeys
+}
+
 // Signatures is a collections of parts that must satisfy multisig
 // threshold requirement.
 type Signatures []Part
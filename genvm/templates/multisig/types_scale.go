@@ -46,6 +46,44 @@ func (t *SpawnArguments) DecodeScale(dec *scale.Decoder) (total int, err error)
 	return total, nil
 }
 
+func (t *UpdateKeysArguments) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	{
+		n, err := scale.EncodeCompact8(enc, uint8(t.Required))
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	{
+		n, err := scale.EncodeStructSliceWithLimit(enc, t.PublicKeys, 10)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (t *UpdateKeysArguments) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	{
+		field, n, err := scale.DecodeCompact8(dec)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Required = uint8(field)
+	}
+	{
+		field, n, err := scale.DecodeStructSliceWithLimit[types.Hash32](dec, 10)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.PublicKeys = field
+	}
+	return total, nil
+}
+
 func (t *Part) EncodeScale(enc *scale.Encoder) (total int, err error) {
 	{
 		n, err := scale.EncodeCompact8(enc, uint8(t.Ref))
@@ -0,0 +1,28 @@
+package passkey
+
+import (
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
+)
+
+//go:generate scalegen
+
+// PublicKey is a p-256 public key in the compressed format.
+type PublicKey [33]byte
+
+// SpawnArguments for the passkey wallet.
+type SpawnArguments struct {
+	PublicKey PublicKey
+}
+
+// SpendArguments contains recipient and amount.
+type SpendArguments = wallet.SpendArguments
+
+// Assertion is a signature produced by the WebAuthn authenticator.
+// Challenge in the client data is the sha256 digest of the signing body, encoded with base64url.
+type Assertion struct {
+	AuthenticatorData []byte `scale:"max=256"`
+	ClientDataJSON    []byte `scale:"max=1024"`
+	// Signature is an ASN.1 DER encoded ecdsa signature. S must be in the lower half of the order,
+	// clients replace S with N-S if the authenticator returned the signature with high S.
+	Signature []byte `scale:"max=72"`
+}
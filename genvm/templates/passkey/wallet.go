@@ -0,0 +1,74 @@
+package passkey
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-scale"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+// New returns Wallet instance with SpawnArguments.
+func New(args *SpawnArguments) (*Wallet, error) {
+	if _, err := parsePublicKey(args.PublicKey); err != nil {
+		return nil, err
+	}
+	return &Wallet{PublicKey: args.PublicKey}, nil
+}
+
+//go:generate scalegen
+
+// Wallet is a single-key wallet that verifies WebAuthn assertions signed by p-256 passkeys.
+type Wallet struct {
+	PublicKey PublicKey
+}
+
+// MaxSpend returns amount specified in the SpendArguments for Spend method.
+func (s *Wallet) MaxSpend(method uint8, args any) (uint64, error) {
+	switch method {
+	case core.MethodSpawn:
+		return 0, nil
+	case core.MethodSpend:
+		return args.(*SpendArguments).Amount, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
+	}
+}
+
+// AccessList returns destination of the Spend method.
+func (s *Wallet) AccessList(method uint8, args any) []core.Address {
+	if method == core.MethodSpend {
+		return []core.Address{args.(*SpendArguments).Destination}
+	}
+	return nil
+}
+
+// Verify that transaction is asserted by the passkey with PublicKey.
+//
+// Relying party is not checked, the assertion is bound to the transaction by the challenge.
+func (s *Wallet) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool {
+	var assertion Assertion
+	n, err := assertion.DecodeScale(dec)
+	if err != nil {
+		return false
+	}
+	challenge := Challenge(core.SigningBody(host.GetGenesisID().Bytes(), raw[:len(raw)-n]))
+	return verify(s.PublicKey, challenge, &assertion)
+}
+
+// Spend transfers an amount to the address specified in SpendArguments.
+func (s *Wallet) Spend(host core.Host, args *SpendArguments) error {
+	return host.Transfer(args.Destination, args.Amount)
+}
+
+func (s *Wallet) BaseGas(method uint8) uint64 {
+	return BaseGas(method)
+}
+
+func (s *Wallet) LoadGas() uint64 {
+	return LoadGas()
+}
+
+func (s *Wallet) ExecGas(method uint8) uint64 {
+	return ExecGas(method)
+}
@@ -0,0 +1,172 @@
+package passkey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/spacemeshos/go-scale"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+func FuzzVerify(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		wallet := Wallet{}
+		dec := scale.NewDecoder(bytes.NewReader(data))
+		wallet.Verify(&core.Context{}, data, dec)
+	})
+}
+
+func newWallet(tb testing.TB) (*ecdsa.PrivateKey, *Wallet) {
+	tb.Helper()
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	args := SpawnArguments{}
+	copy(args.PublicKey[:], elliptic.MarshalCompressed(elliptic.P256(), pk.X, pk.Y))
+	wallet, err := New(&args)
+	require.NoError(tb, err)
+	return pk, wallet
+}
+
+type assertOpts struct {
+	typ   string
+	flags byte
+	// encode replaces the default encoding of the signature with low S.
+	encode func(r, s *big.Int) []byte
+}
+
+func encodeSignature(tb testing.TB, r, s *big.Int) []byte {
+	tb.Helper()
+	sig, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	require.NoError(tb, err)
+	return sig
+}
+
+func assert(tb testing.TB, pk *ecdsa.PrivateKey, challenge []byte, opts assertOpts) []byte {
+	tb.Helper()
+	if opts.typ == "" {
+		opts.typ = typeGet
+	}
+	client, err := json.Marshal(ClientData{
+		Type:      opts.typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    "https://example.com",
+	})
+	require.NoError(tb, err)
+	rp := sha256.Sum256([]byte("example.com"))
+	auth := append(rp[:], opts.flags, 0, 0, 0, 1)
+	clientHash := sha256.Sum256(client)
+	digest := sha256.Sum256(append(append([]byte{}, auth...), clientHash[:]...))
+	r, s, err := ecdsa.Sign(rand.Reader, pk, digest[:])
+	require.NoError(tb, err)
+	if s.Cmp(halfOrder) > 0 {
+		s.Sub(elliptic.P256().Params().N, s)
+	}
+	sig := encodeSignature(tb, r, s)
+	if opts.encode != nil {
+		sig = opts.encode(r, s)
+	}
+	buf := bytes.NewBuffer(nil)
+	_, err = (&Assertion{AuthenticatorData: auth, ClientDataJSON: client, Signature: sig}).EncodeScale(scale.NewEncoder(buf))
+	require.NoError(tb, err)
+	return buf.Bytes()
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&SpawnArguments{})
+	require.ErrorContains(t, err, "invalid p-256 public key")
+}
+
+func TestVerify(t *testing.T) {
+	pk, wallet := newWallet(t)
+	msg := []byte{1, 2, 3}
+	genesis := types.Hash20{1}
+	challenge := Challenge(core.SigningBody(genesis[:], msg))
+	verify := func(genesis types.Hash20, assertion []byte) bool {
+		return wallet.Verify(&core.Context{GenesisID: genesis}, append(msg, assertion...),
+			scale.NewDecoder(bytes.NewReader(assertion)))
+	}
+	t.Run("valid", func(t *testing.T) {
+		require.True(t, verify(genesis, assert(t, pk, challenge, assertOpts{flags: flagUserPresent})))
+	})
+	t.Run("other genesis", func(t *testing.T) {
+		require.False(t, verify(types.Hash20{2}, assert(t, pk, challenge, assertOpts{flags: flagUserPresent})))
+	})
+	t.Run("other challenge", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge[1:], assertOpts{flags: flagUserPresent})))
+	})
+	t.Run("other key", func(t *testing.T) {
+		other, _ := newWallet(t)
+		require.False(t, verify(genesis, assert(t, other, challenge, assertOpts{flags: flagUserPresent})))
+	})
+	t.Run("user not present", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge, assertOpts{})))
+	})
+	t.Run("registration", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge, assertOpts{
+			typ:   "webauthn.create",
+			flags: flagUserPresent,
+		})))
+	})
+	t.Run("high s", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge, assertOpts{
+			flags: flagUserPresent,
+			encode: func(r, s *big.Int) []byte {
+				return encodeSignature(t, r, new(big.Int).Sub(elliptic.P256().Params().N, s))
+			},
+		})))
+	})
+	t.Run("non-canonical der", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge, assertOpts{
+			flags: flagUserPresent,
+			encode: func(r, s *big.Int) []byte {
+				// length of the sequence in the long form
+				sig := encodeSignature(t, r, s)
+				return append([]byte{sig[0], 0x81}, sig[1:]...)
+			},
+		})))
+	})
+	t.Run("normalized", func(t *testing.T) {
+		require.True(t, verify(genesis, assert(t, pk, challenge, assertOpts{
+			flags: flagUserPresent,
+			encode: func(r, s *big.Int) []byte {
+				high := encodeSignature(t, r, new(big.Int).Sub(elliptic.P256().Params().N, s))
+				sig, err := NormalizeSignature(high)
+				require.NoError(t, err)
+				return sig
+			},
+		})))
+	})
+	t.Run("trailing data", func(t *testing.T) {
+		require.False(t, verify(genesis, assert(t, pk, challenge, assertOpts{
+			flags: flagUserPresent,
+			encode: func(r, s *big.Int) []byte {
+				return append(encodeSignature(t, r, s), 0)
+			},
+		})))
+	})
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pk, wallet := newWallet(b)
+	msg := make([]byte, 100)
+	assertion := assert(b, pk, Challenge(core.SigningBody(make([]byte, 20), msg)), assertOpts{flags: flagUserPresent})
+	raw := append(msg, assertion...)
+	ctx := &core.Context{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !wallet.Verify(ctx, raw, scale.NewDecoder(bytes.NewReader(assertion))) {
+			b.Fatal("invalid assertion")
+		}
+	}
+}
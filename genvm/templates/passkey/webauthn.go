@@ -0,0 +1,114 @@
+package passkey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+const (
+	// minAuthenticatorData is the size of rp id hash (32), flags (1) and signature counter (4).
+	minAuthenticatorData = 37
+	flagUserPresent      = 0x01
+
+	typeGet = "webauthn.get"
+)
+
+// halfOrder is used to reject signatures with high S.
+var halfOrder = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+
+func parsePublicKey(key PublicKey) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), key[:])
+	if x == nil {
+		return nil, errors.New("invalid p-256 public key")
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// Challenge returns the challenge that authenticator has to sign.
+func Challenge(body []byte) []byte {
+	hash := sha256.Sum256(body)
+	return hash[:]
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// parseSignature parses DER encoded signature. Signatures with high S and non-canonical
+// encodings are rejected, so that the same transaction can't be submitted under a different id.
+func parseSignature(der []byte) (*ecdsaSignature, error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after signature")
+	}
+	if canonical, err := asn1.Marshal(sig); err != nil || !bytes.Equal(canonical, der) {
+		return nil, errors.New("signature is not der encoded")
+	}
+	if sig.R.Sign() <= 0 || sig.R.Cmp(elliptic.P256().Params().N) >= 0 {
+		return nil, errors.New("r is out of range")
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(halfOrder) > 0 {
+		return nil, errors.New("s is out of range")
+	}
+	return &sig, nil
+}
+
+// NormalizeSignature replaces S with N-S in the DER encoded signature if S is in the upper half
+// of the order. Authenticators return signatures with either S, only the low one is valid.
+func NormalizeSignature(der []byte) ([]byte, error) {
+	var sig ecdsaSignature
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, err
+	}
+	if sig.S.Cmp(halfOrder) > 0 {
+		sig.S.Sub(elliptic.P256().Params().N, sig.S)
+	}
+	return asn1.Marshal(sig)
+}
+
+// ClientData is a subset of the client data collected by the WebAuthn client.
+type ClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// verify that the assertion is signed by the key and includes the challenge.
+func verify(key PublicKey, challenge []byte, assertion *Assertion) bool {
+	if len(assertion.AuthenticatorData) < minAuthenticatorData ||
+		assertion.AuthenticatorData[32]&flagUserPresent == 0 {
+		return false
+	}
+	var client ClientData
+	if err := json.Unmarshal(assertion.ClientDataJSON, &client); err != nil {
+		return false
+	}
+	if client.Type != typeGet || client.Challenge != base64.RawURLEncoding.EncodeToString(challenge) {
+		return false
+	}
+	sig, err := parseSignature(assertion.Signature)
+	if err != nil {
+		return false
+	}
+	pub, err := parsePublicKey(key)
+	if err != nil {
+		return false
+	}
+	clientHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := make([]byte, 0, len(assertion.AuthenticatorData)+len(clientHash))
+	signed = append(signed, assertion.AuthenticatorData...)
+	signed = append(signed, clientHash[:]...)
+	digest := sha256.Sum256(signed)
+	return ecdsa.Verify(pub, digest[:], sig.R, sig.S)
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"math"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+const (
+	// PAYMENT_SIZE is the size of the encoded Payment.
+	PAYMENT_SIZE = 56
+	// STATE_SIZE is the size of the scheduler state with all payments.
+	STATE_SIZE = core.PUBLIC_KEY_SIZE + 4 + MaxPayments*PAYMENT_SIZE
+)
+
+func BaseGas(method uint8) uint64 {
+	switch method {
+	case core.MethodSpawn:
+		return core.TX + core.EDVERIFY + core.SPAWN
+	case core.MethodSpend, MethodSchedule, MethodCancel, MethodExecute:
+		return core.TX + core.EDVERIFY
+	}
+	return math.MaxUint64
+}
+
+func LoadGas() uint64 {
+	return core.ACCOUNT_ACCESS + core.SizeGas(core.LOAD, STATE_SIZE+core.ACCOUNT_HEADER_SIZE)
+}
+
+func ExecGas(method uint8) uint64 {
+	switch method {
+	case core.MethodSpawn:
+		return core.SizeGas(core.STORE, STATE_SIZE+core.ACCOUNT_HEADER_SIZE)
+	case core.MethodSpend:
+		gas := core.ACCOUNT_ACCESS
+		gas += core.SizeGas(core.LOAD, core.ACCOUNT_BALANCE_SIZE)
+		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE)
+		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_BALANCE_SIZE)
+		return gas
+	case MethodSchedule, MethodCancel:
+		return core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE+PAYMENT_SIZE)
+	case MethodExecute:
+		gas := core.ACCOUNT_ACCESS
+		gas += core.SizeGas(core.LOAD, core.ACCOUNT_BALANCE_SIZE)
+		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE+PAYMENT_SIZE)
+		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_BALANCE_SIZE)
+		return gas
+	}
+	return math.MaxUint64
+}
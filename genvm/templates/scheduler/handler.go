@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/spacemeshos/go-scale"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/registry"
+)
+
+func init() {
+	TemplateAddress[len(TemplateAddress)-1] = 5
+}
+
+// Register Scheduler template.
+func Register(registry *registry.Registry) {
+	registry.Register(TemplateAddress, &handler{})
+}
+
+var (
+	_ (core.Handler) = (*handler)(nil)
+	// TemplateAddress is an address of the Scheduler template.
+	TemplateAddress core.Address
+)
+
+type handler struct{}
+
+// Parse header and arguments.
+func (*handler) Parse(host core.Host, method uint8, decoder *scale.Decoder) (output core.ParseOutput, err error) {
+	var p core.Payload
+	if _, err = p.DecodeScale(decoder); err != nil {
+		err = fmt.Errorf("%w: %w", core.ErrMalformed, err)
+		return
+	}
+	output.GasPrice = p.GasPrice
+	output.Nonce = p.Nonce
+	return output, nil
+}
+
+// New instatiates scheduler with spawn arguments.
+func (*handler) New(args any) (core.Template, error) {
+	return New(args.(*SpawnArguments)), nil
+}
+
+// Load scheduler from stored state.
+func (*handler) Load(state []byte) (core.Template, error) {
+	decoder := scale.NewDecoder(bytes.NewReader(state))
+	var scheduler Scheduler
+	if _, err := scheduler.DecodeScale(decoder); err != nil {
+		return nil, fmt.Errorf("%w: malformed state %w", core.ErrInternal, err)
+	}
+	return &scheduler, nil
+}
+
+// Exec dispatches execution request based on the method selector.
+func (*handler) Exec(host core.Host, method uint8, args scale.Encodable) error {
+	switch method {
+	case core.MethodSpawn:
+		return host.Spawn(args)
+	case core.MethodSpend:
+		return host.Template().(*Scheduler).Spend(host, args.(*SpendArguments))
+	case MethodSchedule:
+		return host.Template().(*Scheduler).Schedule(args.(*ScheduleArguments))
+	case MethodCancel:
+		return host.Template().(*Scheduler).Cancel(args.(*CancelArguments))
+	case MethodExecute:
+		return host.Template().(*Scheduler).Execute(host, args.(*ExecuteArguments))
+	}
+	return fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
+}
+
+// Args ...
+func (h *handler) Args(method uint8) scale.Type {
+	switch method {
+	case core.MethodSpawn:
+		return &SpawnArguments{}
+	case core.MethodSpend:
+		return &SpendArguments{}
+	case MethodSchedule:
+		return &ScheduleArguments{}
+	case MethodCancel:
+		return &CancelArguments{}
+	case MethodExecute:
+		return &ExecuteArguments{}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/spacemeshos/go-scale"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+const (
+	// MaxPayments is the maximal number of payments in the schedule.
+	MaxPayments = 8
+	// MaxInterval is the maximal number of layers between installments.
+	MaxInterval = 1 << 24
+)
+
+var (
+	// ErrInvalidSchedule is raised if schedule arguments don't describe a payment.
+	ErrInvalidSchedule = errors.New("scheduler: invalid schedule")
+	// ErrTooManyPayments is raised if schedule already has MaxPayments.
+	ErrTooManyPayments = errors.New("scheduler: too many payments")
+	// ErrPaymentNotFound is raised if payment with requested id is not in the schedule.
+	ErrPaymentNotFound = errors.New("scheduler: payment not found")
+	// ErrNotDue is raised if execute is submitted before the payment is due.
+	ErrNotDue = errors.New("scheduler: payment is not due")
+	// ErrAmountNotAvailable is raised if a single installment exceeds max amount of the execute transaction.
+	ErrAmountNotAvailable = errors.New("scheduler: installment exceeds max amount")
+)
+
+// New returns Scheduler instance with SpawnArguments.
+func New(args *SpawnArguments) *Scheduler {
+	return &Scheduler{PublicKey: args.PublicKey}
+}
+
+//go:generate scalegen
+
+// Payment is a scheduled transfer.
+type Payment struct {
+	ID        uint32
+	Recipient core.Address
+	Amount    uint64
+	// Next is the layer when the next installment is due.
+	Next     core.LayerID
+	Interval uint32
+	// Remaining is the number of installments left, 0 for payments repeated until cancelled.
+	Remaining uint32
+	// MaxGasPrice is the highest gas price of unsigned execute transactions.
+	MaxGasPrice uint64
+}
+
+// due returns the number of installments due at the layer.
+func (p *Payment) due(lid core.LayerID) uint64 {
+	if lid.Before(p.Next) {
+		return 0
+	}
+	count := uint64(1)
+	if p.Interval != 0 {
+		count += uint64(lid.Difference(p.Next) / p.Interval)
+	}
+	if p.Remaining != 0 && count > uint64(p.Remaining) {
+		count = uint64(p.Remaining)
+	}
+	return count
+}
+
+// Scheduler is a single-key wallet with a schedule of payments.
+// Payments are transferred by execute transactions once they are due, executes don't need
+// the owner signature.
+type Scheduler struct {
+	PublicKey core.PublicKey
+	NextID    uint32
+	Payments  []Payment `scale:"max=8"` // match MaxPayments
+}
+
+// MaxSpend returns amount specified in the SpendArguments or ExecuteArguments.
+func (s *Scheduler) MaxSpend(method uint8, args any) (uint64, error) {
+	switch method {
+	case core.MethodSpawn, MethodSchedule, MethodCancel:
+		return 0, nil
+	case core.MethodSpend:
+		return args.(*SpendArguments).Amount, nil
+	case MethodExecute:
+		return args.(*ExecuteArguments).MaxAmount, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
+	}
+}
+
+// AccessList returns destination of the Spend method or recipient of the executed payment.
+func (s *Scheduler) AccessList(method uint8, args any) []core.Address {
+	switch method {
+	case core.MethodSpend:
+		return []core.Address{args.(*SpendArguments).Destination}
+	case MethodExecute:
+		if i := s.find(args.(*ExecuteArguments).ID); i >= 0 {
+			return []core.Address{s.Payments[i].Recipient}
+		}
+	}
+	return nil
+}
+
+// Verify that transaction is signed by the owner of the PublicKey using ed25519.
+// Execute transactions without a valid signature are accepted if they can transfer an installment
+// and their gas price doesn't exceed MaxGasPrice of the payment.
+func (s *Scheduler) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool {
+	if s.verifySignature(host, raw, dec) {
+		return true
+	}
+	ctx, ok := host.(*core.Context)
+	if !ok || ctx.Header.Method != MethodExecute || s.Executable(host, MethodExecute, ctx.Args) != nil {
+		return false
+	}
+	return ctx.Header.GasPrice <= s.Payments[s.find(ctx.Args.(*ExecuteArguments).ID)].MaxGasPrice
+}
+
+func (s *Scheduler) verifySignature(host core.Host, raw []byte, dec *scale.Decoder) bool {
+	sig := core.Signature{}
+	n, err := sig.DecodeScale(dec)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(
+		ed25519.PublicKey(s.PublicKey[:]),
+		core.SigningBody(host.GetGenesisID().Bytes(), raw[:len(raw)-n]),
+		sig[:],
+	)
+}
+
+// Executable returns an error if the execute transaction can't transfer an installment.
+// Execute transactions may be submitted by anyone and pay fees from the scheduler balance,
+// so transactions that don't transfer anything are not executed.
+// Due installments are not checked if the layer is not known, e.g. when transaction is added to mempool.
+func (s *Scheduler) Executable(host core.Host, method uint8, args any) error {
+	if method != MethodExecute {
+		return nil
+	}
+	execute, ok := args.(*ExecuteArguments)
+	if !ok {
+		return fmt.Errorf("%w: execute arguments", core.ErrMalformed)
+	}
+	i := s.find(execute.ID)
+	if i < 0 {
+		return fmt.Errorf("%w: %d", ErrPaymentNotFound, execute.ID)
+	}
+	payment := &s.Payments[i]
+	if execute.MaxAmount < payment.Amount {
+		return fmt.Errorf("%w: %d < %d", ErrAmountNotAvailable, execute.MaxAmount, payment.Amount)
+	}
+	if lid := host.Layer(); lid != 0 && payment.due(lid) == 0 {
+		return fmt.Errorf("%w: %d at %s", ErrNotDue, execute.ID, payment.Next)
+	}
+	return nil
+}
+
+// Mutates returns true for methods that change the schedule.
+func (s *Scheduler) Mutates(method uint8) bool {
+	switch method {
+	case MethodSchedule, MethodCancel, MethodExecute:
+		return true
+	}
+	return false
+}
+
+// Spend transfers an amount to the address specified in SpendArguments.
+func (s *Scheduler) Spend(host core.Host, args *SpendArguments) error {
+	return host.Transfer(args.Destination, args.Amount)
+}
+
+// Schedule adds a payment. Payments are assigned sequential ids starting from 0.
+func (s *Scheduler) Schedule(args *ScheduleArguments) error {
+	if args.Amount == 0 {
+		return fmt.Errorf("%w: zero amount", ErrInvalidSchedule)
+	}
+	if args.Interval == 0 && args.Count != 1 {
+		return fmt.Errorf("%w: one-off payment with count %d", ErrInvalidSchedule, args.Count)
+	}
+	if args.Interval > MaxInterval {
+		return fmt.Errorf("%w: interval %d is above %d", ErrInvalidSchedule, args.Interval, MaxInterval)
+	}
+	if uint64(args.Start)+uint64(args.Interval) > math.MaxUint32 {
+		return fmt.Errorf("%w: start %d with interval %d overflows layer", ErrInvalidSchedule, args.Start, args.Interval)
+	}
+	if len(s.Payments) == MaxPayments {
+		return ErrTooManyPayments
+	}
+	s.Payments = append(s.Payments, Payment{
+		ID:          s.NextID,
+		Recipient:   args.Recipient,
+		Amount:      args.Amount,
+		Next:        args.Start,
+		Interval:    args.Interval,
+		Remaining:   args.Count,
+		MaxGasPrice: args.MaxGasPrice,
+	})
+	s.NextID++
+	return nil
+}
+
+func (s *Scheduler) find(id uint32) int {
+	for i := range s.Payments {
+		if s.Payments[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Scheduler) remove(i int) {
+	s.Payments = append(s.Payments[:i], s.Payments[i+1:]...)
+}
+
+// Cancel removes a payment from the schedule.
+func (s *Scheduler) Cancel(args *CancelArguments) error {
+	i := s.find(args.ID)
+	if i < 0 {
+		return fmt.Errorf("%w: %d", ErrPaymentNotFound, args.ID)
+	}
+	s.remove(i)
+	return nil
+}
+
+// Execute transfers installments of the payment that are due at the current layer.
+// Installments that were missed earlier are transferred together, as long as they fit into MaxAmount.
+// Payment is removed once the next installment can't be represented by a layer.
+func (s *Scheduler) Execute(host core.Host, args *ExecuteArguments) error {
+	i := s.find(args.ID)
+	if i < 0 {
+		return fmt.Errorf("%w: %d", ErrPaymentNotFound, args.ID)
+	}
+	payment := &s.Payments[i]
+	count := payment.due(host.Layer())
+	if count == 0 {
+		return fmt.Errorf("%w: %d at %s", ErrNotDue, args.ID, payment.Next)
+	}
+	if fit := args.MaxAmount / payment.Amount; fit < count {
+		count = fit
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %d < %d", ErrAmountNotAvailable, args.MaxAmount, payment.Amount)
+	}
+	hi, amount := bits.Mul64(count, payment.Amount)
+	if hi != 0 {
+		return fmt.Errorf("%w: amount overflow", ErrAmountNotAvailable)
+	}
+	if err := host.Transfer(payment.Recipient, amount); err != nil {
+		return err
+	}
+	if payment.Remaining != 0 {
+		payment.Remaining -= uint32(count)
+		if payment.Remaining == 0 {
+			s.remove(i)
+			return nil
+		}
+	}
+	next := uint64(payment.Next) + count*uint64(payment.Interval)
+	if next > math.MaxUint32 {
+		s.remove(i)
+		return nil
+	}
+	payment.Next = core.LayerID(next)
+	return nil
+}
+
+func (s *Scheduler) BaseGas(method uint8) uint64 {
+	return BaseGas(method)
+}
+
+func (s *Scheduler) LoadGas() uint64 {
+	return LoadGas()
+}
+
+func (s *Scheduler) ExecGas(method uint8) uint64 {
+	return ExecGas(method)
+}
@@ -0,0 +1,280 @@
+package scheduler
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/spacemeshos/go-scale"
+	"github.com/spacemeshos/go-scale/tester"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+func FuzzSchedulerConsistency(f *testing.F) {
+	tester.FuzzConsistency[Scheduler](f)
+}
+
+func FuzzSchedulerSafety(f *testing.F) {
+	tester.FuzzSafety[Scheduler](f)
+}
+
+func FuzzVerify(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		scheduler := Scheduler{}
+		dec := scale.NewDecoder(bytes.NewReader(data))
+		scheduler.Verify(&core.Context{}, data, dec)
+	})
+}
+
+type loader map[core.Address]core.Account
+
+func (l loader) Get(address core.Address) (core.Account, error) {
+	account := l[address]
+	account.Address = address
+	return account, nil
+}
+
+func testContext(lid, balance, maxSpend uint64) *core.Context {
+	ctx := &core.Context{Loader: loader{}, LayerID: core.LayerID(lid)}
+	ctx.PrincipalAccount.Address = core.Address{'s'}
+	ctx.PrincipalAccount.Balance = balance
+	ctx.Header.MaxSpend = maxSpend
+	return ctx
+}
+
+func TestMaxSpend(t *testing.T) {
+	scheduler := Scheduler{}
+	for _, tc := range []struct {
+		desc   string
+		method uint8
+		args   any
+		expect uint64
+	}{
+		{desc: "spawn", method: core.MethodSpawn, args: &SpawnArguments{}},
+		{desc: "spend", method: core.MethodSpend, args: &SpendArguments{Amount: 100}, expect: 100},
+		{desc: "schedule", method: MethodSchedule, args: &ScheduleArguments{Amount: 100}},
+		{desc: "cancel", method: MethodCancel, args: &CancelArguments{}},
+		{desc: "execute", method: MethodExecute, args: &ExecuteArguments{MaxAmount: 100}, expect: 100},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			max, err := scheduler.MaxSpend(tc.method, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, max)
+		})
+	}
+	_, err := scheduler.MaxSpend(100, nil)
+	require.ErrorIs(t, err, core.ErrMalformed)
+}
+
+func TestVerify(t *testing.T) {
+	pub, pk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	spawn := &SpawnArguments{}
+	copy(spawn.PublicKey[:], pub)
+	scheduler := New(spawn)
+
+	msg := []byte{1, 2, 3}
+	empty := types.Hash20{}
+	sig := ed25519.Sign(pk, core.SigningBody(empty[:], msg))
+	t.Run("Valid", func(t *testing.T) {
+		ctx := &core.Context{GenesisID: empty}
+		require.True(t, scheduler.Verify(ctx, append(msg, sig...), scale.NewDecoder(bytes.NewReader(sig))))
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		ctx := &core.Context{GenesisID: types.Hash20{1}}
+		require.False(t, scheduler.Verify(ctx, append(msg, sig...), scale.NewDecoder(bytes.NewReader(sig))))
+	})
+	require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 10, Start: 5, Count: 1, MaxGasPrice: 2}))
+	for _, tc := range []struct {
+		desc     string
+		method   uint8
+		lid      uint64
+		gasPrice uint64
+		args     *ExecuteArguments
+		valid    bool
+	}{
+		{desc: "Unsigned execute", lid: 5, gasPrice: 2, args: &ExecuteArguments{MaxAmount: 10}, valid: true},
+		{desc: "Unsigned execute unknown layer", gasPrice: 1, args: &ExecuteArguments{MaxAmount: 10}, valid: true},
+		{desc: "Unsigned execute over max gas price", lid: 5, gasPrice: 3, args: &ExecuteArguments{MaxAmount: 10}},
+		{desc: "Unsigned execute not due", lid: 4, gasPrice: 1, args: &ExecuteArguments{MaxAmount: 10}},
+		{desc: "Unsigned execute not found", lid: 5, gasPrice: 1, args: &ExecuteArguments{ID: 1, MaxAmount: 10}},
+		{desc: "Unsigned execute over max amount", gasPrice: 1, args: &ExecuteArguments{MaxAmount: 9}},
+		{desc: "Unsigned spend", method: core.MethodSpend, gasPrice: 1, args: &ExecuteArguments{MaxAmount: 10}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := &core.Context{GenesisID: empty, LayerID: core.LayerID(tc.lid), Args: tc.args}
+			ctx.Header.Method = MethodExecute
+			if tc.method != 0 {
+				ctx.Header.Method = tc.method
+			}
+			ctx.Header.GasPrice = tc.gasPrice
+			require.Equal(t, tc.valid, scheduler.Verify(ctx, msg, scale.NewDecoder(bytes.NewReader(nil))))
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	t.Run("zero amount", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Count: 1}), ErrInvalidSchedule)
+	})
+	t.Run("repeated one-off", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Count: 2}), ErrInvalidSchedule)
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Amount: 1}), ErrInvalidSchedule)
+	})
+	t.Run("interval above max", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Start: 1, Interval: math.MaxUint32, Count: 2}),
+			ErrInvalidSchedule)
+		require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Start: 1, Interval: MaxInterval, Count: 2}))
+	})
+	t.Run("start overflows", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Start: math.MaxUint32, Interval: 1}),
+			ErrInvalidSchedule)
+	})
+	t.Run("too many", func(t *testing.T) {
+		scheduler := Scheduler{}
+		for i := 0; i < MaxPayments; i++ {
+			require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Count: 1}))
+			require.Equal(t, uint32(i), scheduler.Payments[i].ID)
+		}
+		require.ErrorIs(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Count: 1}), ErrTooManyPayments)
+	})
+	t.Run("cancel", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 1, Count: 1}))
+		require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 2, Count: 1}))
+		require.NoError(t, scheduler.Cancel(&CancelArguments{ID: 0}))
+		require.Len(t, scheduler.Payments, 1)
+		require.Equal(t, uint32(1), scheduler.Payments[0].ID)
+		require.ErrorIs(t, scheduler.Cancel(&CancelArguments{ID: 0}), ErrPaymentNotFound)
+
+		// ids are not reused after cancellation
+		require.NoError(t, scheduler.Schedule(&ScheduleArguments{Amount: 3, Count: 1}))
+		require.Equal(t, uint32(2), scheduler.Payments[1].ID)
+	})
+}
+
+func TestExecute(t *testing.T) {
+	recipient := core.Address{'r'}
+	for _, tc := range []struct {
+		desc      string
+		schedule  ScheduleArguments
+		lid       uint64
+		maxAmount uint64
+		err       error
+		expect    uint64
+		remaining []Payment
+	}{
+		{
+			desc:      "not due",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Count: 1},
+			lid:       4,
+			maxAmount: 10,
+			err:       ErrNotDue,
+			remaining: []Payment{{Recipient: recipient, Amount: 10, Next: 5, Remaining: 1}},
+		},
+		{
+			desc:      "one-off",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Count: 1},
+			lid:       7,
+			maxAmount: 100,
+			expect:    10,
+		},
+		{
+			desc:      "recurring",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Interval: 3},
+			lid:       5,
+			maxAmount: 100,
+			expect:    10,
+			remaining: []Payment{{Recipient: recipient, Amount: 10, Next: 8, Interval: 3}},
+		},
+		{
+			desc:      "catch up",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Interval: 3},
+			lid:       12,
+			maxAmount: 100,
+			expect:    30,
+			remaining: []Payment{{Recipient: recipient, Amount: 10, Next: 14, Interval: 3}},
+		},
+		{
+			desc:      "catch up limited by count",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Interval: 3, Count: 2},
+			lid:       12,
+			maxAmount: 100,
+			expect:    20,
+		},
+		{
+			desc:      "catch up limited by max amount",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Interval: 3, Count: 4},
+			lid:       12,
+			maxAmount: 25,
+			expect:    20,
+			remaining: []Payment{{Recipient: recipient, Amount: 10, Next: 11, Interval: 3, Remaining: 2}},
+		},
+		{
+			desc:      "installment over max amount",
+			schedule:  ScheduleArguments{Amount: 10, Start: 5, Count: 1},
+			lid:       5,
+			maxAmount: 9,
+			err:       ErrAmountNotAvailable,
+			remaining: []Payment{{Recipient: recipient, Amount: 10, Next: 5, Remaining: 1}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			scheduler := Scheduler{}
+			tc.schedule.Recipient = recipient
+			require.NoError(t, scheduler.Schedule(&tc.schedule))
+
+			ctx := testContext(tc.lid, 1000, tc.maxAmount)
+			args := &ExecuteArguments{MaxAmount: tc.maxAmount}
+			err := scheduler.Execute(ctx, args)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, 1000-tc.expect, ctx.PrincipalAccount.Balance)
+			require.ElementsMatch(t, tc.remaining, scheduler.Payments)
+		})
+	}
+	t.Run("not found", func(t *testing.T) {
+		scheduler := Scheduler{}
+		args := &ExecuteArguments{ID: 1, MaxAmount: 100}
+		err := scheduler.Execute(testContext(1, 1000, 100), args)
+		require.ErrorIs(t, err, ErrPaymentNotFound)
+	})
+	t.Run("next installment overflows", func(t *testing.T) {
+		// payments are bounded when scheduled, state is crafted to check that execute doesn't panic
+		scheduler := Scheduler{Payments: []Payment{
+			{Recipient: recipient, Amount: 1, Next: 1, Interval: math.MaxUint32, Remaining: 3},
+			{ID: 1, Recipient: recipient, Amount: 1, Next: math.MaxUint32 - MaxInterval, Interval: MaxInterval},
+		}}
+		ctx := testContext(10, 1000, 100)
+		require.NoError(t, scheduler.Execute(ctx, &ExecuteArguments{MaxAmount: 100}))
+		require.Equal(t, uint64(999), ctx.PrincipalAccount.Balance)
+		require.Len(t, scheduler.Payments, 1)
+
+		ctx = testContext(math.MaxUint32-MaxInterval, 1000, 100)
+		require.NoError(t, scheduler.Execute(ctx, &ExecuteArguments{ID: 1, MaxAmount: 100}))
+		require.Equal(t, core.LayerID(math.MaxUint32), scheduler.Payments[0].Next)
+
+		ctx = testContext(math.MaxUint32, 1000, 100)
+		require.NoError(t, scheduler.Execute(ctx, &ExecuteArguments{ID: 1, MaxAmount: 100}))
+		require.Equal(t, uint64(999), ctx.PrincipalAccount.Balance)
+		require.Empty(t, scheduler.Payments)
+	})
+	t.Run("insufficient balance", func(t *testing.T) {
+		scheduler := Scheduler{}
+		require.NoError(t, scheduler.Schedule(&ScheduleArguments{Recipient: recipient, Amount: 10, Count: 1}))
+		err := scheduler.Execute(testContext(1, 5, 100), &ExecuteArguments{MaxAmount: 100})
+		require.ErrorIs(t, err, core.ErrNoBalance)
+		require.Len(t, scheduler.Payments, 1)
+	})
+}
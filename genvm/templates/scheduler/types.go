@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
+)
+
+const (
+	// MethodSchedule adds a payment to the schedule.
+	MethodSchedule = 17
+	// MethodCancel removes a payment from the schedule.
+	MethodCancel = 18
+	// MethodExecute transfers due installments of a payment. It may be submitted without
+	// a signature by anyone, for example by the recipient, with gas price up to MaxGasPrice
+	// of the payment. Transactions that can't transfer an installment are ineffective.
+	MethodExecute = 19
+)
+
+// SpendArguments contains recipient and amount.
+type SpendArguments = wallet.SpendArguments
+
+//go:generate scalegen
+
+// SpawnArguments for the scheduler.
+type SpawnArguments struct {
+	PublicKey core.PublicKey
+}
+
+// ScheduleArguments describe a payment of Amount to Recipient at or after Start layer.
+// If Interval is not zero payment is repeated every Interval layers,
+// Count times or until it is cancelled if Count is zero.
+type ScheduleArguments struct {
+	Recipient core.Address
+	Amount    uint64
+	Start     core.LayerID
+	Interval  uint32
+	Count     uint32
+	// MaxGasPrice is the highest gas price of unsigned execute transactions, their fees are paid
+	// by the scheduler. Zero allows only executes signed by the owner.
+	MaxGasPrice uint64
+}
+
+// CancelArguments refer to the payment that will be removed from the schedule.
+type CancelArguments struct {
+	ID uint32
+}
+
+// ExecuteArguments refer to the payment that will be executed.
+// Only as many due installments as fit into MaxAmount are transferred.
+type ExecuteArguments struct {
+	ID        uint32
+	MaxAmount uint64
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spacemeshos/go-scale/tester"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzScheduleArgumentsConsistency(f *testing.F) {
+	tester.FuzzConsistency[ScheduleArguments](f)
+}
+
+func FuzzScheduleArgumentsSafety(f *testing.F) {
+	tester.FuzzSafety[ScheduleArguments](f)
+}
+
+func TestGolden(t *testing.T) {
+	golden, err := filepath.Abs("./golden")
+	require.NoError(t, err)
+	t.Run("SpawnArguments", func(t *testing.T) {
+		tester.GoldenTest[SpawnArguments](t, filepath.Join(golden, "SpawnArguments.json"))
+	})
+	t.Run("ScheduleArguments", func(t *testing.T) {
+		tester.GoldenTest[ScheduleArguments](t, filepath.Join(golden, "ScheduleArguments.json"))
+	})
+	t.Run("CancelArguments", func(t *testing.T) {
+		tester.GoldenTest[CancelArguments](t, filepath.Join(golden, "CancelArguments.json"))
+	})
+	t.Run("ExecuteArguments", func(t *testing.T) {
+		tester.GoldenTest[ExecuteArguments](t, filepath.Join(golden, "ExecuteArguments.json"))
+	})
+}
@@ -0,0 +1,21 @@
+package secp256k1
+
+import (
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
+)
+
+//go:generate scalegen
+
+// PublicKey is a secp256k1 public key in the compressed format.
+type PublicKey [33]byte
+
+// Signature is a secp256k1 ecdsa signature in the compact format (r || s).
+type Signature [64]byte
+
+// SpawnArguments for the secp256k1 wallet.
+type SpawnArguments struct {
+	PublicKey PublicKey
+}
+
+// SpendArguments contains recipient and amount.
+type SpendArguments = wallet.SpendArguments
@@ -0,0 +1,89 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/spacemeshos/go-scale"
+
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+// New returns Wallet instance with SpawnArguments.
+func New(args *SpawnArguments) (*Wallet, error) {
+	if _, err := secp.ParsePubKey(args.PublicKey[:]); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &Wallet{PublicKey: args.PublicKey}, nil
+}
+
+//go:generate scalegen
+
+// Wallet is a single-key wallet that verifies secp256k1 ecdsa signatures.
+type Wallet struct {
+	PublicKey PublicKey
+}
+
+// MaxSpend returns amount specified in the SpendArguments for Spend method.
+func (s *Wallet) MaxSpend(method uint8, args any) (uint64, error) {
+	switch method {
+	case core.MethodSpawn:
+		return 0, nil
+	case core.MethodSpend:
+		return args.(*SpendArguments).Amount, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
+	}
+}
+
+// AccessList returns destination of the Spend method.
+func (s *Wallet) AccessList(method uint8, args any) []core.Address {
+	if method == core.MethodSpend {
+		return []core.Address{args.(*SpendArguments).Destination}
+	}
+	return nil
+}
+
+// Verify that transaction is signed by the owner of the PublicKey.
+//
+// Signed message is a sha256 digest of the signing body. Signatures with high S are rejected,
+// so that the same transaction can't be submitted under a different id.
+func (s *Wallet) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool {
+	var sig Signature
+	n, err := scale.DecodeByteArray(dec, sig[:])
+	if err != nil {
+		return false
+	}
+	var r, ss secp.ModNScalar
+	if r.SetByteSlice(sig[:32]) || r.IsZero() {
+		return false
+	}
+	if ss.SetByteSlice(sig[32:]) || ss.IsZero() || ss.IsOverHalfOrder() {
+		return false
+	}
+	pub, err := secp.ParsePubKey(s.PublicKey[:])
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(core.SigningBody(host.GetGenesisID().Bytes(), raw[:len(raw)-n]))
+	return ecdsa.NewSignature(&r, &ss).Verify(hash[:], pub)
+}
+
+// Spend transfers an amount to the address specified in SpendArguments.
+func (s *Wallet) Spend(host core.Host, args *SpendArguments) error {
+	return host.Transfer(args.Destination, args.Amount)
+}
+
+func (s *Wallet) BaseGas(method uint8) uint64 {
+	return BaseGas(method)
+}
+
+func (s *Wallet) LoadGas() uint64 {
+	return LoadGas()
+}
+
+func (s *Wallet) ExecGas(method uint8) uint64 {
+	return ExecGas(method)
+}
@@ -0,0 +1,101 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/spacemeshos/go-scale"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
+)
+
+func FuzzVerify(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		wallet := Wallet{}
+		dec := scale.NewDecoder(bytes.NewReader(data))
+		wallet.Verify(&core.Context{}, data, dec)
+	})
+}
+
+func sign(tb testing.TB, pk *secp.PrivateKey, genesis types.Hash20, msg []byte) []byte {
+	tb.Helper()
+	hash := sha256.Sum256(core.SigningBody(genesis[:], msg))
+	return ecdsa.SignCompact(pk, hash[:], true)[1:]
+}
+
+func newWallet(tb testing.TB) (*secp.PrivateKey, *Wallet) {
+	tb.Helper()
+	pk, err := secp.GeneratePrivateKey()
+	require.NoError(tb, err)
+	args := SpawnArguments{}
+	copy(args.PublicKey[:], pk.PubKey().SerializeCompressed())
+	wallet, err := New(&args)
+	require.NoError(tb, err)
+	return pk, wallet
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&SpawnArguments{})
+	require.ErrorContains(t, err, "invalid public key")
+}
+
+func TestMaxSpend(t *testing.T) {
+	wallet := Wallet{}
+	max, err := wallet.MaxSpend(core.MethodSpawn, &SpawnArguments{})
+	require.NoError(t, err)
+	require.Zero(t, max)
+	max, err = wallet.MaxSpend(core.MethodSpend, &SpendArguments{Amount: 100})
+	require.NoError(t, err)
+	require.EqualValues(t, 100, max)
+}
+
+func TestVerify(t *testing.T) {
+	pk, wallet := newWallet(t)
+	msg := []byte{1, 2, 3}
+	genesis := types.Hash20{1}
+	verify := func(genesis types.Hash20, sig []byte) bool {
+		return wallet.Verify(&core.Context{GenesisID: genesis}, append(msg, sig...), scale.NewDecoder(bytes.NewReader(sig)))
+	}
+	t.Run("valid", func(t *testing.T) {
+		require.True(t, verify(genesis, sign(t, pk, genesis, msg)))
+	})
+	t.Run("other genesis", func(t *testing.T) {
+		require.False(t, verify(types.Hash20{2}, sign(t, pk, genesis, msg)))
+	})
+	t.Run("other key", func(t *testing.T) {
+		other, _ := newWallet(t)
+		require.False(t, verify(genesis, sign(t, other, genesis, msg)))
+	})
+	t.Run("high s", func(t *testing.T) {
+		sig := sign(t, pk, genesis, msg)
+		var s secp.ModNScalar
+		s.SetByteSlice(sig[32:])
+		s.Negate()
+		high := append([]byte{}, sig[:32]...)
+		b := s.Bytes()
+		high = append(high, b[:]...)
+		require.False(t, verify(genesis, high))
+	})
+	t.Run("short", func(t *testing.T) {
+		require.False(t, verify(genesis, sign(t, pk, genesis, msg)[:63]))
+	})
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pk, wallet := newWallet(b)
+	msg := make([]byte, 100)
+	sig := sign(b, pk, types.Hash20{}, msg)
+	raw := append(msg, sig...)
+	ctx := &core.Context{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !wallet.Verify(ctx, raw, scale.NewDecoder(bytes.NewReader(sig))) {
+			b.Fatal("invalid signature")
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package vesting
 
 import (
+	"github.com/spacemeshos/go-spacemesh/genvm/core"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/multisig"
 )
 
@@ -17,6 +18,15 @@ func (v *Vesting) MaxSpend(method uint8, args any) (uint64, error) {
 	return v.MultiSig.MaxSpend(method, args)
 }
 
+// AccessList returns the vault and the destination for drain vault or forwards to multisig template.
+func (v *Vesting) AccessList(method uint8, args any) []core.Address {
+	if method == MethodDrainVault {
+		drain := args.(*DrainVaultArguments)
+		return []core.Address{drain.Vault, drain.Destination}
+	}
+	return v.MultiSig.AccessList(method, args)
+}
+
 func (v *Vesting) BaseGas(method uint8) uint64 {
 	return BaseGas(method, int(v.MultiSig.Required))
 }
@@ -10,7 +10,7 @@ func BaseGas(method uint8) uint64 {
 	switch method {
 	case core.MethodSpawn:
 		return core.TX + core.EDVERIFY + core.SPAWN
-	case core.MethodSpend:
+	case core.MethodSpend, MethodBatchSpend:
 		return core.TX + core.EDVERIFY
 	}
 	return math.MaxUint64
@@ -30,6 +30,16 @@ func ExecGas(method uint8) uint64 {
 		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE)
 		gas += core.SizeGas(core.UPDATE, core.ACCOUNT_BALANCE_SIZE)
 		return gas
+	case MethodBatchSpend:
+		return core.SizeGas(core.UPDATE, core.ACCOUNT_HEADER_SIZE)
 	}
 	return math.MaxUint64
 }
+
+// OutputsGas is a cost of transfers to n destinations, charged in addition to ExecGas of BatchSpend.
+func OutputsGas(n int) uint64 {
+	gas := core.ACCOUNT_ACCESS
+	gas += core.SizeGas(core.LOAD, core.ACCOUNT_BALANCE_SIZE)
+	gas += core.SizeGas(core.UPDATE, core.ACCOUNT_BALANCE_SIZE)
+	return uint64(n) * gas
+}
@@ -4,6 +4,13 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/core"
 )
 
+const (
+	// MethodBatchSpend transfers coins to several destinations in one transaction.
+	MethodBatchSpend = 17
+	// MaxOutputs is the maximal number of outputs in BatchSpendArguments.
+	MaxOutputs = 100
+)
+
 //go:generate scalegen
 
 // SpawnArguments ...
@@ -16,3 +23,8 @@ type SpendArguments struct {
 	Destination core.Address
 	Amount      uint64
 }
+
+// BatchSpendArguments contains a list of recipients and amounts.
+type BatchSpendArguments struct {
+	Outputs []SpendArguments `scale:"max=100"` // match MaxOutputs
+}
@@ -65,3 +65,26 @@ func (t *SpendArguments) DecodeScale(dec *scale.Decoder) (total int, err error)
 	}
 	return total, nil
 }
+
+func (t *BatchSpendArguments) EncodeScale(enc *scale.Encoder) (total int, err error) {
+	{
+		n, err := scale.EncodeStructSliceWithLimit(enc, t.Outputs, 100)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (t *BatchSpendArguments) DecodeScale(dec *scale.Decoder) (total int, err error) {
+	{
+		field, n, err := scale.DecodeStructSliceWithLimit[SpendArguments](dec, 100)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		t.Outputs = field
+	}
+	return total, nil
+}
@@ -18,6 +18,14 @@ func FuzzSpawnArgumentsSafety(f *testing.F) {
 	tester.FuzzSafety[SpawnArguments](f)
 }
 
+func FuzzBatchSpendArgumentsConsistency(f *testing.F) {
+	tester.FuzzConsistency[BatchSpendArguments](f)
+}
+
+func FuzzBatchSpendArgumentsSafety(f *testing.F) {
+	tester.FuzzSafety[BatchSpendArguments](f)
+}
+
 func TestGolden(t *testing.T) {
 	golden, err := filepath.Abs("./golden")
 	require.NoError(t, err)
@@ -27,6 +35,9 @@ func TestGolden(t *testing.T) {
 	t.Run("SpendArguments", func(t *testing.T) {
 		tester.GoldenTest[SpendArguments](t, filepath.Join(golden, "SpendArguments.json"))
 	})
+	t.Run("BatchSpendArguments", func(t *testing.T) {
+		tester.GoldenTest[BatchSpendArguments](t, filepath.Join(golden, "BatchSpendArguments.json"))
+	})
 	t.Run("SpendPayload", func(t *testing.T) {
 		tester.GoldenTest[core.Payload](t, filepath.Join(golden, "SpendPayload.json"))
 	})
@@ -1,7 +1,9 @@
 package wallet
 
 import (
+	"errors"
 	"fmt"
+	"math/bits"
 
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 	"github.com/spacemeshos/go-scale"
@@ -21,18 +23,41 @@ type Wallet struct {
 	PublicKey core.PublicKey
 }
 
-// MaxSpend returns amount specified in the SpendArguments for Spend method.
+// MaxSpend returns amount specified in the SpendArguments for Spend method
+// and the sum of all outputs for BatchSpend method.
 func (s *Wallet) MaxSpend(method uint8, args any) (uint64, error) {
 	switch method {
 	case core.MethodSpawn:
 		return 0, nil
 	case core.MethodSpend:
 		return args.(*SpendArguments).Amount, nil
+	case MethodBatchSpend:
+		total, err := args.(*BatchSpendArguments).Total()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", core.ErrMalformed, err)
+		}
+		return total, nil
 	default:
 		return 0, fmt.Errorf("%w: unknown method %d", core.ErrMalformed, method)
 	}
 }
 
+// AccessList returns destinations of the spend methods.
+func (s *Wallet) AccessList(method uint8, args any) []core.Address {
+	switch method {
+	case core.MethodSpend:
+		return []core.Address{args.(*SpendArguments).Destination}
+	case MethodBatchSpend:
+		outputs := args.(*BatchSpendArguments).Outputs
+		rst := make([]core.Address, 0, len(outputs))
+		for _, output := range outputs {
+			rst = append(rst, output.Destination)
+		}
+		return rst
+	}
+	return nil
+}
+
 // Verify that transaction is signed by the owner of the PublicKey using ed25519.
 func (s *Wallet) Verify(host core.Host, raw []byte, dec *scale.Decoder) bool {
 	sig := core.Signature{}
@@ -52,6 +77,24 @@ func (s *Wallet) Spend(host core.Host, args *SpendArguments) error {
 	return host.Transfer(args.Destination, args.Amount)
 }
 
+// BatchSpend transfers amounts to all destinations in BatchSpendArguments.
+// Either all outputs are transferred or none.
+func (s *Wallet) BatchSpend(host core.Host, args *BatchSpendArguments) error {
+	total, err := args.Total()
+	if err != nil {
+		return err
+	}
+	if total > host.Balance() {
+		return core.ErrNoBalance
+	}
+	for _, output := range args.Outputs {
+		if err := host.Transfer(output.Destination, output.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Wallet) BaseGas(method uint8) uint64 {
 	return BaseGas(method)
 }
@@ -63,3 +106,27 @@ func (s *Wallet) LoadGas() uint64 {
 func (s *Wallet) ExecGas(method uint8) uint64 {
 	return ExecGas(method)
 }
+
+// VariableGas charges for every output of the BatchSpend method.
+func (s *Wallet) VariableGas(method uint8, args any) uint64 {
+	if method == MethodBatchSpend {
+		return OutputsGas(len(args.(*BatchSpendArguments).Outputs))
+	}
+	return 0
+}
+
+// Total returns the sum of all outputs.
+func (b *BatchSpendArguments) Total() (uint64, error) {
+	if len(b.Outputs) == 0 {
+		return 0, errors.New("batch without outputs")
+	}
+	var total uint64
+	for _, output := range b.Outputs {
+		var carry uint64
+		total, carry = bits.Add64(total, output.Amount, 0)
+		if carry != 0 {
+			return 0, errors.New("total amount overflows uint64")
+		}
+	}
+	return total, nil
+}
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"bytes"
+	"math"
 	"testing"
 
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
@@ -33,6 +34,31 @@ func TestMaxSpend(t *testing.T) {
 		require.NoError(t, err)
 		require.EqualValues(t, amount, max)
 	})
+	t.Run("BatchSpend", func(t *testing.T) {
+		max, err := wallet.MaxSpend(MethodBatchSpend, &BatchSpendArguments{
+			Outputs: []SpendArguments{{Amount: 100}, {Amount: 200}, {Amount: 0}},
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 300, max)
+	})
+	t.Run("BatchSpend/Empty", func(t *testing.T) {
+		_, err := wallet.MaxSpend(MethodBatchSpend, &BatchSpendArguments{})
+		require.ErrorIs(t, err, core.ErrMalformed)
+	})
+	t.Run("BatchSpend/Overflow", func(t *testing.T) {
+		_, err := wallet.MaxSpend(MethodBatchSpend, &BatchSpendArguments{
+			Outputs: []SpendArguments{{Amount: math.MaxUint64}, {Amount: 1}},
+		})
+		require.ErrorIs(t, err, core.ErrMalformed)
+	})
+}
+
+func TestVariableGas(t *testing.T) {
+	wallet := Wallet{}
+	require.Zero(t, wallet.VariableGas(core.MethodSpend, &SpendArguments{}))
+	one := wallet.VariableGas(MethodBatchSpend, &BatchSpendArguments{Outputs: make([]SpendArguments, 1)})
+	require.NotZero(t, one)
+	require.Equal(t, 10*one, wallet.VariableGas(MethodBatchSpend, &BatchSpendArguments{Outputs: make([]SpendArguments, 10)}))
 }
 
 func TestVerify(t *testing.T) {
@@ -5,6 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/spacemeshos/go-scale"
@@ -14,6 +17,9 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/core"
 	"github.com/spacemeshos/go-spacemesh/genvm/registry"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/multisig"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/passkey"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/scheduler"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/secp256k1"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/vault"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/vesting"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
@@ -41,15 +47,87 @@ func WithLogger(logger log.Log) Opt {
 type Config struct {
 	GasLimit  uint64
 	GenesisID types.Hash20
+	// ExecutionWorkers is the number of transactions in the block that may be executed concurrently.
+	// Transactions are executed sequentially if it is not larger than one.
+	ExecutionWorkers int `mapstructure:"execution-workers"`
+	// CompareExecution executes every block both concurrently and sequentially and fails
+	// if results are different. Used for testing parallel execution.
+	CompareExecution bool `mapstructure:"compare-execution"`
+
+	// Archive keeps state of every account for every layer where it was updated.
+	// If false, history older than HistoryLayers is pruned except for snapshot layers.
+	Archive bool `mapstructure:"archive"`
+	// HistoryLayers is the number of recent layers with complete account history.
+	// It must not be lower than the tortoise hdist, as the vm can't revert pruned layers.
+	HistoryLayers uint32 `mapstructure:"history-layers"`
+	// SnapshotInterval is the distance between layers that are kept after pruning.
+	SnapshotInterval uint32        `mapstructure:"snapshot-interval"`
+	PruneInterval    time.Duration `mapstructure:"prune-interval"`
+
+	// TxVersion1Layer is the first layer where transactions with layer limits (version 1) are valid.
+	TxVersion1Layer types.LayerID `mapstructure:"tx-version1-layer"`
+	// TemplatesUpgradeLayer is the first layer where templates added after genesis can be used.
+	TemplatesUpgradeLayer types.LayerID `mapstructure:"templates-upgrade-layer"`
 }
 
 // DefaultConfig returns the default RewardConfig.
 func DefaultConfig() Config {
 	return Config{
-		GasLimit: 100_000_000,
+		GasLimit:         100_000_000,
+		Archive:          true,
+		HistoryLayers:    1000,
+		SnapshotInterval: 10_000,
+		PruneInterval:    10 * time.Minute,
+		// disabled until the activation layer is scheduled for the network
+		TxVersion1Layer:       math.MaxUint32,
+		TemplatesUpgradeLayer: math.MaxUint32,
 	}
 }
 
+// upgradeTemplates are enabled since the TemplatesUpgradeLayer.
+var upgradeTemplates = map[core.Address]struct{}{
+	scheduler.TemplateAddress: {},
+	secp256k1.TemplateAddress: {},
+	passkey.TemplateAddress:   {},
+}
+
+// upgradeMethods are methods of genesis templates that are enabled since the TemplatesUpgradeLayer.
+var upgradeMethods = map[core.Address][]uint8{
+	wallet.TemplateAddress:   {wallet.MethodBatchSpend},
+	multisig.TemplateAddress: {multisig.MethodUpdateKeys},
+	vesting.TemplateAddress:  {multisig.MethodUpdateKeys},
+}
+
+// enabled returns false if the template is not enabled in the layer.
+func (c Config) enabled(lid types.LayerID, template core.Address) bool {
+	if _, exists := upgradeTemplates[template]; exists {
+		return !lid.Before(c.TemplatesUpgradeLayer)
+	}
+	return true
+}
+
+// methodEnabled returns false if the method of the template is not enabled in the layer.
+func (c Config) methodEnabled(lid types.LayerID, template core.Address, method uint8) bool {
+	if slices.Contains(upgradeMethods[template], method) {
+		return !lid.Before(c.TemplatesUpgradeLayer)
+	}
+	return true
+}
+
+// Validate checks that the history kept after pruning is sufficient to revert hdist layers.
+func (c Config) Validate(hdist uint32) error {
+	if c.Archive {
+		return nil
+	}
+	if c.HistoryLayers < hdist {
+		return fmt.Errorf("vm history layers (%d) must not be lower than hdist (%d)", c.HistoryLayers, hdist)
+	}
+	if c.SnapshotInterval == 0 {
+		return errors.New("vm snapshot interval must be positive")
+	}
+	return nil
+}
+
 // WithConfig updates config on the vm.
 func WithConfig(cfg Config) Opt {
 	return func(vm *VM) {
@@ -69,6 +147,9 @@ func New(db *sql.Database, opts ...Opt) *VM {
 	multisig.Register(vm.registry)
 	vesting.Register(vm.registry)
 	vault.Register(vm.registry)
+	scheduler.Register(vm.registry)
+	secp256k1.Register(vm.registry)
+	passkey.Register(vm.registry)
 	for _, opt := range opts {
 		opt(vm)
 	}
@@ -81,6 +162,11 @@ type VM struct {
 	db       *sql.Database
 	cfg      Config
 	registry *registry.Registry
+
+	// mu serializes writes to the accounts from Apply, Revert and the pruner.
+	mu sync.Mutex
+	// horizon is the oldest layer that vm can revert to.
+	horizon types.LayerID
 }
 
 // Validation initializes validation request.
@@ -121,6 +207,17 @@ func (v *VM) GetAllAccounts() ([]*types.Account, error) {
 }
 
 func (v *VM) revert(lid types.LayerID) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// horizon is updated before pruning starts, and the pruned layer is persisted with pruned history
+	pruned, _, err := accounts.Pruned(v.db)
+	if err != nil {
+		return err
+	}
+	horizon := max(v.horizon, pruned)
+	if lid.Before(horizon) {
+		return fmt.Errorf("%w: revert to %s, history is kept since %s", ErrPruned, lid, horizon)
+	}
 	tx, err := v.db.Tx(context.Background())
 	if err != nil {
 		return err
@@ -187,6 +284,36 @@ func (v *VM) ApplyGenesis(genesis []types.Account) error {
 	return tx.Commit()
 }
 
+// GetGenesisStateRoot returns hash of the accounts saved by ApplyGenesis. See GenesisStateRoot.
+func (v *VM) GetGenesisStateRoot() (types.Hash32, error) {
+	snapshot, err := accounts.Snapshot(v.db, 0)
+	if err != nil {
+		return types.Hash32{}, fmt.Errorf("genesis accounts: %w", err)
+	}
+	genesis := make([]types.Account, 0, len(snapshot))
+	for _, account := range snapshot {
+		genesis = append(genesis, *account)
+	}
+	return GenesisStateRoot(genesis), nil
+}
+
+// GenesisStateRoot computes hash of the genesis accounts ordered by address,
+// accounts are encoded the same way as in the state root of the applied layer.
+func GenesisStateRoot(genesis []types.Account) types.Hash32 {
+	sorted := slices.Clone(genesis)
+	slices.SortFunc(sorted, func(a, b types.Account) int {
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	hasher := hash.New()
+	encoder := scale.NewEncoder(hasher)
+	for i := range sorted {
+		sorted[i].EncodeScale(encoder)
+	}
+	var root types.Hash32
+	hasher.Sum(root[:0])
+	return root
+}
+
 // Apply transactions.
 func (v *VM) Apply(lctx ApplyContext, txs []types.Transaction, blockRewards []types.CoinbaseReward) ([]types.Transaction, []types.TransactionWithResult, error) {
 	if lctx.Layer.Before(types.GetEffectiveGenesis()) {
@@ -217,6 +344,8 @@ func (v *VM) Apply(lctx ApplyContext, txs []types.Transaction, blockRewards []ty
 	encoder := scale.NewEncoder(hasher)
 	total := 0
 
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	tx, err := v.db.TxImmediate(context.Background())
 	if err != nil {
 		return nil, nil, err
@@ -280,7 +409,32 @@ func (v *VM) Apply(lctx ApplyContext, txs []types.Transaction, blockRewards []ty
 	return skipped, results, nil
 }
 
+// execute transactions of the block. Transactions are counted only once, even if they
+// were executed both concurrently and sequentially.
 func (v *VM) execute(lctx ApplyContext, ss *core.StagedCache, txs []types.Transaction) ([]types.TransactionWithResult, []types.Transaction, uint64, error) {
+	var (
+		executed    []types.TransactionWithResult
+		ineffective []types.Transaction
+		fees        uint64
+		err         error
+	)
+	switch {
+	case v.cfg.ExecutionWorkers <= 1:
+		executed, ineffective, fees, err = v.executeSequential(lctx, ss, txs)
+	case v.cfg.CompareExecution:
+		executed, ineffective, fees, err = v.executeCompared(lctx, ss, txs)
+	default:
+		executed, ineffective, fees, err = v.executeParallel(lctx, ss, txs)
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	txCount.Add(float64(len(txs)))
+	invalidTxCount.Add(float64(len(ineffective)))
+	return executed, ineffective, fees, nil
+}
+
+func (v *VM) executeSequential(lctx ApplyContext, ss *core.StagedCache, txs []types.Transaction) ([]types.TransactionWithResult, []types.Transaction, uint64, error) {
 	var (
 		rd          bytes.Reader
 		decoder     = scale.NewDecoder(&rd)
@@ -290,134 +444,172 @@ func (v *VM) execute(lctx ApplyContext, ss *core.StagedCache, txs []types.Transa
 		limit       = v.cfg.GasLimit
 	)
 	for i := range txs {
-		logger := v.logger.WithFields(log.Int("ith", i))
-		txCount.Inc()
-
-		t1 := time.Now()
-		tx := txs[i]
-
-		rd.Reset(tx.GetRaw().Raw)
-		req := &Request{
-			vm:      v,
-			cache:   ss,
-			lid:     lctx.Layer,
-			raw:     txs[i].GetRaw(),
-			decoder: decoder,
-		}
-
-		header, err := req.Parse()
+		out, err := v.executeTx(v.logger.WithFields(log.Int("ith", i)), lctx, ss, &rd, decoder, &txs[i], limit)
 		if err != nil {
-			logger.With().Warning("ineffective transaction. failed to parse",
-				tx.GetRaw().ID,
-				log.Err(err),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw()})
-			invalidTxCount.Inc()
-			continue
+			return nil, nil, 0, err
 		}
-		ctx := req.ctx
-		args := req.args
-
-		if header.GasPrice == 0 {
-			logger.With().Warning("ineffective transaction. zero gas price",
-				log.Object("header", header),
-				log.Object("account", &ctx.PrincipalAccount),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw()})
-			invalidTxCount.Inc()
-			continue
-		}
-		if intrinsic := core.IntrinsicGas(ctx.Gas.BaseGas, tx.GetRaw().Raw); ctx.PrincipalAccount.Balance < intrinsic {
-			logger.With().Warning("ineffective transaction. intrinstic gas not covered",
-				log.Object("header", header),
-				log.Object("account", &ctx.PrincipalAccount),
-				log.Uint64("intrinsic gas", intrinsic),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw()})
-			invalidTxCount.Inc()
+		if out.ineffective != nil {
+			ineffective = append(ineffective, *out.ineffective)
 			continue
 		}
-		if limit < ctx.Header.MaxGas {
-			logger.With().Warning("ineffective transaction. out of block gas",
-				log.Uint64("block gas limit", v.cfg.GasLimit),
-				log.Uint64("current limit", limit),
-				log.Object("header", header),
-				log.Object("account", &ctx.PrincipalAccount),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw()})
-			invalidTxCount.Inc()
-			continue
+		if err := out.ctx.Apply(ss); err != nil {
+			return nil, nil, 0, fmt.Errorf("%w: %w", core.ErrInternal, err)
 		}
+		fees += out.ctx.Fee()
+		limit -= out.ctx.Consumed()
+		executed = append(executed, out.result)
+	}
+	return executed, ineffective, fees, nil
+}
 
-		// NOTE this part is executed only for transactions that weren't verified
-		// when saved into database by txs module
-		if !tx.Verified() && !req.Verify() {
-			logger.With().Warning("ineffective transaction. failed verify",
-				log.Object("header", header),
-				log.Object("account", &ctx.PrincipalAccount),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw()})
-			invalidTxCount.Inc()
-			continue
-		}
+// txOutcome is either an executed transaction with changes that are not yet applied
+// or an ineffective transaction.
+type txOutcome struct {
+	ctx         *core.Context
+	result      types.TransactionWithResult
+	ineffective *types.Transaction
 
-		if ctx.PrincipalAccount.NextNonce > ctx.Header.Nonce {
-			logger.With().Warning("ineffective transaction. nonce too low",
-				log.Object("header", header),
-				log.Object("account", &ctx.PrincipalAccount),
-			)
-			ineffective = append(ineffective, types.Transaction{RawTx: tx.GetRaw(), TxHeader: header})
-			invalidTxCount.Inc()
-			continue
-		}
+	// maxGas is set if transaction was checked against the block gas limit.
+	maxGas uint64
+}
+
+// executeTx executes transaction with accounts from the loader. Changes made by the transaction
+// are kept in the context, so that the caller can apply them.
+func (v *VM) executeTx(
+	logger log.Log,
+	lctx ApplyContext,
+	loader core.AccountLoader,
+	rd *bytes.Reader,
+	decoder *scale.Decoder,
+	tx *types.Transaction,
+	limit uint64,
+) (txOutcome, error) {
+	t1 := time.Now()
+
+	rd.Reset(tx.GetRaw().Raw)
+	req := &Request{
+		vm:      v,
+		cache:   loader,
+		lid:     lctx.Layer,
+		raw:     tx.GetRaw(),
+		decoder: decoder,
+	}
+	var out txOutcome
+	ineffective := func(hdr *types.TxHeader) (txOutcome, error) {
+		out.ineffective = &types.Transaction{RawTx: tx.GetRaw(), TxHeader: hdr}
+		return out, nil
+	}
+
+	header, err := req.Parse()
+	if err != nil {
+		logger.With().Warning("ineffective transaction. failed to parse",
+			tx.GetRaw().ID,
+			log.Err(err),
+		)
+		return ineffective(nil)
+	}
+	ctx := req.ctx
+	args := req.args
 
-		t2 := time.Now()
-		logger.With().Debug("applying transaction",
+	if header.GasPrice == 0 {
+		logger.With().Warning("ineffective transaction. zero gas price",
 			log.Object("header", header),
 			log.Object("account", &ctx.PrincipalAccount),
 		)
+		return ineffective(nil)
+	}
+	if intrinsic := core.IntrinsicGas(ctx.Gas.BaseGas, tx.GetRaw().Raw); ctx.PrincipalAccount.Balance < intrinsic {
+		logger.With().Warning("ineffective transaction. intrinstic gas not covered",
+			log.Object("header", header),
+			log.Object("account", &ctx.PrincipalAccount),
+			log.Uint64("intrinsic gas", intrinsic),
+		)
+		return ineffective(nil)
+	}
+	out.maxGas = ctx.Header.MaxGas
+	if limit < ctx.Header.MaxGas {
+		logger.With().Warning("ineffective transaction. out of block gas",
+			log.Uint64("block gas limit", v.cfg.GasLimit),
+			log.Uint64("current limit", limit),
+			log.Object("header", header),
+			log.Object("account", &ctx.PrincipalAccount),
+		)
+		return ineffective(nil)
+	}
 
-		rst := types.TransactionWithResult{}
-		rst.Layer = lctx.Layer
+	// NOTE this part is executed only for transactions that weren't verified
+	// when saved into database by txs module
+	if !tx.Verified() && !req.Verify() {
+		logger.With().Warning("ineffective transaction. failed verify",
+			log.Object("header", header),
+			log.Object("account", &ctx.PrincipalAccount),
+		)
+		return ineffective(nil)
+	}
 
-		err = ctx.Consume(ctx.Header.MaxGas)
-		if err == nil {
-			err = ctx.PrincipalHandler.Exec(ctx, ctx.Header.Method, args)
-		}
-		if err != nil {
-			logger.With().Debug("transaction failed",
+	if conditional, ok := ctx.PrincipalTemplate.(core.ConditionalTemplate); ok {
+		if err := conditional.Executable(ctx, ctx.Header.Method, args); err != nil {
+			logger.With().Warning("ineffective transaction. not executable",
 				log.Object("header", header),
 				log.Object("account", &ctx.PrincipalAccount),
 				log.Err(err),
 			)
-			if errors.Is(err, core.ErrInternal) {
-				return nil, nil, 0, err
-			}
+			return ineffective(header)
 		}
-		transactionDurationExecute.Observe(float64(time.Since(t2)))
+	}
 
-		rst.RawTx = txs[i].GetRaw()
-		rst.TxHeader = &ctx.Header
-		rst.Status = types.TransactionSuccess
-		if err != nil {
-			rst.Status = types.TransactionFailure
-			rst.Message = err.Error()
-		}
-		rst.Gas = ctx.Consumed()
-		rst.Fee = ctx.Fee()
-		rst.Addresses = ctx.Updated()
+	if ctx.PrincipalAccount.NextNonce > ctx.Header.Nonce {
+		logger.With().Warning("ineffective transaction. nonce too low",
+			log.Object("header", header),
+			log.Object("account", &ctx.PrincipalAccount),
+		)
+		return ineffective(header)
+	}
 
-		err = ctx.Apply(ss)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("%w: %w", core.ErrInternal, err)
+	t2 := time.Now()
+	logger.With().Debug("applying transaction",
+		log.Object("header", header),
+		log.Object("account", &ctx.PrincipalAccount),
+	)
+
+	rst := types.TransactionWithResult{}
+	rst.Layer = lctx.Layer
+
+	err = ctx.Consume(ctx.Header.MaxGas)
+	if err == nil {
+		err = ctx.PrincipalHandler.Exec(ctx, ctx.Header.Method, args)
+	}
+	if mutable, ok := ctx.PrincipalTemplate.(core.MutableTemplate); ok && err == nil &&
+		ctx.Header.Method != core.MethodSpawn && mutable.Mutates(ctx.Header.Method) {
+		err = ctx.SavePrincipalState()
+	}
+	if err != nil {
+		logger.With().Debug("transaction failed",
+			log.Object("header", header),
+			log.Object("account", &ctx.PrincipalAccount),
+			log.Err(err),
+		)
+		if errors.Is(err, core.ErrInternal) {
+			return out, err
 		}
-		fees += ctx.Fee()
-		limit -= ctx.Consumed()
+	}
+	transactionDurationExecute.Observe(float64(time.Since(t2)))
 
-		executed = append(executed, rst)
-		transactionDuration.Observe(float64(time.Since(t1)))
+	rst.RawTx = tx.GetRaw()
+	rst.TxHeader = &ctx.Header
+	rst.Status = types.TransactionSuccess
+	if err != nil {
+		rst.Status = types.TransactionFailure
+		rst.Message = err.Error()
 	}
-	return executed, ineffective, fees, nil
+	rst.Gas = ctx.Consumed()
+	rst.Fee = ctx.Fee()
+	rst.Addresses = ctx.Updated()
+
+	out.ctx = ctx
+	out.result = rst
+	transactionDuration.Observe(float64(time.Since(t1)))
+	return out, nil
 }
 
 // Request used to implement 2-step validation flow.
@@ -425,7 +617,7 @@ func (v *VM) execute(lctx ApplyContext, ss *core.StagedCache, txs []types.Transa
 // if transaction can't be executed.
 type Request struct {
 	vm    *VM
-	cache *core.StagedCache
+	cache core.AccountLoader
 
 	lid     types.LayerID
 	raw     types.RawTx
@@ -442,7 +634,16 @@ func (r *Request) Parse() (*core.Header, error) {
 	if len(r.raw.Raw) > core.TxSizeLimit {
 		return nil, fmt.Errorf("%w: tx size (%d) > limit (%d)", core.ErrTxLimit, len(r.raw.Raw), core.TxSizeLimit)
 	}
-	header, ctx, args, err := parse(r.vm.logger, r.lid, r.vm.registry, r.cache, r.vm.cfg, r.raw.Raw, r.decoder)
+	next := r.lid
+	if next == 0 {
+		// transaction will be applied no earlier than in the layer after the last applied
+		applied, err := layers.GetLastApplied(r.vm.db)
+		if err != nil {
+			return nil, fmt.Errorf("%w: get last applied layer %w", core.ErrInternal, err)
+		}
+		next = applied.Add(1)
+	}
+	header, ctx, args, err := parse(r.vm.logger, r.lid, next, r.vm.registry, r.cache, r.vm.cfg, r.raw.Raw, r.decoder)
 	if err != nil {
 		return nil, err
 	}
@@ -463,14 +664,44 @@ func (r *Request) Verify() bool {
 	return rst
 }
 
-func parse(logger log.Log, lid types.LayerID, reg *registry.Registry, loader core.AccountLoader, cfg Config, raw []byte, decoder *scale.Decoder) (*core.Header, *core.Context, scale.Encodable, error) {
+// parse decodes the transaction and checks that it may be applied in the layer lid,
+// which is zero when the layer is not known. next is used to check that the version
+// of the transaction and the template are enabled, it equals lid unless the layer is not known.
+func parse(
+	logger log.Log,
+	lid, next types.LayerID,
+	reg *registry.Registry,
+	loader core.AccountLoader,
+	cfg Config,
+	raw []byte,
+	decoder *scale.Decoder,
+) (*core.Header, *core.Context, scale.Encodable, error) {
 	version, _, err := scale.DecodeCompact8(decoder)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("%w: failed to decode version %w", core.ErrMalformed, err)
 	}
-	if version != 0 {
+	var limits types.LayerLimits
+	switch version {
+	case 0:
+	case 1:
+		if next < cfg.TxVersion1Layer {
+			return nil, nil, nil, fmt.Errorf("%w: version 1 is not enabled before layer %d",
+				core.ErrMalformed, cfg.TxVersion1Layer)
+		}
+		// version 1 prefixes transaction with the range of layers where it may be applied
+		if _, err := limits.DecodeScale(decoder); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: failed to decode layer limits %w", core.ErrMalformed, err)
+		}
+		if limits.Max != 0 && limits.Min > limits.Max {
+			return nil, nil, nil, fmt.Errorf("%w: invalid layer limits %d-%d", core.ErrMalformed, limits.Min, limits.Max)
+		}
+	default:
 		return nil, nil, nil, fmt.Errorf("%w: unsupported version %d", core.ErrMalformed, version)
 	}
+	// layer is not known when transaction is validated for mempool
+	if lid != 0 && !limits.Contain(lid) {
+		return nil, nil, nil, fmt.Errorf("%w: layer %d not in %d-%d", core.ErrLayerLimits, lid, limits.Min, limits.Max)
+	}
 
 	var principal core.Address
 	if _, err := principal.DecodeScale(decoder); err != nil {
@@ -496,7 +727,7 @@ func parse(logger log.Log, lid types.LayerID, reg *registry.Registry, loader cor
 
 	if account.TemplateAddress != nil {
 		ctx.PrincipalHandler = reg.Get(*account.TemplateAddress)
-		if ctx.PrincipalHandler == nil {
+		if ctx.PrincipalHandler == nil || !cfg.enabled(next, *account.TemplateAddress) {
 			return nil, nil, nil, fmt.Errorf("%w: unknown template %s", core.ErrMalformed, *account.TemplateAddress)
 		}
 		ctx.PrincipalTemplate, err = ctx.PrincipalHandler.Load(account.State)
@@ -516,7 +747,7 @@ func parse(logger log.Log, lid types.LayerID, reg *registry.Registry, loader cor
 			return nil, nil, nil, fmt.Errorf("%w failed to decode template address %w", core.ErrMalformed, err)
 		}
 		handler = reg.Get(*templateAddress)
-		if handler == nil {
+		if handler == nil || !cfg.enabled(next, *templateAddress) {
 			return nil, nil, nil, fmt.Errorf("%w: unknown template %s", core.ErrMalformed, *templateAddress)
 		}
 		if ctx.PrincipalHandler == nil {
@@ -537,7 +768,7 @@ func parse(logger log.Log, lid types.LayerID, reg *registry.Registry, loader cor
 		return nil, nil, nil, err
 	}
 	args := handler.Args(method)
-	if args == nil {
+	if args == nil || !cfg.methodEnabled(next, *templateAddress, method) {
 		return nil, nil, nil, fmt.Errorf("%w: unknown method %s %d", core.ErrMalformed, *templateAddress, method)
 	}
 	if _, err := args.DecodeScale(decoder); err != nil {
@@ -564,12 +795,16 @@ func parse(logger log.Log, lid types.LayerID, reg *registry.Registry, loader cor
 	} else {
 		ctx.Gas.FixedGas += ctx.PrincipalTemplate.LoadGas()
 		ctx.Gas.FixedGas += ctx.PrincipalTemplate.ExecGas(method)
+		if variable, ok := ctx.PrincipalTemplate.(core.VariableGasTemplate); ok {
+			ctx.Gas.FixedGas += variable.VariableGas(method, args)
+		}
 	}
 	ctx.Gas.BaseGas = ctx.PrincipalTemplate.BaseGas(method)
 
 	ctx.ParseOutput = output
 
 	ctx.Header.Principal = principal
+	ctx.Header.LayerLimits = limits
 	ctx.Header.TemplateAddress = *templateAddress
 	ctx.Header.Method = method
 	ctx.Header.MaxGas = core.MaxGas(ctx.Gas.BaseGas, ctx.Gas.FixedGas, raw)
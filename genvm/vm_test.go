@@ -2,6 +2,8 @@ package vm
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -14,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 	"github.com/spacemeshos/economics/constants"
 	"github.com/spacemeshos/economics/rewards"
@@ -25,6 +28,9 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/core"
 	"github.com/spacemeshos/go-spacemesh/genvm/sdk"
 	sdkmultisig "github.com/spacemeshos/go-spacemesh/genvm/sdk/multisig"
+	sdkpasskey "github.com/spacemeshos/go-spacemesh/genvm/sdk/passkey"
+	sdkscheduler "github.com/spacemeshos/go-spacemesh/genvm/sdk/scheduler"
+	sdksecp256k1 "github.com/spacemeshos/go-spacemesh/genvm/sdk/secp256k1"
 	sdkvesting "github.com/spacemeshos/go-spacemesh/genvm/sdk/vesting"
 	sdkwallet "github.com/spacemeshos/go-spacemesh/genvm/sdk/wallet"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/multisig"
@@ -45,12 +51,22 @@ func testContext(lid types.LayerID) ApplyContext {
 	}
 }
 
+// testConfig executes every block both in parallel and sequentially,
+// so that all tests also check that parallel execution produces the same results.
+func testConfig() Config {
+	return Config{
+		GasLimit:         math.MaxUint64,
+		ExecutionWorkers: 4,
+		CompareExecution: true,
+	}
+}
+
 func newTester(tb testing.TB) *tester {
 	return &tester{
 		TB: tb,
 		VM: New(sql.InMemory(),
 			WithLogger(logtest.New(tb)),
-			WithConfig(Config{GasLimit: math.MaxUint64}),
+			WithConfig(testConfig()),
 		),
 		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
@@ -272,7 +288,7 @@ func (t *tester) persistent() *tester {
 	t.Cleanup(func() { require.NoError(t, db.Close()) })
 	require.NoError(t, err)
 	t.VM = New(db, WithLogger(logtest.New(t)),
-		WithConfig(Config{GasLimit: math.MaxUint64}))
+		WithConfig(testConfig()))
 	return t
 }
 
@@ -2569,3 +2585,336 @@ func TestMain(m *testing.M) {
 	types.SetLayersPerEpoch(2)
 	os.Exit(m.Run())
 }
+
+func TestMultisigUpdateKeys(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		add  func(*tester) *tester
+	}{
+		{desc: "multisig", add: func(tt *tester) *tester { return tt.addMultisig(1, 2, 3) }},
+		{desc: "vesting", add: func(tt *tester) *tester { return tt.addVesting(1, 2, 3) }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := tc.add(newTester(t)).applyGenesis()
+			var account *multisigAccount
+			switch typed := tt.accounts[0].(type) {
+			case *multisigAccount:
+				account = typed
+			case *vestingAccount:
+				account = &typed.multisigAccount
+			}
+			pub, pk, err := ed25519.GenerateKey(nil)
+			require.NoError(t, err)
+			updateKeys := func(required uint8, pubs []ed25519.PublicKey, nonce core.Nonce) types.RawTx {
+				agg := sdkmultisig.UpdateKeys(0, account.pks[0], account.address, required, pubs, nonce)
+				for i := 1; i < account.k; i++ {
+					part := sdkmultisig.UpdateKeys(uint8(i), account.pks[i], account.address, required, pubs, nonce)
+					agg.Add(*part.Part(uint8(i)))
+				}
+				return types.NewRawTx(agg.Raw())
+			}
+			spend := func(ref uint8, pk ed25519.PrivateKey, nonce core.Nonce) types.RawTx {
+				return types.NewRawTx(sdkmultisig.Spend(ref, pk, account.address, types.Address{1}, 100, nonce).Raw())
+			}
+
+			lid := types.GetEffectiveGenesis()
+			_, results, err := tt.Apply(testContext(lid), notVerified(
+				types.NewRawTx(account.selfSpawn(0)),
+				updateKeys(2, []ed25519.PublicKey{pub}, 1),
+				updateKeys(1, []ed25519.PublicKey{pub}, 2),
+			), nil)
+			require.NoError(t, err)
+			require.Len(t, results, 3)
+			require.Equal(t, types.TransactionSuccess, results[0].Status)
+			require.Equal(t, types.TransactionFailure, results[1].Status)
+			require.Contains(t, results[1].Message, "requires atleast 2 keys")
+			require.Equal(t, types.TransactionSuccess, results[2].Status)
+			gas := multisig.BaseGas(multisig.MethodUpdateKeys, account.k) + multisig.LoadGas(len(account.pks)) +
+				multisig.ExecGas(multisig.MethodUpdateKeys, len(account.pks)) + multisig.KeysGas(1) +
+				core.TxDataGas(len(results[2].Raw))
+			require.Equal(t, gas, results[2].Gas, "charged for the actual number of keys")
+
+			state, err := accounts.Latest(tt.db, account.address)
+			require.NoError(t, err)
+			var ms multisig.MultiSig
+			_, err = ms.DecodeScale(scale.NewDecoder(bytes.NewReader(state.State)))
+			require.NoError(t, err)
+			require.EqualValues(t, 1, ms.Required)
+			require.Len(t, ms.PublicKeys, 1)
+			require.Equal(t, pub, ed25519.PublicKey(ms.PublicKeys[0][:]))
+
+			lid = lid.Add(1)
+			skipped, results, err := tt.Apply(testContext(lid), notVerified(
+				spend(0, account.pks[0], 3),
+				spend(0, pk, 3),
+			), nil)
+			require.NoError(t, err)
+			require.Len(t, skipped, 1, "old key is no longer valid")
+			require.Len(t, results, 1)
+			require.Equal(t, types.TransactionSuccess, results[0].Status)
+		})
+	}
+}
+
+func TestWalletBatchSpend(t *testing.T) {
+	const balance = 1_000_000_000
+	tt := newTester(t).addSingleSig(4).applyGenesisWithBalance(balance)
+	pk := signing.PrivateKey(tt.accounts[0].(*singlesigAccount).pk)
+	principal := tt.accounts[0].getAddress()
+	outputs := []wallet.SpendArguments{
+		{Destination: tt.accounts[1].getAddress(), Amount: 100},
+		{Destination: tt.accounts[2].getAddress(), Amount: 200},
+		{Destination: tt.accounts[3].getAddress(), Amount: 300},
+	}
+	lid := types.GetEffectiveGenesis()
+	batch := sdkwallet.BatchSpend(pk, outputs, 1)
+	insufficient := sdkwallet.BatchSpend(pk, []wallet.SpendArguments{
+		{Destination: tt.accounts[1].getAddress(), Amount: 100},
+		{Destination: tt.accounts[2].getAddress(), Amount: balance},
+	}, 2)
+	skipped, results, err := tt.Apply(testContext(lid), notVerified(
+		tt.selfSpawn(0),
+		types.NewRawTx(batch),
+		types.NewRawTx(insufficient),
+	), nil)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, results, 3)
+	require.Equal(t, types.TransactionSuccess, results[1].Status, results[1].Message)
+	require.Equal(t, types.TransactionFailure, results[2].Status)
+	require.Contains(t, results[2].Message, core.ErrNoBalance.Error())
+
+	gas := wallet.BaseGas(wallet.MethodBatchSpend) + wallet.LoadGas() +
+		wallet.ExecGas(wallet.MethodBatchSpend) + wallet.OutputsGas(len(outputs)) +
+		core.TxDataGas(len(batch))
+	require.Equal(t, gas, results[1].Gas)
+	require.Less(t, int(results[1].Gas), len(outputs)*tt.estimateSpendGas(0, 1, 100, 1),
+		"batch is cheaper than separate spends")
+
+	for i, output := range outputs {
+		received, err := tt.GetBalance(output.Destination)
+		require.NoError(t, err)
+		// first output of the failed batch must not be applied
+		require.Equal(t, balance+output.Amount, received, "output %d", i)
+	}
+	remaining, err := tt.GetBalance(principal)
+	require.NoError(t, err)
+	require.Equal(t, balance-600-results[0].Fee-results[1].Fee-results[2].Fee, remaining)
+
+	t.Run("max spend", func(t *testing.T) {
+		req := tt.Validation(types.NewRawTx(sdkwallet.BatchSpend(pk, outputs, 3)))
+		header, err := req.Parse()
+		require.NoError(t, err)
+		require.EqualValues(t, 600, header.MaxSpend)
+	})
+	t.Run("empty", func(t *testing.T) {
+		req := tt.Validation(types.NewRawTx(sdkwallet.BatchSpend(pk, nil, 3)))
+		_, err := req.Parse()
+		require.ErrorIs(t, err, core.ErrMalformed)
+	})
+}
+
+func TestLayerLimits(t *testing.T) {
+	tt := newTester(t).addSingleSig(2).applyGenesis()
+	pk := signing.PrivateKey(tt.accounts[0].(*singlesigAccount).pk)
+	to := tt.accounts[1].getAddress()
+	lid := types.GetEffectiveGenesis().Add(1)
+	_, _, err := tt.Apply(testContext(lid), notVerified(tt.selfSpawn(0)), nil)
+	require.NoError(t, err)
+
+	lid = lid.Add(1)
+	early := sdkwallet.Spend(pk, to, 100, 1, sdk.WithLayerLimits(lid.Add(1).Uint32(), lid.Add(10).Uint32()))
+	expired := sdkwallet.Spend(pk, to, 100, 1, sdk.WithLayerLimits(0, lid.Sub(1).Uint32()))
+	valid := sdkwallet.Spend(pk, to, 100, 1, sdk.WithLayerLimits(lid.Uint32(), lid.Uint32()))
+
+	t.Run("parse", func(t *testing.T) {
+		header, err := tt.Validation(types.NewRawTx(valid)).Parse()
+		require.NoError(t, err)
+		require.Equal(t, types.LayerLimits{Min: lid.Uint32(), Max: lid.Uint32()}, header.LayerLimits)
+
+		header, err = tt.Validation(types.NewRawTx(sdkwallet.Spend(pk, to, 100, 1))).Parse()
+		require.NoError(t, err)
+		require.Equal(t, types.LayerLimits{}, header.LayerLimits)
+
+		// layer is unknown during validation, therefore only the range itself is checked
+		_, err = tt.Validation(types.NewRawTx(expired)).Parse()
+		require.NoError(t, err)
+		_, err = tt.Validation(types.NewRawTx(
+			sdkwallet.Spend(pk, to, 100, 1, sdk.WithLayerLimits(10, 9)),
+		)).Parse()
+		require.ErrorIs(t, err, core.ErrMalformed)
+	})
+
+	skipped, results, err := tt.Apply(testContext(lid), notVerified(
+		types.NewRawTx(early),
+		types.NewRawTx(expired),
+		types.NewRawTx(valid),
+	), nil)
+	require.NoError(t, err)
+	require.Len(t, skipped, 2)
+	require.Equal(t, types.NewRawTx(early).ID, skipped[0].ID)
+	require.Equal(t, types.NewRawTx(expired).ID, skipped[1].ID)
+	require.Len(t, results, 1)
+	require.Equal(t, types.NewRawTx(valid).ID, results[0].ID)
+	require.Equal(t, types.TransactionSuccess, results[0].Status)
+}
+
+func TestLayerLimitsActivation(t *testing.T) {
+	tt := newTester(t).addSingleSig(2).applyGenesis()
+	pk := signing.PrivateKey(tt.accounts[0].(*singlesigAccount).pk)
+	to := tt.accounts[1].getAddress()
+	lid := types.GetEffectiveGenesis().Add(1)
+	activation := lid.Add(2)
+	tt.cfg.TxVersion1Layer = activation
+	_, _, err := tt.Apply(testContext(lid), notVerified(tt.selfSpawn(0)), nil)
+	require.NoError(t, err)
+
+	lid = lid.Add(1)
+	limited := sdkwallet.Spend(pk, to, 100, 1, sdk.WithLayerLimits(0, activation.Uint32()))
+	_, err = tt.Validation(types.NewRawTx(limited)).Parse()
+	require.ErrorIs(t, err, core.ErrMalformed)
+	_, err = tt.Validation(types.NewRawTx(sdkwallet.Spend(pk, to, 100, 1))).Parse()
+	require.NoError(t, err)
+
+	skipped, results, err := tt.Apply(testContext(lid), notVerified(types.NewRawTx(limited)), nil)
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+	require.Empty(t, results)
+	require.NoError(t, layers.SetApplied(tt.db, lid, types.RandomBlockID()))
+
+	// the transaction is validated for the layer after the last applied
+	_, err = tt.Validation(types.NewRawTx(limited)).Parse()
+	require.NoError(t, err)
+
+	lid = lid.Add(1)
+	skipped, results, err = tt.Apply(testContext(lid), notVerified(types.NewRawTx(limited)), nil)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, results, 1)
+	require.Equal(t, types.TransactionSuccess, results[0].Status)
+}
+
+func TestTemplatesUpgradeActivation(t *testing.T) {
+	_, edkey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	scheduler := signing.PrivateKey(edkey)
+	_, edkey, err = ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	owner := signing.PrivateKey(edkey)
+	pub, edkey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	pubs := []ed25519.PublicKey{pub}
+	secpkey, err := secp.GeneratePrivateKey()
+	require.NoError(t, err)
+	p256key, err := ecdsa.GenerateKey(elliptic.P256(), rand.New(rand.NewSource(time.Now().UnixNano())))
+	require.NoError(t, err)
+	passkey := sdkpasskey.PublicKey(p256key)
+	passkeySpawn, err := sdkpasskey.Assert(p256key, "example.com", "https://example.com",
+		sdkpasskey.SelfSpawn(passkey, 0).Challenge())
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		desc      string
+		principal core.Address
+		// spawn is applied at genesis, before the upgrade.
+		spawn []byte
+		tx    []byte
+	}{
+		{
+			desc:      "scheduler",
+			principal: sdkscheduler.Address(signing.Public(scheduler)),
+			tx:        sdkscheduler.SelfSpawn(scheduler, 0),
+		},
+		{
+			desc:      "secp256k1",
+			principal: sdksecp256k1.Address(secpkey.PubKey()),
+			tx:        sdksecp256k1.SelfSpawn(secpkey, 0),
+		},
+		{
+			desc:      "passkey",
+			principal: sdkpasskey.Address(passkey),
+			tx:        sdkpasskey.SelfSpawn(passkey, 0).Raw(passkeySpawn),
+		},
+		{
+			desc:      "wallet batch spend",
+			principal: sdkwallet.Address(signing.Public(owner)),
+			spawn:     sdkwallet.SelfSpawn(owner, 0),
+			tx: sdkwallet.BatchSpend(owner, []wallet.SpendArguments{
+				{Destination: types.Address{1}, Amount: 100},
+			}, 1),
+		},
+		{
+			desc:      "multisig update keys",
+			principal: sdkmultisig.Address(multisig.TemplateAddress, 1, pub),
+			spawn:     sdkmultisig.SelfSpawn(0, edkey, multisig.TemplateAddress, 1, pubs, 0).Raw(),
+			tx:        sdkmultisig.UpdateKeys(0, edkey, sdkmultisig.Address(multisig.TemplateAddress, 1, pub), 1, pubs, 1).Raw(),
+		},
+		{
+			desc:      "vesting update keys",
+			principal: sdkmultisig.Address(vesting.TemplateAddress, 1, pub),
+			spawn:     sdkmultisig.SelfSpawn(0, edkey, vesting.TemplateAddress, 1, pubs, 0).Raw(),
+			tx:        sdkmultisig.UpdateKeys(0, edkey, sdkmultisig.Address(vesting.TemplateAddress, 1, pub), 1, pubs, 1).Raw(),
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := newTester(t)
+			activation := types.GetEffectiveGenesis().Add(2)
+			tt.cfg.TemplatesUpgradeLayer = activation
+			require.NoError(t, tt.ApplyGenesis([]core.Account{{Address: tc.principal, Balance: 1_000_000}}))
+			if tc.spawn != nil {
+				_, results, err := tt.Apply(testContext(types.GetEffectiveGenesis()),
+					notVerified(types.NewRawTx(tc.spawn)), nil)
+				require.NoError(t, err)
+				require.Len(t, results, 1)
+				require.Equal(t, types.TransactionSuccess, results[0].Status, results[0].Message)
+			}
+
+			lid := activation.Sub(1)
+			_, err = tt.Validation(types.NewRawTx(tc.tx)).Parse()
+			require.ErrorIs(t, err, core.ErrMalformed)
+			skipped, results, err := tt.Apply(testContext(lid), notVerified(types.NewRawTx(tc.tx)), nil)
+			require.NoError(t, err)
+			require.Len(t, skipped, 1)
+			require.Empty(t, results)
+			require.NoError(t, layers.SetApplied(tt.db, lid, types.RandomBlockID()))
+
+			// the transaction is validated for the layer after the last applied
+			_, err = tt.Validation(types.NewRawTx(tc.tx)).Parse()
+			require.NoError(t, err)
+
+			skipped, results, err = tt.Apply(testContext(activation), notVerified(types.NewRawTx(tc.tx)), nil)
+			require.NoError(t, err)
+			require.Empty(t, skipped)
+			require.Len(t, results, 1)
+			require.Equal(t, types.TransactionSuccess, results[0].Status, results[0].Message)
+		})
+	}
+}
+
+func TestGenesisStateRoot(t *testing.T) {
+	tt := newTester(t).addSingleSig(3)
+	genesis := []types.Account{
+		{Address: tt.accounts[2].getAddress(), Balance: 300_000_000},
+		{Address: tt.accounts[0].getAddress(), Balance: 100_000_000},
+		{Address: tt.accounts[1].getAddress(), Balance: 200_000_000},
+	}
+	_, err := tt.GetGenesisStateRoot()
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	require.NoError(t, tt.ApplyGenesis(genesis))
+	root, err := tt.GetGenesisStateRoot()
+	require.NoError(t, err)
+	require.Equal(t, GenesisStateRoot(genesis), root)
+	require.Equal(t, GenesisStateRoot([]types.Account{genesis[1], genesis[2], genesis[0]}), root)
+
+	genesis[0].Balance++
+	require.NotEqual(t, GenesisStateRoot(genesis), root)
+
+	// accounts updated after genesis are not included
+	ineffective, _, err := tt.Apply(testContext(types.GetEffectiveGenesis()), notVerified(tt.selfSpawn(1)), nil)
+	require.NoError(t, err)
+	require.Empty(t, ineffective)
+	updated, err := tt.GetGenesisStateRoot()
+	require.NoError(t, err)
+	require.Equal(t, root, updated)
+}
@@ -0,0 +1,427 @@
+// Package localpoet implements a PoET service for private networks and tests that run on a single machine.
+//
+// Service follows the round schedule of the poet binary: round for epoch N is open for submissions
+// until genesis + phase shift + N * epoch duration, after that proof is generated until the round end
+// (cycle gap before the next round closes) and published. It implements the grpc api of the poet,
+// therefore it can be served over grpc, over http with grpc gateway (see cmd/localpoet), or registered
+// in the same process with activation.PoetRegistry.RegisterLocal.
+//
+// Unlike poet binary the state is not persisted, rounds and proofs are lost on restart.
+package localpoet
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/spacemeshos/merkle-tree"
+	"github.com/spacemeshos/poet/hash"
+	"github.com/spacemeshos/poet/prover"
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"github.com/spacemeshos/poet/shared"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+)
+
+const fileWriterBufSize = 4096
+
+// Config of the round schedule and proof generation.
+type Config struct {
+	Genesis       time.Time
+	EpochDuration time.Duration
+	PhaseShift    time.Duration
+	CycleGap      time.Duration
+
+	// MaxExecution limits duration of the proof generation, it runs until the round end if zero.
+	// Proof is published earlier and has fewer leaves, it saves cpu if many services run on one machine.
+	MaxExecution time.Duration
+
+	// PowDifficulty for submissions. Challenge of the proof of work is InitialPowChallenge
+	// until the first proof is generated, root of the last proof afterwards.
+	PowDifficulty       uint
+	InitialPowChallenge string
+
+	// DataDir for the merkle tree layers that are not kept in memory, temporary directory if empty.
+	DataDir string
+	// MinMemoryLayer is the lowest layer of the merkle tree that is kept in memory.
+	MinMemoryLayer uint
+}
+
+// DefaultConfig returns the default configuration of the service.
+func DefaultConfig() Config {
+	return Config{
+		Genesis:             time.Now(),
+		EpochDuration:       5 * time.Minute,
+		CycleGap:            30 * time.Second,
+		InitialPowChallenge: "localpoet",
+		MinMemoryLayer:      prover.LowestMerkleMinMemoryLayer,
+	}
+}
+
+// Validate that the schedule is consistent.
+func (c Config) Validate() error {
+	switch {
+	case c.EpochDuration <= 0:
+		return errors.New("epoch duration must be positive")
+	case c.CycleGap < 0 || c.CycleGap >= c.EpochDuration:
+		return fmt.Errorf("cycle gap %v must be between 0 and epoch duration %v", c.CycleGap, c.EpochDuration)
+	case c.PhaseShift < 0:
+		return errors.New("phase shift must not be negative")
+	case c.MaxExecution < 0:
+		return errors.New("max execution must not be negative")
+	}
+	return nil
+}
+
+// Opt for configuring Service.
+type Opt func(*Service)
+
+// WithLogger configures logger for the service.
+func WithLogger(logger *zap.Logger) Opt {
+	return func(s *Service) {
+		s.logger = logger
+	}
+}
+
+// WithKey sets the key of the service, public key is the id of the service
+// that nodes use to match proofs with submissions. Random key is used by default.
+func WithKey(key ed25519.PrivateKey) Opt {
+	return func(s *Service) {
+		s.key = key
+	}
+}
+
+type powParams struct {
+	challenge  []byte
+	difficulty uint
+}
+
+func (p powParams) equal(other *rpcapi.PowParams) bool {
+	return p.difficulty == uint(other.GetDifficulty()) && bytes.Equal(p.challenge, other.GetChallenge())
+}
+
+type round struct {
+	id         string
+	epoch      uint32
+	members    [][]byte
+	challenges map[types.NodeID][]byte
+}
+
+func newRound(epoch uint32) *round {
+	return &round{
+		id:         strconv.FormatUint(uint64(epoch), 10),
+		epoch:      epoch,
+		challenges: map[types.NodeID][]byte{},
+	}
+}
+
+// Service accepts submissions to the open round and publishes proofs of the executed rounds.
+type Service struct {
+	rpcapi.UnimplementedPoetServiceServer
+
+	cfg    Config
+	logger *zap.Logger
+	key    ed25519.PrivateKey
+
+	mu        sync.Mutex
+	open      *round
+	executing string
+	pow       powParams
+	prevPow   *powParams
+	proofs    map[string]*rpcapi.PoetProof
+}
+
+// New creates service, rounds are opened by Run.
+func New(cfg Config, opts ...Opt) (*Service, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Service{
+		cfg:    cfg,
+		logger: zap.NewNop(),
+		pow: powParams{
+			challenge:  []byte(cfg.InitialPowChallenge),
+			difficulty: cfg.PowDifficulty,
+		},
+		proofs: map[string]*rpcapi.PoetProof{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.key == nil {
+		_, key, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return nil, fmt.Errorf("generate key: %w", err)
+		}
+		s.key = key
+	}
+	return s, nil
+}
+
+// PublicKey returns the id of the service.
+func (s *Service) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// roundStart is the time when round stops accepting submissions and starts execution.
+func (s *Service) roundStart(epoch uint32) time.Time {
+	return s.cfg.Genesis.Add(s.cfg.PhaseShift).Add(s.cfg.EpochDuration * time.Duration(epoch))
+}
+
+// roundEnd is the time when execution of the round ends.
+func (s *Service) roundEnd(epoch uint32) time.Time {
+	return s.roundStart(epoch).Add(s.cfg.EpochDuration).Add(-s.cfg.CycleGap)
+}
+
+func (s *Service) firstEpoch(now time.Time) uint32 {
+	if d := now.Sub(s.cfg.Genesis.Add(s.cfg.PhaseShift)); d > 0 {
+		return uint32(d/s.cfg.EpochDuration) + 1
+	}
+	return 0
+}
+
+// Run opens rounds according to the schedule and executes closed rounds until context is canceled.
+func (s *Service) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.open != nil {
+		s.mu.Unlock()
+		return errors.New("service is already running")
+	}
+	s.open = newRound(s.firstEpoch(time.Now()))
+	s.mu.Unlock()
+	s.logger.Info("local poet started",
+		zap.Binary("id", s.PublicKey()),
+		zap.Time("genesis", s.cfg.Genesis),
+		zap.Duration("epoch duration", s.cfg.EpochDuration),
+		zap.Duration("phase shift", s.cfg.PhaseShift),
+		zap.Duration("cycle gap", s.cfg.CycleGap),
+	)
+
+	var eg errgroup.Group
+	defer eg.Wait()
+	for {
+		s.mu.Lock()
+		open := s.open
+		s.mu.Unlock()
+		s.logger.Info("round opened",
+			zap.String("round", open.id),
+			zap.Time("closes", s.roundStart(open.epoch)),
+		)
+		timer := time.NewTimer(time.Until(s.roundStart(open.epoch)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		s.mu.Lock()
+		s.open = newRound(open.epoch + 1)
+		s.executing = open.id
+		s.mu.Unlock()
+		eg.Go(func() error {
+			s.execute(ctx, open)
+			return nil
+		})
+	}
+}
+
+func (s *Service) execute(ctx context.Context, r *round) {
+	logger := s.logger.With(zap.String("round", r.id))
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.executing == r.id {
+			s.executing = ""
+		}
+	}()
+	if len(r.members) == 0 {
+		logger.Info("round closed without members")
+		return
+	}
+	proof, err := s.generateProof(ctx, r)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Error("round failed", zap.Error(err))
+		}
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proofs[r.id] = proof
+	prev := s.pow
+	s.prevPow = &prev
+	s.pow = powParams{challenge: proof.Proof.Root, difficulty: s.cfg.PowDifficulty}
+	logger.Info("proof published",
+		zap.Int("members", len(r.members)),
+		zap.Uint64("leaves", proof.Leaves),
+	)
+}
+
+func (s *Service) generateProof(ctx context.Context, r *round) (*rpcapi.PoetProof, error) {
+	tree, err := merkle.NewTreeBuilder().WithHashFunc(shared.HashMembershipTreeNode).Build()
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range r.members {
+		if err := tree.AddLeaf(member); err != nil {
+			return nil, err
+		}
+	}
+	statement := tree.Root()
+
+	end := s.roundEnd(r.epoch)
+	if s.cfg.MaxExecution > 0 {
+		if limit := time.Now().Add(s.cfg.MaxExecution); limit.Before(end) {
+			end = limit
+		}
+	}
+	if s.cfg.DataDir != "" {
+		if err := os.MkdirAll(s.cfg.DataDir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := os.MkdirTemp(s.cfg.DataDir, "round-"+r.id+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	s.logger.Info("executing round",
+		zap.String("round", r.id),
+		zap.Int("members", len(r.members)),
+		zap.Binary("statement", statement),
+		zap.Time("end", end),
+	)
+	leaves, proof, err := prover.GenerateProofWithoutPersistency(
+		ctx,
+		prover.TreeConfig{
+			MinMemoryLayer:    s.cfg.MinMemoryLayer,
+			Datadir:           dir,
+			FileWriterBufSize: fileWriterBufSize,
+		},
+		hash.GenLabelHashFunc(statement),
+		hash.GenMerkleHashFunc(statement),
+		end,
+		shared.T,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("generate proof: %w", err)
+	}
+	return &rpcapi.PoetProof{
+		Proof: &rpcapi.MerkleProof{
+			Root:         proof.Root,
+			ProvenLeaves: proof.ProvenLeaves,
+			ProofNodes:   proof.ProofNodes,
+		},
+		Members: r.members,
+		Leaves:  leaves,
+	}, nil
+}
+
+// HTTPHandler serves the api over http, same as the REST api of the poet binary.
+func (s *Service) HTTPHandler(ctx context.Context) (http.Handler, error) {
+	mux := runtime.NewServeMux()
+	if err := rpcapi.RegisterPoetServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// PowParams returns parameters of the proof of work required for submission.
+func (s *Service) PowParams(context.Context, *rpcapi.PowParamsRequest) (*rpcapi.PowParamsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &rpcapi.PowParamsResponse{
+		PowParams: &rpcapi.PowParams{
+			Challenge:  s.pow.challenge,
+			Difficulty: uint32(s.pow.difficulty),
+		},
+	}, nil
+}
+
+// Submit registers challenge in the open round.
+func (s *Service) Submit(_ context.Context, in *rpcapi.SubmitRequest) (*rpcapi.SubmitResponse, error) {
+	if len(in.Pubkey) != ed25519.PublicKeySize {
+		return nil, status.Error(codes.InvalidArgument, "invalid public key")
+	}
+	if len(in.Challenge) != len(types.Member{}) {
+		return nil, status.Errorf(codes.InvalidArgument, "challenge must be %d bytes", len(types.Member{}))
+	}
+	if !ed25519.Verify(in.Pubkey, bytes.Join([][]byte{in.Prefix, in.Challenge}, nil), in.Signature) {
+		return nil, status.Error(codes.InvalidArgument, "invalid signature")
+	}
+	nodeID := types.BytesToNodeID(in.Pubkey)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open == nil {
+		return nil, status.Error(codes.FailedPrecondition, "service is not started")
+	}
+	var params *powParams
+	if s.pow.equal(in.PowParams) {
+		params = &s.pow
+	} else if s.prevPow != nil && s.prevPow.equal(in.PowParams) {
+		params = s.prevPow
+	} else {
+		return nil, status.Error(codes.InvalidArgument, "invalid proof of work parameters")
+	}
+	hash := shared.CalcSubmitPowHash(params.challenge, in.Challenge, in.Pubkey, nil, in.Nonce)
+	if !shared.CheckLeadingZeroBits(hash, params.difficulty) {
+		return nil, status.Error(codes.InvalidArgument, "invalid proof of work")
+	}
+
+	r := s.open
+	if existing, ok := r.challenges[nodeID]; !ok {
+		r.challenges[nodeID] = in.Challenge
+		r.members = append(r.members, in.Challenge)
+	} else if !bytes.Equal(existing, in.Challenge) {
+		return nil, status.Error(codes.AlreadyExists, "node already submitted a different challenge")
+	}
+	return &rpcapi.SubmitResponse{
+		RoundId:  r.id,
+		RoundEnd: durationpb.New(time.Until(s.roundEnd(r.epoch))),
+	}, nil
+}
+
+// Info returns the id of the service and the state of the rounds.
+func (s *Service) Info(context.Context, *rpcapi.InfoRequest) (*rpcapi.InfoResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := &rpcapi.InfoResponse{
+		ExecutingRoundId: s.executing,
+		ServicePubkey:    s.PublicKey(),
+		PhaseShift:       durationpb.New(s.cfg.PhaseShift),
+		CycleGap:         durationpb.New(s.cfg.CycleGap),
+	}
+	if s.open != nil {
+		info.OpenRoundId = s.open.id
+	}
+	return info, nil
+}
+
+// Proof returns the proof of the executed round.
+func (s *Service) Proof(_ context.Context, in *rpcapi.ProofRequest) (*rpcapi.ProofResponse, error) {
+	s.mu.Lock()
+	proof, exists := s.proofs[in.RoundId]
+	s.mu.Unlock()
+	if !exists {
+		return nil, status.Error(codes.NotFound, "proof not found")
+	}
+	return &rpcapi.ProofResponse{
+		Proof:  proof,
+		Pubkey: s.PublicKey(),
+	}, nil
+}
@@ -0,0 +1,253 @@
+package localpoet
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	rpcapi "github.com/spacemeshos/poet/release/proto/go/rpc/api/v1"
+	"github.com/spacemeshos/poet/shared"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spacemeshos/go-spacemesh/activation"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Genesis = time.Now()
+	cfg.PhaseShift = 500 * time.Millisecond
+	cfg.EpochDuration = time.Second
+	cfg.CycleGap = 500 * time.Millisecond
+	return cfg
+}
+
+func runService(tb testing.TB, cfg Config) *Service {
+	svc, err := New(cfg, WithLogger(zaptest.NewLogger(tb)))
+	require.NoError(tb, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	var eg errgroup.Group
+	eg.Go(func() error { return svc.Run(ctx) })
+	tb.Cleanup(func() {
+		cancel()
+		require.NoError(tb, eg.Wait())
+	})
+	require.Eventually(tb, func() bool {
+		info, err := svc.Info(ctx, &rpcapi.InfoRequest{})
+		return err == nil && info.OpenRoundId != ""
+	}, time.Second, 10*time.Millisecond)
+	return svc
+}
+
+func poetConfig() activation.PoetConfig {
+	cfg := activation.DefaultPoetConfig()
+	cfg.RequestRetryDelay = 50 * time.Millisecond
+	cfg.MaxRequestRetries = 100
+	return cfg
+}
+
+func submit(tb testing.TB, client activation.PoetProvingServiceClient, signer *signing.EdSigner, challenge types.Hash32) (*types.PoetRound, error) {
+	ctx := context.Background()
+	params, err := client.PowParams(ctx)
+	require.NoError(tb, err)
+	nonce, err := shared.FindSubmitPowNonce(ctx, params.Challenge, challenge[:], signer.NodeID().Bytes(), params.Difficulty)
+	require.NoError(tb, err)
+	prefix := bytes.Join([][]byte{signer.Prefix(), {byte(signing.POET)}}, nil)
+	return client.Submit(ctx, prefix, challenge[:], signer.Sign(signing.POET, challenge[:]), signer.NodeID(),
+		activation.PoetPoW{Nonce: nonce, Params: *params})
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+	for _, tc := range []struct {
+		desc   string
+		modify func(*Config)
+	}{
+		{"epoch", func(cfg *Config) { cfg.EpochDuration = 0 }},
+		{"cycle gap", func(cfg *Config) { cfg.CycleGap = cfg.EpochDuration }},
+		{"phase shift", func(cfg *Config) { cfg.PhaseShift = -1 }},
+		{"max execution", func(cfg *Config) { cfg.MaxExecution = -1 }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tc.modify(&cfg)
+			require.Error(t, cfg.Validate())
+			_, err := New(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	cfg := testConfig()
+	cfg.Genesis = time.Unix(1000, 0)
+	svc, err := New(cfg)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), svc.firstEpoch(cfg.Genesis))
+	require.Equal(t, uint32(0), svc.firstEpoch(cfg.Genesis.Add(cfg.PhaseShift)))
+	require.Equal(t, uint32(1), svc.firstEpoch(cfg.Genesis.Add(cfg.PhaseShift+1)))
+	require.Equal(t, uint32(3), svc.firstEpoch(cfg.Genesis.Add(cfg.PhaseShift+2*cfg.EpochDuration+1)))
+
+	require.Equal(t, cfg.Genesis.Add(cfg.PhaseShift+2*cfg.EpochDuration), svc.roundStart(2))
+	require.Equal(t, svc.roundStart(3).Add(-cfg.CycleGap), svc.roundEnd(2))
+}
+
+func TestPublishesValidProof(t *testing.T) {
+	svc := runService(t, testConfig())
+	registry := activation.NewPoetRegistry(zaptest.NewLogger(t))
+	registry.RegisterLocal("test", svc)
+	client, err := registry.NewClient("local://test", poetConfig())
+	require.NoError(t, err)
+
+	id, err := client.PoetServiceID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte(svc.PublicKey()), id.ServiceID)
+
+	challenges := make([]types.Hash32, 3)
+	var round *types.PoetRound
+	for i := range challenges {
+		signer, err := signing.NewEdSigner()
+		require.NoError(t, err)
+		challenges[i] = types.RandomHash()
+		round, err = submit(t, client, signer, challenges[i])
+		require.NoError(t, err)
+		// submitting the same challenge again is allowed
+		_, err = submit(t, client, signer, challenges[i])
+		require.NoError(t, err)
+		_, err = submit(t, client, signer, types.RandomHash())
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+	}
+	require.Equal(t, "0", round.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	proof, members, err := client.Proof(ctx, round.ID)
+	require.NoError(t, err)
+	require.Len(t, members, len(challenges))
+	for i, challenge := range challenges {
+		require.Equal(t, types.Member(challenge), members[i])
+	}
+	require.NotZero(t, proof.LeafCount)
+
+	db := activation.NewPoetDb(sql.InMemory(), logtest.New(t))
+	require.NoError(t, db.Validate(proof.Statement[:], proof.PoetProof, proof.PoetServiceID, proof.RoundID, proof.Signature))
+
+	// next round requires pow with the root of the published proof
+	params, err := client.PowParams(ctx)
+	require.NoError(t, err)
+	require.Equal(t, proof.Root, params.Challenge)
+}
+
+func TestRejectsInvalidSubmissions(t *testing.T) {
+	cfg := testConfig()
+	cfg.PowDifficulty = 4
+	svc, err := New(cfg)
+	require.NoError(t, err)
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	challenge := types.RandomHash()
+	request := func() *rpcapi.SubmitRequest {
+		ctx := context.Background()
+		params, err := svc.PowParams(ctx, &rpcapi.PowParamsRequest{})
+		require.NoError(t, err)
+		nonce, err := shared.FindSubmitPowNonce(ctx, params.PowParams.Challenge, challenge[:], signer.NodeID().Bytes(), uint(params.PowParams.Difficulty))
+		require.NoError(t, err)
+		signature := signer.Sign(signing.POET, challenge[:])
+		return &rpcapi.SubmitRequest{
+			Prefix:    bytes.Join([][]byte{signer.Prefix(), {byte(signing.POET)}}, nil),
+			Challenge: challenge[:],
+			Signature: signature.Bytes(),
+			Pubkey:    signer.NodeID().Bytes(),
+			Nonce:     nonce,
+			PowParams: params.PowParams,
+		}
+	}
+
+	_, err = svc.Submit(context.Background(), request())
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	svc = runService(t, cfg)
+	for _, tc := range []struct {
+		desc   string
+		modify func(*rpcapi.SubmitRequest)
+	}{
+		{"pubkey", func(req *rpcapi.SubmitRequest) { req.Pubkey = req.Pubkey[1:] }},
+		{"challenge", func(req *rpcapi.SubmitRequest) { req.Challenge = req.Challenge[1:] }},
+		{"signature", func(req *rpcapi.SubmitRequest) { req.Prefix = nil }},
+		{"pow params", func(req *rpcapi.SubmitRequest) { req.PowParams.Difficulty = 1 }},
+		{"pow", func(req *rpcapi.SubmitRequest) {
+			for {
+				req.Nonce++
+				hash := shared.CalcSubmitPowHash(req.PowParams.Challenge, req.Challenge, req.Pubkey, nil, req.Nonce)
+				if !shared.CheckLeadingZeroBits(hash, uint(req.PowParams.Difficulty)) {
+					return
+				}
+			}
+		}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := request()
+			tc.modify(req)
+			_, err := svc.Submit(context.Background(), req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+	_, err = svc.Submit(context.Background(), request())
+	require.NoError(t, err)
+
+	_, err = svc.Proof(context.Background(), &rpcapi.ProofRequest{RoundId: "0"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServeTransports(t *testing.T) {
+	svc := runService(t, testConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	rpcapi.RegisterPoetServiceServer(srv, svc)
+	go srv.Serve(grpcLis)
+	t.Cleanup(srv.Stop)
+
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	handler, err := svc.HTTPHandler(ctx)
+	require.NoError(t, err)
+	httpSrv := &http.Server{Handler: handler}
+	go httpSrv.Serve(httpLis)
+	t.Cleanup(func() { httpSrv.Close() })
+
+	registry := activation.NewPoetRegistry(zaptest.NewLogger(t))
+	for _, address := range []string{
+		"grpc://" + grpcLis.Addr().String(),
+		"http://" + httpLis.Addr().String(),
+		httpLis.Addr().String(),
+	} {
+		t.Run(address, func(t *testing.T) {
+			client, err := registry.NewClient(address, poetConfig())
+			require.NoError(t, err)
+			id, err := client.PoetServiceID(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []byte(svc.PublicKey()), id.ServiceID)
+
+			signer, err := signing.NewEdSigner()
+			require.NoError(t, err)
+			round, err := submit(t, client, signer, types.RandomHash())
+			require.NoError(t, err)
+			require.NotEmpty(t, round.ID)
+		})
+	}
+}
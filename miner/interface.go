@@ -12,6 +12,8 @@ import (
 
 type proposalOracle interface {
 	ProposalEligibility(types.LayerID, types.Beacon, types.VRFPostIndex) (*EpochEligibility, error)
+	// CalcEligibility computes eligibility without using the state of ProposalEligibility.
+	CalcEligibility(types.LayerID, types.Beacon, types.VRFPostIndex) (*EpochEligibility, error)
 }
 
 type conservativeState interface {
@@ -17,3 +17,19 @@ var ProposalBuildDuration = metrics.NewHistogramWithBuckets(
 	[]string{},
 	[]float64{10, 100, 1000, 5 * 1000, 10 * 1000, 60 * 1000, 10 * 60 * 1000, 60 * 60 * 1000},
 )
+
+// EligibilityOutcomes counts layers where smesher was eligible by the outcome.
+var EligibilityOutcomes = metrics.NewCounter(
+	"eligibility_outcomes",
+	subsystem,
+	"number of layers where smesher was eligible by the outcome",
+	[]string{"outcome"},
+)
+
+// ConsecutiveMissedLayers is the number of the latest layers where smesher was eligible, but not rewarded.
+var ConsecutiveMissedLayers = metrics.NewGauge(
+	"consecutive_missed_layers",
+	subsystem,
+	"number of the latest layers where smesher was eligible, but not rewarded",
+	[]string{},
+)
@@ -0,0 +1,309 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/datastore"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/miner/metrics"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/ballots"
+	"github.com/spacemeshos/go-spacemesh/sql/blocks"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/rewards"
+)
+
+// missedAlertThreshold is the number of consecutive missed layers that is logged as an error.
+const missedAlertThreshold = 3
+
+// layerAttempt is what ProposalBuilder did in the layer.
+type layerAttempt struct {
+	err       error
+	proposal  types.ProposalID
+	published time.Time
+	// publishErr is set if proposal was built, but failed to publish.
+	publishErr error
+}
+
+// missDetector reconciles eligibilities of the smesher with the ballots, blocks and rewards
+// once the layer is verified by tortoise and applied, and reports layers where smesher wasn't rewarded.
+//
+// Attempts are kept in memory, layers before the start of the detector are not reconciled.
+// Methods that record attempts are no-op on nil missDetector.
+type missDetector struct {
+	logger   log.Log
+	cdb      *datastore.CachedDB
+	clock    layerClock
+	tortoise votesEncoder
+	nodeID   types.NodeID
+	// lateAfter is the delay after the start of the layer when hare starts.
+	lateAfter time.Duration
+	// eligibility computes eligibility for the epoch of the layer if ProposalBuilder didn't.
+	eligibility func(types.LayerID) (*EpochEligibility, error)
+
+	// mu protects state shared with ProposalBuilder, it is not held while layers are reconciled.
+	mu       sync.Mutex
+	attempts map[types.LayerID]*layerAttempt
+	epochs   map[types.EpochID]*EpochEligibility
+	last     types.LayerID
+
+	// computed and consecutive are accessed only by reconcile.
+	computed    map[types.EpochID]*EpochEligibility
+	consecutive int
+}
+
+func (d *missDetector) attempt(lid types.LayerID) *layerAttempt {
+	if _, exist := d.attempts[lid]; !exist {
+		d.attempts[lid] = &layerAttempt{}
+	}
+	return d.attempts[lid]
+}
+
+// eligible records eligibility computed by ProposalBuilder.
+func (d *missDetector) eligible(ee *EpochEligibility) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.epochs[ee.Epoch] = ee
+}
+
+// handled records the result of ProposalBuilder.handleLayer.
+func (d *missDetector) handled(lid types.LayerID, err error) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attempt(lid).err = err
+}
+
+// published records the result of the proposal publication.
+func (d *missDetector) published(lid types.LayerID, proposal types.ProposalID, at time.Time, err error) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	attempt := d.attempt(lid)
+	attempt.proposal = proposal
+	attempt.published = at
+	attempt.publishErr = err
+}
+
+func (d *missDetector) run(ctx context.Context) {
+	d.mu.Lock()
+	d.last = d.clock.CurrentLayer()
+	d.mu.Unlock()
+	next := d.clock.CurrentLayer().Add(1)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.clock.AwaitLayer(next):
+			next = d.clock.CurrentLayer().Add(1)
+			if err := d.reconcile(ctx); err != nil {
+				d.logger.With().Warning("failed to reconcile eligibilities", log.Err(err))
+			}
+		}
+	}
+}
+
+// reconcile layers that are verified and applied since the previous call.
+func (d *missDetector) reconcile(ctx context.Context) error {
+	applied, err := layers.GetLastApplied(d.cdb)
+	if err != nil {
+		return err
+	}
+	upto := d.tortoise.LatestComplete()
+	if applied < upto {
+		upto = applied
+	}
+	// attempts and eligibilities are copied under the lock, and layers are reconciled without it
+	// so that ProposalBuilder is not blocked by database queries and eligibility computation.
+	d.mu.Lock()
+	from := d.last.Add(1)
+	attempts := map[types.LayerID]layerAttempt{}
+	for lid := from; lid <= upto; lid = lid.Add(1) {
+		if attempt, exist := d.attempts[lid]; exist {
+			attempts[lid] = *attempt
+		}
+	}
+	epochs := maps.Clone(d.epochs)
+	d.mu.Unlock()
+
+	for lid := from; lid <= upto; lid = lid.Add(1) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		var attempt *layerAttempt
+		if recorded, exist := attempts[lid]; exist {
+			attempt = &recorded
+		}
+		if err := d.reconcileLayer(lid, epochs, attempt); err != nil {
+			return fmt.Errorf("layer %d: %w", lid, err)
+		}
+		d.mu.Lock()
+		d.last = lid
+		delete(d.attempts, lid)
+		d.mu.Unlock()
+	}
+
+	d.mu.Lock()
+	last := d.last.GetEpoch()
+	for epoch := range d.epochs {
+		if epoch < last {
+			delete(d.epochs, epoch)
+		}
+	}
+	d.mu.Unlock()
+	for epoch := range d.computed {
+		if epoch < last {
+			delete(d.computed, epoch)
+		}
+	}
+	return nil
+}
+
+func (d *missDetector) reconcileLayer(
+	lid types.LayerID,
+	epochs map[types.EpochID]*EpochEligibility,
+	attempt *layerAttempt,
+) error {
+	if lid <= types.GetEffectiveGenesis() {
+		return nil
+	}
+	ee, exist := epochs[lid.GetEpoch()]
+	if !exist {
+		ee, exist = d.computed[lid.GetEpoch()]
+	}
+	if !exist {
+		var err error
+		ee, err = d.eligibility(lid)
+		if err != nil {
+			d.logger.With().Warning("eligibility is unknown, layer is not reconciled", lid, log.Err(err))
+			return nil
+		}
+		d.computed[lid.GetEpoch()] = ee
+	}
+	if ee == nil || len(ee.Proofs[lid]) == 0 {
+		return nil
+	}
+	ev := events.EventEligibility{
+		Layer:         lid,
+		Smesher:       d.nodeID,
+		Atx:           ee.Atx,
+		Eligibilities: uint32(len(ee.Proofs[lid])),
+	}
+	if err := d.classify(&ev, attempt); err != nil {
+		return err
+	}
+	if hdr, err := d.cdb.GetAtxHeader(ee.Atx); err == nil {
+		received, err := rewards.ListRange(d.cdb, hdr.Coinbase, lid, lid)
+		if err != nil {
+			return err
+		}
+		for _, reward := range received {
+			ev.Reward += reward.TotalReward
+		}
+	}
+	if ev.Missed() {
+		d.consecutive++
+	} else {
+		d.consecutive = 0
+	}
+	ev.ConsecutiveMissed = d.consecutive
+	metrics.EligibilityOutcomes.WithLabelValues(ev.Outcome).Inc()
+	metrics.ConsecutiveMissedLayers.WithLabelValues().Set(float64(d.consecutive))
+	events.ReportEligibility(ev)
+
+	fields := []log.LoggableField{
+		lid,
+		ev.Proposal,
+		log.Uint32("eligibilities", ev.Eligibilities),
+		log.String("outcome", ev.Outcome),
+		log.String("details", ev.Details),
+		log.Int("consecutive_missed", ev.ConsecutiveMissed),
+	}
+	switch {
+	case !ev.Missed():
+		d.logger.With().Debug("smesher rewarded in layer", fields...)
+	case d.consecutive >= missedAlertThreshold:
+		d.logger.With().Error("smesher missed rewards in consecutive layers", fields...)
+	default:
+		d.logger.With().Warning("smesher missed rewards in layer", fields...)
+	}
+	return nil
+}
+
+func (d *missDetector) classify(ev *events.EventEligibility, attempt *layerAttempt) error {
+	switch {
+	case attempt == nil:
+		ev.Outcome = events.EligibilityNotBuilt
+		ev.Details = "layer wasn't handled"
+		return nil
+	case errors.Is(attempt.err, errNotSynced):
+		ev.Outcome = events.EligibilityNotSynced
+		return nil
+	case attempt.err != nil:
+		ev.Outcome = events.EligibilityNotBuilt
+		ev.Details = attempt.err.Error()
+		return nil
+	case attempt.proposal == types.EmptyProposalID:
+		ev.Outcome = events.EligibilityNotBuilt
+		ev.Details = "proposal wasn't published"
+		return nil
+	}
+	ev.Proposal = attempt.proposal
+	if attempt.publishErr != nil {
+		ev.Outcome = events.EligibilityRejected
+		ev.Details = attempt.publishErr.Error()
+		return nil
+	}
+	ballot, err := ballots.LayerBallotByNodeID(d.cdb, ev.Layer, d.nodeID)
+	switch {
+	case errors.Is(err, sql.ErrNotFound):
+		ev.Outcome = events.EligibilityRejected
+		ev.Details = "ballot is not stored"
+		return nil
+	case err != nil:
+		return err
+	case ballot.IsMalicious():
+		ev.Outcome = events.EligibilityRejected
+		ev.Details = "smesher is malicious"
+		return nil
+	}
+
+	id, err := layers.GetApplied(d.cdb, ev.Layer)
+	if err != nil {
+		return err
+	}
+	ev.Outcome = events.EligibilityNotInBlock
+	if id == types.EmptyBlockID {
+		ev.Details = "layer is empty"
+	} else {
+		block, err := blocks.Get(d.cdb, id)
+		if err != nil {
+			return err
+		}
+		for _, reward := range block.Rewards {
+			if reward.AtxID == ev.Atx {
+				ev.Outcome = events.EligibilityRewarded
+				return nil
+			}
+		}
+	}
+	if late := attempt.published.Sub(d.clock.LayerToTime(ev.Layer)); late > d.lateAfter {
+		ev.Outcome = events.EligibilityLate
+		ev.Details = fmt.Sprintf("published %v after the start of the layer", late.Round(time.Millisecond))
+	}
+	return nil
+}
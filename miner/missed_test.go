@@ -0,0 +1,143 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
+	"github.com/spacemeshos/go-spacemesh/sql/ballots"
+	"github.com/spacemeshos/go-spacemesh/sql/blocks"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+)
+
+func TestMissDetector(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub, err := events.Subscribe[events.EventEligibility]()
+	require.NoError(t, err)
+
+	const networkDelay = 10 * time.Second
+	b := createBuilder(t, WithMissDetection(), WithNetworkDelay(networkDelay))
+	genesis := time.Now()
+	b.mClock.EXPECT().LayerToTime(gomock.Any()).DoAndReturn(func(lid types.LayerID) time.Time {
+		return genesis.Add(time.Duration(lid) * time.Minute)
+	}).AnyTimes()
+	d := b.detector
+	d.last = types.LayerID(8)
+
+	atx := types.RandomATXID()
+	proofs := func(lids ...types.LayerID) map[types.LayerID][]types.VotingEligibility {
+		rst := map[types.LayerID][]types.VotingEligibility{}
+		for _, lid := range lids {
+			rst[lid] = genProofs(t, 1)
+		}
+		return rst
+	}
+	// eligibility for the epoch 3 is computed by the builder, for the epoch 4 by the detector
+	d.eligible(&EpochEligibility{Epoch: 3, Atx: atx, Proofs: proofs(9, 10, 11)})
+	beacon := types.RandomBeacon()
+	nonce := types.VRFPostIndex(1)
+	b.mNonce.EXPECT().VRFNonce(b.signer.NodeID(), types.EpochID(4)).Return(nonce, nil)
+	b.mBeacon.EXPECT().GetBeacon(types.EpochID(4)).Return(beacon, nil)
+	// eligibility cached for building proposals is not replaced
+	b.mOracle.EXPECT().CalcEligibility(types.LayerID(12), beacon, nonce).
+		Return(&EpochEligibility{Epoch: 4, Atx: atx, Proofs: proofs(12, 13, 14)}, nil)
+
+	publish := func(lid types.LayerID, delay time.Duration, stored bool) {
+		d.handled(lid, nil)
+		d.published(lid, types.ProposalID{byte(lid)}, b.mClock.LayerToTime(lid).Add(delay), nil)
+		if stored {
+			ballot := types.NewExistingBallot(types.BallotID{byte(lid)}, types.EmptyEdSignature, b.signer.NodeID(), lid)
+			require.NoError(t, ballots.Add(b.cdb, &ballot))
+		}
+	}
+	apply := func(lid types.LayerID, rewarded ...types.ATXID) {
+		block := types.NewExistingBlock(types.BlockID{byte(lid)}, types.InnerBlock{LayerIndex: lid})
+		for _, id := range rewarded {
+			block.Rewards = append(block.Rewards, types.AnyReward{AtxID: id})
+		}
+		require.NoError(t, blocks.Add(b.cdb, block))
+		require.NoError(t, layers.SetApplied(b.cdb, lid, block.ID()))
+	}
+	publish(9, time.Second, true)
+	apply(9, atx)
+	publish(10, 2*networkDelay, true)
+	apply(10, types.RandomATXID())
+	publish(11, time.Second, true)
+	require.NoError(t, layers.SetApplied(b.cdb, 11, types.EmptyBlockID))
+	d.handled(12, errNotSynced)
+	apply(12)
+	publish(13, time.Second, false)
+	apply(13)
+	apply(14)
+
+	b.mTortoise.EXPECT().LatestComplete().Return(types.LayerID(13))
+	require.NoError(t, d.reconcile(context.Background()))
+	require.Equal(t, types.LayerID(13), d.last)
+	b.mTortoise.EXPECT().LatestComplete().Return(types.LayerID(20))
+	require.NoError(t, d.reconcile(context.Background()))
+	require.Equal(t, types.LayerID(14), d.last)
+	require.Empty(t, d.attempts)
+
+	for i, expected := range []struct {
+		lid      types.LayerID
+		outcome  string
+		proposal bool
+	}{
+		{9, events.EligibilityRewarded, true},
+		{10, events.EligibilityLate, true},
+		{11, events.EligibilityNotInBlock, true},
+		{12, events.EligibilityNotSynced, false},
+		{13, events.EligibilityRejected, true},
+		{14, events.EligibilityNotBuilt, false},
+	} {
+		select {
+		case ev := <-sub.Out():
+			require.Equal(t, expected.lid, ev.Layer)
+			require.Equal(t, expected.outcome, ev.Outcome, ev.Details)
+			require.Equal(t, atx, ev.Atx)
+			require.Equal(t, uint32(1), ev.Eligibilities)
+			require.Equal(t, i, ev.ConsecutiveMissed)
+			if expected.proposal {
+				require.Equal(t, types.ProposalID{byte(expected.lid)}, ev.Proposal)
+			} else {
+				require.Equal(t, types.EmptyProposalID, ev.Proposal)
+			}
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for event", "layer %d", expected.lid)
+		}
+	}
+	select {
+	case ev := <-sub.Out():
+		require.FailNow(t, "unexpected event", "layer %d", ev.Layer)
+	default:
+	}
+
+	t.Run("not locked while reconciling", func(t *testing.T) {
+		b := createBuilder(t, WithMissDetection())
+		d := b.detector
+		d.last = types.LayerID(20)
+		d.eligibility = func(lid types.LayerID) (*EpochEligibility, error) {
+			// builder records attempts while eligibility is computed
+			d.handled(lid.Add(1), nil)
+			return nil, nil
+		}
+		require.NoError(t, layers.SetApplied(b.cdb, 21, types.EmptyBlockID))
+		b.mTortoise.EXPECT().LatestComplete().Return(types.LayerID(21))
+		require.NoError(t, d.reconcile(context.Background()))
+		require.Equal(t, types.LayerID(21), d.last)
+		require.Contains(t, d.attempts, types.LayerID(22))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var d *missDetector
+		d.eligible(&EpochEligibility{})
+		d.handled(1, errors.New("test"))
+		d.published(1, types.ProposalID{1}, time.Now(), nil)
+	})
+}
@@ -37,6 +37,45 @@ func (m *MockproposalOracle) EXPECT() *MockproposalOracleMockRecorder {
 	return m.recorder
 }
 
+// CalcEligibility mocks base method.
+func (m *MockproposalOracle) CalcEligibility(arg0 types.LayerID, arg1 types.Beacon, arg2 types.VRFPostIndex) (*EpochEligibility, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalcEligibility", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*EpochEligibility)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalcEligibility indicates an expected call of CalcEligibility.
+func (mr *MockproposalOracleMockRecorder) CalcEligibility(arg0, arg1, arg2 interface{}) *proposalOracleCalcEligibilityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalcEligibility", reflect.TypeOf((*MockproposalOracle)(nil).CalcEligibility), arg0, arg1, arg2)
+	return &proposalOracleCalcEligibilityCall{Call: call}
+}
+
+// proposalOracleCalcEligibilityCall wrap *gomock.Call
+type proposalOracleCalcEligibilityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *proposalOracleCalcEligibilityCall) Return(arg0 *EpochEligibility, arg1 error) *proposalOracleCalcEligibilityCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *proposalOracleCalcEligibilityCall) Do(f func(types.LayerID, types.Beacon, types.VRFPostIndex) (*EpochEligibility, error)) *proposalOracleCalcEligibilityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *proposalOracleCalcEligibilityCall) DoAndReturn(f func(types.LayerID, types.Beacon, types.VRFPostIndex) (*EpochEligibility, error)) *proposalOracleCalcEligibilityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ProposalEligibility mocks base method.
 func (m *MockproposalOracle) ProposalEligibility(arg0 types.LayerID, arg1 types.Beacon, arg2 types.VRFPostIndex) (*EpochEligibility, error) {
 	m.ctrl.T.Helper()
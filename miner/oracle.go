@@ -97,6 +97,13 @@ func (o *Oracle) ProposalEligibility(lid types.LayerID, beacon types.Beacon, non
 	return ee, nil
 }
 
+// CalcEligibility computes eligibility for the epoch of the layer. Unlike ProposalEligibility
+// it doesn't use or replace the cached eligibility and doesn't emit events, therefore it is safe
+// to use for the past epochs while the miner builds proposals.
+func (o *Oracle) CalcEligibility(lid types.LayerID, beacon types.Beacon, nonce types.VRFPostIndex) (*EpochEligibility, error) {
+	return o.calcEligibilityProofs(lid, lid.GetEpoch(), beacon, nonce)
+}
+
 func (o *Oracle) activesFromFirstBlock(targetEpoch types.EpochID, ownAtx types.ATXID, ownWeight uint64) (uint64, []types.ATXID, error) {
 	if ownAtx == types.EmptyATXID || ownWeight == 0 {
 		o.log.Fatal("invalid miner atx")
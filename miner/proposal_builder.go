@@ -58,6 +58,7 @@ type ProposalBuilder struct {
 	proposalOracle proposalOracle
 	beaconProvider system.BeaconGetter
 	syncer         system.SyncStateProvider
+	detector       *missDetector
 }
 
 // config defines configuration for the ProposalBuilder.
@@ -72,6 +73,8 @@ type config struct {
 
 	// used to determine whether a node has enough information on the active set this epoch
 	goodAtxPct int
+	// detectMisses enables reconciliation of eligibilities with blocks and rewards.
+	detectMisses bool
 }
 
 func (c *config) MarshalLogObject(encoder log.ObjectEncoder) error {
@@ -82,6 +85,7 @@ func (c *config) MarshalLogObject(encoder log.ObjectEncoder) error {
 	encoder.AddUint32("emit empty set", c.emitEmptyActiveSet.Uint32())
 	encoder.AddDuration("network delay", c.networkDelay)
 	encoder.AddInt("good atx percent", c.goodAtxPct)
+	encoder.AddBool("detect misses", c.detectMisses)
 	return nil
 }
 
@@ -158,6 +162,13 @@ func WithEmitEmptyActiveSet(lid types.LayerID) Opt {
 	}
 }
 
+// WithMissDetection enables reporting of layers where smesher was eligible, but wasn't rewarded.
+func WithMissDetection() Opt {
+	return func(pb *ProposalBuilder) {
+		pb.cfg.detectMisses = true
+	}
+}
+
 func withOracle(o proposalOracle) Opt {
 	return func(pb *ProposalBuilder) {
 		pb.proposalOracle = o
@@ -207,6 +218,20 @@ func NewProposalBuilder(
 	if pb.nonceFetcher == nil {
 		pb.nonceFetcher = defaultFetcher{pb.cdb}
 	}
+	if pb.cfg.detectMisses {
+		pb.detector = &missDetector{
+			logger:      pb.logger,
+			cdb:         pb.cdb,
+			clock:       pb.clock,
+			tortoise:    pb.tortoise,
+			nodeID:      pb.signer.NodeID(),
+			lateAfter:   pb.cfg.networkDelay,
+			eligibility: pb.epochEligibility,
+			attempts:    map[types.LayerID]*layerAttempt{},
+			epochs:      map[types.EpochID]*EpochEligibility{},
+			computed:    map[types.EpochID]*EpochEligibility{},
+		}
+	}
 	return pb
 }
 
@@ -217,6 +242,12 @@ func (pb *ProposalBuilder) Start(ctx context.Context) error {
 			pb.createProposalLoop(log.WithNewSessionID(ctx))
 			return nil
 		})
+		if pb.detector != nil {
+			pb.eg.Go(func() error {
+				pb.detector.run(pb.ctx)
+				return nil
+			})
+		}
 	})
 	return nil
 }
@@ -237,6 +268,26 @@ func (pb *ProposalBuilder) stopped() bool {
 	}
 }
 
+// epochEligibility computes eligibility for the epoch of the layer, it is nil if smesher has no atx for the epoch.
+// Eligibility cached for building proposals is not changed.
+func (pb *ProposalBuilder) epochEligibility(lid types.LayerID) (*EpochEligibility, error) {
+	nonce, err := pb.nonceFetcher.VRFNonce(pb.signer.NodeID(), lid.GetEpoch())
+	if errors.Is(err, sql.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	beacon, err := pb.beaconProvider.GetBeacon(lid.GetEpoch())
+	if err != nil {
+		return nil, errNoBeacon
+	}
+	ee, err := pb.proposalOracle.CalcEligibility(lid, beacon, nonce)
+	if errors.Is(err, errMinerHasNoATXInPreviousEpoch) {
+		return nil, nil
+	}
+	return ee, err
+}
+
 func (pb *ProposalBuilder) createProposal(
 	ctx context.Context,
 	layerID types.LayerID,
@@ -429,6 +480,7 @@ func (pb *ProposalBuilder) handleLayer(ctx context.Context, layerID types.LayerI
 		}
 		return fmt.Errorf("proposal eligibility: %w", err)
 	}
+	pb.detector.eligible(epochEligibility)
 	proofs := epochEligibility.Proofs[layerID]
 	if len(proofs) == 0 {
 		pb.logger.WithContext(ctx).With().Debug("not eligible for proposal in layer", layerID)
@@ -473,7 +525,9 @@ func (pb *ProposalBuilder) handleLayer(ctx context.Context, layerID types.LayerI
 		if err != nil {
 			pb.logger.WithContext(newCtx).With().Fatal("failed to serialize proposal", log.Err(err))
 		}
-		if err = pb.publisher.Publish(newCtx, pubsub.ProposalProtocol, data); err != nil {
+		err = pb.publisher.Publish(newCtx, pubsub.ProposalProtocol, data)
+		pb.detector.published(layerID, p.ID(), time.Now(), err)
+		if err != nil {
 			pb.logger.WithContext(newCtx).With().Error("failed to send proposal", log.Err(err))
 		} else {
 			events.EmitProposal(layerID, p.ID())
@@ -499,7 +553,9 @@ func (pb *ProposalBuilder) createProposalLoop(ctx context.Context) {
 			}
 			next = current.Add(1)
 			lyrCtx := log.WithNewSessionID(ctx)
-			if err := pb.handleLayer(lyrCtx, current); err != nil {
+			err := pb.handleLayer(lyrCtx, current)
+			pb.detector.handled(current, err)
+			if err != nil {
 				switch {
 				case errors.Is(err, errGenesis), errors.Is(err, errNotSynced):
 				default:
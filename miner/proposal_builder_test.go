@@ -46,7 +46,7 @@ type testBuilder struct {
 	mNonce    *MocknonceFetcher
 }
 
-func createBuilder(tb testing.TB) *testBuilder {
+func createBuilder(tb testing.TB, opts ...Opt) *testBuilder {
 	types.SetLayersPerEpoch(layersPerEpoch)
 	edSigner, vrfSigner := genSigners(tb)
 	ctrl := gomock.NewController(tb)
@@ -64,13 +64,15 @@ func createBuilder(tb testing.TB) *testBuilder {
 	cdb := datastore.NewCachedDB(sql.InMemory(), lg)
 	pb.ProposalBuilder = NewProposalBuilder(context.Background(), pb.mClock, edSigner, vrfSigner,
 		cdb, pb.mPubSub, pb.mTortoise, pb.mBeacon, pb.mSync, pb.mCState,
-		WithLogger(lg),
-		WithLayerSize(20),
-		WithLayerPerEpoch(3),
-		WithNodeID(edSigner.NodeID()),
-		WithHdist(3),
-		withOracle(pb.mOracle),
-		withNonceFetcher(pb.mNonce),
+		append([]Opt{
+			WithLogger(lg),
+			WithLayerSize(20),
+			WithLayerPerEpoch(3),
+			WithNodeID(edSigner.NodeID()),
+			WithHdist(3),
+			withOracle(pb.mOracle),
+			withNonceFetcher(pb.mNonce),
+		}, opts...)...,
 	)
 	return pb
 }
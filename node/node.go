@@ -24,6 +24,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 	poetconfig "github.com/spacemeshos/poet/config"
 	"github.com/spacemeshos/poet/server"
+	"github.com/spacemeshos/post/proving"
 	"github.com/spacemeshos/post/verifying"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -54,6 +55,7 @@ import (
 	"github.com/spacemeshos/go-spacemesh/hare3/compat"
 	"github.com/spacemeshos/go-spacemesh/hash"
 	"github.com/spacemeshos/go-spacemesh/layerpatrol"
+	"github.com/spacemeshos/go-spacemesh/localpoet"
 	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/malfeasance"
 	"github.com/spacemeshos/go-spacemesh/mesh"
@@ -312,6 +314,22 @@ func New(opts ...Option) *App {
 	return app
 }
 
+// postSetupProvider is the post setup and proving used by the atx builder, it is implemented by
+// activation.PostSetupManager for the local post data and by grpcserver.PostClient for the remote one.
+type postSetupProvider interface {
+	Status() *activation.PostSetupStatus
+	Providers() ([]activation.PostSetupProvider, error)
+	Benchmark(p activation.PostSetupProvider) (int, error)
+	PrepareInitializer(ctx context.Context, opts activation.PostSetupOpts) error
+	StartSession(context context.Context) error
+	Reset() error
+	GenerateProof(ctx context.Context, challenge []byte, options ...proving.OptionFunc) (*types.Post, *types.PostMetadata, error)
+	CommitmentAtx() (types.ATXID, error)
+	VRFNonce() (*types.VRFPostIndex, error)
+	LastOpts() *activation.PostSetupOpts
+	Config() activation.PostConfig
+}
+
 // App is the cli app singleton.
 type App struct {
 	*cobra.Command
@@ -336,7 +354,11 @@ type App struct {
 	hOracle            *eligibility.Oracle
 	blockGen           *blocks.Generator
 	certifier          *blocks.Certifier
-	postSetupMgr       *activation.PostSetupManager
+	postSetupMgr       postSetupProvider
+	postClient         *grpcserver.PostClient
+	poetRegistry       *activation.PoetRegistry
+	poetHealth         *activation.PoetHealth
+	postChecker        *activation.PostChecker
 	atxBuilder         *activation.Builder
 	atxHandler         *activation.Handler
 	txHandler          *txs.TxHandler
@@ -562,17 +584,40 @@ func (app *App) initServices(ctx context.Context) error {
 	for i := 0; i < app.Config.SMESHING.VerifyingOpts.Workers; i++ {
 		postVerifiers = append(postVerifiers, verifier)
 	}
-	app.postVerifier = activation.NewOffloadingPostVerifier(postVerifiers, nipostValidatorLogger)
+	var remoteVerifiers []activation.RemotePostVerifier
+	for _, address := range app.Config.SMESHING.RemoteVerifiers.Addresses {
+		client, err := grpcserver.NewPostVerifierClient(address, app.Config.SMESHING.RemoteVerifiers, app.Config.POST)
+		if err != nil {
+			for _, created := range remoteVerifiers {
+				created.Close()
+			}
+			return err
+		}
+		remoteVerifiers = append(remoteVerifiers, client)
+	}
+	app.postVerifier = activation.NewOffloadingPostVerifier(postVerifiers, nipostValidatorLogger,
+		activation.WithRemotePostVerifiers(
+			app.Config.SMESHING.RemoteVerifiers.Concurrency,
+			app.Config.SMESHING.RemoteVerifiers.HealthInterval,
+			remoteVerifiers...,
+		),
+	)
 
 	validator := activation.NewValidator(poetDb, app.Config.POST, nipostValidatorLogger, app.postVerifier)
 	app.validator = validator
 
-	cfg := vm.DefaultConfig()
+	cfg := app.Config.VM
 	cfg.GasLimit = app.Config.BlockGasLimit
 	cfg.GenesisID = app.Config.Genesis.GenesisID()
+	if err := cfg.Validate(app.Config.Tortoise.Hdist); err != nil {
+		return err
+	}
 	state := vm.New(app.db,
 		vm.WithConfig(cfg),
 		vm.WithLogger(app.addLogger(VMLogger, lg)))
+	app.eg.Go(func() error {
+		return state.Prune(ctx)
+	})
 	app.conState = txs.NewConservativeState(state, app.db,
 		txs.WithCSConfig(txs.CSConfig{
 			BlockGasLimit:     app.Config.BlockGasLimit,
@@ -660,6 +705,7 @@ func (app *App) initServices(ctx context.Context) error {
 		trtl,
 		app.addLogger(ATXHandlerLogger, lg),
 		app.Config.POET,
+		activation.WithVerifiedPosts(app.Config.POST, app.Config.SMESHING.VerifyingOpts.Reverify),
 	)
 
 	// we can't have an epoch offset which is greater/equal than the number of layers in an epoch
@@ -813,20 +859,49 @@ func (app *App) initServices(ctx context.Context) error {
 		miner.WithHdist(app.Config.Tortoise.Hdist),
 		miner.WithNetworkDelay(app.Config.HARE.WakeupDelta),
 		miner.WithMinGoodAtxPct(minerGoodAtxPct),
+		miner.WithMissDetection(),
 		miner.WithLogger(app.addLogger(ProposalBuilderLogger, lg)),
 	)
 
-	postSetupMgr, err := activation.NewPostSetupManager(
-		app.edSgn.NodeID(),
-		app.Config.POST,
-		app.addLogger(PostLogger, lg),
-		app.cachedDB, goldenATXID,
-		app.Config.SMESHING.ProvingOpts,
-	)
-	if err != nil {
-		app.log.Panic("failed to create post setup manager: %v", err)
+	var postSetupMgr postSetupProvider
+	postDataLock := activation.NewPostDataLock()
+	if app.Config.SMESHING.RemotePost.Address != "" {
+		client, err := grpcserver.NewPostClient(
+			app.edSgn.NodeID(),
+			app.Config.POST,
+			app.Config.SMESHING.RemotePost,
+			app.addLogger(PostLogger, lg),
+		)
+		if err != nil {
+			return fmt.Errorf("create remote post client: %w", err)
+		}
+		app.postClient = client
+		postSetupMgr = client
+	} else {
+		postSetupMgr, err = activation.NewPostSetupManager(
+			app.edSgn.NodeID(),
+			app.Config.POST,
+			app.addLogger(PostLogger, lg),
+			app.cachedDB, goldenATXID,
+			app.Config.SMESHING.ProvingOpts,
+			activation.WithPostSetupDataLock(postDataLock),
+		)
+		if err != nil {
+			app.log.Panic("failed to create post setup manager: %v", err)
+		}
 	}
 
+	app.postChecker = activation.NewPostChecker(
+		app.addLogger(PostLogger, lg),
+		activation.WithPostCheckDataLock(postDataLock),
+	)
+	app.poetRegistry = activation.NewPoetRegistry(app.addLogger(NipostBuilderLogger, lg).Zap().Named("poet"))
+	app.poetHealth = activation.NewPoetHealth(
+		app.db,
+		app.Config.TickSize,
+		app.Config.POET.MaxMissedProofs,
+		app.addLogger(NipostBuilderLogger, lg),
+	)
 	nipostBuilder, err := activation.NewNIPostBuilder(
 		app.edSgn.NodeID(),
 		postSetupMgr,
@@ -838,6 +913,8 @@ func (app *App) initServices(ctx context.Context) error {
 		app.Config.POET,
 		app.clock,
 		activation.WithNipostValidator(app.validator),
+		activation.WithNipostPoetRegistry(app.poetRegistry),
+		activation.WithPoetHealth(app.poetHealth),
 	)
 	if err != nil {
 		app.log.Panic("failed to create nipost builder: %v", err)
@@ -859,6 +936,24 @@ func (app *App) initServices(ctx context.Context) error {
 		GoldenATXID:     goldenATXID,
 		LayersPerEpoch:  layersPerEpoch,
 	}
+	if !app.Config.TIME.Peersync.Disable {
+		app.ptimesync = peersync.New(
+			app.host,
+			app.host,
+			peersync.WithLog(app.addLogger(TimeSyncLogger, lg)),
+			peersync.WithConfig(app.Config.TIME.Peersync),
+		)
+	}
+	builderOpts := []activation.BuilderOption{
+		activation.WithContext(ctx),
+		activation.WithPoetConfig(app.Config.POET),
+		activation.WithPoetRetryInterval(app.Config.HARE.WakeupDelta),
+		activation.WithValidator(app.validator),
+		activation.WithPoetRegistry(app.poetRegistry),
+	}
+	if app.ptimesync != nil {
+		builderOpts = append(builderOpts, activation.WithClockOffset(app.ptimesync, app.Config.TIME.Peersync.MaxClockOffset))
+	}
 	atxBuilder := activation.NewBuilder(
 		builderConfig,
 		app.edSgn.NodeID(),
@@ -871,10 +966,7 @@ func (app *App) initServices(ctx context.Context) error {
 		app.clock,
 		newSyncer,
 		app.addLogger("atxBuilder", lg),
-		activation.WithContext(ctx),
-		activation.WithPoetConfig(app.Config.POET),
-		activation.WithPoetRetryInterval(app.Config.HARE.WakeupDelta),
-		activation.WithValidator(app.validator),
+		builderOpts...,
 	)
 
 	malfeasanceHandler := malfeasance.NewHandler(
@@ -934,14 +1026,6 @@ func (app *App) initServices(ctx context.Context) error {
 	app.fetcher = fetcher
 	app.beaconProtocol = beaconProtocol
 	app.tortoise = trtl
-	if !app.Config.TIME.Peersync.Disable {
-		app.ptimesync = peersync.New(
-			app.host,
-			app.host,
-			peersync.WithLog(app.addLogger(TimeSyncLogger, lg)),
-			peersync.WithConfig(app.Config.TIME.Peersync),
-		)
-	}
 	if err := app.host.Start(); err != nil {
 		return err
 	}
@@ -966,13 +1050,15 @@ func (app *App) launchStandalone(ctx context.Context) error {
 	if err := app.beaconProtocol.UpdateBeacon(epoch, value); err != nil {
 		return fmt.Errorf("update standalone beacon: %w", err)
 	}
-	cfg := poetconfig.DefaultConfig()
-	cfg.PoetDir = filepath.Join(app.Config.DataDir(), "poet")
-
 	parsed, err := url.Parse(app.Config.PoETServers[0])
 	if err != nil {
 		return err
 	}
+	if parsed.Scheme == "local" {
+		return app.launchLocalPoet(ctx, parsed.Host)
+	}
+	cfg := poetconfig.DefaultConfig()
+	cfg.PoetDir = filepath.Join(app.Config.DataDir(), "poet")
 	cfg.RawRESTListener = parsed.Host
 	cfg.Service.Genesis.UnmarshalFlag(app.Config.Genesis.GenesisTime)
 	cfg.Service.EpochDuration = app.Config.LayerDuration * time.Duration(app.Config.LayersPerEpoch)
@@ -999,6 +1085,30 @@ func (app *App) launchStandalone(ctx context.Context) error {
 	return nil
 }
 
+// launchLocalPoet runs poet stand-in in the node process, it is reached by clients with local://<name>.
+func (app *App) launchLocalPoet(ctx context.Context, name string) error {
+	genesis, err := time.Parse(time.RFC3339, app.Config.Genesis.GenesisTime)
+	if err != nil {
+		return fmt.Errorf("parse genesis time: %w", err)
+	}
+	cfg := localpoet.DefaultConfig()
+	cfg.Genesis = genesis
+	cfg.EpochDuration = app.Config.LayerDuration * time.Duration(app.Config.LayersPerEpoch)
+	cfg.PhaseShift = app.Config.POET.PhaseShift
+	cfg.CycleGap = app.Config.POET.CycleGap
+	cfg.DataDir = filepath.Join(app.Config.DataDir(), "poet")
+	svc, err := localpoet.New(cfg, localpoet.WithLogger(app.log.Zap().Named("poet")))
+	if err != nil {
+		return fmt.Errorf("init local poet: %w", err)
+	}
+	app.poetRegistry.RegisterLocal(name, svc)
+	app.log.With().Warning("launching local poet in standalone mode", log.String("name", name))
+	app.eg.Go(func() error {
+		return svc.Run(ctx)
+	})
+	return nil
+}
+
 func (app *App) listenToUpdates(ctx context.Context) {
 	app.eg.Go(func() error {
 		ch, err := app.updater.Subscribe()
@@ -1031,6 +1141,11 @@ func (app *App) listenToUpdates(ctx context.Context) {
 }
 
 func (app *App) startServices(ctx context.Context) error {
+	persister := events.NewPersister(app.db, app.clock, app.Config.SMESHING.EventsRetention, app.log.WithName("events"))
+	app.eg.Go(func() error {
+		return persister.Run(ctx)
+	})
+
 	if err := app.fetcher.Start(); err != nil {
 		return fmt.Errorf("failed to start fetcher: %w", err)
 	}
@@ -1081,11 +1196,24 @@ func (app *App) initService(ctx context.Context, svc grpcserver.Service) (grpcse
 	case grpcserver.Admin:
 		return grpcserver.NewAdminService(app.db, app.Config.DataDir(), app.host), nil
 	case grpcserver.Smesher:
-		return grpcserver.NewSmesherService(app.postSetupMgr, app.atxBuilder, app.Config.API.SmesherStreamInterval, app.Config.SMESHING.Opts), nil
+		return grpcserver.NewSmesherService(
+			app.postSetupMgr,
+			app.atxBuilder,
+			app.poetHealth,
+			app.postChecker,
+			app.Config.API.SmesherStreamInterval,
+			app.Config.SMESHING.Opts,
+		), nil
 	case grpcserver.Transaction:
 		return grpcserver.NewTransactionService(app.db, app.host, app.mesh, app.conState, app.syncer, app.txHandler), nil
 	case grpcserver.Activation:
 		return grpcserver.NewActivationService(app.cachedDB, types.ATXID(app.Config.Genesis.GoldenATX())), nil
+	case grpcserver.Beacon:
+		return grpcserver.NewBeaconService(app.db), nil
+	case grpcserver.Mempool:
+		return grpcserver.NewMempoolService(app.conState), nil
+	case grpcserver.Fee:
+		return grpcserver.NewFeeService(app.conState), nil
 	}
 	return nil, fmt.Errorf("unknown service %s", svc)
 }
@@ -1119,13 +1247,16 @@ func (app *App) startAPIServices(ctx context.Context) error {
 	if len(app.Config.API.PublicServices) > 0 {
 		app.grpcPublicService = app.newGrpc(logger, app.Config.API.PublicListener)
 	}
-	if len(app.Config.API.PrivateServices) > 0 {
+	if len(app.Config.API.PrivateServices) > 0 || len(app.Config.API.ExperimentalServices) > 0 {
 		app.grpcPrivateService = app.newGrpc(logger, app.Config.API.PrivateListener)
 	}
 	for _, svc := range app.Config.API.PublicServices {
 		if _, exists := unique[svc]; exists {
 			return fmt.Errorf("can't start more than one %s", svc)
 		}
+		if grpcserver.IsExperimental(svc) {
+			return fmt.Errorf("%s is experimental, it can be enabled with grpc-experimental-services", svc)
+		}
 		gsvc, err := app.initService(ctx, svc)
 		if err != nil {
 			return err
@@ -1139,6 +1270,9 @@ func (app *App) startAPIServices(ctx context.Context) error {
 		if _, exists := unique[svc]; exists {
 			return fmt.Errorf("can't start more than one %s", svc)
 		}
+		if grpcserver.IsExperimental(svc) {
+			return fmt.Errorf("%s is experimental, it can be enabled with grpc-experimental-services", svc)
+		}
 		gsvc, err := app.initService(ctx, svc)
 		if err != nil {
 			return err
@@ -1147,6 +1281,27 @@ func (app *App) startAPIServices(ctx context.Context) error {
 		gsvc.RegisterService(app.grpcPrivateService)
 		unique[svc] = struct{}{}
 	}
+	experimental := map[grpcserver.Service]struct{}{}
+	for _, svc := range app.Config.API.ExperimentalServices {
+		if _, exists := experimental[svc]; exists {
+			return fmt.Errorf("can't start more than one experimental %s", svc)
+		}
+		gsvc, err := app.initService(ctx, svc)
+		if err != nil {
+			return err
+		}
+		logger.Info("registering experimental service %s", svc)
+		switch gsvc := gsvc.(type) {
+		case grpcserver.ExperimentalServiceAPI:
+			gsvc.RegisterExperimentalService(app.grpcPrivateService)
+		default:
+			if !grpcserver.IsExperimental(svc) {
+				return fmt.Errorf("%s has no experimental methods", svc)
+			}
+			gsvc.RegisterService(app.grpcPrivateService)
+		}
+		experimental[svc] = struct{}{}
+	}
 	if len(app.Config.API.JSONListener) > 0 {
 		if len(public) == 0 {
 			return fmt.Errorf("can't start json server without public services")
@@ -1206,10 +1361,18 @@ func (app *App) stopServices(ctx context.Context) {
 		_ = app.atxBuilder.StopSmeshing(false)
 	}
 
+	if app.postClient != nil {
+		app.postClient.Close()
+	}
+
 	if app.postVerifier != nil {
 		app.postVerifier.Close()
 	}
 
+	if app.postChecker != nil {
+		app.postChecker.Stop()
+	}
+
 	if app.hare != nil {
 		app.hare.Close()
 	}
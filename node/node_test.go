@@ -337,6 +337,35 @@ func TestSpacemeshApp_GrpcService(t *testing.T) {
 	r.Equal(message, response.Msg.Value)
 }
 
+func TestSpacemeshApp_ExperimentalServices(t *testing.T) {
+	for _, tc := range []struct {
+		desc                 string
+		public, experimental []grpcserver.Service
+		err                  string
+	}{
+		{desc: "experimental", experimental: []grpcserver.Service{grpcserver.Fee, grpcserver.Smesher}},
+		{desc: "public", public: []grpcserver.Service{grpcserver.Fee}, err: "fee is experimental"},
+		{desc: "no experimental methods", experimental: []grpcserver.Service{grpcserver.Node}, err: "node has no experimental methods"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			app := New(WithLog(logtest.New(t)))
+			app.Config.API = grpcserver.DefaultTestConfig()
+			app.Config.API.JSONListener = ""
+			app.Config.API.PublicServices = tc.public
+			app.Config.API.PrivateServices = nil
+			app.Config.API.PrivateListener = "127.0.0.1:0"
+			app.Config.API.ExperimentalServices = tc.experimental
+			err := app.startAPIServices(context.Background())
+			t.Cleanup(func() { app.stopServices(context.Background()) })
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestSpacemeshApp_JsonServiceNotRunning(t *testing.T) {
 	r := require.New(t)
 	app := New(WithLog(logtest.New(t)))
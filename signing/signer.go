@@ -22,6 +22,8 @@ const (
 	HARE     = 3
 	POET     = 4
 
+	BOOTSTRAP = 5
+
 	BEACON_FIRST_MSG    = 10
 	BEACON_FOLLOWUP_MSG = 11
 )
@@ -39,6 +41,8 @@ func (d Domain) String() string {
 		return "HARE"
 	case POET:
 		return "POET"
+	case BOOTSTRAP:
+		return "BOOTSTRAP"
 	case BEACON_FIRST_MSG:
 		return "BEACON_FIRST_MSG"
 	case BEACON_FOLLOWUP_MSG:
@@ -1,12 +1,20 @@
 package accounts
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
 )
 
+// ErrPruned is returned when account history at the layer was pruned.
+var ErrPruned = errors.New("account history is pruned")
+
+// ErrSnapshotInterval is returned when history is pruned with an interval different
+// from the one that was used to prune it before.
+var ErrSnapshotInterval = errors.New("snapshot interval differs from pruned history")
+
 func load(db sql.Executor, address types.Address, query string, enc sql.Encoder) (types.Account, error) {
 	var account types.Account
 	_, err := db.Exec(query, enc, func(stmt *sql.Statement) bool {
@@ -54,6 +62,9 @@ func Latest(db sql.Executor, address types.Address) (types.Account, error) {
 
 // Get account data that was valid at the specified layer.
 func Get(db sql.Executor, address types.Address, layer types.LayerID) (types.Account, error) {
+	if err := checkHistory(db, layer); err != nil {
+		return types.Account{}, err
+	}
 	account, err := load(db, address, "select balance, next_nonce, layer_updated, template, state from accounts where address = ?1 and layer_updated <= ?2;", func(stmt *sql.Statement) {
 		stmt.BindBytes(1, address.Bytes())
 		stmt.BindInt64(2, int64(layer))
@@ -90,6 +101,9 @@ func All(db sql.Executor) ([]*types.Account, error) {
 }
 
 func Snapshot(db sql.Executor, layer types.LayerID) ([]*types.Account, error) {
+	if err := checkHistory(db, layer); err != nil {
+		return nil, err
+	}
 	var rst []*types.Account
 	if rows, err := db.Exec(`
 			select address, balance, next_nonce, max(layer_updated), template, state from accounts 
@@ -155,3 +169,80 @@ func Revert(db sql.Executor, after types.LayerID) error {
 	}
 	return nil
 }
+
+// Prune deletes account states that were updated in [from, to) and were replaced
+// by a later update before the next snapshot layer or before `to`, whichever comes first.
+// Snapshot layers are multiples of interval, including genesis layer 0.
+// The range of pruned history is recorded, see Pruned.
+//
+// After pruning, Get returns correct state for layers that are not older than `to`
+// and for snapshot layers. Revert must not be called with a layer older than `to`.
+// Rows older than `from` are not inspected, therefore `from` must not be larger than
+// the last snapshot layer before the previous `to`.
+// Interval can't be changed once history was pruned, ErrSnapshotInterval is returned.
+func Prune(db sql.Executor, from, to types.LayerID, interval uint32) (int, error) {
+	if interval == 0 {
+		return 0, fmt.Errorf("snapshot interval must be positive")
+	}
+	pruned, snapshots, err := Pruned(db)
+	if err != nil {
+		return 0, err
+	}
+	if pruned != 0 && snapshots != interval {
+		return 0, fmt.Errorf("%w: pruned with %d, requested %d", ErrSnapshotInterval, snapshots, interval)
+	}
+	rows, err := db.Exec(`delete from accounts 
+		where layer_updated >= ?1 and layer_updated < ?2 and exists (
+			select 1 from accounts newer where newer.address = accounts.address
+			and newer.layer_updated > accounts.layer_updated
+			and newer.layer_updated <= min(?2, ((accounts.layer_updated + ?3 - 1) / ?3) * ?3)
+		) returning 1;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(from))
+			stmt.BindInt64(2, int64(to))
+			stmt.BindInt64(3, int64(interval))
+		}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("prune from %v to %v: %w", from, to, err)
+	}
+	if _, err := db.Exec(`insert into accounts_pruned (id, layer, interval) values (0, ?1, ?2)
+		on conflict (id) do update set layer = max(layer, excluded.layer);`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(to))
+			stmt.BindInt64(2, int64(interval))
+		}, nil); err != nil {
+		return 0, fmt.Errorf("record pruned layer %v: %w", to, err)
+	}
+	return rows, nil
+}
+
+// Pruned returns the layer before which account history was pruned and the interval
+// between snapshot layers that were kept. Layer is zero if history was never pruned.
+func Pruned(db sql.Executor) (types.LayerID, uint32, error) {
+	var (
+		layer    types.LayerID
+		interval uint32
+	)
+	if _, err := db.Exec("select layer, interval from accounts_pruned where id = 0;", nil,
+		func(stmt *sql.Statement) bool {
+			layer = types.LayerID(stmt.ColumnInt64(0))
+			interval = uint32(stmt.ColumnInt64(1))
+			return true
+		}); err != nil {
+		return 0, 0, fmt.Errorf("load pruned layer: %w", err)
+	}
+	return layer, interval, nil
+}
+
+// checkHistory returns ErrPruned if state at the layer can't be loaded after pruning.
+func checkHistory(db sql.Executor, layer types.LayerID) error {
+	pruned, interval, err := Pruned(db)
+	if err != nil {
+		return err
+	}
+	if layer.Before(pruned) && layer.Uint32()%interval != 0 {
+		return fmt.Errorf("%w: layer %v is before %v and is not a multiple of %d",
+			ErrPruned, layer, pruned, interval)
+	}
+	return nil
+}
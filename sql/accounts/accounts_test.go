@@ -104,3 +104,76 @@ func TestSnapshot(t *testing.T) {
 		}
 	}
 }
+
+func TestPrune(t *testing.T) {
+	const (
+		interval = 5
+		cutoff   = 17
+	)
+	db := sql.InMemory()
+	all := genSeq(types.Address{1}, 20)
+	for i, account := range genSeq(types.Address{2}, 20) {
+		if i%3 == 0 {
+			all = append(all, account)
+		}
+	}
+	for _, update := range all {
+		require.NoError(t, Update(db, update))
+	}
+	expected := map[types.LayerID][]*types.Account{}
+	for lid := types.LayerID(0); lid <= 20; lid++ {
+		snapshot, err := Snapshot(db, lid)
+		if lid == 0 {
+			require.ErrorIs(t, err, sql.ErrNotFound)
+		} else {
+			require.NoError(t, err)
+		}
+		expected[lid] = snapshot
+	}
+
+	before, _, err := Pruned(db)
+	require.NoError(t, err)
+	require.Zero(t, before)
+
+	pruned, err := Prune(db, 0, 10, interval)
+	require.NoError(t, err)
+	require.Equal(t, 8+2, pruned)
+	pruned, err = Prune(db, 10, cutoff, interval)
+	require.NoError(t, err)
+	require.Equal(t, 5, pruned)
+	pruned, err = Prune(db, 10, cutoff, interval)
+	require.NoError(t, err)
+	require.Zero(t, pruned)
+	_, err = Prune(db, 10, 20, interval+1)
+	require.ErrorIs(t, err, ErrSnapshotInterval)
+
+	for lid, snapshot := range expected {
+		if lid%interval != 0 && lid < cutoff {
+			_, err := Snapshot(db, lid)
+			require.ErrorIs(t, err, ErrPruned, "layer %d", lid)
+			_, err = Get(db, types.Address{1}, lid)
+			require.ErrorIs(t, err, ErrPruned, "layer %d", lid)
+			continue
+		}
+		actual, err := Snapshot(db, lid)
+		if lid == 0 {
+			require.ErrorIs(t, err, sql.ErrNotFound)
+		} else {
+			require.NoError(t, err, "layer %d", lid)
+		}
+		require.Equal(t, snapshot, actual, "layer %d", lid)
+	}
+
+	before, snapshots, err := Pruned(db)
+	require.NoError(t, err)
+	require.EqualValues(t, cutoff, before)
+	require.EqualValues(t, interval, snapshots)
+
+	require.NoError(t, Revert(db, cutoff))
+	latest, err := Latest(db, types.Address{1})
+	require.NoError(t, err)
+	require.EqualValues(t, cutoff, latest.Layer)
+
+	_, err = Prune(db, 0, cutoff, 0)
+	require.Error(t, err)
+}
@@ -55,3 +55,37 @@ func Set(db sql.Executor, epoch types.EpochID, beacon types.Beacon) error {
 
 	return nil
 }
+
+// SetRecord stores the encoded record of the beacon protocol execution in the epoch.
+func SetRecord(db sql.Executor, epoch types.EpochID, record []byte) error {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, int64(epoch))
+		stmt.BindBytes(2, record)
+	}
+	_, err := db.Exec(`insert into beacon_records (epoch, record) values (?1, ?2)
+		on conflict do update set record = ?2;`, enc, nil)
+	if err != nil {
+		return fmt.Errorf("insert record for epoch %v: %w", epoch, err)
+	}
+	return nil
+}
+
+// GetRecord gets the encoded record of the beacon protocol execution in the epoch.
+func GetRecord(db sql.Executor, epoch types.EpochID) (record []byte, err error) {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, int64(epoch))
+	}
+	dec := func(stmt *sql.Statement) bool {
+		record = make([]byte, stmt.ColumnLen(0))
+		stmt.ColumnBytes(0, record)
+		return true
+	}
+	rows, err := db.Exec("select record from beacon_records where epoch = ?1", enc, dec)
+	if err != nil {
+		return nil, fmt.Errorf("record for epoch %v: %w", epoch, err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("record for epoch %v: %w", epoch, sql.ErrNotFound)
+	}
+	return record, nil
+}
@@ -69,3 +69,21 @@ func TestSet(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, fallbackBeacon, got)
 }
+
+func TestRecord(t *testing.T) {
+	db := sql.InMemory()
+	epoch := types.EpochID(3)
+
+	_, err := GetRecord(db, epoch)
+	require.ErrorIs(t, err, sql.ErrNotFound)
+
+	require.NoError(t, SetRecord(db, epoch, []byte("first")))
+	got, err := GetRecord(db, epoch)
+	require.NoError(t, err)
+	require.Equal(t, []byte("first"), got)
+
+	require.NoError(t, SetRecord(db, epoch, []byte("second")))
+	got, err = GetRecord(db, epoch)
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), got)
+}
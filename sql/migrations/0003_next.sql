@@ -1,5 +1,48 @@
+CREATE TABLE accounts_pruned
+(
+    id       INT PRIMARY KEY CHECK (id = 0),
+    layer    INT NOT NULL,
+    interval INT NOT NULL
+) WITHOUT ROWID;
 CREATE TABLE activesets
 (
     id     CHAR(32) PRIMARY KEY,
     active_set    BLOB
-) WITHOUT ROWID;
\ No newline at end of file
+) WITHOUT ROWID;
+CREATE TABLE beacon_records
+(
+    epoch  INT PRIMARY KEY NOT NULL,
+    record BLOB NOT NULL
+) WITHOUT ROWID;
+CREATE TABLE poet_submissions
+(
+    address        VARCHAR NOT NULL,
+    epoch          INT NOT NULL,
+    service_id     BLOB,
+    round_id       VARCHAR,
+    submitted      BOOL NOT NULL,
+    submit_latency INT NOT NULL,
+    error          VARCHAR,
+    proof          INT NOT NULL,
+    proof_latency  INT NOT NULL,
+    leaf_count     INT NOT NULL,
+    tick_count     INT NOT NULL,
+    PRIMARY KEY (address, epoch)
+) WITHOUT ROWID;
+CREATE TABLE user_events
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    epoch     INT NOT NULL,
+    layer     INT NOT NULL,
+    kind      VARCHAR NOT NULL,
+    timestamp INT NOT NULL,
+    failure   BOOL NOT NULL,
+    event     BLOB NOT NULL
+);
+CREATE INDEX user_events_by_epoch ON user_events (epoch, id);
+CREATE TABLE verified_posts
+(
+    atx    CHAR(32) PRIMARY KEY,
+    proof  CHAR(32) NOT NULL,
+    params CHAR(32) NOT NULL
+) WITHOUT ROWID;
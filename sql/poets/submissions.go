@@ -0,0 +1,170 @@
+package poets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// ProofState is the state of the proof for the submitted challenge.
+type ProofState int
+
+const (
+	// ProofPending is the state until the proof is requested from the poet.
+	ProofPending ProofState = iota
+	// ProofReceived when the poet delivered the proof before the deadline.
+	ProofReceived
+	// ProofMissed when the poet failed to deliver the proof before the deadline.
+	ProofMissed
+	// ProofSkipped when the node stopped waiting for the proof because a better poet delivered it.
+	ProofSkipped
+)
+
+func (s ProofState) String() string {
+	switch s {
+	case ProofPending:
+		return "pending"
+	case ProofReceived:
+		return "received"
+	case ProofMissed:
+		return "missed"
+	case ProofSkipped:
+		return "skipped"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// Submission is a record of the challenge submitted to the poet for the publish epoch
+// and of the proof received for it.
+type Submission struct {
+	Address   string
+	Epoch     types.EpochID
+	ServiceID []byte
+	RoundID   string
+
+	Submitted     bool
+	SubmitLatency time.Duration
+	// Error of the last failed request to the poet.
+	Error string
+
+	Proof ProofState
+	// ProofLatency is the time from the end of the round until proof was received.
+	ProofLatency time.Duration
+	LeafCount    uint64
+	TickCount    uint64
+}
+
+// AddSubmission inserts the record of the submission, record for the same address and epoch is replaced.
+func AddSubmission(db sql.Executor, s *Submission) error {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, s.Address)
+		stmt.BindInt64(2, int64(s.Epoch))
+		stmt.BindBytes(3, s.ServiceID)
+		stmt.BindText(4, s.RoundID)
+		stmt.BindBool(5, s.Submitted)
+		stmt.BindInt64(6, int64(s.SubmitLatency))
+		stmt.BindText(7, s.Error)
+		stmt.BindInt64(8, int64(s.Proof))
+		stmt.BindInt64(9, int64(s.ProofLatency))
+		stmt.BindInt64(10, int64(s.LeafCount))
+		stmt.BindInt64(11, int64(s.TickCount))
+	}
+	_, err := db.Exec(`
+		insert or replace into poet_submissions (address, epoch, service_id, round_id, submitted,
+			submit_latency, error, proof, proof_latency, leaf_count, tick_count)
+		values (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11);`, enc, nil)
+	if err != nil {
+		return fmt.Errorf("add submission %s/%d: %w", s.Address, s.Epoch, err)
+	}
+	return nil
+}
+
+// SetProof updates the state of the proof for the submission.
+// Error is replaced only if errMsg is not empty.
+func SetProof(db sql.Executor, address string, epoch types.EpochID, state ProofState,
+	latency time.Duration, leafCount, tickCount uint64, errMsg string,
+) error {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, address)
+		stmt.BindInt64(2, int64(epoch))
+		stmt.BindInt64(3, int64(state))
+		stmt.BindInt64(4, int64(latency))
+		stmt.BindInt64(5, int64(leafCount))
+		stmt.BindInt64(6, int64(tickCount))
+		stmt.BindText(7, errMsg)
+	}
+	rows, err := db.Exec(`
+		update poet_submissions set proof = ?3, proof_latency = ?4, leaf_count = ?5, tick_count = ?6,
+			error = case when ?7 = '' then error else ?7 end
+		where address = ?1 and epoch = ?2 returning 1;`, enc, nil)
+	if err != nil {
+		return fmt.Errorf("set proof %s/%d: %w", address, epoch, err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("set proof %s/%d: %w", address, epoch, sql.ErrNotFound)
+	}
+	return nil
+}
+
+const submissionColumns = `address, epoch, service_id, round_id, submitted,
+	submit_latency, error, proof, proof_latency, leaf_count, tick_count`
+
+func decodeSubmission(stmt *sql.Statement) Submission {
+	s := Submission{
+		Address:       stmt.ColumnText(0),
+		Epoch:         types.EpochID(stmt.ColumnInt64(1)),
+		RoundID:       stmt.ColumnText(3),
+		Submitted:     stmt.ColumnInt(4) != 0,
+		SubmitLatency: time.Duration(stmt.ColumnInt64(5)),
+		Error:         stmt.ColumnText(6),
+		Proof:         ProofState(stmt.ColumnInt64(7)),
+		ProofLatency:  time.Duration(stmt.ColumnInt64(8)),
+		LeafCount:     uint64(stmt.ColumnInt64(9)),
+		TickCount:     uint64(stmt.ColumnInt64(10)),
+	}
+	if n := stmt.ColumnLen(2); n > 0 {
+		s.ServiceID = make([]byte, n)
+		stmt.ColumnBytes(2, s.ServiceID)
+	}
+	return s
+}
+
+// Submissions returns at most limit latest submissions to the poet at address, latest first.
+func Submissions(db sql.Executor, address string, limit int) ([]Submission, error) {
+	var rst []Submission
+	enc := func(stmt *sql.Statement) {
+		stmt.BindText(1, address)
+		stmt.BindInt64(2, int64(limit))
+	}
+	dec := func(stmt *sql.Statement) bool {
+		rst = append(rst, decodeSubmission(stmt))
+		return true
+	}
+	_, err := db.Exec("select "+submissionColumns+` from poet_submissions
+		where address = ?1 order by epoch desc limit ?2;`, enc, dec)
+	if err != nil {
+		return nil, fmt.Errorf("submissions %s: %w", address, err)
+	}
+	return rst, nil
+}
+
+// SubmissionsFrom returns submissions for publish epochs starting with from,
+// ordered by address and by epoch, latest first.
+func SubmissionsFrom(db sql.Executor, from types.EpochID) ([]Submission, error) {
+	var rst []Submission
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, int64(from))
+	}
+	dec := func(stmt *sql.Statement) bool {
+		rst = append(rst, decodeSubmission(stmt))
+		return true
+	}
+	_, err := db.Exec("select "+submissionColumns+` from poet_submissions
+		where epoch >= ?1 order by address, epoch desc;`, enc, dec)
+	if err != nil {
+		return nil, fmt.Errorf("submissions from %d: %w", from, err)
+	}
+	return rst, nil
+}
@@ -0,0 +1,81 @@
+package poets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func TestSubmissions(t *testing.T) {
+	db := sql.InMemory()
+	const poet1, poet2 = "https://poet1", "https://poet2"
+
+	for epoch := types.EpochID(1); epoch <= 3; epoch++ {
+		require.NoError(t, AddSubmission(db, &Submission{
+			Address:       poet1,
+			Epoch:         epoch,
+			ServiceID:     []byte("poet1"),
+			RoundID:       epoch.String(),
+			Submitted:     true,
+			SubmitLatency: time.Second,
+		}))
+	}
+	require.NoError(t, AddSubmission(db, &Submission{
+		Address: poet2,
+		Epoch:   2,
+		Error:   "unavailable",
+	}))
+
+	got, err := Submissions(db, poet1, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, Submission{
+		Address:       poet1,
+		Epoch:         3,
+		ServiceID:     []byte("poet1"),
+		RoundID:       "3",
+		Submitted:     true,
+		SubmitLatency: time.Second,
+		Proof:         ProofPending,
+	}, got[0])
+	require.Equal(t, types.EpochID(2), got[1].Epoch)
+
+	require.NoError(t, SetProof(db, poet1, 3, ProofReceived, time.Minute, 1000, 10, ""))
+	require.NoError(t, SetProof(db, poet1, 2, ProofMissed, 0, 0, 0, "not found"))
+	require.ErrorIs(t, SetProof(db, poet2, 3, ProofMissed, 0, 0, 0, ""), sql.ErrNotFound)
+
+	got, err = Submissions(db, poet1, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, ProofReceived, got[0].Proof)
+	require.Equal(t, time.Minute, got[0].ProofLatency)
+	require.Equal(t, uint64(1000), got[0].LeafCount)
+	require.Equal(t, uint64(10), got[0].TickCount)
+	require.Empty(t, got[0].Error)
+	require.Equal(t, ProofMissed, got[1].Proof)
+	require.Equal(t, "not found", got[1].Error)
+	require.Equal(t, ProofPending, got[2].Proof)
+
+	all, err := SubmissionsFrom(db, 2)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, poet1, all[0].Address)
+	require.Equal(t, types.EpochID(3), all[0].Epoch)
+	require.Equal(t, types.EpochID(2), all[1].Epoch)
+	require.Equal(t, poet2, all[2].Address)
+	require.False(t, all[2].Submitted)
+	require.Nil(t, all[2].ServiceID)
+	require.Equal(t, "unavailable", all[2].Error)
+
+	// resubmission replaces the record
+	require.NoError(t, AddSubmission(db, &Submission{Address: poet2, Epoch: 2, Submitted: true}))
+	got, err = Submissions(db, poet2, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.True(t, got[0].Submitted)
+	require.Empty(t, got[0].Error)
+}
@@ -54,3 +54,24 @@ func List(db sql.Executor, coinbase types.Address) (rst []*types.Reward, err err
 		})
 	return
 }
+
+// ListRange returns rewards for the coinbase address in layers [from, to].
+func ListRange(db sql.Executor, coinbase types.Address, from, to types.LayerID) (rst []*types.Reward, err error) {
+	_, err = db.Exec(`select layer, total_reward, layer_reward from rewards
+		where coinbase = ?1 and layer between ?2 and ?3 order by layer;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, coinbase[:])
+			stmt.BindInt64(2, int64(from.Uint32()))
+			stmt.BindInt64(3, int64(to.Uint32()))
+		}, func(stmt *sql.Statement) bool {
+			reward := &types.Reward{
+				Coinbase:    coinbase,
+				Layer:       types.LayerID(uint32(stmt.ColumnInt64(0))),
+				TotalReward: uint64(stmt.ColumnInt64(1)),
+				LayerReward: uint64(stmt.ColumnInt64(2)),
+			}
+			rst = append(rst, reward)
+			return true
+		})
+	return
+}
@@ -72,6 +72,11 @@ func TestRewards(t *testing.T) {
 	require.Equal(t, part, got[1].TotalReward)
 	require.Equal(t, lyrReward, got[1].LayerReward)
 
+	got, err = ListRange(db, coinbase2, lid2, lid2.Add(10))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, lid2, got[0].Layer)
+
 	unknownAddr := types.Address{1, 2, 3}
 	got, err = List(db, unknownAddr)
 	require.NoError(t, err)
@@ -0,0 +1,84 @@
+package userevents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Event is a persisted user event. Event is an encoded spacemesh.v1.Event,
+// Kind names the type of its details.
+type Event struct {
+	ID        int64
+	Epoch     types.EpochID
+	Layer     types.LayerID
+	Kind      string
+	Timestamp time.Time
+	Failure   bool
+	Event     []byte
+}
+
+// Add inserts the event, ID is assigned by the database.
+func Add(db sql.Executor, ev *Event) error {
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, int64(ev.Epoch))
+		stmt.BindInt64(2, int64(ev.Layer))
+		stmt.BindText(3, ev.Kind)
+		stmt.BindInt64(4, ev.Timestamp.UnixNano())
+		stmt.BindBool(5, ev.Failure)
+		stmt.BindBytes(6, ev.Event)
+	}
+	dec := func(stmt *sql.Statement) bool {
+		ev.ID = stmt.ColumnInt64(0)
+		return false
+	}
+	if _, err := db.Exec(`
+		insert into user_events (epoch, layer, kind, timestamp, failure, event)
+		values (?1, ?2, ?3, ?4, ?5, ?6) returning id;`, enc, dec); err != nil {
+		return fmt.Errorf("add %s event: %w", ev.Kind, err)
+	}
+	return nil
+}
+
+// List returns events that occurred in epochs [from, to] in the order they were added.
+func List(db sql.Executor, from, to types.EpochID) ([]Event, error) {
+	var rst []Event
+	enc := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, int64(from))
+		stmt.BindInt64(2, int64(to))
+	}
+	dec := func(stmt *sql.Statement) bool {
+		ev := Event{
+			ID:        stmt.ColumnInt64(0),
+			Epoch:     types.EpochID(stmt.ColumnInt64(1)),
+			Layer:     types.LayerID(stmt.ColumnInt64(2)),
+			Kind:      stmt.ColumnText(3),
+			Timestamp: time.Unix(0, stmt.ColumnInt64(4)),
+			Failure:   stmt.ColumnInt(5) != 0,
+			Event:     make([]byte, stmt.ColumnLen(6)),
+		}
+		stmt.ColumnBytes(6, ev.Event)
+		rst = append(rst, ev)
+		return true
+	}
+	if _, err := db.Exec(`
+		select id, epoch, layer, kind, timestamp, failure, event from user_events
+		where epoch between ?1 and ?2 order by epoch, id;`, enc, dec); err != nil {
+		return nil, fmt.Errorf("list events in [%d, %d]: %w", from, to, err)
+	}
+	return rst, nil
+}
+
+// Prune deletes events that occurred before the epoch and returns the number of deleted events.
+func Prune(db sql.Executor, before types.EpochID) (int, error) {
+	n, err := db.Exec(`delete from user_events where epoch < ?1 returning id;`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(before))
+		}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("prune events before %d: %w", before, err)
+	}
+	return n, nil
+}
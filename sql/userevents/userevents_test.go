@@ -0,0 +1,53 @@
+package userevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func TestUserEvents(t *testing.T) {
+	db := sql.InMemory()
+	now := time.Now()
+	var added []Event
+	for epoch := types.EpochID(1); epoch <= 3; epoch++ {
+		for _, kind := range []string{"poet_wait_round", "atx_published"} {
+			ev := Event{
+				Epoch:     epoch,
+				Layer:     epoch.FirstLayer(),
+				Kind:      kind,
+				Timestamp: now.Add(time.Duration(epoch) * time.Minute),
+				Failure:   epoch == 2,
+				Event:     []byte(kind),
+			}
+			require.NoError(t, Add(db, &ev))
+			require.NotZero(t, ev.ID)
+			added = append(added, ev)
+		}
+	}
+
+	got, err := List(db, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for i, ev := range got {
+		require.Equal(t, added[2+i].ID, ev.ID)
+		require.Equal(t, added[2+i].Kind, ev.Kind)
+		require.Equal(t, added[2+i].Layer, ev.Layer)
+		require.True(t, added[2+i].Timestamp.Equal(ev.Timestamp))
+		require.True(t, ev.Failure)
+		require.Equal(t, added[2+i].Event, ev.Event)
+	}
+
+	n, err := Prune(db, 3)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	got, err = List(db, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, types.EpochID(3), got[0].Epoch)
+	require.False(t, got[0].Failure)
+}
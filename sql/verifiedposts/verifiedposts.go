@@ -0,0 +1,63 @@
+package verifiedposts
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// Record states that PoST of the atx with the proof hash was valid
+// when verified with the parameters hash.
+type Record struct {
+	Atx    types.ATXID
+	Proof  types.Hash32
+	Params types.Hash32
+}
+
+// Add the record, replacing the previous record of the same atx.
+func Add(db sql.Executor, record Record) error {
+	if _, err := db.Exec(`
+		insert into verified_posts (atx, proof, params) values (?1, ?2, ?3)
+		on conflict (atx) do update set proof = ?2, params = ?3;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, record.Atx.Bytes())
+			stmt.BindBytes(2, record.Proof.Bytes())
+			stmt.BindBytes(3, record.Params.Bytes())
+		}, nil); err != nil {
+		return fmt.Errorf("add verified post %s: %w", record.Atx, err)
+	}
+	return nil
+}
+
+// Has returns true if the same record was added.
+func Has(db sql.Executor, record Record) (bool, error) {
+	rows, err := db.Exec(`
+		select 1 from verified_posts where atx = ?1 and proof = ?2 and params = ?3;`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, record.Atx.Bytes())
+			stmt.BindBytes(2, record.Proof.Bytes())
+			stmt.BindBytes(3, record.Params.Bytes())
+		}, nil)
+	if err != nil {
+		return false, fmt.Errorf("has verified post %s: %w", record.Atx, err)
+	}
+	return rows > 0, nil
+}
+
+// All returns all records.
+func All(db sql.Executor) ([]Record, error) {
+	var rst []Record
+	if _, err := db.Exec(`select atx, proof, params from verified_posts;`, nil,
+		func(stmt *sql.Statement) bool {
+			var record Record
+			stmt.ColumnBytes(0, record.Atx[:])
+			stmt.ColumnBytes(1, record.Proof[:])
+			stmt.ColumnBytes(2, record.Params[:])
+			rst = append(rst, record)
+			return true
+		}); err != nil {
+		return nil, fmt.Errorf("all verified posts: %w", err)
+	}
+	return rst, nil
+}
@@ -0,0 +1,45 @@
+package verifiedposts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+func TestVerifiedPosts(t *testing.T) {
+	db := sql.InMemory()
+	record := Record{
+		Atx:    types.RandomATXID(),
+		Proof:  types.RandomHash(),
+		Params: types.RandomHash(),
+	}
+	has, err := Has(db, record)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.NoError(t, Add(db, record))
+	has, err = Has(db, record)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	changed := record
+	changed.Params = types.RandomHash()
+	has, err = Has(db, changed)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// record is replaced when verified with different parameters
+	require.NoError(t, Add(db, changed))
+	has, err = Has(db, record)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	other := Record{Atx: types.RandomATXID(), Proof: types.RandomHash(), Params: changed.Params}
+	require.NoError(t, Add(db, other))
+	all, err := All(db)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Record{changed, other}, all)
+}
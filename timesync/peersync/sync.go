@@ -133,6 +133,9 @@ func New(h host.Host, peers getPeers, opts ...Option) *Sync {
 // Sync manages background worker that compares peers time with system time.
 type Sync struct {
 	errCnt uint32
+	// offset of the last successful round, valid if measured is set.
+	offset   atomic.Int64
+	measured atomic.Bool
 
 	config Config
 	log    log.Log
@@ -227,6 +230,8 @@ func (s *Sync) run() error {
 					atomic.StoreUint32(&s.errCnt, 0)
 				}
 				offsetGauge.Set(offset.Seconds())
+				s.offset.Store(int64(offset))
+				s.measured.Store(true)
 				timeout = s.config.RoundInterval
 			} else {
 				s.log.With().Error("failed to fetch offset from peers", log.Err(err))
@@ -246,6 +251,12 @@ func (s *Sync) run() error {
 	}
 }
 
+// Offset returns the offset of the system clock from the peers clock measured in the last round.
+// False is returned if the offset wasn't measured yet.
+func (s *Sync) Offset() (time.Duration, bool) {
+	return time.Duration(s.offset.Load()), s.measured.Load()
+}
+
 // GetOffset computes offset from received response. The method is stateless and safe to use concurrently.
 func (s *Sync) GetOffset(ctx context.Context, id uint64, prs []p2p.Peer) (time.Duration, error) {
 	var (
@@ -25,23 +25,44 @@ const (
 	units     = 10
 )
 
-func newCore(rng *rand.Rand, id string, logger log.Log) *core {
+// Behavior is a set of deviations from the honest protocol.
+// Zero value is an honest behavior.
+type Behavior struct {
+	// Equivocate publishes two ballots in every layer.
+	Equivocate bool
+	// Withhold never publishes ballots.
+	Withhold bool
+	// AgainstHare votes against every hare output.
+	AgainstHare bool
+	// WrongBeacon uses a random beacon instead of the beacon produced by the network.
+	WrongBeacon bool
+	// Delay publishes ballots with a delay in layers.
+	Delay uint32
+}
+
+// Honest returns true if behavior doesn't deviate from the protocol.
+func (b Behavior) Honest() bool {
+	return b == Behavior{}
+}
+
+func newCore(rng *rand.Rand, id string, logger log.Log, cfg tortoise.Config, behavior Behavior) *core {
 	cdb := datastore.NewCachedDB(sql.InMemory(), logger)
 	sig, err := signing.NewEdSigner(signing.WithKeyFromRand(rng))
 	if err != nil {
 		panic(err)
 	}
 	c := &core{
-		id:      id,
-		logger:  logger,
-		rng:     rng,
-		cdb:     cdb,
-		beacons: newBeaconStore(),
-		units:   units,
-		signer:  sig,
+		id:        id,
+		logger:    logger,
+		rng:       rng,
+		cdb:       cdb,
+		beacons:   newBeaconStore(),
+		units:     units,
+		layerSize: cfg.LayerSize,
+		signer:    sig,
+		behavior:  behavior,
+		delayed:   map[types.LayerID][]*types.Ballot{},
 	}
-	cfg := tortoise.DefaultConfig()
-	cfg.LayerSize = layerSize
 	c.tortoise, err = tortoise.New(
 		tortoise.WithLogger(logger.Named("trtl")),
 		tortoise.WithConfig(cfg),
@@ -63,8 +84,9 @@ type core struct {
 	tortoise *tortoise.Tortoise
 
 	// generated on setup
-	units  uint32
-	signer *signing.EdSigner
+	units     uint32
+	layerSize uint32
+	signer    *signing.EdSigner
 
 	// set in the first layer of each epoch
 	refBallot     *types.BallotID
@@ -73,6 +95,12 @@ type core struct {
 
 	// set at the end of the epoch (MessageLayerEnd for the last layer of the epoch)
 	atx types.ATXID
+
+	behavior Behavior
+	// ballots that will be published at the start of the layer
+	delayed map[types.LayerID][]*types.Ballot
+	// latest layer that was loaded into the tortoise
+	recovered types.LayerID
 }
 
 // OnMessage receive blocks, atx, input vector, beacon, coinflip and store them.
@@ -85,12 +113,16 @@ func (c *core) OnMessage(m Messenger, event Message) {
 		if !ev.LayerID.After(types.GetEffectiveGenesis()) {
 			return
 		}
+		for _, ballot := range c.delayed[ev.LayerID] {
+			m.Send(MessageBallot{Ballot: ballot})
+		}
+		delete(c.delayed, ev.LayerID)
 		if c.refBallot == nil {
 			total, _, err := c.cdb.GetEpochWeight(ev.LayerID.GetEpoch())
 			if err != nil {
 				panic(err)
 			}
-			c.eligibilities = max(uint32(c.weight*layerSize/total), 1)
+			c.eligibilities = max(uint32(c.weight*uint64(c.layerSize)/total), 1)
 		}
 		votes, err := c.tortoise.EncodeVotes(context.TODO())
 		if err != nil {
@@ -130,11 +162,47 @@ func (c *core) OnMessage(m Messenger, event Message) {
 			id := ballot.ID()
 			c.refBallot = &id
 		}
-		m.Send(MessageBallot{Ballot: ballot})
+		ballots := []*types.Ballot{ballot}
+		if c.behavior.Equivocate {
+			// same votes but different eligibility makes ballot with a different id
+			equivocation := &types.Ballot{}
+			equivocation.Layer = ev.LayerID
+			equivocation.Votes = ballot.Votes
+			equivocation.OpinionHash = ballot.OpinionHash
+			equivocation.AtxID = ballot.AtxID
+			equivocation.RefBallot = *c.refBallot
+			for i := uint32(0); i < c.eligibilities; i++ {
+				equivocation.EligibilityProofs = append(equivocation.EligibilityProofs,
+					types.VotingEligibility{J: c.eligibilities + i})
+			}
+			equivocation.Signature = c.signer.Sign(signing.BALLOT, equivocation.SignedBytes())
+			equivocation.SmesherID = c.signer.NodeID()
+			equivocation.Initialize()
+			ballots = append(ballots, equivocation)
+		}
+		switch {
+		case c.behavior.Withhold:
+		case c.behavior.Delay > 0:
+			lid := ev.LayerID.Add(c.behavior.Delay)
+			c.delayed[lid] = append(c.delayed[lid], ballots...)
+		default:
+			for _, ballot := range ballots {
+				m.Send(MessageBallot{Ballot: ballot})
+			}
+		}
 	case MessageLayerEnd:
 		if ev.LayerID.After(types.GetEffectiveGenesis()) {
 			tortoise.RecoverLayer(context.Background(), c.tortoise, c.cdb, c.beacons, ev.LayerID, ev.LayerID)
-			m.Notify(EventVerified{ID: c.id, Verified: c.tortoise.LatestComplete(), Layer: ev.LayerID})
+			c.recovered = ev.LayerID
+			updates := c.tortoise.Updates()
+			if c.behavior.Honest() {
+				m.Notify(EventVerified{ID: c.id, Verified: c.tortoise.LatestComplete(), Layer: ev.LayerID})
+				for _, layer := range updates {
+					if layer.Verified {
+						m.Notify(EventDecided{ID: c.id, Layer: layer.Layer, Block: layer.FirstValid()})
+					}
+				}
+			}
 		}
 
 		if ev.LayerID.GetEpoch() == ev.LayerID.Add(1).GetEpoch() {
@@ -164,11 +232,23 @@ func (c *core) OnMessage(m Messenger, event Message) {
 	case MessageBlock:
 		ids, err := blocks.IDsInLayer(c.cdb, ev.Block.LayerIndex)
 		if errors.Is(err, sql.ErrNotFound) || len(ids) == 0 {
-			certificates.SetHareOutput(c.cdb, ev.Block.LayerIndex, ev.Block.ID())
+			output := ev.Block.ID()
+			if c.behavior.AgainstHare {
+				output = types.EmptyBlockID
+			}
+			certificates.SetHareOutput(c.cdb, ev.Block.LayerIndex, output)
 		}
 		blocks.Add(c.cdb, ev.Block)
 	case MessageBallot:
+		existing, err := ballots.LayerBallotByNodeID(c.cdb, ev.Ballot.Layer, ev.Ballot.SmesherID)
+		if err == nil && existing.ID() != ev.Ballot.ID() {
+			c.tortoise.OnMalfeasance(ev.Ballot.SmesherID)
+		}
 		ballots.Add(c.cdb, ev.Ballot)
+		if !ev.Ballot.Layer.After(c.recovered) {
+			// layer was already loaded into the tortoise, late ballot needs to be added explicitly
+			c.tortoise.OnBallot(ev.Ballot.ToTortoiseData())
+		}
 	case MessageAtx:
 		vAtx, err := ev.Atx.Verify(1, 2)
 		if err != nil {
@@ -176,6 +256,12 @@ func (c *core) OnMessage(m Messenger, event Message) {
 		}
 		atxs.Add(c.cdb, vAtx)
 	case MessageBeacon:
+		if c.behavior.WrongBeacon {
+			beacon := types.Beacon{}
+			c.rng.Read(beacon[:])
+			c.beacons.StoreBeacon(ev.EpochID, beacon)
+			return
+		}
 		c.beacons.StoreBeacon(ev.EpochID, ev.Beacon)
 	case MessageCoinflip:
 		layers.SetWeakCoin(c.cdb, ev.LayerID, ev.Coinflip)
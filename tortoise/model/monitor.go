@@ -1,9 +1,12 @@
 package model
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 )
@@ -25,6 +28,15 @@ type EventVerified struct {
 	Revert   bool
 }
 
+// EventDecided is raised for every layer that was verified by tortoise,
+// including layers where decision was changed after verification.
+type EventDecided struct {
+	ID    string
+	Layer types.LayerID
+	// Block is the first valid block in the layer, or empty if layer is empty.
+	Block types.BlockID
+}
+
 func newVerifiedMonitor(tb testing.TB, genesis types.LayerID) *verifiedMonitor {
 	return &verifiedMonitor{
 		tb:       tb,
@@ -58,3 +70,106 @@ func (m *verifiedMonitor) Test() {
 		require.Equal(m.tb, m.last.Sub(1), verified, "id=%s", id)
 	}
 }
+
+func newSafetyMonitor() *safetyMonitor {
+	return &safetyMonitor{
+		decided:    map[types.LayerID]map[string]types.BlockID{},
+		violations: map[types.LayerID]string{},
+	}
+}
+
+// safetyMonitor checks that all nodes decided on the same block in every verified layer.
+type safetyMonitor struct {
+	decided map[types.LayerID]map[string]types.BlockID
+	// reverts is a number of decisions that were changed after layer was verified.
+	reverts    int
+	violations map[types.LayerID]string
+}
+
+func (m *safetyMonitor) OnEvent(event Event) {
+	switch ev := event.(type) {
+	case EventDecided:
+		if _, exist := m.decided[ev.Layer]; !exist {
+			m.decided[ev.Layer] = map[string]types.BlockID{}
+		}
+		if prev, exist := m.decided[ev.Layer][ev.ID]; exist && prev != ev.Block {
+			m.reverts++
+		}
+		m.decided[ev.Layer][ev.ID] = ev.Block
+	}
+}
+
+func (m *safetyMonitor) Test() {
+	for lid, decided := range m.decided {
+		if _, exist := m.violations[lid]; exist {
+			continue
+		}
+		ids := maps.Keys(decided)
+		slices.Sort(ids)
+		for _, id := range ids[1:] {
+			if decided[id] != decided[ids[0]] {
+				m.violations[lid] = fmt.Sprintf("layer %d: id=%s decided %s, id=%s decided %s",
+					lid, ids[0], decided[ids[0]], id, decided[id])
+				break
+			}
+		}
+	}
+}
+
+// Violations returns violations sorted by layer.
+func (m *safetyMonitor) Violations() []string {
+	lids := maps.Keys(m.violations)
+	slices.Sort(lids)
+	rst := make([]string, 0, len(lids))
+	for _, lid := range lids {
+		rst = append(rst, m.violations[lid])
+	}
+	return rst
+}
+
+func newLivenessMonitor(genesis types.LayerID, bound uint32) *livenessMonitor {
+	return &livenessMonitor{
+		genesis:  genesis,
+		bound:    bound,
+		verified: map[string]types.LayerID{},
+	}
+}
+
+// livenessMonitor checks that verified layer is within bound from the last layer.
+type livenessMonitor struct {
+	genesis    types.LayerID
+	bound      uint32
+	last       types.LayerID
+	verified   map[string]types.LayerID
+	maxLag     uint32
+	violations []string
+}
+
+func (m *livenessMonitor) OnEvent(event Event) {
+	switch ev := event.(type) {
+	case EventVerified:
+		m.verified[ev.ID] = ev.Verified
+		if ev.Layer.After(m.last) {
+			m.last = ev.Layer
+		}
+	}
+}
+
+func (m *livenessMonitor) Test() {
+	if !m.last.After(m.genesis) {
+		return
+	}
+	ids := maps.Keys(m.verified)
+	slices.Sort(ids)
+	for _, id := range ids {
+		verified := max(m.verified[id], m.genesis)
+		lag := m.last.Difference(verified)
+		m.maxLag = max(m.maxLag, lag)
+		if lag > m.bound {
+			// single violation per layer is enough
+			m.violations = append(m.violations, fmt.Sprintf(
+				"layer %d: id=%s verified %d, lag %d is over bound %d", m.last, id, verified, lag, m.bound))
+			return
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/tortoise"
 )
 
 type model interface {
@@ -14,15 +15,24 @@ type model interface {
 }
 
 func newCluster(logger log.Log, rng *rand.Rand) *cluster {
-	return &cluster{logger: logger, rng: rng}
+	cfg := tortoise.DefaultConfig()
+	cfg.LayerSize = layerSize
+	return &cluster{logger: logger, rng: rng, cfg: cfg}
 }
 
 type cluster struct {
 	rng    *rand.Rand
 	logger log.Log
+	cfg    tortoise.Config
 	models []model
 }
 
+// withConfig sets tortoise config for cores that will be added after this call.
+func (r *cluster) withConfig(cfg tortoise.Config) *cluster {
+	r.cfg = cfg
+	return r
+}
+
 func (r *cluster) nextid() string {
 	return strconv.Itoa(len(r.models))
 }
@@ -34,7 +44,12 @@ func (r *cluster) add(m model) *cluster {
 
 func (r *cluster) addCore() *cluster {
 	id := r.nextid()
-	return r.add(newCore(r.rng, id, r.logger.Named("core-"+id)))
+	return r.add(newCore(r.rng, id, r.logger.Named("core-"+id), r.cfg, Behavior{}))
+}
+
+func (r *cluster) addAdversary(behavior Behavior) *cluster {
+	id := r.nextid()
+	return r.add(newCore(r.rng, id, r.logger.Named("adversary-"+id), r.cfg, behavior))
 }
 
 func (r *cluster) addHare() *cluster {
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/tortoise"
+)
+
+// Adversary controls a fraction of the total weight and deviates from the protocol.
+type Adversary struct {
+	// Fraction of all smeshers that follow this behavior.
+	// All smeshers have the same weight in the model, therefore it is also a fraction of the weight.
+	Fraction float64
+	Behavior Behavior
+}
+
+// Scenario describes a cluster with scripted adversaries.
+type Scenario struct {
+	// Seed for all random choices in the model. If 0 seed is generated,
+	// and can be found in the report.
+	Seed           int64
+	Smeshers       int
+	Layers         int
+	LayersPerEpoch uint32
+	Config         tortoise.Config
+	Adversaries    []Adversary
+	// DropBallots is a probability in percents that ballot will not be delivered to all nodes.
+	DropBallots int
+	// LivenessBound is a max distance between the last layer and the verified layer on every honest node.
+	LivenessBound uint32
+}
+
+// Report of the scenario run.
+type Report struct {
+	Seed int64
+	// Safety violations, when honest nodes decided on different blocks in the same layer.
+	Safety []string
+	// Liveness violations, when verified layer didn't advance within a bound.
+	Liveness []string
+	// MaxLag is the max observed distance between the last layer and the verified layer.
+	MaxLag uint32
+	// Reverts is a number of times that honest nodes changed decision in verified layers.
+	Reverts int
+}
+
+// Failed returns true if safety or liveness was violated.
+func (r *Report) Failed() bool {
+	return len(r.Safety) > 0 || len(r.Liveness) > 0
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "seed=%d max-lag=%d reverts=%d", r.Seed, r.MaxLag, r.Reverts)
+	for _, v := range r.Safety {
+		fmt.Fprintf(&b, "\nsafety: %s", v)
+	}
+	for _, v := range r.Liveness {
+		fmt.Fprintf(&b, "\nliveness: %s", v)
+	}
+	return b.String()
+}
+
+// Run executes scenario and reports violations of safety and liveness.
+// Running scenario with the seed from the report reproduces the same execution.
+//
+// Zero fields of the scenario config are taken from tortoise.DefaultConfig.
+// Scenario sets global number of layers per epoch and restores it before returning,
+// therefore scenarios must not run concurrently with other code that depends on it.
+func Run(logger log.Log, s Scenario) (*Report, error) {
+	if s.Smeshers <= 0 {
+		return nil, fmt.Errorf("scenario should have at least one smesher, got %d", s.Smeshers)
+	}
+	honest := s.Smeshers
+	for _, adversary := range s.Adversaries {
+		if adversary.Fraction < 0 || adversary.Fraction > 1 {
+			return nil, fmt.Errorf("fraction of adversaries should be within [0, 1], got %v", adversary.Fraction)
+		}
+		honest -= int(adversary.Fraction * float64(s.Smeshers))
+	}
+	if honest <= 0 {
+		return nil, fmt.Errorf("scenario should have at least one honest smesher. adversaries control %d",
+			s.Smeshers-honest)
+	}
+	cfg := withDefaults(s.Config)
+	if cfg.Hdist < cfg.Zdist {
+		return nil, fmt.Errorf("hdist (%d) should not be lower than zdist (%d)", cfg.Hdist, cfg.Zdist)
+	}
+	if s.Seed == 0 {
+		s.Seed = time.Now().UnixNano()
+	}
+	if s.LayersPerEpoch != 0 {
+		layers, genesis := types.GetLayersPerEpoch(), types.GetEffectiveGenesis()
+		types.SetLayersPerEpoch(s.LayersPerEpoch)
+		defer func() {
+			types.SetLayersPerEpoch(layers)
+			types.SetEffectiveGenesis(genesis.Uint32())
+		}()
+	}
+	rng := rand.New(rand.NewSource(s.Seed))
+	c := newCluster(logger, rng).withConfig(cfg)
+	for _, adversary := range s.Adversaries {
+		n := int(adversary.Fraction * float64(s.Smeshers))
+		for i := 0; i < n; i++ {
+			c.addAdversary(adversary.Behavior)
+		}
+	}
+	for i := 0; i < honest; i++ {
+		c.addCore()
+	}
+	c.addHare().addBeacon()
+
+	safety := newSafetyMonitor()
+	liveness := newLivenessMonitor(types.GetEffectiveGenesis(), s.LivenessBound)
+	r := newFailingRunner(c, &reliableMessenger{}, []Monitor{safety, liveness}, rng, [2]int{s.DropBallots, 100}).
+		failable(MessageBallot{})
+	for i := 0; i < s.Layers; i++ {
+		r.next()
+		safety.Test()
+		liveness.Test()
+	}
+	return &Report{
+		Seed:     s.Seed,
+		Safety:   safety.Violations(),
+		Liveness: liveness.violations,
+		MaxLag:   liveness.maxLag,
+		Reverts:  safety.reverts,
+	}, nil
+}
+
+// withDefaults replaces zero distances and limits of the config with the default ones,
+// layer size defaults to the layer size of the model.
+func withDefaults(cfg tortoise.Config) tortoise.Config {
+	defaults := tortoise.DefaultConfig()
+	if cfg.LayerSize == 0 {
+		cfg.LayerSize = layerSize
+	}
+	if cfg.Hdist == 0 {
+		cfg.Hdist = defaults.Hdist
+	}
+	if cfg.Zdist == 0 {
+		cfg.Zdist = defaults.Zdist
+	}
+	if cfg.WindowSize == 0 {
+		cfg.WindowSize = defaults.WindowSize
+	}
+	if cfg.MaxExceptions == 0 {
+		cfg.MaxExceptions = defaults.MaxExceptions
+	}
+	return cfg
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log/logtest"
+	"github.com/spacemeshos/go-spacemesh/tortoise"
+)
+
+func TestScenarios(t *testing.T) {
+	cfg := tortoise.DefaultConfig()
+	cfg.LayerSize = 20
+	cfg.Hdist = 10
+	cfg.Zdist = 4
+	cfg.BadBeaconVoteDelayLayers = 4
+	for _, tc := range []struct {
+		desc        string
+		adversaries []Adversary
+		bound       uint32
+		live        bool
+	}{
+		{desc: "honest", bound: 1, live: true},
+		{
+			desc:        "equivocate",
+			adversaries: []Adversary{{Fraction: 0.2, Behavior: Behavior{Equivocate: true}}},
+			bound:       2, live: true,
+		},
+		{
+			desc:        "withhold",
+			adversaries: []Adversary{{Fraction: 0.2, Behavior: Behavior{Withhold: true}}},
+			bound:       2, live: true,
+		},
+		{
+			desc:        "against hare",
+			adversaries: []Adversary{{Fraction: 0.2, Behavior: Behavior{AgainstHare: true}}},
+			bound:       2, live: true,
+		},
+		{
+			desc:        "wrong beacon",
+			adversaries: []Adversary{{Fraction: 0.2, Behavior: Behavior{WrongBeacon: true}}},
+			bound:       2, live: true,
+		},
+		{
+			desc:        "late",
+			adversaries: []Adversary{{Fraction: 0.2, Behavior: Behavior{Delay: 2}}},
+			bound:       2, live: true,
+		},
+		{
+			desc:        "withhold majority",
+			adversaries: []Adversary{{Fraction: 0.6, Behavior: Behavior{Withhold: true}}},
+			bound:       2,
+		},
+		{
+			desc: "mixed",
+			adversaries: []Adversary{
+				{Fraction: 0.1, Behavior: Behavior{Equivocate: true, AgainstHare: true}},
+				{Fraction: 0.1, Behavior: Behavior{WrongBeacon: true, Delay: 1}},
+			},
+			bound: 2, live: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			report, err := Run(logtest.New(t), Scenario{
+				Seed:           1001,
+				Smeshers:       20,
+				Layers:         24,
+				LayersPerEpoch: 4,
+				Config:         cfg,
+				Adversaries:    tc.adversaries,
+				LivenessBound:  tc.bound,
+			})
+			require.NoError(t, err)
+			t.Log(report)
+			require.Empty(t, report.Safety, "seed %d", report.Seed)
+			if tc.live {
+				require.Empty(t, report.Liveness, "seed %d", report.Seed)
+			} else {
+				require.NotEmpty(t, report.Liveness, "seed %d", report.Seed)
+			}
+		})
+	}
+}
+
+func TestScenarioReproducible(t *testing.T) {
+	cfg := tortoise.DefaultConfig()
+	cfg.LayerSize = 10
+	scenario := Scenario{
+		Smeshers:       10,
+		Layers:         12,
+		LayersPerEpoch: 4,
+		Config:         cfg,
+		Adversaries: []Adversary{
+			{Fraction: 0.3, Behavior: Behavior{Equivocate: true, Delay: 1}},
+		},
+		DropBallots:   10,
+		LivenessBound: 1,
+	}
+	first, err := Run(logtest.New(t), scenario)
+	require.NoError(t, err)
+	require.NotZero(t, first.Seed)
+	scenario.Seed = first.Seed
+	second, err := Run(logtest.New(t), scenario)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
+
+func TestScenarioInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		scenario Scenario
+		err      string
+	}{
+		{desc: "no smeshers", err: "at least one smesher"},
+		{
+			desc: "no honest",
+			scenario: Scenario{
+				Smeshers:    10,
+				Adversaries: []Adversary{{Fraction: 0.5}, {Fraction: 0.5}},
+			},
+			err: "at least one honest smesher",
+		},
+		{
+			desc:     "fraction",
+			scenario: Scenario{Smeshers: 10, Adversaries: []Adversary{{Fraction: 1.5}}},
+			err:      "within [0, 1]",
+		},
+		{
+			desc:     "hdist",
+			scenario: Scenario{Smeshers: 10, Config: tortoise.Config{Hdist: 4}},
+			err:      "hdist (4) should not be lower than zdist (8)",
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := Run(logtest.New(t), tc.scenario)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestScenarioRestoresLayersPerEpoch(t *testing.T) {
+	layers, genesis := types.GetLayersPerEpoch(), types.GetEffectiveGenesis()
+	report, err := Run(logtest.New(t), Scenario{
+		Seed:           1001,
+		Smeshers:       5,
+		Layers:         4,
+		LayersPerEpoch: layers + 1,
+		// zero fields are taken from the default config
+		Config: tortoise.Config{Hdist: 12},
+	})
+	require.NoError(t, err)
+	require.Empty(t, report.Safety)
+	require.Equal(t, layers, types.GetLayersPerEpoch())
+	require.Equal(t, genesis, types.GetEffectiveGenesis())
+}
@@ -72,31 +72,66 @@ type traceRunner struct {
 	pending       map[types.BallotID]*DecodedBallot
 	assertOutputs bool
 	assertErrors  bool
+	// override is applied to the config recorded in the trace.
+	override ConfigOverride
+	// observe is called with results computed by tortoise for ResultsTrace and UpdatesTrace.
+	observe func(rst []result.Layer, err error)
 }
 
-func RunTrace(path string, breakpoint func(), opts ...Opt) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	dec := json.NewDecoder(bufio.NewReaderSize(f, 1<<20))
-	enum := newEventEnum()
-	runner := &traceRunner{
+func newTraceRunner(opts ...Opt) *traceRunner {
+	return &traceRunner{
 		opts:          opts,
 		pending:       map[types.BallotID]*DecodedBallot{},
 		assertOutputs: true,
 		assertErrors:  true,
 	}
-	for {
-		ev, err := enum.Decode(dec)
+}
+
+// run executes event and recovers from panics, so that they can be reported
+// as a failure of that event.
+func (r *traceRunner) run(i int, ev traceEvent) (err error) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			err = &replayError{Index: i, Type: ev.Type(), Err: fmt.Errorf("panic: %v", rerr)}
+		}
+	}()
+	if err := ev.Run(r); err != nil {
+		return &replayError{Index: i, Type: ev.Type(), Err: err}
+	}
+	return nil
+}
+
+// replayError is returned when event in the trace failed.
+type replayError struct {
+	Index int
+	Type  eventType
+	Err   error
+}
+
+func (e *replayError) Error() string {
+	return fmt.Sprintf("event %d (%s): %v", e.Index, eventName(e.Type), e.Err)
+}
+
+func (e *replayError) Unwrap() error {
+	return e.Err
+}
+
+func RunTrace(path string, breakpoint func(), opts ...Opt) error {
+	reader, err := openTrace(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	runner := newTraceRunner(opts...)
+	for i := 0; ; i++ {
+		ev, err := reader.Next()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil
 			}
 			return err
 		}
-		if err := ev.Run(runner); err != nil {
+		if err := runner.run(i, ev); err != nil {
 			return err
 		}
 		if breakpoint != nil {
@@ -105,6 +140,33 @@ func RunTrace(path string, breakpoint func(), opts ...Opt) error {
 	}
 }
 
+type traceReader struct {
+	f    *os.File
+	dec  *json.Decoder
+	enum eventEnum
+}
+
+func openTrace(path string) (*traceReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &traceReader{
+		f:    f,
+		dec:  json.NewDecoder(bufio.NewReaderSize(f, 1<<20)),
+		enum: newEventEnum(),
+	}, nil
+}
+
+// Next returns next event from the trace or io.EOF.
+func (r *traceReader) Next() (traceEvent, error) {
+	return r.enum.Decode(r.dec)
+}
+
+func (r *traceReader) Close() error {
+	return r.f.Close()
+}
+
 type eventType = uint16
 
 const (
@@ -125,6 +187,42 @@ const (
 	traceMalfeasence
 )
 
+func eventName(t eventType) string {
+	switch t {
+	case traceStart:
+		return "config"
+	case traceWeakCoin:
+		return "weakcoin"
+	case traceBeacon:
+		return "beacon"
+	case traceAtx:
+		return "atx"
+	case traceBallot:
+		return "ballot"
+	case traceDecode:
+		return "decode"
+	case traceStore:
+		return "store"
+	case traceEncode:
+		return "encode"
+	case traceTally:
+		return "tally"
+	case traceBlock:
+		return "block"
+	case traceHare:
+		return "hare"
+	case traceActiveset:
+		return "activeset"
+	case traceResults:
+		return "results"
+	case traceUpdates:
+		return "updates"
+	case traceMalfeasence:
+		return "malfeasance"
+	}
+	return fmt.Sprintf("unknown(%d)", t)
+}
+
 type traceEvent interface {
 	Type() eventType
 	New() traceEvent
@@ -153,14 +251,18 @@ func (c *ConfigTrace) New() traceEvent {
 func (c *ConfigTrace) Run(r *traceRunner) error {
 	types.SetLayersPerEpoch(c.EpochSize)
 	types.SetEffectiveGenesis(c.EffectiveGenesis)
-	trt, err := New(append(r.opts, WithConfig(Config{
+	cfg := Config{
 		Hdist:                    c.Hdist,
 		Zdist:                    c.Zdist,
 		WindowSize:               c.WindowSize,
 		MaxExceptions:            int(c.MaxExceptions),
 		BadBeaconVoteDelayLayers: c.BadBeaconVoteDelayLayers,
 		LayerSize:                c.LayerSize,
-	}))...)
+	}
+	if r.override != nil {
+		r.override(&cfg)
+	}
+	trt, err := New(append(r.opts, WithConfig(cfg))...)
 	if err != nil {
 		return err
 	}
@@ -283,6 +385,11 @@ func (s *StoreBallotTrace) New() traceEvent {
 func (s *StoreBallotTrace) Run(r *traceRunner) error {
 	pending, exist := r.pending[s.ID]
 	if !exist {
+		if !r.assertErrors {
+			// decoding might fail with a different build or config.
+			// the ballot is not stored in that case.
+			return nil
+		}
 		return fmt.Errorf("id %v should be pending", s.ID)
 	}
 	if s.Malicious {
@@ -379,6 +486,9 @@ func (r *ResultsTrace) New() traceEvent {
 
 func (r *ResultsTrace) Run(rt *traceRunner) error {
 	rst, err := rt.trt.Results(r.From, r.To)
+	if rt.observe != nil {
+		rt.observe(rst, err)
+	}
 	if rt.assertErrors {
 		if err := assertErrors(err, r.Error); err != nil {
 			return err
@@ -406,6 +516,9 @@ func (u *UpdatesTrace) New() traceEvent {
 
 func (u *UpdatesTrace) Run(r *traceRunner) error {
 	rst := r.trt.Updates()
+	if r.observe != nil {
+		r.observe(rst, nil)
+	}
 	if diff := cmp.Diff(rst, u.Results, cmpopts.EquateEmpty()); len(diff) > 0 && r.assertOutputs {
 		return errors.New(diff)
 	}
@@ -494,6 +607,11 @@ func (e *eventEnum) Decode(dec *json.Decoder) (traceEvent, error) {
 	if err := dec.Decode(&event); err != nil {
 		return nil, err
 	}
+	return e.New(&event)
+}
+
+// New creates event from its recorded output.
+func (e *eventEnum) New(event *output) (traceEvent, error) {
 	ev := e.types[event.Type]
 	if ev == nil {
 		return nil, fmt.Errorf("type %d is not registered", event.Type)
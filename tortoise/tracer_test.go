@@ -1,7 +1,9 @@
 package tortoise
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -82,3 +84,136 @@ func TestData(t *testing.T) {
 		})
 	}
 }
+
+func TestTraceTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tortoise.trace")
+	const size = 10
+	s := sim.New(
+		sim.WithLayerSize(size),
+	)
+	s.Setup()
+
+	ctx := context.Background()
+	cfg := defaultTestConfig()
+	cfg.LayerSize = size
+	trt := tortoiseFromSimState(t, s.GetState(0), WithConfig(cfg), WithTracer(WithOutput(path)))
+	var last types.LayerID
+	for i := 0; i < 30; i++ {
+		last = s.Next()
+	}
+	trt.TallyVotes(ctx, last)
+	trt.Updates()
+	_, err := trt.Results(types.GetEffectiveGenesis()+1, trt.LatestComplete())
+	require.NoError(t, err)
+
+	// append results that were not produced by tortoise
+	corrupted := filepath.Join(t.TempDir(), "corrupted.trace")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	events := bytes.Count(data, []byte{'\n'})
+	results := &ResultsTrace{From: types.GetEffectiveGenesis() + 1, To: trt.LatestComplete()}
+	raw, err := json.Marshal(results)
+	require.NoError(t, err)
+	line, err := json.Marshal(output{Type: traceResults, Event: raw})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(corrupted, append(append(data, line...), '\n'), 0o600))
+
+	t.Run("diff", func(t *testing.T) {
+		div, err := DiffTrace(path, nil, func(cfg *Config) {}, WithLogger(logtest.New(t)))
+		require.NoError(t, err)
+		require.Nil(t, div)
+	})
+	t.Run("record", func(t *testing.T) {
+		recorded := filepath.Join(t.TempDir(), "recorded.trace")
+		require.NoError(t, RecordTrace(path, recorded, nil, WithLogger(logtest.New(t))))
+		div, err := DiffRecordedTraces(path, recorded)
+		require.NoError(t, err)
+		require.Nil(t, div)
+		require.NoError(t, RunTrace(recorded, nil, WithLogger(logtest.New(t))))
+	})
+	t.Run("diff recorded", func(t *testing.T) {
+		div, err := DiffRecordedTraces(corrupted, corrupted)
+		require.NoError(t, err)
+		require.Nil(t, div)
+
+		// corrupted trace has one more results event
+		_, err = DiffRecordedTraces(path, corrupted)
+		require.Error(t, err)
+
+		other := filepath.Join(t.TempDir(), "other.trace")
+		require.NoError(t, RecordTrace(corrupted, other, nil, WithLogger(logtest.New(t))))
+		div, err = DiffRecordedTraces(corrupted, other)
+		require.NoError(t, err)
+		require.NotNil(t, div)
+		require.Equal(t, events, div.Event)
+		require.False(t, div.Updates)
+		require.Equal(t, results.From, div.Layer)
+		require.Empty(t, div.Left)
+		require.NotEmpty(t, div.Right)
+	})
+	t.Run("minimize", func(t *testing.T) {
+		require.Error(t, RunTrace(corrupted, nil, WithLogger(logtest.New(t))))
+		minimized := filepath.Join(t.TempDir(), "minimized.trace")
+		rst, err := MinimizeTrace(corrupted, minimized, WithLogger(logtest.New(t)))
+		require.NoError(t, err)
+		require.Equal(t, events+1, rst.Original)
+		require.Less(t, rst.Minimized, rst.Original)
+		require.Error(t, rst.Err)
+		require.Error(t, RunTrace(minimized, nil, WithLogger(logtest.New(t))))
+
+		_, err = MinimizeTrace(path, minimized, WithLogger(logtest.New(t)))
+		require.ErrorContains(t, err, "trace doesn't fail")
+	})
+	t.Run("summary", func(t *testing.T) {
+		summary, err := SummarizeTrace(path)
+		require.NoError(t, err)
+		require.NotNil(t, summary.Config)
+		require.Equal(t, cfg.Hdist, summary.Config.Hdist)
+		require.NotEmpty(t, summary.Atxs)
+		require.NotEmpty(t, summary.Layers)
+		verified, valid := 0, 0
+		for _, layer := range summary.Layers {
+			if layer.Verified {
+				verified++
+			}
+			for _, block := range layer.Decisions {
+				if block.Valid {
+					valid++
+				}
+			}
+		}
+		require.NotZero(t, verified)
+		require.NotZero(t, valid)
+	})
+}
+
+func TestDdmin(t *testing.T) {
+	for _, tc := range []struct {
+		desc   string
+		n      int
+		expect []int
+	}{
+		{desc: "empty", n: 10},
+		{desc: "single", n: 10, expect: []int{7}},
+		{desc: "pair", n: 100, expect: []int{3, 91}},
+		{desc: "all", n: 4, expect: []int{0, 1, 2, 3}},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			rst := ddmin(tc.n, func(indexes []int) bool {
+				for _, expected := range tc.expect {
+					found := false
+					for _, i := range indexes {
+						found = found || i == expected
+					}
+					if !found {
+						return false
+					}
+				}
+				return true
+			})
+			require.Equal(t, tc.expect, rst)
+		})
+	}
+}
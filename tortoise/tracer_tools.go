@@ -0,0 +1,514 @@
+package tortoise
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/common/types/result"
+)
+
+// ConfigOverride modifies config recorded in the trace before tortoise is created.
+type ConfigOverride func(*Config)
+
+// TraceDivergence describes the first results that differ between two replays of the trace.
+type TraceDivergence struct {
+	// Event is an index of the ResultsTrace or UpdatesTrace event in the trace.
+	Event int
+	// Updates is true if divergence was found in the UpdatesTrace.
+	Updates  bool
+	From, To types.LayerID
+	// Layer is the first layer with a different decision.
+	Layer       types.LayerID
+	Left, Right []result.Layer
+	LeftError   string
+	RightError  string
+	Diff        string
+}
+
+// DiffTrace replays the same trace with two tortoise instances that are configured
+// with left and right overrides, and returns the first divergent results.
+// Returns nil if all results are the same.
+func DiffTrace(path string, left, right ConfigOverride, opts ...Opt) (*TraceDivergence, error) {
+	lreader, err := openTrace(path)
+	if err != nil {
+		return nil, err
+	}
+	defer lreader.Close()
+	rreader, err := openTrace(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rreader.Close()
+
+	var lrst, rrst []result.Layer
+	var lerr, rerr error
+	lrunner := newTraceRunner(opts...)
+	lrunner.assertOutputs, lrunner.assertErrors = false, false
+	lrunner.override = left
+	lrunner.observe = func(rst []result.Layer, err error) {
+		lrst, lerr = rst, err
+	}
+	rrunner := newTraceRunner(opts...)
+	rrunner.assertOutputs, rrunner.assertErrors = false, false
+	rrunner.override = right
+	rrunner.observe = func(rst []result.Layer, err error) {
+		rrst, rerr = rst, err
+	}
+	for i := 0; ; i++ {
+		lev, err := lreader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		rev, err := rreader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if err := lrunner.run(i, lev); err != nil {
+			return nil, fmt.Errorf("left: %w", err)
+		}
+		if err := rrunner.run(i, rev); err != nil {
+			return nil, fmt.Errorf("right: %w", err)
+		}
+		var from, to types.LayerID
+		switch ev := lev.(type) {
+		case *ResultsTrace:
+			from, to = ev.From, ev.To
+		case *UpdatesTrace:
+			from, to = ev.From, ev.To
+		default:
+			continue
+		}
+		if div := diffResults(lrst, rrst, errString(lerr), errString(rerr)); div != nil {
+			div.Event = i
+			div.Updates = lev.Type() == traceUpdates
+			div.From, div.To = from, to
+			return div, nil
+		}
+	}
+}
+
+// DiffRecordedTraces compares results recorded in two traces.
+// Traces are expected to be recorded from the same inputs, for example
+// by replaying the same trace with RecordTrace using different builds.
+func DiffRecordedTraces(left, right string) (*TraceDivergence, error) {
+	lreader, err := openTrace(left)
+	if err != nil {
+		return nil, err
+	}
+	defer lreader.Close()
+	rreader, err := openTrace(right)
+	if err != nil {
+		return nil, err
+	}
+	defer rreader.Close()
+
+	lindex, rindex := 0, 0
+	for ; ; lindex, rindex = lindex+1, rindex+1 {
+		lev, lupdates, lerr := nextResults(lreader, &lindex)
+		rev, _, rerr := nextResults(rreader, &rindex)
+		if errors.Is(lerr, io.EOF) && errors.Is(rerr, io.EOF) {
+			return nil, nil
+		} else if errors.Is(lerr, io.EOF) {
+			return nil, fmt.Errorf("%s has less results than %s", left, right)
+		} else if errors.Is(rerr, io.EOF) {
+			return nil, fmt.Errorf("%s has less results than %s", right, left)
+		} else if lerr != nil {
+			return nil, lerr
+		} else if rerr != nil {
+			return nil, rerr
+		}
+		if lev.From != rev.From || lev.To != rev.To {
+			return nil, fmt.Errorf("event %d: requested range [%d, %d] doesn't match [%d, %d] in event %d",
+				lindex, lev.From, lev.To, rev.From, rev.To, rindex)
+		}
+		if div := diffResults(lev.Results, rev.Results, lev.Error, rev.Error); div != nil {
+			div.Event = lindex
+			div.Updates = lupdates
+			div.From, div.To = lev.From, lev.To
+			return div, nil
+		}
+	}
+}
+
+// nextResults skips events until ResultsTrace or UpdatesTrace is found.
+// Index is advanced to the index of the returned event.
+func nextResults(reader *traceReader, index *int) (*ResultsTrace, bool, error) {
+	for ; ; *index++ {
+		ev, err := reader.Next()
+		if err != nil {
+			return nil, false, err
+		}
+		switch ev := ev.(type) {
+		case *ResultsTrace:
+			return ev, false, nil
+		case *UpdatesTrace:
+			return &ev.ResultsTrace, true, nil
+		}
+	}
+}
+
+func diffResults(left, right []result.Layer, lerr, rerr string) *TraceDivergence {
+	diff := cmp.Diff(left, right, cmpopts.EquateEmpty())
+	if len(diff) == 0 && lerr == rerr {
+		return nil
+	}
+	div := &TraceDivergence{
+		Left:       left,
+		Right:      right,
+		LeftError:  lerr,
+		RightError: rerr,
+		Diff:       diff,
+	}
+	for i := 0; i < len(left) || i < len(right); i++ {
+		if i >= len(left) {
+			div.Layer = right[i].Layer
+		} else if i >= len(right) {
+			div.Layer = left[i].Layer
+		} else if !cmp.Equal(left[i], right[i], cmpopts.EquateEmpty()) {
+			div.Layer = min(left[i].Layer, right[i].Layer)
+		} else {
+			continue
+		}
+		return div
+	}
+	return div
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// RecordTrace replays trace without asserting recorded outputs, and writes a new trace
+// to out with outputs computed by this build of the tortoise.
+func RecordTrace(path, out string, override ConfigOverride, opts ...Opt) error {
+	reader, err := openTrace(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	runner := newTraceRunner(append(opts, WithTracer(WithOutput(out)))...)
+	runner.assertOutputs, runner.assertErrors = false, false
+	runner.override = override
+	for i := 0; ; i++ {
+		ev, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := runner.run(i, ev); err != nil {
+			return err
+		}
+	}
+}
+
+// MinimizeResult is returned by MinimizeTrace.
+type MinimizeResult struct {
+	Original  int
+	Minimized int
+	// Err is the failure reproduced by the minimized trace.
+	Err error
+}
+
+// MinimizeTrace shrinks failing trace to the smallest subset of events that still fails
+// on the same event, and writes it to out.
+//
+// The first event in the trace must be ConfigTrace. Events recorded after the failure are dropped,
+// and events before it are removed using delta debugging. Events are streamed from the trace
+// on every replay, only indexes of the selected events are kept in memory.
+func MinimizeTrace(path, out string, opts ...Opt) (*MinimizeResult, error) {
+	var (
+		total   int
+		failure *replayError
+		enum    = newEventEnum()
+		runner  = newTraceRunner(opts...)
+	)
+	if err := streamTrace(path, func(i int, event *output) (bool, error) {
+		total++
+		if i == 0 && event.Type != traceStart {
+			return false, errors.New("trace should start with config event")
+		}
+		if failure != nil {
+			// events after the failure are only counted
+			return true, nil
+		}
+		ev, err := enum.New(event)
+		if err != nil {
+			return false, err
+		}
+		if err := runner.run(i, ev); !errors.As(err, &failure) && err != nil {
+			return false, err
+		}
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, errors.New("trace should start with config event")
+	}
+	if failure == nil {
+		return nil, errors.New("trace doesn't fail")
+	}
+	if failure.Index == 0 {
+		return nil, fmt.Errorf("trace fails on config event: %w", failure)
+	}
+	// candidates are events in the range [1, failure.Index)
+	subset := func(candidates []int) []int {
+		rst := make([]int, 0, len(candidates)+2)
+		rst = append(rst, 0)
+		for _, i := range candidates {
+			rst = append(rst, i+1)
+		}
+		return append(rst, failure.Index)
+	}
+	minimized := subset(ddmin(failure.Index-1, func(candidates []int) bool {
+		replayed := subset(candidates)
+		var rerr *replayError
+		err := replayEvents(path, replayed, opts...)
+		return errors.As(err, &rerr) && rerr.Index == len(replayed)-1 && rerr.Type == failure.Type
+	}))
+	if err := copyEvents(path, out, minimized); err != nil {
+		return nil, err
+	}
+	return &MinimizeResult{
+		Original:  total,
+		Minimized: len(minimized),
+		Err:       replayEvents(path, minimized, opts...),
+	}, nil
+}
+
+// ddmin implements delta debugging algorithm. It returns a 1-minimal subset of indexes in range [0, n)
+// for which test returns true. Test must return true for the full range.
+func ddmin(n int, test func([]int) bool) []int {
+	current := make([]int, n)
+	for i := range current {
+		current[i] = i
+	}
+	if test(nil) {
+		return nil
+	}
+	granularity := 2
+	for len(current) >= 2 {
+		chunks := split(current, granularity)
+		reduced := false
+		for _, chunk := range chunks {
+			if test(chunk) {
+				current = chunk
+				granularity = 2
+				reduced = true
+				break
+			}
+		}
+		if !reduced && granularity > 2 {
+			for i := range chunks {
+				complement := make([]int, 0, len(current)-len(chunks[i]))
+				for j, chunk := range chunks {
+					if i != j {
+						complement = append(complement, chunk...)
+					}
+				}
+				if test(complement) {
+					current = complement
+					granularity = max(granularity-1, 2)
+					reduced = true
+					break
+				}
+			}
+		}
+		if !reduced {
+			if granularity >= len(current) {
+				break
+			}
+			granularity = min(2*granularity, len(current))
+		}
+	}
+	return current
+}
+
+func split(indexes []int, n int) [][]int {
+	chunks := make([][]int, 0, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(indexes)-start)/(n-i)
+		chunks = append(chunks, indexes[start:end])
+		start = end
+	}
+	return chunks
+}
+
+// streamTrace decodes events from the trace one by one and passes them to fn with their index.
+// Reading stops when fn returns false or the trace ends.
+func streamTrace(path string, fn func(int, *output) (bool, error)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReaderSize(f, 1<<20))
+	for i := 0; ; i++ {
+		var event output
+		if err := dec.Decode(&event); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if next, err := fn(i, &event); err != nil || !next {
+			return err
+		}
+	}
+}
+
+// replayEvents replays events with sorted indexes from the trace with assertions enabled,
+// using fresh tortoise instance. Errors refer to the position of the event in indexes.
+func replayEvents(path string, indexes []int, opts ...Opt) error {
+	enum := newEventEnum()
+	runner := newTraceRunner(opts...)
+	pos := 0
+	return streamTrace(path, func(i int, event *output) (bool, error) {
+		if i != indexes[pos] {
+			return true, nil
+		}
+		ev, err := enum.New(event)
+		if err != nil {
+			return false, err
+		}
+		if err := runner.run(pos, ev); err != nil {
+			return false, err
+		}
+		pos++
+		return pos < len(indexes), nil
+	})
+}
+
+// copyEvents writes events with sorted indexes from the trace to out.
+func copyEvents(path, out string, indexes []int) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := bufio.NewWriter(f)
+	enc := json.NewEncoder(buf)
+	pos := 0
+	if err := streamTrace(path, func(i int, event *output) (bool, error) {
+		if i != indexes[pos] {
+			return true, nil
+		}
+		if err := enc.Encode(event); err != nil {
+			return false, err
+		}
+		pos++
+		return pos < len(indexes), nil
+	}); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// LayerSummary is a summary of inputs and decisions for a single layer in the trace.
+type LayerSummary struct {
+	Layer    types.LayerID
+	Ballots  int
+	Blocks   int
+	Hare     *types.BlockID
+	Coin     *bool
+	Verified bool
+	Opinion  types.Hash32
+	// Decisions are blocks from the last results for this layer recorded in the trace.
+	Decisions []result.Block
+}
+
+// TraceSummary is a summary of the trace computed without replaying it.
+type TraceSummary struct {
+	Config *ConfigTrace
+	Events map[string]int
+	// Atxs is a number of atxs per target epoch.
+	Atxs   map[types.EpochID]int
+	Layers []LayerSummary
+}
+
+// SummarizeTrace reads trace and collects per-layer inputs and the last recorded decisions.
+func SummarizeTrace(path string) (*TraceSummary, error) {
+	reader, err := openTrace(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	summary := &TraceSummary{
+		Events: map[string]int{},
+		Atxs:   map[types.EpochID]int{},
+	}
+	layers := map[types.LayerID]*LayerSummary{}
+	layer := func(lid types.LayerID) *LayerSummary {
+		if _, exist := layers[lid]; !exist {
+			layers[lid] = &LayerSummary{Layer: lid}
+		}
+		return layers[lid]
+	}
+	for {
+		ev, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		summary.Events[eventName(ev.Type())]++
+		switch ev := ev.(type) {
+		case *ConfigTrace:
+			summary.Config = ev
+		case *AtxTrace:
+			summary.Atxs[ev.Header.TargetEpoch]++
+		case *BallotTrace:
+			layer(ev.Ballot.Layer).Ballots++
+		case *DecodeBallotTrace:
+			if len(ev.Error) == 0 {
+				layer(ev.Ballot.Layer).Ballots++
+			}
+		case *BlockTrace:
+			layer(ev.Header.LayerID).Blocks++
+		case *HareTrace:
+			vote := ev.Vote
+			layer(ev.Layer).Hare = &vote
+		case *WeakCoinTrace:
+			coin := ev.Coin
+			layer(ev.Layer).Coin = &coin
+		case *ResultsTrace:
+			summarizeResults(layer, ev.Results)
+		case *UpdatesTrace:
+			summarizeResults(layer, ev.Results)
+		}
+	}
+	for _, l := range layers {
+		summary.Layers = append(summary.Layers, *l)
+	}
+	sort.Slice(summary.Layers, func(i, j int) bool {
+		return summary.Layers[i].Layer < summary.Layers[j].Layer
+	})
+	return summary, nil
+}
+
+func summarizeResults(layer func(types.LayerID) *LayerSummary, rst []result.Layer) {
+	for _, rlayer := range rst {
+		l := layer(rlayer.Layer)
+		l.Verified = rlayer.Verified
+		l.Opinion = rlayer.Opinion
+		l.Decisions = rlayer.Blocks
+	}
+}
@@ -1,10 +1,13 @@
 package txs
 
 import (
+	"bytes"
 	"container/list"
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -20,6 +23,9 @@ import (
 const (
 	maxTXsPerAcct  = 100
 	maxTXsPerNonce = 100
+	// replaceFeeBump is the minimal fee increase (in percents) required to replace
+	// a pending transaction with the same nonce.
+	replaceFeeBump = 10
 )
 
 var (
@@ -27,6 +33,8 @@ var (
 	errInsufficientBalance = errors.New("insufficient balance")
 	errTooManyNonce        = errors.New("account has too many nonce pending")
 	errLayerNotInOrder     = errors.New("layers not applied in order")
+	errExpired             = errors.New("transaction expired")
+	errUnderpriced         = errors.New("replacement transaction underpriced")
 )
 
 // a candidate for the mempool.
@@ -72,7 +80,8 @@ type accountCache struct {
 	// https://github.com/spacemeshos/go-spacemesh/issues/3668
 	moreInDB bool
 
-	cachedTXs map[types.TransactionID]*NanoTX // shared with the cache instance
+	cachedTXs map[types.TransactionID]*NanoTX   // shared with the cache instance
+	evicted   map[types.TransactionID]evictedTX // shared with the cache instance
 }
 
 func (ac *accountCache) nextNonce() uint64 {
@@ -139,6 +148,15 @@ func (ac *accountCache) accept(logger log.Log, ntx *NanoTX, blockSeed []byte) er
 	} else if prevCand := prev.Value.(*candidate); prevCand.nonce() < ntx.Nonce {
 		added = ac.txsByNonce.InsertAfter(cand, prev)
 	} else { // existing nonce
+		if len(blockSeed) == 0 && !ntx.Replaces(prevCand.best) {
+			logger.With().Debug("replacement transaction underpriced",
+				ntx.ID,
+				log.Stringer("pending", prevCand.id()),
+				log.Uint64("nonce", ntx.Nonce),
+				log.Uint64("fee", ntx.Fee()),
+				log.Uint64("pending_fee", prevCand.best.Fee()))
+			return errUnderpriced
+		}
 		if !ntx.Better(prevCand.best, blockSeed) {
 			return nil
 		}
@@ -307,6 +325,8 @@ func (ac *accountCache) add(logger log.Log, tx *types.Transaction, received time
 			mempoolTxCount.WithLabelValues(tooManyNonce).Inc()
 		} else if errors.Is(err, errInsufficientBalance) {
 			mempoolTxCount.WithLabelValues(balanceTooSmall).Inc()
+		} else if errors.Is(err, errUnderpriced) {
+			mempoolTxCount.WithLabelValues(underpriced).Inc()
 		}
 		return err
 	}
@@ -320,26 +340,28 @@ func (ac *accountCache) addPendingFromNonce(logger log.Log, db *sql.Database, no
 		logger.With().Error("failed to get more pending txs from db", log.Err(err))
 		return err
 	}
+	mtxs = dropEvicted(dropExpired(logger, mtxs, applied), ac.evicted)
 
 	if len(mtxs) == 0 {
 		ac.moreInDB = false
 		return nil
 	}
 
-	if applied != 0 {
-		for _, mtx := range mtxs {
-			if mtx.State == types.APPLIED {
-				continue
-			}
-			nextLayer, nextBlock, err := getNextIncluded(db, mtx.ID, applied)
-			if err != nil {
-				return err
-			}
-			mtx.LayerID = nextLayer
-			mtx.BlockID = nextBlock
-			if nextLayer != 0 {
-				logger.With().Debug("next layer found", mtx.ID, nextLayer)
-			}
+	// proposals and blocks are looked up even if no layer was applied yet, as the account may be reset
+	// by eviction before that. otherwise transactions included into proposals would look as if they
+	// were not included, and would be selected into proposals and evicted again.
+	for _, mtx := range mtxs {
+		if mtx.State == types.APPLIED {
+			continue
+		}
+		nextLayer, nextBlock, err := getNextIncluded(db, mtx.ID, applied)
+		if err != nil {
+			return err
+		}
+		mtx.LayerID = nextLayer
+		mtx.BlockID = nextBlock
+		if nextLayer != 0 {
+			logger.With().Debug("next layer found", mtx.ID, nextLayer)
 		}
 	}
 
@@ -406,15 +428,65 @@ func (ac *accountCache) shouldEvict() bool {
 	return ac.txsByNonce.Len() == 0 && !ac.moreInDB
 }
 
+// txs returns the best transactions for every nonce of the account.
+func (ac *accountCache) txs() map[types.TransactionID]*NanoTX {
+	rst := make(map[types.TransactionID]*NanoTX, ac.txsByNonce.Len())
+	for e := ac.txsByNonce.Front(); e != nil; e = e.Next() {
+		cand := e.Value.(*candidate)
+		rst[cand.id()] = cand.best
+	}
+	return rst
+}
+
+// hasExpired returns true if any of the candidates can't be applied after the applied layer.
+func (ac *accountCache) hasExpired(applied types.LayerID) bool {
+	for e := ac.txsByNonce.Front(); e != nil; e = e.Next() {
+		if e.Value.(*candidate).best.LayerLimits.Expired(applied.Add(1)) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropExpired removes transactions that can't be applied after the applied layer.
+// They are kept in the database, but will never be considered for the mempool again.
+func dropExpired(logger log.Log, mtxs []*types.MeshTransaction, applied types.LayerID) []*types.MeshTransaction {
+	rst := mtxs[:0]
+	for _, mtx := range mtxs {
+		if mtx.LayerLimits.Expired(applied.Add(1)) {
+			logger.With().Debug("dropped expired transaction",
+				mtx.ID,
+				log.Uint64("nonce", mtx.Nonce),
+				log.Uint32("max_layer", mtx.LayerLimits.Max),
+				log.Stringer("applied", applied))
+			continue
+		}
+		rst = append(rst, mtx)
+	}
+	return rst
+}
+
 type stateFunc func(types.Address) (uint64, uint64)
 
 type Cache struct {
 	logger log.Log
 	stateF stateFunc
 
-	mu        sync.Mutex
+	mu sync.Mutex
+	// applied is the last applied layer. transactions that expire before the next layer
+	// are not accepted to the cache.
+	applied   types.LayerID
 	pending   map[types.Address]*accountCache
 	cachedTXs map[types.TransactionID]*NanoTX // shared with accountCache instances
+	// evicted transactions stay in the database, but are not loaded into the cache
+	// until the node is restarted. Shared with accountCache instances.
+	evicted map[types.TransactionID]evictedTX
+}
+
+// evictedTX is kept until the nonce of the principal is above the nonce of the transaction.
+type evictedTX struct {
+	principal types.Address
+	nonce     uint64
 }
 
 func NewCache(s stateFunc, logger log.Log) *Cache {
@@ -423,6 +495,26 @@ func NewCache(s stateFunc, logger log.Log) *Cache {
 		stateF:    s,
 		pending:   make(map[types.Address]*accountCache),
 		cachedTXs: make(map[types.TransactionID]*NanoTX),
+		evicted:   make(map[types.TransactionID]evictedTX),
+	}
+}
+
+func dropEvicted(mtxs []*types.MeshTransaction, evicted map[types.TransactionID]evictedTX) []*types.MeshTransaction {
+	if len(evicted) == 0 {
+		return mtxs
+	}
+	return slices.DeleteFunc(mtxs, func(mtx *types.MeshTransaction) bool {
+		_, ok := evicted[mtx.ID]
+		return ok
+	})
+}
+
+// pruneEvicted forgets evicted transactions that can't be applied anymore.
+func (c *Cache) pruneEvicted() {
+	for tid, tx := range c.evicted {
+		if nonce, _ := c.stateF(tx.principal); nonce > tx.nonce {
+			delete(c.evicted, tid)
+		}
 	}
 }
 
@@ -465,8 +557,12 @@ func (c *Cache) buildFromScratch(db *sql.Database) error {
 		if err != nil {
 			return fmt.Errorf("get pending addr=%s nonce=%d %w", addr.Address, addr.Nonce, err)
 		}
-		rst = append(rst, txs...)
+		rst = append(rst, dropExpired(c.logger, txs, applied)...)
 	}
+	c.mu.Lock()
+	c.applied = applied
+	rst = dropEvicted(rst, c.evicted)
+	c.mu.Unlock()
 	for _, mtx := range rst {
 		if mtx.State == types.APPLIED {
 			continue
@@ -525,6 +621,7 @@ func (c *Cache) createAcctIfNotPresent(addr types.Address) {
 			startBalance: balance,
 			txsByNonce:   list.New(),
 			cachedTXs:    c.cachedTXs,
+			evicted:      c.evicted,
 		}
 	}
 }
@@ -560,10 +657,22 @@ func (c *Cache) Add(ctx context.Context, db *sql.Database, tx *types.Transaction
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	principal := tx.Principal
-	c.createAcctIfNotPresent(principal)
-	defer c.cleanupAccounts(map[types.Address]struct{}{principal: {}})
 	logger := c.logger.WithContext(ctx).WithFields(principal)
-	err := c.pending[principal].add(logger, tx, received)
+	var err error
+	if tx.LayerLimits.Expired(c.applied.Add(1)) {
+		logger.With().Debug("transaction expired",
+			tx.ID,
+			log.Uint32("max_layer", tx.LayerLimits.Max),
+			log.Stringer("applied", c.applied))
+		mempoolTxCount.WithLabelValues(expired).Inc()
+		err = errExpired
+	} else {
+		c.createAcctIfNotPresent(principal)
+		defer c.cleanupAccounts(map[types.Address]struct{}{principal: {}})
+		before := mempoolSnapshot{principal: c.pending[principal].txs()}
+		err = c.pending[principal].add(logger, tx, received)
+		c.reportUpdates(before, nil)
+	}
 	if acceptable(err) {
 		err = nil
 		mempoolTxCount.WithLabelValues(accepted).Inc()
@@ -635,10 +744,11 @@ func (c *Cache) updateLayer(lid types.LayerID, bid types.BlockID, tids []types.T
 	return nil
 }
 
-func (c *Cache) applyEmptyLayer(db *sql.Database, lid types.LayerID) error {
+func (c *Cache) applyEmptyLayer(logger log.Log, db *sql.Database, lid types.LayerID) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.applied = lid
 	for tid, ntx := range c.cachedTXs {
 		if ntx.Layer == lid {
 			nbid, nlid, err := getNextIncluded(db, tid, lid)
@@ -648,6 +758,22 @@ func (c *Cache) applyEmptyLayer(db *sql.Database, lid types.LayerID) error {
 			ntx.UpdateLayer(nbid, nlid)
 		}
 	}
+	toCleanup := make(map[types.Address]struct{})
+	defer c.cleanupAccounts(toCleanup)
+	before := make(mempoolSnapshot)
+	defer c.reportUpdates(before, nil)
+	for principal, accCache := range c.pending {
+		if !accCache.hasExpired(lid) {
+			continue
+		}
+		toCleanup[principal] = struct{}{}
+		before[principal] = accCache.txs()
+		nextNonce, balance := c.stateF(principal)
+		if err := accCache.resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
+			logger.With().Error("failed to reset cache for principal", principal, log.Err(err))
+			return err
+		}
+	}
 	return nil
 }
 
@@ -666,11 +792,13 @@ func (c *Cache) ApplyLayer(
 	}
 
 	if bid == types.EmptyBlockID {
-		return c.applyEmptyLayer(db, lid)
+		return c.applyEmptyLayer(logger, db, lid)
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.applied = lid
+	defer c.pruneEvicted()
 
 	toCleanup := make(map[types.Address]struct{})
 	toReset := make(map[types.Address]struct{})
@@ -724,9 +852,12 @@ func (c *Cache) ApplyLayer(
 		toReset[tx.Principal] = struct{}{}
 	}
 	defer c.cleanupAccounts(toCleanup)
+	before := make(mempoolSnapshot, len(byPrincipal)+len(toReset))
+	defer c.reportUpdates(before, nil)
 
 	for principal := range byPrincipal {
 		c.createAcctIfNotPresent(principal)
+		before[principal] = c.pending[principal].txs()
 		nextNonce, balance := c.stateF(principal)
 		logger.With().Debug("new account nonce/balance",
 			principal,
@@ -744,11 +875,12 @@ func (c *Cache) ApplyLayer(
 		if _, ok := toCleanup[principal]; ok {
 			continue
 		}
-		if accCache.moreInDB {
+		if accCache.moreInDB || accCache.hasExpired(lid) {
 			toReset[principal] = struct{}{}
 		}
 	}
 	for principal := range toReset {
+		before[principal] = c.pending[principal].txs()
 		nextNonce, balance := c.stateF(principal)
 		t2 := time.Now()
 		if err := c.pending[principal].resetAfterApply(logger, db, nextNonce, balance, lid); err != nil {
@@ -765,10 +897,24 @@ func (c *Cache) RevertToLayer(db *sql.Database, revertTo types.LayerID) error {
 		return err
 	}
 
+	c.mu.Lock()
+	before := make(mempoolSnapshot, len(c.pending))
+	for principal, accCache := range c.pending {
+		before[principal] = accCache.txs()
+	}
+	c.mu.Unlock()
 	if err := c.buildFromScratch(db); err != nil {
 		c.logger.With().Error("failed to build from scratch after revert", log.Err(err))
 		return err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for principal := range c.pending {
+		if _, ok := before[principal]; !ok {
+			before[principal] = nil
+		}
+	}
+	c.reportUpdates(before, nil)
 	return nil
 }
 
@@ -800,6 +946,199 @@ func (c *Cache) GetMempool(logger log.Log) map[types.Address][]*NanoTX {
 	return all
 }
 
+// PendingAccount is a snapshot of the account transactions in the mempool.
+type PendingAccount struct {
+	Address types.Address
+	// NextNonce and Balance are projected after all pending transactions are applied.
+	NextNonce    uint64
+	Balance      uint64
+	Transactions []NanoTX
+}
+
+// Pending returns accounts with transactions in the mempool ordered by address.
+// If addresses are provided only those accounts are returned.
+func (c *Cache) Pending(addrs ...types.Address) []PendingAccount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(addrs) == 0 {
+		addrs = make([]types.Address, 0, len(c.pending))
+		for addr := range c.pending {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	rst := make([]PendingAccount, 0, len(addrs))
+	for _, addr := range addrs {
+		accCache, ok := c.pending[addr]
+		if !ok || accCache.txsByNonce.Len() == 0 {
+			continue
+		}
+		acc := PendingAccount{
+			Address:      addr,
+			NextNonce:    accCache.nextNonce(),
+			Balance:      accCache.availBalance(),
+			Transactions: make([]NanoTX, 0, accCache.txsByNonce.Len()),
+		}
+		for e := accCache.txsByNonce.Front(); e != nil; e = e.Next() {
+			acc.Transactions = append(acc.Transactions, *e.Value.(*candidate).best)
+		}
+		rst = append(rst, acc)
+	}
+	return rst
+}
+
+// Evict removes pending transactions from the mempool and returns evicted transactions.
+// Transactions are kept in the database and are not loaded into the mempool until restart.
+// Transactions that are already included in proposals or blocks are not evicted.
+func (c *Cache) Evict(ctx context.Context, db *sql.Database, tids []types.TransactionID) ([]types.TransactionID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		logger  = c.logger.WithContext(ctx)
+		evicted = make(map[types.TransactionID]struct{}, len(tids))
+		rst     = make([]types.TransactionID, 0, len(tids))
+		toReset = make(map[types.Address]struct{})
+	)
+	for _, tid := range tids {
+		mtx, err := transactions.Get(db, tid)
+		if errors.Is(err, sql.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return rst, err
+		}
+		if mtx.State != types.MEMPOOL || mtx.TxHeader == nil {
+			continue
+		}
+		if _, ok := c.evicted[tid]; ok {
+			continue
+		}
+		included, _, err := getNextIncluded(db, tid, c.applied)
+		if err != nil {
+			return rst, err
+		}
+		if included != 0 {
+			logger.With().Debug("transaction is included and can't be evicted", tid, included)
+			continue
+		}
+		c.evicted[tid] = evictedTX{principal: mtx.Principal, nonce: mtx.Nonce}
+		logger.With().Info("evicted transaction", tid, mtx.Principal, log.Uint64("nonce", mtx.Nonce))
+		evicted[tid] = struct{}{}
+		rst = append(rst, tid)
+		if c.has(tid) {
+			toReset[mtx.Principal] = struct{}{}
+		}
+	}
+	defer c.cleanupAccounts(toReset)
+	before := make(mempoolSnapshot, len(toReset))
+	defer c.reportUpdates(before, evicted)
+	for principal := range toReset {
+		accCache := c.pending[principal]
+		before[principal] = accCache.txs()
+		if err := accCache.resetAfterApply(logger, db, accCache.startNonce, accCache.startBalance, c.applied); err != nil {
+			return rst, err
+		}
+	}
+	return rst, nil
+}
+
+// mempoolSnapshot is a set of transactions in the mempool by principal.
+type mempoolSnapshot map[types.Address]map[types.TransactionID]*NanoTX
+
+// reportUpdates reports transactions that were added to or removed from the mempool
+// since the snapshot was taken. Must be called before accounts are cleaned up.
+func (c *Cache) reportUpdates(before mempoolSnapshot, evicted map[types.TransactionID]struct{}) {
+	for principal, prev := range before {
+		var (
+			current   map[types.TransactionID]*NanoTX
+			nextNonce uint64
+			nonces    = make(map[uint64]struct{})
+		)
+		if accCache, ok := c.pending[principal]; ok {
+			current = accCache.txs()
+			nextNonce = accCache.startNonce
+		}
+		for tid, ntx := range current {
+			nonces[ntx.Nonce] = struct{}{}
+			if _, ok := prev[tid]; !ok {
+				events.ReportMempool(mempoolEvent(ntx, true, ""))
+			}
+		}
+		for tid, ntx := range prev {
+			if _, ok := current[tid]; ok {
+				continue
+			}
+			var reason string
+			if _, ok := evicted[tid]; ok {
+				reason = events.MempoolEvicted
+			} else if ntx.LayerLimits.Expired(c.applied.Add(1)) {
+				reason = events.MempoolExpired
+			} else if ntx.Nonce < nextNonce {
+				reason = events.MempoolApplied
+			} else if _, ok := nonces[ntx.Nonce]; ok {
+				reason = events.MempoolReplaced
+			} else {
+				reason = events.MempoolInfeasible
+			}
+			events.ReportMempool(mempoolEvent(ntx, false, reason))
+		}
+	}
+}
+
+func mempoolEvent(ntx *NanoTX, added bool, reason string) events.EventMempool {
+	return events.EventMempool{
+		ID:        ntx.ID,
+		Principal: ntx.Principal,
+		Nonce:     ntx.Nonce,
+		Fee:       ntx.Fee(),
+		Added:     added,
+		Reason:    reason,
+	}
+}
+
+// NonceRange is an inclusive range of nonces.
+type NonceRange struct {
+	From, To uint64
+}
+
+// NonceGaps returns the next nonce in state for the account and the ranges of nonces that are missing
+// between it and the highest nonce of the pending transactions.
+// Transactions after a gap can't be applied until the gap is filled.
+func (c *Cache) NonceGaps(db sql.Executor, addr types.Address) (uint64, []NonceRange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var nextNonce uint64
+	if acc, ok := c.pending[addr]; ok {
+		nextNonce = acc.startNonce
+	} else {
+		nextNonce, _ = c.stateF(addr)
+	}
+	mtxs, err := transactions.GetAcctPendingFromNonce(db, addr, nextNonce)
+	if err != nil {
+		return 0, nil, fmt.Errorf("pending transactions for %s: %w", addr, err)
+	}
+	var (
+		gaps []NonceRange
+		next = nextNonce
+	)
+	// pending transactions are ordered by nonce, and there may be several for the same nonce
+	for _, mtx := range dropExpired(c.logger, mtxs, c.applied) {
+		if mtx.Nonce < next {
+			continue
+		}
+		if mtx.Nonce > next {
+			gaps = append(gaps, NonceRange{From: next, To: mtx.Nonce - 1})
+		}
+		if mtx.Nonce == math.MaxUint64 {
+			break
+		}
+		next = mtx.Nonce + 1
+	}
+	return nextNonce, gaps, nil
+}
+
 // checkApplyOrder returns an error if layers were not applied in order.
 func checkApplyOrder(logger log.Log, db *sql.Database, toApply types.LayerID) error {
 	lastApplied, err := layers.GetLastApplied(db)
@@ -1,7 +1,9 @@
 package txs
 
 import (
+	"bytes"
 	"context"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/events"
 	"github.com/spacemeshos/go-spacemesh/log/logtest"
 	"github.com/spacemeshos/go-spacemesh/signing"
 	"github.com/spacemeshos/go-spacemesh/sql"
@@ -988,6 +991,132 @@ func TestCache_Account_NotEvictedAfterApplyDueToNonceGap(t *testing.T) {
 	checkTXStateFromDB(t, tc.db, []*types.MeshTransaction{pendingInsufficient}, types.MEMPOOL)
 }
 
+func TestCache_Account_Add_ReplacementUnderpriced(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	const fee = defaultFee * 10
+	pending := &types.MeshTransaction{
+		Transaction: *newTx(t, ta.nonce, defaultAmount, fee, ta.signer),
+		Received:    time.Now(),
+	}
+	require.NoError(t, transactions.Add(tc.db, &pending.Transaction, pending.Received))
+	buildSingleAccountCache(t, tc, ta, []*types.MeshTransaction{pending})
+
+	for _, price := range []uint64{fee - 1, fee, fee + fee*replaceFeeBump/100 - 1} {
+		tx := newTx(t, ta.nonce, defaultAmount, price, ta.signer)
+		require.ErrorIs(t, tc.Add(context.Background(), tc.db, tx, time.Now(), false), errUnderpriced)
+		checkNoTX(t, tc.Cache, tx.ID)
+		checkTXNotInDB(t, tc.db, tx.ID)
+	}
+	checkTX(t, tc.Cache, pending.ID, 0, types.EmptyBlockID)
+
+	better := &types.MeshTransaction{
+		Transaction: *newTx(t, ta.nonce, defaultAmount, fee+fee*replaceFeeBump/100, ta.signer),
+		Received:    time.Now(),
+	}
+	require.NoError(t, tc.Add(context.Background(), tc.db, &better.Transaction, better.Received, false))
+	checkTX(t, tc.Cache, better.ID, 0, types.EmptyBlockID)
+	checkNoTX(t, tc.Cache, pending.ID)
+	checkMempool(t, tc.Cache, map[types.Address][]*types.MeshTransaction{ta.principal: {better}})
+}
+
+func TestCache_Account_Add_Expired(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	lid := types.LayerID(97)
+	require.NoError(t, layers.SetApplied(tc.db, lid, types.RandomBlockID()))
+	buildSingleAccountCache(t, tc, ta, nil)
+
+	expired := newTx(t, ta.nonce, defaultAmount, defaultFee, ta.signer)
+	expired.LayerLimits = types.LayerLimits{Max: lid.Uint32()}
+	require.ErrorIs(t, tc.Add(context.Background(), tc.db, expired, time.Now(), false), errExpired)
+	checkNoTX(t, tc.Cache, expired.ID)
+	checkTXNotInDB(t, tc.db, expired.ID)
+
+	valid := newTx(t, ta.nonce, defaultAmount, defaultFee, ta.signer)
+	valid.LayerLimits = types.LayerLimits{Max: lid.Add(1).Uint32()}
+	require.NoError(t, tc.Add(context.Background(), tc.db, valid, time.Now(), false))
+	checkTX(t, tc.Cache, valid.ID, 0, types.EmptyBlockID)
+}
+
+func TestCache_Account_ExpiredPrunedAfterApply(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		empty bool
+	}{
+		{desc: "block"},
+		{desc: "empty layer", empty: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cache, ta := createSingleAccountTestCache(t)
+			lid := types.LayerID(97)
+			mtxs := genTXs(t, ta.signer, ta.nonce, ta.nonce+2, time.Now())
+			mtxs[1].LayerLimits = types.LayerLimits{Max: lid.Uint32()}
+			saveTXs(t, cache.db, mtxs)
+			buildSingleAccountCache(t, cache, ta, mtxs)
+
+			next, gaps, err := cache.NonceGaps(cache.db, ta.principal)
+			require.NoError(t, err)
+			require.Equal(t, ta.nonce, next)
+			require.Empty(t, gaps)
+
+			require.NoError(t, layers.SetApplied(cache.db, lid.Sub(1), types.RandomBlockID()))
+			var (
+				bid     = types.EmptyBlockID
+				results []types.TransactionWithResult
+				pending = []*types.MeshTransaction{mtxs[0], mtxs[2]}
+			)
+			if !tc.empty {
+				bid = types.BlockID{1, 2, 3}
+				results = makeResults(lid, bid, mtxs[0].Transaction)
+				ta.nonce++
+				ta.balance -= mtxs[0].Spending()
+				pending = mtxs[2:]
+			}
+			require.NoError(t, cache.ApplyLayer(context.Background(), cache.db, lid, bid, results, nil))
+			// the tx at the next nonce expired, later nonce stays until the gap is filled
+			checkNoTX(t, cache.Cache, mtxs[1].ID)
+			checkMempool(t, cache.Cache, map[types.Address][]*types.MeshTransaction{ta.principal: pending})
+			checkTXStateFromDB(t, cache.db, mtxs[1:], types.MEMPOOL)
+
+			next, gaps, err = cache.NonceGaps(cache.db, ta.principal)
+			require.NoError(t, err)
+			require.Equal(t, ta.nonce, next)
+			require.Equal(t, []NonceRange{{From: mtxs[1].Nonce, To: mtxs[1].Nonce}}, gaps)
+		})
+	}
+}
+
+func TestCache_NonceGaps(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	mtxs := genTXs(t, ta.signer, ta.nonce, ta.nonce+6, time.Now())
+	// the same nonce twice
+	mtxs[2] = newMeshTX(t, ta.nonce+1, ta.signer, defaultAmount, time.Now())
+	mtxs = append(mtxs[:3], mtxs[5:]...)
+	saveTXs(t, tc.db, mtxs)
+
+	next, gaps, err := tc.NonceGaps(tc.db, ta.principal)
+	require.NoError(t, err)
+	require.Equal(t, ta.nonce, next)
+	require.Equal(t, []NonceRange{{From: ta.nonce + 2, To: ta.nonce + 4}}, gaps)
+
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	next, gaps, err = tc.NonceGaps(tc.db, types.GenerateAddress(signer.PublicKey().Bytes()))
+	require.NoError(t, err)
+	require.Zero(t, next)
+	require.Empty(t, gaps)
+
+	// the highest nonce doesn't enumerate the gap
+	last := newMeshTX(t, math.MaxUint64, ta.signer, defaultAmount, time.Now())
+	saveTXs(t, tc.db, []*types.MeshTransaction{last})
+	next, gaps, err = tc.NonceGaps(tc.db, ta.principal)
+	require.NoError(t, err)
+	require.Equal(t, ta.nonce, next)
+	require.Equal(t, []NonceRange{
+		{From: ta.nonce + 2, To: ta.nonce + 4},
+		{From: ta.nonce + 7, To: math.MaxUint64 - 1},
+	}, gaps)
+}
+
 func TestCache_BuildFromScratch(t *testing.T) {
 	tc, accounts := createCache(t, 1000)
 	mtxs := make(map[types.Address][]*types.MeshTransaction)
@@ -1359,3 +1488,121 @@ func TestCache_GetProjection(t *testing.T) {
 		require.Equal(t, expectedBalance, balance)
 	}
 }
+
+func TestCache_Pending(t *testing.T) {
+	tc, accounts := createCache(t, 10)
+	mtxsByAccount := buildSmallCache(t, tc, accounts, 10)
+
+	pending := tc.Pending()
+	require.Len(t, pending, len(mtxsByAccount))
+	for i := 1; i < len(pending); i++ {
+		require.Negative(t, bytes.Compare(pending[i-1].Address[:], pending[i].Address[:]))
+	}
+	for _, acc := range pending {
+		mtxs := mtxsByAccount[acc.Address]
+		nonce, balance := tc.GetProjection(acc.Address)
+		require.Equal(t, nonce, acc.NextNonce)
+		require.Equal(t, balance, acc.Balance)
+		require.Len(t, acc.Transactions, len(mtxs))
+		for i, mtx := range mtxs {
+			require.Equal(t, mtx.ID, acc.Transactions[i].ID)
+			require.Equal(t, mtx.Nonce, acc.Transactions[i].Nonce)
+		}
+	}
+
+	for principal := range mtxsByAccount {
+		selected := tc.Pending(principal, types.Address{1})
+		require.Len(t, selected, 1)
+		require.Equal(t, principal, selected[0].Address)
+		break
+	}
+}
+
+func TestCache_Evict(t *testing.T) {
+	events.InitializeReporter()
+	t.Cleanup(events.CloseEventReporter)
+	sub, err := events.Subscribe[events.EventMempool]()
+	require.NoError(t, err)
+	t.Cleanup(sub.Close)
+
+	tc, ta := createSingleAccountTestCache(t)
+	mtxs := genAndSaveTXs(t, tc.db, ta.signer, ta.nonce, ta.nonce+2, time.Now())
+	buildSingleAccountCache(t, tc, ta, mtxs)
+	lid := types.LayerID(10)
+	require.NoError(t, tc.LinkTXsWithProposal(tc.db, lid, types.ProposalID{1}, []types.TransactionID{mtxs[0].ID}))
+
+	// the replacement is reported as added, and the replaced transaction as removed
+	better := newMeshTX(t, ta.nonce+2, ta.signer, defaultAmount, time.Now())
+	better.GasPrice = defaultFee * 2
+	require.NoError(t, tc.Add(context.Background(), tc.db, &better.Transaction, better.Received, false))
+	for _, expected := range []events.EventMempool{
+		{ID: better.ID, Principal: ta.principal, Nonce: better.Nonce, Fee: better.Fee(), Added: true},
+		{ID: mtxs[2].ID, Principal: ta.principal, Nonce: mtxs[2].Nonce, Fee: mtxs[2].Fee(), Reason: events.MempoolReplaced},
+	} {
+		select {
+		case ev := <-sub.Out():
+			require.Equal(t, expected, ev)
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for event")
+		}
+	}
+
+	evicted, err := tc.Evict(context.Background(), tc.db, []types.TransactionID{
+		mtxs[0].ID, // included in the proposal
+		mtxs[1].ID,
+		mtxs[2].ID, // not in the cache, but still pending in the database
+		types.RandomTransactionID(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactionID{mtxs[1].ID, mtxs[2].ID}, evicted)
+	checkTX(t, tc.Cache, mtxs[0].ID, lid, types.EmptyBlockID)
+	checkNoTX(t, tc.Cache, mtxs[1].ID)
+	checkTX(t, tc.Cache, better.ID, 0, types.EmptyBlockID)
+	// evicted transactions are kept in the database
+	checkTXStateFromDB(t, tc.db, mtxs[1:], types.MEMPOOL)
+	checkMempool(t, tc.Cache, map[types.Address][]*types.MeshTransaction{ta.principal: {better}})
+	select {
+	case ev := <-sub.Out():
+		require.Equal(t, events.EventMempool{
+			ID: mtxs[1].ID, Principal: ta.principal, Nonce: mtxs[1].Nonce, Fee: mtxs[1].Fee(), Reason: events.MempoolEvicted,
+		}, ev)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+	}
+	select {
+	case ev := <-sub.Out():
+		require.FailNow(t, "unexpected event", "%+v", ev)
+	default:
+	}
+
+	// evicted transactions are not loaded from the database when the cache is rebuilt
+	require.NoError(t, tc.buildFromScratch(tc.db))
+	checkNoTX(t, tc.Cache, mtxs[1].ID)
+	checkNoTX(t, tc.Cache, mtxs[2].ID)
+	evicted, err = tc.Evict(context.Background(), tc.db, []types.TransactionID{mtxs[1].ID})
+	require.NoError(t, err)
+	require.Empty(t, evicted)
+
+	// and forgotten once the nonce is applied
+	ta.nonce = mtxs[2].Nonce + 1
+	require.NoError(t, tc.ApplyLayer(context.Background(), tc.db, types.LayerID(1), types.BlockID{1}, nil, nil))
+	require.Empty(t, tc.evicted)
+}
+
+func TestCache_ResetBeforeFirstApplied(t *testing.T) {
+	tc, ta := createSingleAccountTestCache(t)
+	mtxs := genAndSaveTXs(t, tc.db, ta.signer, ta.nonce, ta.nonce+2, time.Now())
+	buildSingleAccountCache(t, tc, ta, mtxs)
+	lid := types.LayerID(10)
+	require.NoError(t, tc.LinkTXsWithProposal(tc.db, lid, types.ProposalID{1}, []types.TransactionID{mtxs[0].ID}))
+
+	// account is reloaded from the database before any layer is applied
+	tc.mu.Lock()
+	require.Zero(t, tc.applied)
+	accCache := tc.pending[ta.principal]
+	require.NoError(t, accCache.resetAfterApply(tc.logger, tc.db, accCache.startNonce, accCache.startBalance, tc.applied))
+	tc.mu.Unlock()
+
+	checkTX(t, tc.Cache, mtxs[0].ID, lid, types.EmptyBlockID)
+	checkMempool(t, tc.Cache, map[types.Address][]*types.MeshTransaction{ta.principal: mtxs[1:]})
+}
@@ -55,6 +55,7 @@ type ConservativeState struct {
 	cfg    CSConfig
 	db     *sql.Database
 	cache  *Cache
+	fees   *feeHistory
 }
 
 // NewConservativeState returns a ConservativeState.
@@ -64,6 +65,7 @@ func NewConservativeState(state vmState, db *sql.Database, opts ...ConservativeS
 		cfg:     defaultCSConfig(),
 		logger:  log.NewNop(),
 		db:      db,
+		fees:    newFeeHistory(),
 	}
 	for _, opt := range opts {
 		opt(cs)
@@ -87,7 +89,7 @@ func (cs *ConservativeState) getState(addr types.Address) (uint64, uint64) {
 // SelectProposalTXs picks a specific number of random txs for miner to pack in a proposal.
 func (cs *ConservativeState) SelectProposalTXs(lid types.LayerID, numEligibility int) []types.TransactionID {
 	logger := cs.logger.WithFields(lid)
-	mi := newMempoolIterator(logger, cs.cache, cs.cfg.BlockGasLimit)
+	mi := newMempoolIterator(logger, cs.cache, lid, cs.cfg.BlockGasLimit)
 	predictedBlock, byAddrAndNonce := mi.PopAll()
 	numTXs := numEligibility * cs.cfg.NumTXsPerProposal
 	return getProposalTXs(logger.WithFields(lid), numTXs, predictedBlock, byAddrAndNonce)
@@ -123,7 +125,12 @@ func (cs *ConservativeState) AddToCache(ctx context.Context, tx *types.Transacti
 
 // RevertCache reverts the conservative cache to the given layer.
 func (cs *ConservativeState) RevertCache(revertTo types.LayerID) error {
-	return cs.cache.RevertToLayer(cs.db, revertTo)
+	cs.fees.revert(revertTo)
+	if err := cs.cache.RevertToLayer(cs.db, revertTo); err != nil {
+		return err
+	}
+	cs.fees.setMempool(newMempoolStats(cs.cache.Pending(), cs.cfg.BlockGasLimit))
+	return nil
 }
 
 func (cs *ConservativeState) UpdateCache(
@@ -138,6 +145,8 @@ func (cs *ConservativeState) UpdateCache(
 		return err
 	}
 	cacheApplyDuration.Observe(float64(time.Since(t0)))
+	cs.fees.add(newLayerFees(lid, cs.cfg.BlockGasLimit, results))
+	cs.fees.setMempool(newMempoolStats(cs.cache.Pending(), cs.cfg.BlockGasLimit))
 	return nil
 }
 
@@ -147,6 +156,64 @@ func (cs *ConservativeState) GetProjection(addr types.Address) (uint64, uint64)
 	return cs.cache.GetProjection(addr)
 }
 
+// NonceGaps returns the next nonce in state for the account and the nonces missing
+// before its pending transactions.
+func (cs *ConservativeState) NonceGaps(addr types.Address) (uint64, []NonceRange, error) {
+	return cs.cache.NonceGaps(cs.db, addr)
+}
+
+// Pending returns accounts with transactions in the mempool.
+func (cs *ConservativeState) Pending(addrs ...types.Address) []PendingAccount {
+	return cs.cache.Pending(addrs...)
+}
+
+// Evict removes pending transactions from the mempool until restart.
+func (cs *ConservativeState) Evict(ctx context.Context, tids []types.TransactionID) ([]types.TransactionID, error) {
+	return cs.cache.Evict(ctx, cs.db, tids)
+}
+
+// LayerFees returns fee statistics for the applied layers in [from, to].
+func (cs *ConservativeState) LayerFees(from, to types.LayerID) ([]*LayerFees, error) {
+	return layerFees(cs.db, cs.fees, cs.cfg.BlockGasLimit, from, to)
+}
+
+// MempoolFees returns fee statistics for the transactions in the mempool.
+// Statistics are computed when a layer is applied and are empty until then.
+func (cs *ConservativeState) MempoolFees() *MempoolFees {
+	stats := cs.fees.getMempool()
+	if stats == nil {
+		return &MempoolFees{}
+	}
+	return stats.fees
+}
+
+// EstimateFee returns the gas price for a transaction to be applied within target layers.
+// It is the highest of the price that outbids the mempool for the gas available in target blocks
+// and the price that was sufficient for inclusion in recent layers.
+// The mempool part is computed when a layer is applied.
+func (cs *ConservativeState) EstimateFee(target uint32) (uint64, error) {
+	if target == 0 || target > MaxFeeTarget {
+		return 0, fmt.Errorf("target should be in [1, %d]: %d", MaxFeeTarget, target)
+	}
+	applied, err := layers.GetLastApplied(cs.db)
+	if err != nil {
+		return 0, fmt.Errorf("last applied layer: %w", err)
+	}
+	from := types.LayerID(0)
+	if applied.Uint32() >= feeEstimateLayers {
+		from = applied.Sub(feeEstimateLayers - 1)
+	}
+	history, err := layerFees(cs.db, cs.fees, cs.cfg.BlockGasLimit, from, applied)
+	if err != nil {
+		return 0, err
+	}
+	price := historyGasPrice(history, target)
+	if stats := cs.fees.getMempool(); stats != nil {
+		price = max(price, stats.gasPrices[target-1])
+	}
+	return price, nil
+}
+
 // LinkTXsWithProposal associates the transactions to a proposal.
 func (cs *ConservativeState) LinkTXsWithProposal(lid types.LayerID, pid types.ProposalID, tids []types.TransactionID) error {
 	return cs.cache.LinkTXsWithProposal(cs.db, lid, pid, tids)
@@ -0,0 +1,281 @@
+package txs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+const (
+	// feeHistorySize is the number of recently applied layers with fee statistics kept in memory.
+	feeHistorySize = 100
+	// feeEstimateLayers is the number of recently applied layers used to estimate fees.
+	feeEstimateLayers = 20
+	// congestedUtilization is the block gas utilization when the layer is considered congested.
+	// Transactions with lower gas price than the lowest included may not fit into such layer.
+	congestedUtilization = 0.9
+	// MaxFeeTarget is the maximal number of layers for fee estimation.
+	MaxFeeTarget = feeEstimateLayers
+	// MinGasPrice is the lowest gas price accepted by the mempool.
+	MinGasPrice = uint64(1)
+)
+
+// FeePercentiles are percentiles of gas prices in fee statistics.
+var FeePercentiles = []int{10, 25, 50, 75, 90}
+
+// LayerFees summarizes gas prices paid by transactions applied in the layer.
+type LayerFees struct {
+	Layer        types.LayerID
+	Transactions int
+	GasUsed      uint64
+	// GasLimit is the block gas limit configured on this node.
+	GasLimit uint64
+	// MinGasPrice is the lowest gas price of the applied transactions.
+	MinGasPrice uint64
+	// GasPrices are gas prices at FeePercentiles. Empty if no transactions were applied.
+	GasPrices []uint64
+}
+
+// Utilization is the ratio between used gas and the block gas limit.
+func (f *LayerFees) Utilization() float64 {
+	if f.GasLimit == 0 {
+		return 0
+	}
+	return float64(f.GasUsed) / float64(f.GasLimit)
+}
+
+// clearingGasPrice is the lowest gas price that was sufficient to be included in the layer.
+func (f *LayerFees) clearingGasPrice() uint64 {
+	if f.Transactions == 0 || f.Utilization() < congestedUtilization {
+		return MinGasPrice
+	}
+	return f.MinGasPrice
+}
+
+func newLayerFees(lid types.LayerID, gasLimit uint64, results []types.TransactionWithResult) *LayerFees {
+	fees := &LayerFees{Layer: lid, GasLimit: gasLimit}
+	prices := make([]uint64, 0, len(results))
+	for i := range results {
+		if results[i].TxHeader == nil {
+			continue
+		}
+		fees.Transactions++
+		fees.GasUsed += results[i].Gas
+		prices = append(prices, results[i].GasPrice)
+	}
+	if len(prices) > 0 {
+		fees.GasPrices = percentiles(prices, FeePercentiles...)
+		fees.MinGasPrice = prices[0]
+	}
+	return fees
+}
+
+// MempoolFees summarizes gas prices of the transactions in the mempool.
+type MempoolFees struct {
+	Transactions int
+	// MaxGas is the sum of max gas of the transactions.
+	MaxGas uint64
+	// GasPrices are gas prices at FeePercentiles. Empty if the mempool is empty.
+	GasPrices []uint64
+}
+
+// percentiles sorts prices and returns them at requested percentiles using nearest-rank method.
+func percentiles(prices []uint64, pcts ...int) []uint64 {
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	rst := make([]uint64, 0, len(pcts))
+	for _, pct := range pcts {
+		rank := int(math.Ceil(float64(pct) / 100 * float64(len(prices))))
+		if rank < 1 {
+			rank = 1
+		}
+		rst = append(rst, prices[rank-1])
+	}
+	return rst
+}
+
+// mempoolStats are fee statistics of the mempool computed when a layer is applied.
+type mempoolStats struct {
+	fees *MempoolFees
+	// gasPrices are the prices that outbid the mempool for targets from 1 to MaxFeeTarget.
+	gasPrices []uint64
+}
+
+func newMempoolStats(accounts []PendingAccount, gasLimit uint64) *mempoolStats {
+	return &mempoolStats{
+		fees:      mempoolFees(accounts),
+		gasPrices: mempoolGasPrices(accounts, gasLimit),
+	}
+}
+
+// feeHistory keeps fee statistics for recently applied layers and the mempool.
+type feeHistory struct {
+	mu      sync.Mutex
+	layers  map[types.LayerID]*LayerFees
+	mempool *mempoolStats
+}
+
+func newFeeHistory() *feeHistory {
+	return &feeHistory{layers: make(map[types.LayerID]*LayerFees)}
+}
+
+func (h *feeHistory) add(fees *LayerFees) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.layers[fees.Layer] = fees
+	if fees.Layer.Uint32() > feeHistorySize {
+		delete(h.layers, fees.Layer.Sub(feeHistorySize))
+	}
+	layerGasUtilization.Set(fees.Utilization())
+	for i, price := range fees.GasPrices {
+		layerGasPrice.WithLabelValues(fmt.Sprint(FeePercentiles[i])).Set(float64(price))
+	}
+}
+
+func (h *feeHistory) get(lid types.LayerID) *LayerFees {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.layers[lid]
+}
+
+func (h *feeHistory) setMempool(stats *mempoolStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mempool = stats
+}
+
+func (h *feeHistory) getMempool() *mempoolStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.mempool
+}
+
+func (h *feeHistory) revert(to types.LayerID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for lid := range h.layers {
+		if lid.After(to) {
+			delete(h.layers, lid)
+		}
+	}
+}
+
+// layerFees returns fee statistics for applied layers in [from, to].
+func layerFees(db sql.Executor, history *feeHistory, gasLimit uint64, from, to types.LayerID) ([]*LayerFees, error) {
+	applied, err := layers.GetLastApplied(db)
+	if err != nil {
+		return nil, fmt.Errorf("last applied layer: %w", err)
+	}
+	if to.After(applied) {
+		to = applied
+	}
+	if from.After(to) {
+		return nil, nil
+	}
+	rst := make([]*LayerFees, 0, to.Difference(from)+1)
+	var missing []types.LayerID
+	for lid := from; !lid.After(to); lid = lid.Add(1) {
+		fees := history.get(lid)
+		if fees == nil {
+			missing = append(missing, lid)
+		}
+		rst = append(rst, fees)
+	}
+	if len(missing) == 0 {
+		return rst, nil
+	}
+	// layers that were applied before the node restarted are loaded from the database
+	start, end := missing[0], missing[len(missing)-1]
+	results := make(map[types.LayerID][]types.TransactionWithResult, len(missing))
+	if err := transactions.IterateResults(db, transactions.ResultsFilter{Start: &start, End: &end},
+		func(rst *types.TransactionWithResult) bool {
+			results[rst.Layer] = append(results[rst.Layer], *rst)
+			return true
+		}); err != nil {
+		return nil, err
+	}
+	for i := range rst {
+		if rst[i] == nil {
+			lid := from.Add(uint32(i))
+			rst[i] = newLayerFees(lid, gasLimit, results[lid])
+		}
+	}
+	return rst, nil
+}
+
+func mempoolFees(accounts []PendingAccount) *MempoolFees {
+	fees := &MempoolFees{}
+	var prices []uint64
+	for _, acc := range accounts {
+		for _, tx := range acc.Transactions {
+			fees.Transactions++
+			fees.MaxGas = saturatingAdd(fees.MaxGas, tx.MaxGas)
+			prices = append(prices, tx.GasPrice)
+		}
+	}
+	if len(prices) > 0 {
+		fees.GasPrices = percentiles(prices, FeePercentiles...)
+	}
+	return fees
+}
+
+// mempoolGasPrices returns the gas prices that outbid enough pending transactions for a new
+// transaction to fit into blocks of the next target layers, for targets from 1 to MaxFeeTarget.
+func mempoolGasPrices(accounts []PendingAccount, gasLimit uint64) []uint64 {
+	var txs []*NanoTX
+	for i := range accounts {
+		for j := range accounts[i].Transactions {
+			txs = append(txs, &accounts[i].Transactions[j])
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].GasPrice > txs[j].GasPrice })
+	rst := make([]uint64, 0, MaxFeeTarget)
+	var (
+		gas  uint64
+		last int
+	)
+	for target := uint64(1); target <= MaxFeeTarget; target++ {
+		capacity := saturatingMul(gasLimit, target)
+		for ; last < len(txs) && gas < capacity; last++ {
+			gas = saturatingAdd(gas, txs[last].MaxGas)
+		}
+		if last > 0 && gas >= capacity {
+			rst = append(rst, txs[last-1].GasPrice+1)
+		} else {
+			rst = append(rst, MinGasPrice)
+		}
+	}
+	return rst
+}
+
+// historyGasPrice returns the gas price that was sufficient for inclusion in at least
+// one out of target recent layers.
+func historyGasPrice(history []*LayerFees, target uint32) uint64 {
+	if len(history) == 0 {
+		return MinGasPrice
+	}
+	prices := make([]uint64, 0, len(history))
+	for _, fees := range history {
+		prices = append(prices, fees.clearingGasPrice())
+	}
+	return percentiles(prices, 100/int(target))[0]
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+func saturatingMul(a, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
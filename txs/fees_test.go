@@ -0,0 +1,214 @@
+package txs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/signing"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/layers"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+func TestPercentiles(t *testing.T) {
+	for _, tc := range []struct {
+		desc   string
+		prices []uint64
+		pcts   []int
+		expect []uint64
+	}{
+		{
+			desc:   "single",
+			prices: []uint64{7},
+			pcts:   FeePercentiles,
+			expect: []uint64{7, 7, 7, 7, 7},
+		},
+		{
+			desc:   "unsorted",
+			prices: []uint64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5},
+			pcts:   FeePercentiles,
+			expect: []uint64{1, 3, 5, 8, 9},
+		},
+		{
+			desc:   "bounds",
+			prices: []uint64{3, 1, 2},
+			pcts:   []int{0, 100},
+			expect: []uint64{1, 3},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expect, percentiles(tc.prices, tc.pcts...))
+		})
+	}
+}
+
+func pendingWithPrices(gas uint64, prices ...uint64) []PendingAccount {
+	acc := PendingAccount{}
+	for _, price := range prices {
+		acc.Transactions = append(acc.Transactions, NanoTX{
+			TxHeader: types.TxHeader{MaxGas: gas, GasPrice: price},
+		})
+	}
+	return []PendingAccount{acc}
+}
+
+func TestMempoolGasPrice(t *testing.T) {
+	pending := pendingWithPrices(defaultGas, 5, 1, 4, 2, 3)
+	// mempool doesn't fill the blocks
+	prices := mempoolGasPrices(pending, 10*defaultGas)
+	require.Len(t, prices, MaxFeeTarget)
+	require.Equal(t, MinGasPrice, prices[0])
+	require.Equal(t, MinGasPrice, mempoolGasPrices(pending, 3*defaultGas)[1])
+	// three transactions with the highest price fill a block
+	require.Equal(t, uint64(4), mempoolGasPrices(pending, 3*defaultGas)[0])
+	prices = mempoolGasPrices(pending, defaultGas)
+	require.Equal(t, []uint64{6, 5, 4, 3, 2, MinGasPrice}, prices[:6])
+	require.Equal(t, MinGasPrice, mempoolGasPrices(nil, defaultGas)[0])
+}
+
+func TestHistoryGasPrice(t *testing.T) {
+	congested := func(price uint64) *LayerFees {
+		return &LayerFees{Transactions: 1, GasUsed: 100, GasLimit: 100, MinGasPrice: price}
+	}
+	history := []*LayerFees{
+		congested(10),
+		{Transactions: 1, GasUsed: 10, GasLimit: 100, MinGasPrice: 100},
+		congested(20),
+		{},
+	}
+	require.Equal(t, uint64(20), historyGasPrice(history, 1))
+	require.Equal(t, uint64(1), historyGasPrice(history, 2))
+	require.Equal(t, uint64(10), historyGasPrice(history[:3], 2))
+	require.Equal(t, MinGasPrice, historyGasPrice(nil, 1))
+}
+
+func addResults(tb testing.TB, db *sql.Database, lid types.LayerID, gas uint64, prices ...uint64) {
+	tb.Helper()
+	signer, err := signing.NewEdSigner()
+	require.NoError(tb, err)
+	require.NoError(tb, db.WithTx(context.Background(), func(dtx *sql.Tx) error {
+		for i, price := range prices {
+			tx := newTx(tb, uint64(i), defaultAmount, price, signer)
+			require.NoError(tb, transactions.Add(dtx, tx, time.Now()))
+			require.NoError(tb, transactions.AddResult(dtx, tx.ID, &types.TransactionResult{
+				Layer: lid,
+				Gas:   gas,
+			}))
+		}
+		return nil
+	}))
+	require.NoError(tb, layers.SetApplied(db, lid, types.BlockID{byte(lid.Uint32())}))
+}
+
+func TestLayerFees(t *testing.T) {
+	tcs := createTestState(t, 10*defaultGas)
+	addResults(t, tcs.db, 1, defaultGas, 3, 1, 2)
+	require.NoError(t, layers.SetApplied(tcs.db, 2, types.EmptyBlockID))
+	addResults(t, tcs.db, 3, 2*defaultGas, 5)
+
+	fees, err := tcs.LayerFees(1, 10)
+	require.NoError(t, err)
+	require.Len(t, fees, 3)
+	require.Equal(t, &LayerFees{
+		Layer:        1,
+		Transactions: 3,
+		GasUsed:      3 * defaultGas,
+		GasLimit:     10 * defaultGas,
+		MinGasPrice:  1,
+		GasPrices:    []uint64{1, 1, 2, 3, 3},
+	}, fees[0])
+	require.InDelta(t, 0.3, fees[0].Utilization(), 1e-9)
+	require.Equal(t, &LayerFees{Layer: 2, GasLimit: 10 * defaultGas}, fees[1])
+	require.Equal(t, 1, fees[2].Transactions)
+	require.Equal(t, 2*defaultGas, fees[2].GasUsed)
+
+	fees, err = tcs.LayerFees(4, 10)
+	require.NoError(t, err)
+	require.Empty(t, fees)
+}
+
+func TestLayerFees_UpdateCache(t *testing.T) {
+	tcs := createTestState(t, 2*defaultGas)
+	lid := types.LayerID(1)
+	_, txs := addBatch(t, tcs, 2)
+	executed := make([]types.TransactionWithResult, 0, len(txs))
+	for _, tx := range txs {
+		executed = append(executed, types.TransactionWithResult{
+			Transaction:       *tx,
+			TransactionResult: types.TransactionResult{Layer: lid, Gas: defaultGas},
+		})
+		tcs.mvm.EXPECT().GetBalance(tx.Principal).Return(defaultBalance, nil).AnyTimes()
+		tcs.mvm.EXPECT().GetNonce(tx.Principal).Return(nonce, nil).AnyTimes()
+	}
+	require.NoError(t, tcs.UpdateCache(context.Background(), lid, types.BlockID{1}, executed, nil))
+	require.NoError(t, layers.SetApplied(tcs.db, lid, types.BlockID{1}))
+
+	// results are not in the database, statistics are served from memory
+	fees, err := tcs.LayerFees(lid, lid)
+	require.NoError(t, err)
+	require.Len(t, fees, 1)
+	require.Equal(t, 2, fees[0].Transactions)
+	require.Equal(t, 1.0, fees[0].Utilization())
+	require.Equal(t, defaultFee, fees[0].clearingGasPrice())
+
+	require.NoError(t, tcs.RevertCache(lid.Sub(1)))
+	fees, err = tcs.LayerFees(lid, lid)
+	require.NoError(t, err)
+	require.Len(t, fees, 1)
+	require.Zero(t, fees[0].Transactions)
+}
+
+func TestEstimateFee(t *testing.T) {
+	tcs := createTestState(t, 2*defaultGas)
+	_, err := tcs.EstimateFee(0)
+	require.Error(t, err)
+	_, err = tcs.EstimateFee(MaxFeeTarget + 1)
+	require.Error(t, err)
+
+	fee, err := tcs.EstimateFee(1)
+	require.NoError(t, err)
+	require.Equal(t, MinGasPrice, fee)
+
+	// recent layers are congested
+	addResults(t, tcs.db, 1, defaultGas, 7, 8)
+	addResults(t, tcs.db, 2, defaultGas, 3, 9)
+	fee, err = tcs.EstimateFee(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), fee)
+	fee, err = tcs.EstimateFee(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), fee)
+
+	// mempool has more transactions than fit into a block
+	signer, err := signing.NewEdSigner()
+	require.NoError(t, err)
+	principal := types.GenerateAddress(signer.PublicKey().Bytes())
+	tcs.mvm.EXPECT().GetBalance(principal).Return(defaultBalance, nil).AnyTimes()
+	tcs.mvm.EXPECT().GetNonce(principal).Return(nonce, nil).AnyTimes()
+	for i := uint64(0); i < 5; i++ {
+		tx := newTx(t, nonce+i, defaultAmount, defaultFee, signer)
+		require.NoError(t, tcs.AddToCache(context.Background(), tx, time.Now()))
+	}
+	// mempool statistics are updated when the next layer is applied
+	fee, err = tcs.EstimateFee(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), fee)
+	require.Zero(t, tcs.MempoolFees().Transactions)
+	require.NoError(t, tcs.UpdateCache(context.Background(), 3, types.EmptyBlockID, nil, nil))
+
+	fee, err = tcs.EstimateFee(2)
+	require.NoError(t, err)
+	require.Equal(t, defaultFee+1, fee)
+	fee, err = tcs.EstimateFee(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), fee)
+
+	mfees := tcs.MempoolFees()
+	require.Equal(t, 5, mfees.Transactions)
+	require.Equal(t, 5*defaultGas, mfees.MaxGas)
+	require.Equal(t, []uint64{defaultFee, defaultFee, defaultFee, defaultFee, defaultFee}, mfees.GasPrices)
+}
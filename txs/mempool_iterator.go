@@ -78,9 +78,29 @@ type mempoolIterator struct {
 	txs          map[types.Address][]*NanoTX
 }
 
-// newMempoolIterator builds and returns a mempoolIterator.
-func newMempoolIterator(logger log.Log, cs conStateCache, gasLimit uint64) *mempoolIterator {
+// newMempoolIterator builds and returns a mempoolIterator for transactions that may be applied in the layer.
+func newMempoolIterator(logger log.Log, cs conStateCache, lid types.LayerID, gasLimit uint64) *mempoolIterator {
 	txs := cs.GetMempool(logger)
+	for addr, ntxs := range txs {
+		// transactions are ordered by nonce. transactions after the one that can't be applied yet
+		// are not applicable either
+		for i, ntx := range ntxs {
+			if lid.Uint32() < ntx.LayerLimits.Min {
+				logger.With().Debug("tx not applicable yet",
+					ntx.ID,
+					ntx.Principal,
+					log.Uint64("nonce", ntx.Nonce),
+					log.Uint32("min_layer", ntx.LayerLimits.Min))
+				ntxs = ntxs[:i]
+				break
+			}
+		}
+		if len(ntxs) == 0 {
+			delete(txs, addr)
+		} else {
+			txs[addr] = ntxs
+		}
+	}
 	mi := &mempoolIterator{
 		logger:       logger,
 		gasRemaining: gasLimit,
@@ -84,7 +84,7 @@ func TestPopAll(t *testing.T) {
 	mockCache := NewMockconStateCache(ctrl)
 	mockCache.EXPECT().GetMempool(gomock.Any()).Return(mempool)
 	gasLimit := uint64(3)
-	mi := newMempoolIterator(logtest.New(t), mockCache, gasLimit)
+	mi := newMempoolIterator(logtest.New(t), mockCache, types.LayerID(10), gasLimit)
 	testPopAll(t, mi, expected[:gasLimit])
 	require.NotEmpty(t, mempool)
 }
@@ -98,7 +98,7 @@ func TestPopAll_SkipSomeGasTooHigh(t *testing.T) {
 	// make the 2nd one too expensive to pick, therefore invalidated all txs from addr0
 	orderedByFee[1].MaxGas = 10
 	expected := []*NanoTX{orderedByFee[0], orderedByFee[4], orderedByFee[5]}
-	mi := newMempoolIterator(logtest.New(t), mockCache, gasLimit)
+	mi := newMempoolIterator(logtest.New(t), mockCache, types.LayerID(10), gasLimit)
 	testPopAll(t, mi, expected)
 	require.NotEmpty(t, mempool)
 }
@@ -109,7 +109,30 @@ func TestPopAll_ExhaustMempool(t *testing.T) {
 	mockCache := NewMockconStateCache(ctrl)
 	mockCache.EXPECT().GetMempool(gomock.Any()).Return(mempool)
 	gasLimit := uint64(100)
-	mi := newMempoolIterator(logtest.New(t), mockCache, gasLimit)
+	mi := newMempoolIterator(logtest.New(t), mockCache, types.LayerID(10), gasLimit)
 	testPopAll(t, mi, expected)
 	require.Empty(t, mempool)
 }
+
+func TestPopAll_LayerLimitsMin(t *testing.T) {
+	mempool, expected := makeMempool()
+	ctrl := gomock.NewController(t)
+	mockCache := NewMockconStateCache(ctrl)
+	mockCache.EXPECT().GetMempool(gomock.Any()).Return(mempool)
+	lid := types.LayerID(10)
+	// the 2nd tx of addr0 can't be applied before the next layer, so the 3rd isn't applicable either
+	addr0 := expected[1].Principal
+	mempool[addr0][1].LayerLimits = types.LayerLimits{Min: lid.Add(1).Uint32()}
+	// the 1st tx of addr1 can be applied in the layer
+	mempool[expected[5].Principal][0].LayerLimits = types.LayerLimits{Min: lid.Uint32()}
+	mi := newMempoolIterator(logtest.New(t), mockCache, lid, 100)
+	testPopAll(t, mi, []*NanoTX{
+		expected[0],
+		expected[1],
+		expected[4],
+		expected[5],
+		expected[6],
+		expected[7],
+		expected[8],
+	})
+}
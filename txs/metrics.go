@@ -24,6 +24,8 @@ const (
 	mempool         = "mempool"
 	balanceTooSmall = "balance"
 	tooManyNonce    = "too_many"
+	underpriced     = "underpriced"
+	expired         = "expired"
 	accepted        = "ok"
 )
 
@@ -76,3 +78,18 @@ var (
 		prometheus.ExponentialBuckets(10_000_000, 2, 10),
 	).WithLabelValues()
 )
+
+var (
+	layerGasPrice = metrics.NewGauge(
+		"layer_gas_price",
+		namespace,
+		"gas price of transactions in the last applied layer at percentile",
+		[]string{"percentile"},
+	)
+	layerGasUtilization = metrics.NewGauge(
+		"layer_gas_utilization",
+		namespace,
+		"ratio between gas used in the last applied layer and the block gas limit",
+		[]string{},
+	).WithLabelValues()
+)
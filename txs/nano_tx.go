@@ -64,6 +64,12 @@ func (n *NanoTX) Better(other *NanoTX, blockSeed []byte) bool {
 	return false
 }
 
+// Replaces returns true if this transaction pays enough to replace `other` with the same nonce
+// in the mempool. The fee must be higher at least by replaceFeeBump percents.
+func (n *NanoTX) Replaces(other *NanoTX) bool {
+	return n.Fee() > other.Fee() && n.Fee()-other.Fee() >= other.Fee()/100*replaceFeeBump
+}
+
 // UpdateLayerMaybe updates the layer of a transaction if it's lower than the current value.
 func (n *NanoTX) UpdateLayerMaybe(lid types.LayerID, bid types.BlockID) {
 	if n.Layer == 0 || lid.Before(n.Layer) {